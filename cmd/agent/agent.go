@@ -20,12 +20,14 @@ import (
 
 	"github.com/gimlet-io/gimlet-cli/cmd/agent/config"
 	"github.com/gimlet-io/gimlet-cli/pkg/agent"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
 	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -64,15 +66,9 @@ func main() {
 		panic(fmt.Errorf("please provide the ENV variable"))
 	}
 
-	envName, namespace, err := parseEnvString(config.Env)
+	envs, err := parseEnvString(config.Env)
 	if err != nil {
-		panic(fmt.Errorf("invalid ENV variable. Format is env1=ns1,env2=ns2"))
-	}
-
-	if namespace != "" {
-		logrus.Infof("Initializing %s kubeEnv in %s namespace scope", envName, namespace)
-	} else {
-		logrus.Infof("Initializing %s kubeEnv in cluster scope", envName)
+		panic(fmt.Errorf("invalid ENV variable. Format is env1=ns1,env2=ns2,env3=label=value&label2=value2: %s", err))
 	}
 
 	k8sConfig, err := k8sConfig(config)
@@ -88,32 +84,34 @@ func main() {
 		panic(err.Error())
 	}
 
-	kubeEnv := &agent.KubeEnv{
-		Name:          envName,
-		Namespace:     namespace,
-		Client:        clientset,
-		DynamicClient: dynamicClient,
-	}
+	agent.DiscoverFluxVersions(clientset.Discovery())
 
 	stopCh := make(chan struct{})
 	defer close(stopCh)
 
-	podController := agent.PodController(kubeEnv, config.Host, config.AgentKey)
-	deploymentController := agent.DeploymentController(kubeEnv, config.Host, config.AgentKey)
-	ingressController := agent.IngressController(kubeEnv, config.Host, config.AgentKey)
-	eventController := agent.EventController(kubeEnv, config.Host, config.AgentKey)
-	gitRepositoryController := agent.GitRepositoryController(kubeEnv, config.Host, config.AgentKey)
-	kustomizationController := agent.KustomizationController(kubeEnv, config.Host, config.AgentKey)
-	go podController.Run(1, stopCh)
-	go deploymentController.Run(1, stopCh)
-	go ingressController.Run(1, stopCh)
-	go eventController.Run(1, stopCh)
-	go gitRepositoryController.Run(1, stopCh)
-	go kustomizationController.Run(1, stopCh)
-
 	messages := make(chan *streaming.WSMessage)
 
-	go serverCommunication(kubeEnv, config, messages, config.Host, config.AgentKey)
+	for _, env := range envs {
+		if env.namespace != "" {
+			logrus.Infof("Initializing %s kubeEnv in %s namespace scope", env.name, env.namespace)
+		} else if env.namespaceSelector != "" {
+			logrus.Infof("Initializing %s kubeEnv in the scope of namespaces matching %s", env.name, env.namespaceSelector)
+		} else {
+			logrus.Infof("Initializing %s kubeEnv in cluster scope", env.name)
+		}
+
+		kubeEnv := &agent.KubeEnv{
+			Name:              env.name,
+			Namespace:         env.namespace,
+			NamespaceSelector: env.namespaceSelector,
+			Client:            clientset,
+			DynamicClient:     dynamicClient,
+		}
+
+		runControllers(kubeEnv, config, stopCh)
+		go serverCommunication(kubeEnv, config, messages, config.Host, config.AgentKey)
+	}
+
 	go serverWSCommunication(config, messages)
 
 	signals := make(chan os.Signal, 1)
@@ -167,16 +165,95 @@ func initLogger(c config.Config) {
 	}
 }
 
-func parseEnvString(envString string) (string, string, error) {
-	if strings.Contains(envString, "=") {
-		parts := strings.Split(envString, "=")
-		if len(parts) != 2 {
-			return "", "", fmt.Errorf("")
+type envScope struct {
+	name              string
+	namespace         string
+	namespaceSelector string
+}
+
+// parseEnvString parses the comma separated list of envs that the agent serves.
+// An env is scoped to the cluster, to a namespace, or to the namespaces matching a label selector:
+// `staging`, `staging=staging-ns` or `payments=team=payments&tier=backend`
+func parseEnvString(envString string) ([]envScope, error) {
+	var envs []envScope
+	for _, part := range strings.Split(envString, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		parts := strings.SplitN(part, "=", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("env name is missing in %s", part)
+		}
+		env := envScope{name: parts[0]}
+		if len(parts) == 2 {
+			scope := parts[1]
+			if strings.ContainsAny(scope, "=!") { // namespace names can't have these characters
+				env.namespaceSelector = strings.ReplaceAll(scope, "&", ",")
+				if _, err := labels.Parse(env.namespaceSelector); err != nil {
+					return nil, fmt.Errorf("invalid namespace selector %s: %s", scope, err)
+				}
+			} else {
+				env.namespace = scope
+			}
+		}
+
+		for _, e := range envs {
+			if e.name == env.name {
+				return nil, fmt.Errorf("%s env is listed twice", env.name)
+			}
 		}
-		return parts[0], parts[1], nil
-	} else {
-		return envString, "", nil
+		envs = append(envs, env)
 	}
+
+	if len(envs) == 0 {
+		return nil, fmt.Errorf("no env is set")
+	}
+	return envs, nil
+}
+
+const podMetricsInterval = 1 * time.Minute
+const driftInterval = 3 * time.Minute
+const certificateInterval = 1 * time.Hour
+
+// runControllers starts the informers of an env
+func runControllers(kubeEnv *agent.KubeEnv, config config.Config, stopCh chan struct{}) {
+	podController := agent.PodController(kubeEnv, config.Host, config.AgentKey)
+	deploymentController := agent.DeploymentController(kubeEnv, config.Host, config.AgentKey)
+	ingressController := agent.IngressController(kubeEnv, config.Host, config.AgentKey)
+	eventController := agent.EventController(kubeEnv, config.Host, config.AgentKey)
+	gitRepositoryController := agent.GitRepositoryController(kubeEnv, config.Host, config.AgentKey)
+	kustomizationController := agent.KustomizationController(kubeEnv, config.Host, config.AgentKey)
+	helmReleaseController := agent.HelmReleaseController(kubeEnv, config.Host, config.AgentKey)
+	helmRepositoryController := agent.HelmRepositoryController(kubeEnv, config.Host, config.AgentKey)
+	ociRepositoryController := agent.OCIRepositoryController(kubeEnv, config.Host, config.AgentKey)
+	bucketController := agent.BucketController(kubeEnv, config.Host, config.AgentKey)
+	certificateController := agent.CertificateController(kubeEnv, config.Host, config.AgentKey)
+	statefulSetController := agent.StatefulSetController(kubeEnv, config.Host, config.AgentKey)
+	daemonSetController := agent.DaemonSetController(kubeEnv, config.Host, config.AgentKey)
+	cronJobController := agent.CronJobController(kubeEnv, config.Host, config.AgentKey)
+	jobController := agent.JobController(kubeEnv, config.Host, config.AgentKey)
+	hpaController := agent.HPAController(kubeEnv, config.Host, config.AgentKey)
+	go podController.Run(1, stopCh)
+	go deploymentController.Run(1, stopCh)
+	go ingressController.Run(1, stopCh)
+	go eventController.Run(1, stopCh)
+	go gitRepositoryController.Run(1, stopCh)
+	go kustomizationController.Run(1, stopCh)
+	go helmReleaseController.Run(1, stopCh)
+	go helmRepositoryController.Run(1, stopCh)
+	go ociRepositoryController.Run(1, stopCh)
+	go bucketController.Run(1, stopCh)
+	go certificateController.Run(1, stopCh)
+	go statefulSetController.Run(1, stopCh)
+	go daemonSetController.Run(1, stopCh)
+	go cronJobController.Run(1, stopCh)
+	go jobController.Run(1, stopCh)
+	go hpaController.Run(1, stopCh)
+	go agent.PollPodMetrics(kubeEnv, config.Host, config.AgentKey, podMetricsInterval, stopCh)
+	go agent.PollDrift(kubeEnv, config.Host, config.AgentKey, driftInterval, stopCh)
+	go agent.PollCertificates(kubeEnv, config.Host, config.AgentKey, certificateInterval, stopCh)
 }
 
 func serverCommunication(
@@ -213,6 +290,9 @@ func serverCommunication(
 						go sendState(kubeEnv, config.Host, config.AgentKey)
 						go sendEvents(kubeEnv, config.Host, config.AgentKey)
 					case "podLogs":
+						if !kubeEnv.InScope(e["namespace"].(string)) {
+							continue
+						}
 						go podLogs(
 							kubeEnv,
 							e["namespace"].(string),
@@ -230,6 +310,12 @@ func serverCommunication(
 						_ = json.Unmarshal(eString, &trigger)
 
 						go buildImage(gimletHost, agentKey, trigger, messages, config.ImageBuilderHost)
+					case "workloadAction":
+						eString, _ := json.Marshal(e)
+						var trigger streaming.WorkloadActionTrigger
+						_ = json.Unmarshal(eString, &trigger)
+
+						go performWorkloadAction(kubeEnv, trigger.WorkloadAction, messages)
 					}
 				} else {
 					logrus.Info("event stream closed")
@@ -246,43 +332,53 @@ func serverCommunication(
 	}
 }
 
-func sendState(kubeEnv *agent.KubeEnv, gimletHost string, agentKey string) {
-	stacks, err := kubeEnv.Services("")
-	if err != nil {
-		logrus.Errorf("could not get state from k8s apiServer: %v", err)
-		return
+func performWorkloadAction(
+	kubeEnv *agent.KubeEnv,
+	action model.WorkloadAction,
+	messages chan *streaming.WSMessage,
+) {
+	var err error
+	switch action.Action {
+	case model.WorkloadActionRestart:
+		err = kubeEnv.Restart(action.Kind, action.Namespace, action.Name)
+	case model.WorkloadActionScale:
+		err = kubeEnv.Scale(action.Kind, action.Namespace, action.Name, action.Replicas)
+	case model.WorkloadActionDeletePod:
+		err = kubeEnv.DeletePod(action.Namespace, action.Name)
+	case model.WorkloadActionSuspend:
+		err = kubeEnv.SuspendKustomization(action.Namespace, action.Name, true)
+	case model.WorkloadActionResume:
+		err = kubeEnv.SuspendKustomization(action.Namespace, action.Name, false)
+	case model.WorkloadActionReconcile:
+		err = kubeEnv.ReconcileKustomization(action.Namespace, action.Name)
+	default:
+		err = fmt.Errorf("unknown action %s", action.Action)
+	}
+
+	result := streaming.WorkloadActionResultWSMessage{
+		ID:     action.ID,
+		Status: model.WorkloadActionSucceeded,
 	}
-
-	stacksString, err := json.Marshal(stacks)
 	if err != nil {
-		logrus.Errorf("could not serialize k8s state: %v", err)
-		return
+		logrus.Errorf("could not perform %s on %s/%s: %s", action.Action, action.Namespace, action.Name, err)
+		result.Status = model.WorkloadActionFailed
+		result.StatusDesc = err.Error()
 	}
 
-	params := url.Values{}
-	params.Add("name", kubeEnv.Name)
-	reqUrl := fmt.Sprintf("%s/agent/state?%s", gimletHost, params.Encode())
-	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(stacksString))
+	serializedPayload, err := json.Marshal(result)
 	if err != nil {
-		logrus.Errorf("could not create http request: %v", err)
+		logrus.Error("cannot serialize payload", err)
 		return
 	}
-	req.Header.Set("Authorization", "BEARER "+agentKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := httpClient()
-	resp, err := client.Do(req)
-	if err != nil {
-		panic(err)
-	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		logrus.Errorf("could not send k8s state: %d - %v", resp.StatusCode, string(body))
-		return
+	messages <- &streaming.WSMessage{
+		Type:    "workloadActionResult",
+		Payload: string(serializedPayload),
 	}
+}
 
+func sendState(kubeEnv *agent.KubeEnv, gimletHost string, agentKey string) {
+	agent.SendState(kubeEnv, gimletHost, agentKey)
 	logrus.Info("init state sent")
 }
 
@@ -304,7 +400,9 @@ func sendEvents(kubeEnv *agent.KubeEnv, gimletHost string, agentKey string) {
 		return
 	}
 
-	reqUrl := fmt.Sprintf("%s/agent/events", gimletHost)
+	params := url.Values{}
+	params.Add("name", kubeEnv.Name)
+	reqUrl := fmt.Sprintf("%s/agent/events?%s", gimletHost, params.Encode())
 	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(eventsString))
 	if err != nil {
 		logrus.Errorf("could not create http request: %v", err)
@@ -373,7 +471,6 @@ func podLogs(
 							for _, container := range containers {
 								go streamPodLogs(kubeEnv, namespace, pod.Name, container.Name, serviceName, messages, runningLogStreams)
 							}
-							return
 						}
 					}
 				}
@@ -442,6 +539,7 @@ func streamPodLogs(
 				Timestamp: timestamp,
 				Container: containerName,
 				Pod:       namespace + "/" + serviceName,
+				PodName:   pod,
 				Message:   message,
 			})
 			if err != nil {
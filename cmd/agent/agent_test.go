@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvString(t *testing.T) {
+	envs, err := parseEnvString("staging")
+	assert.Nil(t, err)
+	assert.Equal(t, []envScope{{name: "staging"}}, envs)
+
+	envs, err = parseEnvString("staging=staging-ns, production=production-ns")
+	assert.Nil(t, err)
+	assert.Equal(t, []envScope{
+		{name: "staging", namespace: "staging-ns"},
+		{name: "production", namespace: "production-ns"},
+	}, envs)
+
+	envs, err = parseEnvString("payments=team=payments&tier!=frontend")
+	assert.Nil(t, err)
+	assert.Equal(t, []envScope{{name: "payments", namespaceSelector: "team=payments,tier!=frontend"}}, envs)
+
+	_, err = parseEnvString("staging=a,staging=b")
+	assert.NotNil(t, err, "envs must be unique")
+
+	_, err = parseEnvString("=staging")
+	assert.NotNil(t, err)
+
+	_, err = parseEnvString("")
+	assert.NotNil(t, err)
+}
@@ -2,39 +2,45 @@ package main
 
 import "sync"
 
+// runningLogStreams keeps the stop channels of the log streams of a service,
+// a service streams the logs of each container of each of its pods
 type runningLogStreams struct {
-	runningLogStreams map[string]chan int
+	runningLogStreams map[string][]chan int
 	lock              sync.Mutex
 }
 
 func NewRunningLogStreams() *runningLogStreams {
 	return &runningLogStreams{
-		runningLogStreams: make(map[string]chan int),
+		runningLogStreams: make(map[string][]chan int),
 	}
 }
 
 func (l *runningLogStreams) Regsiter(channel chan int, namespace string, serviceName string) {
-	pod := namespace + "/" + serviceName
+	svc := namespace + "/" + serviceName
 
 	l.lock.Lock()
-	l.runningLogStreams[pod] = channel
+	l.runningLogStreams[svc] = append(l.runningLogStreams[svc], channel)
 	l.lock.Unlock()
 }
 
 func (l *runningLogStreams) Stop(namespace string, serviceName string) {
+	svc := namespace + "/" + serviceName
+
 	l.lock.Lock()
-	for svc, stopCh := range l.runningLogStreams {
-		if svc == namespace+"/"+serviceName {
-			stopCh <- 0
-		}
+	for _, stopCh := range l.runningLogStreams[svc] {
+		stopCh <- 0
 	}
+	delete(l.runningLogStreams, svc)
 	l.lock.Unlock()
 }
 
 func (l *runningLogStreams) StopAll() {
 	l.lock.Lock()
-	for _, stopCh := range l.runningLogStreams {
-		stopCh <- 0
+	for _, stopChs := range l.runningLogStreams {
+		for _, stopCh := range stopChs {
+			stopCh <- 0
+		}
 	}
+	l.runningLogStreams = make(map[string][]chan int)
 	l.lock.Unlock()
 }
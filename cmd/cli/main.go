@@ -5,13 +5,20 @@ import (
 	"os"
 
 	"github.com/enescakir/emoji"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands/alert"
 	"github.com/gimlet-io/gimlet-cli/pkg/commands/artifact"
 	"github.com/gimlet-io/gimlet-cli/pkg/commands/chart"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands/contexts"
 	"github.com/gimlet-io/gimlet-cli/pkg/commands/environment"
 	"github.com/gimlet-io/gimlet-cli/pkg/commands/gitops"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands/insights"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands/logs"
 	"github.com/gimlet-io/gimlet-cli/pkg/commands/manifest"
 	"github.com/gimlet-io/gimlet-cli/pkg/commands/release"
 	"github.com/gimlet-io/gimlet-cli/pkg/commands/stack"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands/ui"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands/workload"
 	"github.com/gimlet-io/gimlet-cli/pkg/version"
 	"github.com/urfave/cli/v2"
 )
@@ -22,6 +29,9 @@ func main() {
 		Version:              version.String(),
 		Usage:                "a modular Gitops workflow for Kubernetes deployments",
 		EnableBashCompletion: true,
+		Flags: []cli.Flag{
+			commands.ContextFlag,
+		},
 		Commands: []*cli.Command{
 			&chart.Command,
 			&gitops.Command,
@@ -30,6 +40,12 @@ func main() {
 			&release.Command,
 			&stack.Command,
 			&environment.Command,
+			&alert.Command,
+			&insights.Command,
+			&workload.Command,
+			&logs.Command,
+			&contexts.Command,
+			&ui.Command,
 		},
 	}
 	err := app.Run(os.Args)
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 	"github.com/kelseyhightower/envconfig"
@@ -41,6 +42,9 @@ func defaults(c *Config) {
 	if c.GitSSHAddressFormat == "" {
 		c.GitSSHAddressFormat = "git@github.com:%s.git"
 	}
+	if c.CrashLogRetentionDays == 0 {
+		c.CrashLogRetentionDays = 7
+	}
 	if c.ReleaseStats == "" {
 		c.ReleaseStats = "disabled"
 	}
@@ -79,6 +83,7 @@ type Config struct {
 	RepoCachePath           string `envconfig:"REPO_CACHE_PATH"`
 	WebhookSecret           string `envconfig:"WEBHOOK_SECRET"`
 	ReleaseHistorySinceDays int    `envconfig:"RELEASE_HISTORY_SINCE_DAYS"`
+	CrashLogRetentionDays   int    `envconfig:"CRASH_LOG_RETENTION_DAYS"`
 	BootstrapEnv            string `envconfig:"BOOTSTRAP_ENV"`
 
 	AdminToken string `envconfig:"ADMIN_TOKEN"`
@@ -104,6 +109,9 @@ type Config struct {
 	ApiHost          string `envconfig:"API_HOST"`
 	GitRoot          string `envconfig:"GIT_ROOT"`
 	ImageBuilderHost string `envconfig:"IMAGE_BUILDER_HOST"`
+
+	// FluxAPIVersion is the version of the Flux resources generated when bootstrapping envs, eg.: v1
+	FluxAPIVersion string `envconfig:"FLUX_API_VERSION"`
 }
 
 // Logging provides the logging configuration.
@@ -145,6 +153,10 @@ type Notifications struct {
 	Token          string `envconfig:"NOTIFICATIONS_TOKEN"`
 	DefaultChannel string `envconfig:"NOTIFICATIONS_DEFAULT_CHANNEL"`
 	ChannelMapping string `envconfig:"NOTIFICATIONS_CHANNEL_MAPPING"`
+
+	// Digests batches messages per env and message kind, eg.: staging/deploy=15m,preview-*=1h
+	Digests             string        `envconfig:"NOTIFICATIONS_DIGESTS"`
+	DeduplicationWindow time.Duration `envconfig:"NOTIFICATIONS_DEDUPLICATION_WINDOW"`
 }
 
 type GitopsRepoConfig struct {
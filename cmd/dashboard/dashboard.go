@@ -69,7 +69,8 @@ func main() {
 	go clientHub.Run()
 
 	successfullImageBuilds := make(chan streaming.ImageBuildStatusWSMessage)
-	agentWSHub := streaming.NewAgentWSHub(*clientHub, successfullImageBuilds)
+	workloadActionResults := make(chan streaming.WorkloadActionResultWSMessage)
+	agentWSHub := streaming.NewAgentWSHub(*clientHub, successfullImageBuilds, workloadActionResults)
 	go agentWSHub.Run()
 
 	err = reencrypt(store, config.Database.EncryptionKeyNew)
@@ -88,7 +89,7 @@ func main() {
 	}
 
 	tokenManager := customScm.NewTokenManager(dynamicConfig)
-	notificationsManager := initNotifications(config, dynamicConfig, tokenManager)
+	notificationsManager := initNotifications(config, dynamicConfig, tokenManager, agentHub, store)
 
 	alertStateManager := alert.NewAlertStateManager(notificationsManager, *store, 2)
 	// go alertStateManager.Run()
@@ -181,6 +182,28 @@ func main() {
 		go releaseStateWorker.Run()
 	}
 
+	insightsWorker := &worker.InsightsWorker{
+		Store:               store,
+		DeploymentFrequency: deploymentFrequency,
+		LeadTime:            leadTime,
+		ChangeFailureRate:   changeFailureRate,
+		TimeToRestore:       timeToRestore,
+	}
+	go insightsWorker.Run()
+
+	workloadActionWorker := &worker.WorkloadActionWorker{
+		Store:     store,
+		ClientHub: clientHub,
+		Results:   workloadActionResults,
+	}
+	go workloadActionWorker.Run()
+
+	crashLogRetentionWorker := &worker.CrashLogRetentionWorker{
+		Store:     store,
+		Retention: time.Duration(config.CrashLogRetentionDays) * 24 * time.Hour,
+	}
+	go crashLogRetentionWorker.Run()
+
 	branchDeleteEventWorker := worker.NewBranchDeleteEventWorker(
 		tokenManager,
 		config.RepoCachePath,
@@ -347,6 +370,38 @@ func parseChannelMap(config *config.Config) map[string]string {
 	return channelMap
 }
 
+// parseDigestRoutes parses env[/kind]=window pairs, eg.: staging/deploy=15m,preview-*=1h
+func parseDigestRoutes(config *config.Config) []notifications.DigestRoute {
+	routes := []notifications.DigestRoute{}
+	if config.Notifications.Digests == "" {
+		return routes
+	}
+
+	pairs := strings.Split(config.Notifications.Digests, ",")
+	for _, p := range pairs {
+		keyValue := strings.Split(p, "=")
+		if len(keyValue) != 2 {
+			log.Warnf("invalid notification digest: %s", p)
+			continue
+		}
+
+		window, err := time.ParseDuration(keyValue[1])
+		if err != nil {
+			log.Warnf("invalid notification digest window %s: %s", p, err)
+			continue
+		}
+
+		route := notifications.DigestRoute{Window: window}
+		envAndKind := strings.SplitN(keyValue[0], "/", 2)
+		route.Env = envAndKind[0]
+		if len(envAndKind) == 2 {
+			route.Kind = envAndKind[1]
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
 func parseGitopsRepos(gitopsReposString string) ([]*model.Environment, error) {
 	envs := []*model.Environment{}
 	splitGitopsRepos := strings.Split(gitopsReposString, ";")
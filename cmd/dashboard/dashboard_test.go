@@ -141,6 +141,24 @@ func TestParseChannelMapping(t *testing.T) {
 	assertEqual(t, testChannelMap["prod"], "another-team")
 }
 
+func TestParseDigestRoutes(t *testing.T) {
+	config := &config.Config{
+		Notifications: config.Notifications{
+			Digests: "staging/deploy=15m,preview-*=1h,invalid",
+		},
+	}
+
+	routes := parseDigestRoutes(config)
+
+	assert.Equal(t, 2, len(routes))
+	assert.Equal(t, "staging", routes[0].Env)
+	assert.Equal(t, "deploy", routes[0].Kind)
+	assert.Equal(t, 15*time.Minute, routes[0].Window)
+	assert.Equal(t, "preview-*", routes[1].Env)
+	assert.Equal(t, "", routes[1].Kind)
+	assert.Equal(t, time.Hour, routes[1].Window)
+}
+
 func assertEqual(t *testing.T, a interface{}, b interface{}) {
 	if a != b {
 		t.Fatalf("%s != %s", a, b)
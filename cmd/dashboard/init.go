@@ -16,6 +16,7 @@ import (
 	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/dynamicconfig"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/notifications"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
 	"github.com/gimlet-io/gimlet-cli/pkg/git/customScm"
 	"github.com/gorilla/securecookie"
@@ -69,6 +70,8 @@ func initNotifications(
 	config *config.Config,
 	dynamicConfig *dynamicconfig.DynamicConfig,
 	tokenManager customScm.NonImpersonatedTokenManager,
+	agentHub *streaming.AgentHub,
+	store *store.Store,
 ) *notifications.ManagerImpl {
 	notificationsManager := notifications.NewManager()
 	if config.Notifications.Provider == "slack" {
@@ -78,9 +81,15 @@ func initNotifications(
 		notificationsManager.AddProvider(discordNotificationProvider(config))
 	}
 	if dynamicConfig.IsGithub() {
-		notificationsManager.AddProvider(notifications.NewGithubProvider(tokenManager))
+		notificationsManager.AddProvider(notifications.NewGithubProvider(tokenManager, notifications.IngressURL(agentHub), store))
 	} else if dynamicConfig.IsGitlab() {
-		notificationsManager.AddProvider(notifications.NewGitlabProvider(tokenManager, dynamicConfig.Gitlab.URL))
+		notificationsManager.AddProvider(notifications.NewGitlabProvider(tokenManager, dynamicConfig.Gitlab.URL, notifications.IngressURL(agentHub), store))
+	}
+	for _, route := range parseDigestRoutes(config) {
+		notificationsManager.AddDigestRoute(route)
+	}
+	if config.Notifications.DeduplicationWindow != 0 {
+		notificationsManager.SetDeduplicationWindow(config.Notifications.DeduplicationWindow)
 	}
 	go notificationsManager.Run()
 	return notificationsManager
@@ -80,6 +80,7 @@ func bootstrapBuiltInEnv(
 	opts.GitopsRepoUrl = fmt.Sprintf("%s/%s", config.ApiHost, builtInEnv.InfraRepo)
 	opts.GitopsRepoPath = tmpPath
 	opts.Branch = headBranch
+	opts.FluxAPIVersion = config.FluxAPIVersion
 	_, _, _, err = gitops.GenerateManifests(opts)
 	if err != nil {
 		return fmt.Errorf("cannot generate manifest: %s", err)
@@ -111,6 +112,7 @@ func bootstrapBuiltInEnv(
 	opts.GitopsRepoUrl = fmt.Sprintf("%s/%s", config.ApiHost, builtInEnv.AppsRepo)
 	opts.GitopsRepoPath = tmpPath
 	opts.Branch = headBranch
+	opts.FluxAPIVersion = config.FluxAPIVersion
 	_, _, _, err = gitops.GenerateManifests(opts)
 	if err != nil {
 		return fmt.Errorf("cannot generate manifest: %s", err)
@@ -16,6 +16,26 @@ var (
 		Help: "Release status",
 	}, []string{"env", "app", "sourceCommit", "commitMessage", "gitopsCommit", "gitopsCommitCreated"})
 
+	deploymentFrequency = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gimletd_deployment_frequency",
+		Help: "Deployments per day in the last 30 days",
+	}, []string{"env", "app"})
+
+	leadTime = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gimletd_lead_time_seconds",
+		Help: "Median lead time for changes in the last 30 days",
+	}, []string{"env", "app"})
+
+	changeFailureRate = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gimletd_change_failure_rate",
+		Help: "Ratio of failed or rolled back deployments in the last 30 days",
+	}, []string{"env", "app"})
+
+	timeToRestore = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gimletd_time_to_restore_seconds",
+		Help: "Median time to restore service in the last 30 days",
+	}, []string{"env", "app"})
+
 	perf = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Name: "gimletd_perf",
 		Help: "Performance of functions",
@@ -22,6 +22,7 @@ replace oras.land/oras-go => oras.land/oras-go v1.2.3
 replace github.com/google/go-containerregistry => github.com/google/go-containerregistry v0.14.1-0.20230409045903-ed5c185df419
 
 require (
+	atomicgo.dev/keyboard v0.2.9
 	cuelang.org/go v0.4.0
 	github.com/Masterminds/sprig/v3 v3.2.3
 	github.com/MichaelMure/go-term-markdown v0.1.4
@@ -61,6 +62,7 @@ require (
 	github.com/lib/pq v1.10.9
 	github.com/otiai10/copy v1.12.0
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/prometheus/client_golang v1.16.0
 	github.com/russross/meddler v1.0.1
 	github.com/rvflash/elapsed v0.3.0
@@ -88,7 +90,6 @@ require (
 
 require (
 	atomicgo.dev/cursor v0.1.1 // indirect
-	atomicgo.dev/keyboard v0.2.9 // indirect
 	atomicgo.dev/schedule v0.0.2 // indirect
 	github.com/AdaLogics/go-fuzz-headers v0.0.0-20230106234847-43070de90fa1 // indirect
 	github.com/agext/levenshtein v1.2.2 // indirect
@@ -243,7 +244,6 @@ require (
 	github.com/opencontainers/go-digest v1.0.0 // indirect
 	github.com/opencontainers/image-spec v1.1.0-rc3 // indirect
 	github.com/peterbourgon/diskv v2.0.1+incompatible // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/prometheus/client_model v0.4.0 // indirect
 	github.com/prometheus/common v0.42.0 // indirect
 	github.com/prometheus/procfs v0.10.1 // indirect
@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Restart triggers a rolling restart of a deployment, statefulset or daemonset
+// the same way `kubectl rollout restart` does: by stamping the pod template
+func (e *KubeEnv) Restart(kind string, namespace string, name string) error {
+	if !e.InScope(namespace) {
+		return fmt.Errorf("namespace %s is not managed by env %s", namespace, e.Name)
+	}
+
+	patch := []byte(fmt.Sprintf(
+		`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"%s"}}}}}`,
+		time.Now().Format(time.RFC3339),
+	))
+
+	var err error
+	switch kind {
+	case "deployment":
+		_, err = e.Client.AppsV1().Deployments(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case "statefulset":
+		_, err = e.Client.AppsV1().StatefulSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case "daemonset":
+		_, err = e.Client.AppsV1().DaemonSets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	default:
+		return fmt.Errorf("cannot restart %s", kind)
+	}
+	if err != nil {
+		return fmt.Errorf("could not restart %s %s/%s: %s", kind, namespace, name, err)
+	}
+
+	return nil
+}
+
+// Scale sets the replica count of a deployment or statefulset through the scale subresource
+func (e *KubeEnv) Scale(kind string, namespace string, name string, replicas int32) error {
+	if !e.InScope(namespace) {
+		return fmt.Errorf("namespace %s is not managed by env %s", namespace, e.Name)
+	}
+	if replicas < 0 {
+		return fmt.Errorf("replicas must not be negative")
+	}
+
+	switch kind {
+	case "deployment":
+		scale, err := e.Client.AppsV1().Deployments(namespace).GetScale(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("could not get scale of deployment %s/%s: %s", namespace, name, err)
+		}
+		scale.Spec.Replicas = replicas
+		_, err = e.Client.AppsV1().Deployments(namespace).UpdateScale(context.TODO(), name, scale, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("could not scale deployment %s/%s: %s", namespace, name, err)
+		}
+	case "statefulset":
+		scale, err := e.Client.AppsV1().StatefulSets(namespace).GetScale(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("could not get scale of statefulset %s/%s: %s", namespace, name, err)
+		}
+		scale.Spec.Replicas = replicas
+		_, err = e.Client.AppsV1().StatefulSets(namespace).UpdateScale(context.TODO(), name, scale, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("could not scale statefulset %s/%s: %s", namespace, name, err)
+		}
+	default:
+		return fmt.Errorf("cannot scale %s", kind)
+	}
+
+	return nil
+}
+
+// DeletePod deletes a pod, so its controller can replace it
+func (e *KubeEnv) DeletePod(namespace string, name string) error {
+	if !e.InScope(namespace) {
+		return fmt.Errorf("namespace %s is not managed by env %s", namespace, e.Name)
+	}
+
+	err := e.Client.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil {
+		return fmt.Errorf("could not delete pod %s/%s: %s", namespace, name, err)
+	}
+
+	return nil
+}
+
+// SuspendKustomization suspends or resumes the reconciliation of a Flux Kustomization
+func (e *KubeEnv) SuspendKustomization(namespace string, name string, suspend bool) error {
+	if !e.InScope(namespace) {
+		return fmt.Errorf("namespace %s is not managed by env %s", namespace, e.Name)
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend))
+	_, err := e.DynamicClient.
+		Resource(kustomizationResource).
+		Namespace(namespace).
+		Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("could not patch kustomization %s/%s: %s", namespace, name, err)
+	}
+
+	return nil
+}
+
+// ReconcileKustomization asks Flux to apply a Kustomization right away,
+// which also reverts the drifts of the objects it manages
+func (e *KubeEnv) ReconcileKustomization(namespace string, name string) error {
+	if !e.InScope(namespace) {
+		return fmt.Errorf("namespace %s is not managed by env %s", namespace, e.Name)
+	}
+
+	patch := []byte(fmt.Sprintf(
+		`{"metadata":{"annotations":{"reconcile.fluxcd.io/requestedAt":"%s"}}}`,
+		time.Now().Format(time.RFC3339Nano),
+	))
+	_, err := e.DynamicClient.
+		Resource(kustomizationResource).
+		Namespace(namespace).
+		Patch(context.TODO(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("could not patch kustomization %s/%s: %s", namespace, name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRestart(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"}},
+	)
+	kubeEnv := &KubeEnv{Name: "staging", Client: client}
+
+	err := kubeEnv.Restart("deployment", "default", "myapp")
+	assert.Nil(t, err)
+
+	deployment, _ := client.AppsV1().Deployments("default").Get(context.TODO(), "myapp", metav1.GetOptions{})
+	assert.NotEmpty(t, deployment.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"])
+
+	err = kubeEnv.Restart("cronjob", "default", "myapp")
+	assert.NotNil(t, err, "cronjobs can't be restarted")
+}
+
+func TestDeletePod(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "myapp-abc", Namespace: "default"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other-abc", Namespace: "other"}},
+	)
+	kubeEnv := &KubeEnv{Name: "staging", Namespace: "default", Client: client}
+
+	err := kubeEnv.DeletePod("default", "myapp-abc")
+	assert.Nil(t, err)
+	_, err = client.CoreV1().Pods("default").Get(context.TODO(), "myapp-abc", metav1.GetOptions{})
+	assert.NotNil(t, err, "pod should be deleted")
+
+	err = kubeEnv.DeletePod("other", "other-abc")
+	assert.NotNil(t, err, "pods outside of the env's scope can't be deleted")
+	_, err = client.CoreV1().Pods("other").Get(context.TODO(), "other-abc", metav1.GetOptions{})
+	assert.Nil(t, err)
+}
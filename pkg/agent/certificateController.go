@@ -0,0 +1,30 @@
+package agent
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const certificateCRDName = "certificates.cert-manager.io"
+
+// CertificateController follows the cert-manager Certificates, so issuance and renewal
+// show up on the ingresses and in the certificate alerts right away
+func CertificateController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Controller {
+	certificateController := NewDynamicController(
+		certificateCRDName,
+		kubeEnv.DynamicClient,
+		certificateResource,
+		func(informerEvent Event, objectMeta meta_v1.ObjectMeta, obj interface{}) error {
+			switch informerEvent.eventType {
+			case "create":
+				fallthrough
+			case "update":
+				fallthrough
+			case "delete":
+				RequestState(kubeEnv, gimletHost, agentKey)
+				SendCertificates(kubeEnv, gimletHost, agentKey)
+			}
+			return nil
+		})
+	certificateController.kubeEnv = kubeEnv
+	return certificateController
+}
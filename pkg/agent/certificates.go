@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/sirupsen/logrus"
+	networking_v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var certificateResource = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "certificates",
+}
+
+// PollCertificates periodically reports the ingress certificates of the env upstream,
+// so expiry is noticed even if nothing changes in the cluster
+func PollCertificates(kubeEnv *KubeEnv, gimletHost string, agentKey string, interval time.Duration, stopCh chan struct{}) {
+	for {
+		SendCertificates(kubeEnv, gimletHost, agentKey)
+
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func SendCertificates(kubeEnv *KubeEnv, gimletHost string, agentKey string) {
+	certificates, err := kubeEnv.Certificates()
+	if err != nil {
+		logrus.Warnf("could not get certificates: %s", err)
+		return
+	}
+
+	err = sendCertificates(kubeEnv.Name, certificates, gimletHost, agentKey)
+	if err != nil {
+		logrus.Warnf("could not send certificates: %s", err)
+	}
+}
+
+// Certificates returns the certificates of the ingresses in the env,
+// ingresses sharing a secret share the certificate too
+func (e *KubeEnv) Certificates() ([]*api.Certificate, error) {
+	ingresses, err := e.Client.NetworkingV1().Ingresses(e.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get ingresses: %s", err)
+	}
+
+	managed := e.managedCertificates()
+	certificates := []*api.Certificate{}
+	seen := map[string]bool{}
+	for _, ingress := range ingresses.Items {
+		if !e.InScope(ingress.Namespace) {
+			continue
+		}
+		for _, certificate := range e.ingressCertificates(ingress, managed) {
+			key := certificate.Namespace + "/" + certificate.SecretName
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			certificates = append(certificates, certificate)
+		}
+	}
+
+	return certificates, nil
+}
+
+// managedCertificates lists the cert-manager Certificates, if cert-manager is installed
+func (e *KubeEnv) managedCertificates() []unstructured.Unstructured {
+	if e.DynamicClient == nil {
+		return nil
+	}
+
+	certificates, err := e.DynamicClient.
+		Resource(certificateResource).
+		Namespace(e.Namespace).
+		List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		logrus.Debugf("could not get cert-manager certificates, is cert-manager installed? %s", err)
+		return nil
+	}
+	return certificates.Items
+}
+
+// ingressCertificates resolves the certificates of the tls sections of an ingress.
+// cert-manager Certificates are preferred as they know about readiness and the issuer,
+// otherwise the certificate is parsed from the referenced secret
+func (e *KubeEnv) ingressCertificates(ingress networking_v1.Ingress, managed []unstructured.Unstructured) []*api.Certificate {
+	var certificates []*api.Certificate
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName == "" {
+			continue
+		}
+
+		certificate := &api.Certificate{
+			Ingress:    ingress.Namespace + "/" + ingress.Name,
+			Namespace:  ingress.Namespace,
+			SecretName: tls.SecretName,
+			Hosts:      tls.Hosts,
+		}
+
+		if m := managedCertificate(managed, ingress.Namespace, tls.SecretName); m != nil {
+			fromManagedCertificate(certificate, m)
+		} else {
+			e.fromSecret(certificate)
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates
+}
+
+// certificatesForHost picks the certificates that serve the host of an ingress rule.
+// A tls section without hosts serves every host
+func certificatesForHost(certificates []*api.Certificate, host string) []*api.Certificate {
+	var result []*api.Certificate
+	for _, c := range certificates {
+		if len(c.Hosts) == 0 {
+			result = append(result, c)
+			continue
+		}
+		for _, h := range c.Hosts {
+			if h == host {
+				result = append(result, c)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func managedCertificate(managed []unstructured.Unstructured, namespace string, secretName string) *unstructured.Unstructured {
+	for i, m := range managed {
+		name, _, _ := unstructured.NestedString(m.Object, "spec", "secretName")
+		if m.GetNamespace() == namespace && name == secretName {
+			return &managed[i]
+		}
+	}
+	return nil
+}
+
+func fromManagedCertificate(certificate *api.Certificate, m *unstructured.Unstructured) {
+	issuerKind, _, _ := unstructured.NestedString(m.Object, "spec", "issuerRef", "kind")
+	issuerName, _, _ := unstructured.NestedString(m.Object, "spec", "issuerRef", "name")
+	if issuerKind == "" {
+		issuerKind = "Issuer"
+	}
+	certificate.Issuer = issuerKind + "/" + issuerName
+
+	notAfter, _, _ := unstructured.NestedString(m.Object, "status", "notAfter")
+	if t, err := time.Parse(time.RFC3339, notAfter); err == nil {
+		certificate.NotAfter = t.Unix()
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(m.Object, "status", "conditions")
+	if c := findStatusCondition(conditions, "Ready"); c != nil {
+		certificate.Ready = c["status"] == string(metav1.ConditionTrue)
+		certificate.StatusDesc, _ = c["message"].(string)
+	} else {
+		certificate.StatusDesc = "waiting to be issued"
+	}
+}
+
+func (e *KubeEnv) fromSecret(certificate *api.Certificate) {
+	secret, err := e.Client.CoreV1().Secrets(certificate.Namespace).Get(context.TODO(), certificate.SecretName, metav1.GetOptions{})
+	if err != nil {
+		certificate.StatusDesc = fmt.Sprintf("could not get secret %s: %s", certificate.SecretName, err)
+		return
+	}
+
+	block, _ := pem.Decode(secret.Data["tls.crt"])
+	if block == nil {
+		certificate.StatusDesc = fmt.Sprintf("secret %s has no tls.crt in PEM format", certificate.SecretName)
+		return
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		certificate.StatusDesc = fmt.Sprintf("could not parse the certificate in %s: %s", certificate.SecretName, err)
+		return
+	}
+
+	certificate.Issuer = parsed.Issuer.CommonName
+	certificate.NotAfter = parsed.NotAfter.Unix()
+	if time.Now().After(parsed.NotAfter) {
+		certificate.StatusDesc = "certificate has expired"
+		return
+	}
+	certificate.Ready = true
+}
+
+func sendCertificates(env string, certificates []*api.Certificate, gimletHost string, agentKey string) error {
+	certificatesString, err := json.Marshal(certificates)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Add("name", env)
+	reqUrl := fmt.Sprintf("%s/agent/certificates?%s", gimletHost, params.Encode())
+	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(certificatesString))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "BEARER "+agentKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := httpClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%d - %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
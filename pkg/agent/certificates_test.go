@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	networking_v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func selfSignedCertificate(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Example CA"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificates(t *testing.T) {
+	notAfter := time.Now().Add(10 * 24 * time.Hour).Truncate(time.Second)
+	ingress := &networking_v1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: networking_v1.IngressSpec{
+			TLS: []networking_v1.IngressTLS{
+				{Hosts: []string{"myapp.example.com"}, SecretName: "myapp-tls"},
+				{Hosts: []string{"api.example.com"}, SecretName: "api-tls"},
+				{Hosts: []string{"admin.example.com"}, SecretName: "admin-tls"},
+			},
+		},
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp-tls", Namespace: "default"},
+		Data:       map[string][]byte{"tls.crt": selfSignedCertificate(t, notAfter)},
+	}
+	managed := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": "api", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"secretName": "api-tls",
+			"issuerRef":  map[string]interface{}{"kind": "ClusterIssuer", "name": "letsencrypt"},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":    "Ready",
+					"status":  "False",
+					"message": "Issuing certificate as Secret does not exist",
+				},
+			},
+		},
+	}}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{certificateResource: "CertificateList"},
+		managed,
+	)
+	kubeEnv := &KubeEnv{Name: "staging", Client: fake.NewSimpleClientset(ingress, secret), DynamicClient: dynamicClient}
+
+	certificates, err := kubeEnv.Certificates()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(certificates))
+
+	assert.Equal(t, "default/myapp", certificates[0].Ingress)
+	assert.Equal(t, "Example CA", certificates[0].Issuer)
+	assert.Equal(t, notAfter.Unix(), certificates[0].NotAfter)
+	assert.True(t, certificates[0].Ready)
+
+	assert.Equal(t, "ClusterIssuer/letsencrypt", certificates[1].Issuer, "cert-manager Certificates should be preferred over the secret")
+	assert.False(t, certificates[1].Ready)
+	assert.Equal(t, "Issuing certificate as Secret does not exist", certificates[1].StatusDesc)
+
+	assert.False(t, certificates[2].Ready, "a missing secret should not be ready")
+
+	assert.Equal(t, 1, len(certificatesForHost(certificates, "api.example.com")))
+}
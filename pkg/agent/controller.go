@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
@@ -31,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	apps_v1 "k8s.io/api/apps/v1"
+	autoscaling_v2 "k8s.io/api/autoscaling/v2"
 	batch_v1 "k8s.io/api/batch/v1"
 	api_v1 "k8s.io/api/core/v1"
 	rntme "k8s.io/apimachinery/pkg/runtime"
@@ -167,6 +169,12 @@ func (c *Controller) processNextItem() bool {
 	// parallel.
 	defer c.queue.Done(informerEvent)
 
+	// agents serving multiple envs run controllers for each env, they skip the objects of other envs
+	namespace, _, _ := cache.SplitMetaNamespaceKey(informerEvent.(Event).key)
+	if c.kubeEnv != nil && namespace != "" && !c.kubeEnv.InScope(namespace) {
+		return true
+	}
+
 	obj, _, err := c.indexer.GetByKey(informerEvent.(Event).key)
 	if err != nil {
 		log.Errorf("Fetching object with key %s from store failed with %v", informerEvent.(Event).key, err)
@@ -258,12 +266,18 @@ func getObjectMetaData(obj interface{}) meta_v1.ObjectMeta {
 		objectMeta = object.ObjectMeta
 	case *apps_v1.DaemonSet:
 		objectMeta = object.ObjectMeta
+	case *apps_v1.StatefulSet:
+		objectMeta = object.ObjectMeta
 	case *api_v1.Service:
 		objectMeta = object.ObjectMeta
 	case *api_v1.Pod:
 		objectMeta = object.ObjectMeta
 	case *batch_v1.Job:
 		objectMeta = object.ObjectMeta
+	case *batch_v1.CronJob:
+		objectMeta = object.ObjectMeta
+	case *autoscaling_v2.HorizontalPodAutoscaler:
+		objectMeta = object.ObjectMeta
 	case *api_v1.PersistentVolume:
 		objectMeta = object.ObjectMeta
 	case *api_v1.Namespace:
@@ -276,43 +290,54 @@ func getObjectMetaData(obj interface{}) meta_v1.ObjectMeta {
 	return objectMeta
 }
 
-func sendUpdate(host string, agentKey string, env string, update interface{}) {
+// sendUpdate sends a stack update upstream, and buffers it if the dashboard is unreachable.
+// Buffered updates are sent after the next successful state sync, unless the synced state already covers them
+func sendUpdate(kubeEnv *KubeEnv, host string, agentKey string, update interface{}) {
+	err := postUpdate(host, agentKey, kubeEnv.Name, update)
+	if err != nil {
+		log.Warnf("could not send state update, buffering it: %v", err)
+		kubeEnv.stateSync.bufferUpdate(update)
+	}
+}
+
+func postUpdate(host string, agentKey string, env string, update interface{}) error {
 	stacksString, err := json.Marshal(update)
 	if err != nil {
-		log.Errorf("could not serialize k8s state: %v", err)
-		return
+		return fmt.Errorf("could not serialize k8s state: %v", err)
 	}
 
 	req, err := http.NewRequest("POST", host+"/agent/state/"+env+"/update", bytes.NewBuffer(stacksString))
 	if err != nil {
-		log.Errorf("could not create http request: %v", err)
-		return
+		return fmt.Errorf("could not create http request: %v", err)
 	}
 	req.Header.Set("Authorization", "BEARER "+agentKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := httpClient()
 	resp, err := client.Do(req)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := ioutil.ReadAll(resp.Body)
-		log.Errorf("could not send state update: %d - %v", resp.StatusCode, string(body))
-		return
+		return fmt.Errorf("%d - %v", resp.StatusCode, string(body))
 	}
+
+	return nil
 }
 
-func sendEvents(host string, agentKey string, events []api.Event) {
+func sendEvents(host string, agentKey string, env string, events []api.Event) {
 	typeWarningEventsString, err := json.Marshal(events)
 	if err != nil {
 		log.Errorf("could not serialize k8s events: %v", err)
 		return
 	}
 
-	reqUrl := fmt.Sprintf("%s/agent/events", host)
+	params := url.Values{}
+	params.Add("name", env)
+	reqUrl := fmt.Sprintf("%s/agent/events?%s", host, params.Encode())
 	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(typeWarningEventsString))
 	if err != nil {
 		log.Errorf("could not create http request: %v", err)
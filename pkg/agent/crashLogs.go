@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+const crashLogLines = int64(200)
+
+// crashLogs captures the logs of the previous run of the containers that restarted
+// since the last time the pod was looked at. Once a restart is captured it is not fetched again
+func (e *KubeEnv) crashLogs(pod v1.Pod) []*api.CrashLog {
+	e.crashLogsLock.Lock()
+	defer e.crashLogsLock.Unlock()
+	if e.capturedRestarts == nil {
+		e.capturedRestarts = map[string]int32{}
+	}
+
+	var crashLogs []*api.CrashLog
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		terminated := containerStatus.LastTerminationState.Terminated
+		if terminated == nil || containerStatus.RestartCount == 0 {
+			continue
+		}
+
+		key := pod.Namespace + "/" + pod.Name + "/" + containerStatus.Name
+		if e.capturedRestarts[key] >= containerStatus.RestartCount {
+			continue
+		}
+
+		logs, err := e.previousLogs(pod, containerStatus.Name)
+		if err != nil {
+			logrus.Warnf("could not get previous logs of %s: %s", key, err)
+			continue
+		}
+		e.capturedRestarts[key] = containerStatus.RestartCount
+
+		crashLogs = append(crashLogs, &api.CrashLog{
+			Container:    containerStatus.Name,
+			RestartCount: containerStatus.RestartCount,
+			ExitCode:     terminated.ExitCode,
+			Reason:       terminated.Reason,
+			FinishedAt:   terminated.FinishedAt.Unix(),
+			Logs:         logs,
+		})
+	}
+
+	return crashLogs
+}
+
+// forgetCrashLogs drops the captured restarts of a deleted pod
+func (e *KubeEnv) forgetCrashLogs(podKey string) {
+	e.crashLogsLock.Lock()
+	defer e.crashLogsLock.Unlock()
+
+	for key := range e.capturedRestarts {
+		if strings.HasPrefix(key, podKey+"/") {
+			delete(e.capturedRestarts, key)
+		}
+	}
+}
+
+func (e *KubeEnv) previousLogs(pod v1.Pod, container string) (string, error) {
+	tailLines := crashLogLines
+	req := e.Client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+		Container:  container,
+		Previous:   true,
+		TailLines:  &tailLines,
+		Timestamps: true,
+	})
+	logs, err := req.Do(context.TODO()).Raw()
+	if err != nil {
+		return "", err
+	}
+
+	return string(logs), nil
+}
@@ -44,7 +44,7 @@ func DeploymentController(kubeEnv *KubeEnv, gimletHost string, agentKey string)
 
 							SHA: sha,
 						}
-						sendUpdate(gimletHost, agentKey, kubeEnv.Name, update)
+						sendUpdate(kubeEnv, gimletHost, agentKey, update)
 					}
 				}
 			case "update":
@@ -70,7 +70,7 @@ func DeploymentController(kubeEnv *KubeEnv, gimletHost string, agentKey string)
 
 							SHA: sha,
 						}
-						sendUpdate(gimletHost, agentKey, kubeEnv.Name, update)
+						sendUpdate(kubeEnv, gimletHost, agentKey, update)
 					}
 				}
 			case "delete":
@@ -79,9 +79,10 @@ func DeploymentController(kubeEnv *KubeEnv, gimletHost string, agentKey string)
 					Env:     kubeEnv.Name,
 					Subject: informerEvent.key,
 				}
-				sendUpdate(gimletHost, agentKey, kubeEnv.Name, update)
+				sendUpdate(kubeEnv, gimletHost, agentKey, update)
 			}
 			return nil
 		})
+	deploymentController.kubeEnv = kubeEnv
 	return deploymentController
 }
@@ -0,0 +1,472 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+)
+
+const labelAppInstance = "app.kubernetes.io/instance"
+const labelKustomizationName = "kustomize.toolkit.fluxcd.io/name"
+const labelKustomizationNamespace = "kustomize.toolkit.fluxcd.io/namespace"
+
+// unmanagedCandidates are the resources checked for objects of an app that no gitops file owns
+var unmanagedCandidates = []schema.GroupVersionResource{
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	{Group: "batch", Version: "v1", Resource: "cronjobs"},
+	{Group: "", Version: "v1", Resource: "services"},
+	{Group: "", Version: "v1", Resource: "configmaps"},
+	{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+}
+
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+type desiredObject struct {
+	app    string
+	object *unstructured.Unstructured
+}
+
+// PollDrift periodically compares the live objects of the env with the gitops repo
+// and reports the drifts upstream
+func PollDrift(kubeEnv *KubeEnv, gimletHost string, agentKey string, interval time.Duration, stopCh chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(interval):
+		}
+
+		manifests, err := fetchGitopsManifests(kubeEnv.Name, gimletHost, agentKey)
+		if err != nil {
+			logrus.Warnf("could not get gitops manifests: %s", err)
+			continue
+		}
+
+		drifts, err := kubeEnv.Drifts(manifests)
+		if err != nil {
+			logrus.Warnf("could not detect drift: %s", err)
+			continue
+		}
+
+		err = sendDrifts(kubeEnv.Name, drifts, gimletHost, agentKey)
+		if err != nil {
+			logrus.Warnf("could not send drifts: %s", err)
+		}
+	}
+}
+
+// Drifts compares the live objects with the app manifests of the gitops repo
+func (e *KubeEnv) Drifts(manifests map[string]map[string]string) ([]*api.Drift, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(e.Client.Discovery())
+	if err != nil {
+		return nil, fmt.Errorf("could not discover api resources: %s", err)
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+
+	drifts := []*api.Drift{}
+	owned := map[string]bool{}
+	appNamespaces := map[string]map[string]bool{}
+
+	desiredObjects := parseManifests(manifests)
+	autoscaled := desiredHPATargets(desiredObjects)
+	hpaNamespaces := map[string]bool{}
+
+	for _, desired := range desiredObjects {
+		gvk := desired.object.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			logrus.Debugf("could not map %s: %s", gvk.String(), err)
+			continue
+		}
+
+		namespace := desired.object.GetNamespace()
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if namespace == "" {
+				namespace = "default"
+			}
+			if !e.InScope(namespace) {
+				continue
+			}
+			if appNamespaces[desired.app] == nil {
+				appNamespaces[desired.app] = map[string]bool{}
+			}
+			appNamespaces[desired.app][namespace] = true
+
+			if !hpaNamespaces[namespace] {
+				err = e.liveHPATargets(namespace, autoscaled)
+				if err != nil {
+					return nil, err
+				}
+				hpaNamespaces[namespace] = true
+			}
+		}
+
+		name := desired.object.GetName()
+		owned[gvk.Kind+"/"+namespace+"/"+name] = true
+
+		live, err := e.DynamicClient.
+			Resource(mapping.Resource).
+			Namespace(namespace).
+			Get(context.TODO(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			drifts = append(drifts, &api.Drift{
+				App:       desired.app,
+				Type:      api.DriftMissing,
+				Kind:      gvk.Kind,
+				Namespace: namespace,
+				Name:      name,
+			})
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("could not get %s %s/%s: %s", gvk.Kind, namespace, name, err)
+		}
+
+		for _, field := range objectDrifts(desired.object.Object, live.Object) {
+			if field.Field == "spec.replicas" && autoscaled[gvk.Kind+"/"+namespace+"/"+name] {
+				continue
+			}
+			field.App = desired.app
+			field.Type = api.DriftModified
+			field.Kind = gvk.Kind
+			field.Namespace = namespace
+			field.Name = name
+			field.Kustomization = kustomization(live)
+			drifts = append(drifts, field)
+		}
+	}
+
+	unmanaged, err := e.unmanagedObjects(appNamespaces, owned)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(drifts, unmanaged...), nil
+}
+
+// desiredHPATargets returns the objects that the HPAs of the gitops repo scale, as kind/namespace/name
+func desiredHPATargets(objects []desiredObject) map[string]bool {
+	targets := map[string]bool{}
+	for _, desired := range objects {
+		if desired.object.GetKind() != "HorizontalPodAutoscaler" {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(desired.object.Object, "spec", "scaleTargetRef", "kind")
+		name, _, _ := unstructured.NestedString(desired.object.Object, "spec", "scaleTargetRef", "name")
+		namespace := desired.object.GetNamespace()
+		if namespace == "" {
+			namespace = "default"
+		}
+		targets[kind+"/"+namespace+"/"+name] = true
+	}
+	return targets
+}
+
+// liveHPATargets adds the objects that the HPAs of a namespace scale to targets.
+// Their replica count is managed by the HPA, it is not a drift from the gitops repo
+func (e *KubeEnv) liveHPATargets(namespace string, targets map[string]bool) error {
+	hpas, err := e.Client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("could not list horizontal pod autoscalers: %s", err)
+	}
+	for _, hpa := range hpas.Items {
+		targets[hpa.Spec.ScaleTargetRef.Kind+"/"+namespace+"/"+hpa.Spec.ScaleTargetRef.Name] = true
+	}
+	return nil
+}
+
+// unmanagedObjects returns the objects that carry the instance label of an app,
+// but are not in the gitops repo, nor generated by another object
+func (e *KubeEnv) unmanagedObjects(appNamespaces map[string]map[string]bool, owned map[string]bool) ([]*api.Drift, error) {
+	var drifts []*api.Drift
+	for app, namespaces := range appNamespaces {
+		for namespace := range namespaces {
+			for _, resource := range unmanagedCandidates {
+				objects, err := e.DynamicClient.
+					Resource(resource).
+					Namespace(namespace).
+					List(context.TODO(), metav1.ListOptions{LabelSelector: labelAppInstance + "=" + app})
+				if err != nil {
+					return nil, fmt.Errorf("could not list %s: %s", resource.Resource, err)
+				}
+
+				for _, o := range objects.Items {
+					if owned[o.GetKind()+"/"+o.GetNamespace()+"/"+o.GetName()] || len(o.GetOwnerReferences()) > 0 {
+						continue
+					}
+					drifts = append(drifts, &api.Drift{
+						App:           app,
+						Type:          api.DriftUnmanaged,
+						Kind:          o.GetKind(),
+						Namespace:     o.GetNamespace(),
+						Name:          o.GetName(),
+						Kustomization: kustomization(&o),
+					})
+				}
+			}
+		}
+	}
+
+	return drifts, nil
+}
+
+// parseManifests returns the Kubernetes objects of the app manifests.
+// Secrets are left out as they are sealed in the gitops repo
+func parseManifests(manifests map[string]map[string]string) []desiredObject {
+	var objects []desiredObject
+
+	apps := make([]string, 0, len(manifests))
+	for app := range manifests {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+
+	for _, app := range apps {
+		files := make([]string, 0, len(manifests[app]))
+		for file := range manifests[app] {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+
+		for _, file := range files {
+			for _, document := range documentSeparator.Split(manifests[app][file], -1) {
+				var object map[string]interface{}
+				err := yaml.Unmarshal([]byte(document), &object)
+				if err != nil {
+					logrus.Debugf("could not parse %s/%s: %s", app, file, err)
+					continue
+				}
+
+				o := &unstructured.Unstructured{Object: object}
+				if object == nil || o.GetKind() == "" || o.GetName() == "" ||
+					o.GetKind() == "Secret" ||
+					strings.HasPrefix(o.GetAPIVersion(), "kustomize.config.k8s.io") {
+					continue
+				}
+				objects = append(objects, desiredObject{app: app, object: o})
+			}
+		}
+	}
+
+	return objects
+}
+
+// objectDrifts compares the fields set in the gitops repo with the live object.
+// Fields that the cluster sets on its own are not compared
+func objectDrifts(desired map[string]interface{}, live map[string]interface{}) []*api.Drift {
+	desiredFields := map[string]interface{}{}
+	for key, value := range desired {
+		if key == "apiVersion" || key == "kind" || key == "status" {
+			continue
+		}
+		desiredFields[key] = value
+	}
+
+	desiredMetadata, _ := desired["metadata"].(map[string]interface{})
+	delete(desiredFields, "metadata")
+	metadata := map[string]interface{}{}
+	if labels, ok := desiredMetadata["labels"]; ok {
+		metadata["labels"] = labels
+	}
+	if annotations, ok := desiredMetadata["annotations"]; ok {
+		metadata["annotations"] = annotations
+	}
+	if len(metadata) > 0 {
+		desiredFields["metadata"] = metadata
+	}
+
+	return fieldDrifts("", desiredFields, live)
+}
+
+func fieldDrifts(path string, desired interface{}, live interface{}) []*api.Drift {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			if live == nil && len(d) == 0 {
+				return nil
+			}
+			return []*api.Drift{fieldDrift(path, desired, live)}
+		}
+
+		keys := make([]string, 0, len(d))
+		for key := range d {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var drifts []*api.Drift
+		for _, key := range keys {
+			drifts = append(drifts, fieldDrifts(joinPath(path, key), d[key], l[key])...)
+		}
+		return drifts
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			if live == nil && len(d) == 0 {
+				return nil
+			}
+			return []*api.Drift{fieldDrift(path, desired, live)}
+		}
+
+		var drifts []*api.Drift
+		for i := range d {
+			drifts = append(drifts, fieldDrifts(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])...)
+		}
+		return drifts
+	default:
+		if scalarsEqual(desired, live) {
+			return nil
+		}
+		return []*api.Drift{fieldDrift(path, desired, live)}
+	}
+}
+
+func scalarsEqual(desired interface{}, live interface{}) bool {
+	if desired == nil {
+		return true
+	}
+	if live == nil {
+		// the api server leaves out zero values
+		return desired == "" || desired == false || desired == float64(0)
+	}
+
+	d := fmt.Sprint(desired)
+	l := fmt.Sprint(live)
+	if d == l {
+		return true
+	}
+
+	// quantities may be written differently than the api server formats them, eg.: 0.5 and 500m
+	dq, err := resource.ParseQuantity(d)
+	if err != nil {
+		return false
+	}
+	lq, err := resource.ParseQuantity(l)
+	if err != nil {
+		return false
+	}
+	return dq.Cmp(lq) == 0
+}
+
+func fieldDrift(path string, desired interface{}, live interface{}) *api.Drift {
+	return &api.Drift{
+		Field:   path,
+		Desired: printValue(desired),
+		Live:    printValue(live),
+	}
+}
+
+func printValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	var printed string
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(value)
+		printed = string(b)
+	default:
+		printed = fmt.Sprint(value)
+	}
+
+	if len(printed) > 200 {
+		return printed[:200] + "..."
+	}
+	return printed
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func kustomization(o *unstructured.Unstructured) string {
+	labels := o.GetLabels()
+	name, ok := labels[labelKustomizationName]
+	if !ok {
+		return ""
+	}
+	return labels[labelKustomizationNamespace] + "/" + name
+}
+
+func fetchGitopsManifests(env string, gimletHost string, agentKey string) (map[string]map[string]string, error) {
+	params := url.Values{}
+	params.Add("name", env)
+	reqUrl := fmt.Sprintf("%s/agent/gitopsManifests?%s", gimletHost, params.Encode())
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "BEARER "+agentKey)
+
+	client := httpClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%d - %s", resp.StatusCode, string(body))
+	}
+
+	var manifests map[string]map[string]string
+	err = json.Unmarshal(body, &manifests)
+	return manifests, err
+}
+
+func sendDrifts(env string, drifts []*api.Drift, gimletHost string, agentKey string) error {
+	driftsString, err := json.Marshal(drifts)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Add("name", env)
+	reqUrl := fmt.Sprintf("%s/agent/drift?%s", gimletHost, params.Encode())
+	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(driftsString))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "BEARER "+agentKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := httpClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%d - %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/stretchr/testify/assert"
+	autoscaling_v2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const deploymentManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+  namespace: default
+  labels:
+    app.kubernetes.io/instance: myapp
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: myapp
+        image: myapp:v1
+        resources:
+          requests:
+            cpu: 0.5
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: myapp
+`
+
+const serviceManifest = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: myapp
+  namespace: default
+spec:
+  ports:
+  - port: 80
+`
+
+func TestDrifts(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "myapp",
+			"namespace": "default",
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/instance":            "myapp",
+				"kustomize.toolkit.fluxcd.io/name":      "gitops-repo-staging",
+				"kustomize.toolkit.fluxcd.io/namespace": "flux-system",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":                     "myapp",
+							"image":                    "myapp:v1",
+							"terminationMessagePolicy": "File",
+							"resources": map[string]interface{}{
+								"requests": map[string]interface{}{"cpu": "500m"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	unmanaged := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      "myapp-debug",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app.kubernetes.io/instance": "myapp"},
+		},
+	}}
+
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, resource := range unmanagedCandidates {
+		listKinds[resource] = resource.Resource + "List"
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, live, unmanaged)
+
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}},
+		},
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "services", Kind: "Service", Namespaced: true},
+				{Name: "secrets", Kind: "Secret", Namespaced: true},
+			},
+		},
+	}
+
+	kubeEnv := &KubeEnv{Name: "staging", Client: client, DynamicClient: dynamicClient}
+
+	drifts, err := kubeEnv.Drifts(map[string]map[string]string{
+		"myapp": {
+			"deployment.yaml": deploymentManifest,
+			"service.yaml":    serviceManifest,
+		},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, 3, len(drifts))
+	assert.Equal(t, api.DriftModified, drifts[0].Type)
+	assert.Equal(t, "spec.replicas", drifts[0].Field, "cpu should be compared as a quantity")
+	assert.Equal(t, "1", drifts[0].Desired)
+	assert.Equal(t, "3", drifts[0].Live)
+	assert.Equal(t, "flux-system/gitops-repo-staging", drifts[0].Kustomization)
+
+	assert.Equal(t, api.DriftMissing, drifts[1].Type)
+	assert.Equal(t, "Service", drifts[1].Kind)
+
+	assert.Equal(t, api.DriftUnmanaged, drifts[2].Type)
+	assert.Equal(t, "myapp-debug", drifts[2].Name)
+}
+
+func TestDriftsOfAutoscaledObjects(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "myapp",
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app.kubernetes.io/instance": "myapp"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(5),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":      "myapp",
+							"image":     "myapp:v2",
+							"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "500m"}},
+						},
+					},
+				},
+			},
+		},
+	}}
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, resource := range unmanagedCandidates {
+		listKinds[resource] = resource.Resource + "List"
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, live)
+
+	client := fake.NewSimpleClientset(&autoscaling_v2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp", Namespace: "default"},
+		Spec: autoscaling_v2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscaling_v2.CrossVersionObjectReference{Kind: "Deployment", Name: "myapp"},
+		},
+	})
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}},
+		},
+	}
+
+	kubeEnv := &KubeEnv{Name: "staging", Client: client, DynamicClient: dynamicClient}
+	drifts, err := kubeEnv.Drifts(map[string]map[string]string{
+		"myapp": {"deployment.yaml": deploymentManifest},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(drifts), "the replicas of an autoscaled deployment should not drift")
+	assert.Equal(t, "spec.template.spec.containers[0].image", drifts[0].Field)
+}
@@ -19,8 +19,9 @@ func EventController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Cont
 				return err
 			}
 
-			sendEvents(gimletHost, agentKey, events)
+			sendEvents(gimletHost, agentKey, kubeEnv.Name, events)
 			return nil
 		})
+	eventController.kubeEnv = kubeEnv
 	return eventController
 }
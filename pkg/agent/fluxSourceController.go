@@ -0,0 +1,46 @@
+package agent
+
+import (
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const helmRepositoryCRDName = "helmrepositories.source.toolkit.fluxcd.io"
+const ociRepositoryCRDName = "ocirepositories.source.toolkit.fluxcd.io"
+const bucketCRDName = "buckets.source.toolkit.fluxcd.io"
+
+func HelmRepositoryController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Controller {
+	return fluxSourceController(helmRepositoryCRDName, helmRepositoryResource, kubeEnv, gimletHost, agentKey)
+}
+
+func OCIRepositoryController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Controller {
+	return fluxSourceController(ociRepositoryCRDName, ociRepositoryResource, kubeEnv, gimletHost, agentKey)
+}
+
+func BucketController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Controller {
+	return fluxSourceController(bucketCRDName, bucketResource, kubeEnv, gimletHost, agentKey)
+}
+
+func fluxSourceController(
+	crdName string,
+	resource schema.GroupVersionResource,
+	kubeEnv *KubeEnv,
+	gimletHost string,
+	agentKey string,
+) *Controller {
+	return NewDynamicController(
+		crdName,
+		kubeEnv.DynamicClient,
+		resource,
+		func(informerEvent Event, objectMeta meta_v1.ObjectMeta, obj interface{}) error {
+			switch informerEvent.eventType {
+			case "create":
+				fallthrough
+			case "update":
+				fallthrough
+			case "delete":
+				SendFluxState(kubeEnv, gimletHost, agentKey)
+			}
+			return nil
+		})
+}
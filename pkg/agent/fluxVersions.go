@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// fluxAPIVersions are the Flux API versions the agent can watch, the most recent first
+var fluxAPIVersions = []string{"v2", "v1", "v2beta2", "v2beta1", "v1beta2", "v1beta1"}
+
+// ServedFluxVersion returns the most recent Flux API version that the cluster serves a resource in.
+// Versions are looked up per resource, as a group may serve its kinds in different versions
+func ServedFluxVersion(client discovery.DiscoveryInterface, group string, resource string) (string, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return "", fmt.Errorf("could not discover api groups: %s", err)
+	}
+
+	served := map[string]bool{}
+	for _, g := range groups.Groups {
+		if g.Name != group {
+			continue
+		}
+		for _, v := range g.Versions {
+			served[v.Version] = true
+		}
+	}
+	if len(served) == 0 {
+		return "", fmt.Errorf("%s is not served, is Flux installed?", group)
+	}
+
+	for _, version := range fluxAPIVersions {
+		if !served[version] {
+			continue
+		}
+
+		resources, err := client.ServerResourcesForGroupVersion(schema.GroupVersion{Group: group, Version: version}.String())
+		if err != nil {
+			return "", fmt.Errorf("could not discover %s/%s resources: %s", group, version, err)
+		}
+		for _, r := range resources.APIResources {
+			if r.Name == resource {
+				return version, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%s.%s is served in unsupported versions only", resource, group)
+}
+
+// DiscoverFluxVersions points the Flux watches to the API versions the cluster serves
+func DiscoverFluxVersions(client discovery.DiscoveryInterface) {
+	for _, resource := range fluxResources {
+		version, err := ServedFluxVersion(client, resource.Group, resource.Resource)
+		if err != nil {
+			logrus.Warnf("could not discover flux api version: %s", err)
+			continue
+		}
+		resource.Version = version
+		logrus.Infof("watching %s on %s", resource.GroupResource(), version)
+	}
+}
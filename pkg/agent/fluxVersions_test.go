@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServedFluxVersion(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "source.toolkit.fluxcd.io/v1beta2",
+			APIResources: []metav1.APIResource{
+				{Name: "gitrepositories"},
+				{Name: "helmrepositories"},
+				{Name: "buckets"},
+			},
+		},
+		{
+			GroupVersion: "source.toolkit.fluxcd.io/v1",
+			APIResources: []metav1.APIResource{{Name: "gitrepositories"}},
+		},
+		{
+			GroupVersion: "kustomize.toolkit.fluxcd.io/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "kustomizations"}},
+		},
+		{
+			GroupVersion: "helm.toolkit.fluxcd.io/v3alpha1",
+			APIResources: []metav1.APIResource{{Name: "helmreleases"}},
+		},
+	}
+
+	version, err := ServedFluxVersion(client.Discovery(), "source.toolkit.fluxcd.io", "gitrepositories")
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", version, "the most recent version should be preferred")
+
+	version, err = ServedFluxVersion(client.Discovery(), "source.toolkit.fluxcd.io", "helmrepositories")
+	assert.Nil(t, err)
+	assert.Equal(t, "v1beta2", version, "kinds of a group may be served in different versions")
+
+	_, err = ServedFluxVersion(client.Discovery(), "source.toolkit.fluxcd.io", "ocirepositories")
+	assert.NotNil(t, err)
+
+	version, err = ServedFluxVersion(client.Discovery(), "kustomize.toolkit.fluxcd.io", "kustomizations")
+	assert.Nil(t, err)
+	assert.Equal(t, "v1beta1", version)
+
+	_, err = ServedFluxVersion(client.Discovery(), "helm.toolkit.fluxcd.io", "helmreleases")
+	assert.NotNil(t, err, "unknown versions should not be used")
+
+	_, err = ServedFluxVersion(client.Discovery(), "notification.toolkit.fluxcd.io", "alerts")
+	assert.NotNil(t, err)
+}
@@ -58,9 +58,31 @@ func SendFluxState(kubeEnv *KubeEnv, gimletHost string, agentKey string) {
 		logrus.Info(k)
 	}
 
+	// Helm releases and the other sources are optional, not every cluster runs their controllers
+	helmReleases, err := kubeEnv.HelmReleases()
+	if err != nil {
+		logrus.Warnf("could not get helmreleases: %s", err)
+	}
+	helmRepositories, err := kubeEnv.FluxSources(helmRepositoryResource)
+	if err != nil {
+		logrus.Warnf("could not get helmrepositories: %s", err)
+	}
+	ociRepositories, err := kubeEnv.FluxSources(ociRepositoryResource)
+	if err != nil {
+		logrus.Warnf("could not get ocirepositories: %s", err)
+	}
+	buckets, err := kubeEnv.FluxSources(bucketResource)
+	if err != nil {
+		logrus.Warnf("could not get buckets: %s", err)
+	}
+
 	fluxStateString, err := json.Marshal(api.FluxState{
-		GitReppsitories: gitRepositories,
-		Kustomizations:  kustomizations,
+		GitReppsitories:  gitRepositories,
+		Kustomizations:   kustomizations,
+		HelmReleases:     helmReleases,
+		HelmRepositories: helmRepositories,
+		OCIRepositories:  ociRepositories,
+		Buckets:          buckets,
 	})
 	if err != nil {
 		logrus.Errorf("could not serialize flux state: %v", err)
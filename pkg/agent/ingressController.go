@@ -42,7 +42,7 @@ func IngressController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Co
 
 									URL: rule.Host,
 								}
-								sendUpdate(gimletHost, agentKey, kubeEnv.Name, update)
+								sendUpdate(kubeEnv, gimletHost, agentKey, update)
 							}
 						}
 					}
@@ -67,7 +67,7 @@ func IngressController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Co
 
 									URL: rule.Host,
 								}
-								sendUpdate(gimletHost, agentKey, kubeEnv.Name, update)
+								sendUpdate(kubeEnv, gimletHost, agentKey, update)
 							}
 						}
 					}
@@ -78,9 +78,10 @@ func IngressController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Co
 					Env:     kubeEnv.Name,
 					Subject: informerEvent.key,
 				}
-				sendUpdate(gimletHost, agentKey, kubeEnv.Name, update)
+				sendUpdate(kubeEnv, gimletHost, agentKey, update)
 			}
 			return nil
 		})
+	ingressController.kubeEnv = kubeEnv
 	return ingressController
 }
@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fluxcd/pkg/apis/meta"
@@ -43,6 +45,58 @@ type KubeEnv struct {
 	Namespace     string
 	Client        kubernetes.Interface
 	DynamicClient dynamic.Interface
+
+	// NamespaceSelector scopes the env to the namespaces that match the label selector,
+	// it is used when Namespace is empty
+	NamespaceSelector string
+
+	scopeLock        sync.Mutex
+	scopedNamespaces map[string]bool
+	scopeFetched     time.Time
+
+	metricsLock sync.Mutex
+	podMetrics  map[string]containerUsage
+
+	crashLogsLock    sync.Mutex
+	capturedRestarts map[string]int32
+
+	stateSync stateSync
+
+	stateRequestLock   sync.Mutex
+	stateSendScheduled bool
+}
+
+const namespaceScopeTTL = 30 * time.Second
+
+// InScope tells if a namespace belongs to the env
+func (e *KubeEnv) InScope(namespace string) bool {
+	if e.Namespace != "" {
+		return namespace == e.Namespace
+	}
+	if e.NamespaceSelector == "" {
+		return true
+	}
+
+	e.scopeLock.Lock()
+	defer e.scopeLock.Unlock()
+
+	if time.Since(e.scopeFetched) > namespaceScopeTTL {
+		namespaces, err := e.Client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
+			LabelSelector: e.NamespaceSelector,
+		})
+		if err != nil {
+			logrus.Warnf("could not list namespaces of %s: %s", e.Name, err)
+			return e.scopedNamespaces[namespace]
+		}
+
+		e.scopedNamespaces = map[string]bool{}
+		for _, n := range namespaces.Items {
+			e.scopedNamespaces[n.Name] = true
+		}
+		e.scopeFetched = time.Now()
+	}
+
+	return e.scopedNamespaces[namespace]
 }
 
 func (e *KubeEnv) Services(repo string) ([]*api.Stack, error) {
@@ -57,40 +111,89 @@ func (e *KubeEnv) Services(repo string) ([]*api.Stack, error) {
 		return nil, fmt.Errorf("could not get deployments: %s", err)
 	}
 
+	w, err := e.workloads()
+	if err != nil {
+		return nil, err
+	}
+
 	i, err := e.Client.NetworkingV1().Ingresses(e.Namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("could not get ingresses: %s", err)
 	}
 
+	managedCertificates := e.managedCertificates()
+	certificates := map[string][]*api.Certificate{}
+	for _, ingress := range i.Items {
+		certificates[ingress.Namespace+"/"+ingress.Name] = e.ingressCertificates(ingress, managedCertificates)
+	}
+
 	var stacks []*api.Stack
+	matched := map[string]bool{}
 	for _, service := range annotatedServices {
 		deployment, err := e.deploymentForService(service, d.Items)
 		if err != nil {
 			return nil, fmt.Errorf("could not get deployment for service: %s", err)
 		}
 
+		statefulSet, err := e.statefulSetForService(service, w.statefulSets)
+		if err != nil {
+			return nil, fmt.Errorf("could not get statefulset for service: %s", err)
+		}
+		if statefulSet != nil {
+			matched["statefulset/"+statefulSet.FQN()] = true
+		}
+
+		daemonSet, err := e.daemonSetForService(service, w.daemonSets)
+		if err != nil {
+			return nil, fmt.Errorf("could not get daemonset for service: %s", err)
+		}
+		if daemonSet != nil {
+			matched["daemonset/"+daemonSet.FQN()] = true
+		}
+
+		cronJobs := cronJobsForService(service, w.cronJobs, w.jobs)
+		for _, cronJob := range cronJobs {
+			matched["cronjob/"+cronJob.FQN()] = true
+		}
+
 		var ingresses []*api.Ingress
 		for _, ingress := range i.Items {
 			for _, rule := range ingress.Spec.Rules {
 				for _, path := range rule.HTTP.Paths {
 					if path.Backend.Service.Name == service.Name {
-						ingresses = append(ingresses, &api.Ingress{Name: ingress.Name, Namespace: ingress.Namespace, URL: rule.Host})
+						ingresses = append(ingresses, &api.Ingress{
+							Name:         ingress.Name,
+							Namespace:    ingress.Namespace,
+							URL:          rule.Host,
+							Certificates: certificatesForHost(certificates[ingress.Namespace+"/"+ingress.Name], rule.Host),
+						})
 					}
 				}
 			}
 		}
 
 		stacks = append(stacks, &api.Stack{
-			Repo:       service.ObjectMeta.GetAnnotations()[AnnotationGitRepository],
-			Service:    &api.Service{Name: service.Name, Namespace: service.Namespace},
-			Deployment: deployment,
-			Ingresses:  ingresses,
+			Repo:        service.ObjectMeta.GetAnnotations()[AnnotationGitRepository],
+			Service:     &api.Service{Name: service.Name, Namespace: service.Namespace},
+			Deployment:  deployment,
+			StatefulSet: statefulSet,
+			DaemonSet:   daemonSet,
+			CronJobs:    cronJobs,
+			HPA:         hpaForWorkloads(w.hpas, deployment, statefulSet),
+			Ingresses:   ingresses,
 		})
 	}
 
-	return stacks, nil
+	serviceLessStacks, err := e.serviceLessStacks(repo, w, matched)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(stacks, serviceLessStacks...), nil
 }
 
+// The Flux resources are set to the served versions by DiscoverFluxVersions,
+// the versions here are only fallbacks for clusters not discovered
 var gitRepositoryResource = schema.GroupVersionResource{
 	Group:    "source.toolkit.fluxcd.io",
 	Version:  "v1beta1",
@@ -103,6 +206,39 @@ var kustomizationResource = schema.GroupVersionResource{
 	Resource: "kustomizations",
 }
 
+var helmReleaseResource = schema.GroupVersionResource{
+	Group:    "helm.toolkit.fluxcd.io",
+	Version:  "v2beta1",
+	Resource: "helmreleases",
+}
+
+var helmRepositoryResource = schema.GroupVersionResource{
+	Group:    "source.toolkit.fluxcd.io",
+	Version:  "v1beta2",
+	Resource: "helmrepositories",
+}
+
+var ociRepositoryResource = schema.GroupVersionResource{
+	Group:    "source.toolkit.fluxcd.io",
+	Version:  "v1beta2",
+	Resource: "ocirepositories",
+}
+
+var bucketResource = schema.GroupVersionResource{
+	Group:    "source.toolkit.fluxcd.io",
+	Version:  "v1beta2",
+	Resource: "buckets",
+}
+
+var fluxResources = []*schema.GroupVersionResource{
+	&gitRepositoryResource,
+	&kustomizationResource,
+	&helmReleaseResource,
+	&helmRepositoryResource,
+	&ociRepositoryResource,
+	&bucketResource,
+}
+
 func (e *KubeEnv) GitRepositories() ([]*api.GitRepository, error) {
 	gitRepositories, err := e.DynamicClient.
 		Resource(gitRepositoryResource).
@@ -145,6 +281,41 @@ func (e *KubeEnv) Kustomizations() ([]*api.Kustomization, error) {
 	return result, nil
 }
 
+func (e *KubeEnv) HelmReleases() ([]*api.HelmRelease, error) {
+	helmReleases, err := e.DynamicClient.
+		Resource(helmReleaseResource).
+		Namespace("").
+		List(context.TODO(), meta_v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*api.HelmRelease{}
+	for _, h := range helmReleases.Items {
+		result = append(result, asHelmRelease(h))
+	}
+
+	return result, nil
+}
+
+// FluxSources lists the HelmRepositories, OCIRepositories or Buckets
+func (e *KubeEnv) FluxSources(resource schema.GroupVersionResource) ([]*api.FluxSource, error) {
+	sources, err := e.DynamicClient.
+		Resource(resource).
+		Namespace("").
+		List(context.TODO(), meta_v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*api.FluxSource{}
+	for _, s := range sources.Items {
+		result = append(result, asFluxSource(s))
+	}
+
+	return result, nil
+}
+
 func statusAndMessage(conditions []interface{}) (string, string, int64) {
 	if c := findStatusCondition(conditions, meta.ReadyCondition); c != nil {
 		transitionTime, _ := time.Parse(time.RFC3339, c["lastTransitionTime"].(string))
@@ -222,6 +393,49 @@ func asKustomization(g unstructured.Unstructured) (*api.Kustomization, error) {
 	}, nil
 }
 
+func asHelmRelease(h unstructured.Unstructured) *api.HelmRelease {
+	chart, _, _ := unstructured.NestedString(h.Object, "spec", "chart", "spec", "chart")
+	chartVersion, _, _ := unstructured.NestedString(h.Object, "spec", "chart", "spec", "version")
+	lastAttemptedRevision, _, _ := unstructured.NestedString(h.Object, "status", "lastAttemptedRevision")
+	lastAppliedRevision, _, _ := unstructured.NestedString(h.Object, "status", "lastAppliedRevision")
+	conditions, _, _ := unstructured.NestedSlice(h.Object, "status", "conditions")
+	status, statusDesc, lastTransitionTime := statusAndMessage(conditions)
+
+	return &api.HelmRelease{
+		Name:                  h.GetName(),
+		Namespace:             h.GetNamespace(),
+		Chart:                 chart,
+		ChartVersion:          chartVersion,
+		LastAttemptedRevision: lastAttemptedRevision,
+		LastAppliedRevision:   lastAppliedRevision,
+		LastTransitionTime:    lastTransitionTime,
+		Status:                status,
+		StatusDesc:            statusDesc,
+	}
+}
+
+func asFluxSource(s unstructured.Unstructured) *api.FluxSource {
+	url, _, _ := unstructured.NestedString(s.Object, "spec", "url")
+	if url == "" { // buckets have an endpoint and a bucket name instead
+		endpoint, _, _ := unstructured.NestedString(s.Object, "spec", "endpoint")
+		bucketName, _, _ := unstructured.NestedString(s.Object, "spec", "bucketName")
+		url = endpoint + "/" + bucketName
+	}
+	revision, _, _ := unstructured.NestedString(s.Object, "status", "artifact", "revision")
+	conditions, _, _ := unstructured.NestedSlice(s.Object, "status", "conditions")
+	status, statusDesc, lastTransitionTime := statusAndMessage(conditions)
+
+	return &api.FluxSource{
+		Name:               s.GetName(),
+		Namespace:          s.GetNamespace(),
+		URL:                url,
+		Revision:           revision,
+		LastTransitionTime: lastTransitionTime,
+		Status:             status,
+		StatusDesc:         statusDesc,
+	}
+}
+
 func (e *KubeEnv) WarningEvents(repo string) ([]api.Event, error) {
 	integratedServices, err := e.annotatedServices(repo)
 	if err != nil {
@@ -258,7 +472,7 @@ func (e *KubeEnv) WarningEvents(repo string) ([]api.Event, error) {
 									Namespace:      event.Namespace,
 									Name:           event.InvolvedObject.Name,
 									DeploymentName: deployment.Name,
-									Status:         event.Reason,
+									Status:         eventReason(event),
 									StatusDesc:     event.Message,
 								})
 							}
@@ -280,6 +494,9 @@ func (e *KubeEnv) annotatedServices(repo string) ([]v1.Service, error) {
 
 	var services []v1.Service
 	for _, s := range svc.Items {
+		if !e.InScope(s.Namespace) {
+			continue
+		}
 		if _, ok := s.ObjectMeta.GetAnnotations()[AnnotationGitRepository]; ok {
 			if repo == "" {
 				services = append(services, s)
@@ -297,33 +514,55 @@ func (e *KubeEnv) deploymentForService(service v1.Service, deployments []appsv1.
 
 	for _, d := range deployments {
 		if SelectorsMatch(d.Spec.Selector.MatchLabels, service.Spec.Selector) {
-			var sha string
-			if hash, ok := d.GetAnnotations()[AnnotationGitSha]; ok {
-				sha = hash
-			}
-
-			var pods []*api.Pod
-			set := labels.Set(service.Spec.Selector)
-			p, err := e.Client.CoreV1().Pods(e.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: set.AsSelector().String()})
+			pods, err := e.pods(d.Namespace, service.Spec.Selector, d.Name)
 			if err != nil {
 				return nil, err
 			}
-			for _, pod := range p.Items {
-				podStatus := podStatus(pod)
-				podLogs := ""
-				if "CrashLoopBackOff" == podStatus || "Error" == podStatus {
-					podLogs = logs(e, pod)
-				}
-				pods = append(pods, &api.Pod{Name: pod.Name, DeploymentName: d.Name, Namespace: pod.Namespace, Status: podStatus, StatusDescription: podErrorCause(pod), Logs: podLogs})
-			}
 
-			deployment = &api.Deployment{Name: d.Name, Namespace: d.Namespace, Pods: pods, SHA: sha}
+			deployment = &api.Deployment{Name: d.Name, Namespace: d.Namespace, Pods: pods, SHA: sha(d.ObjectMeta)}
 		}
 	}
 
 	return deployment, nil
 }
 
+// pods returns the pods matching the selector, attributed to the given workload
+func (e *KubeEnv) pods(namespace string, selector map[string]string, workloadName string) ([]*api.Pod, error) {
+	var pods []*api.Pod
+	set := labels.Set(selector)
+	p, err := e.Client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: set.AsSelector().String()})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range p.Items {
+		podStatus := podStatus(pod)
+		podLogs := ""
+		if "CrashLoopBackOff" == podStatus || "OOMKilled" == podStatus || "Error" == podStatus {
+			podLogs = logs(e, pod)
+		}
+		pods = append(pods, &api.Pod{
+			Name:              pod.Name,
+			DeploymentName:    workloadName,
+			Namespace:         pod.Namespace,
+			Status:            podStatus,
+			StatusDescription: podErrorCause(pod),
+			RestartCount:      podRestartCount(pod),
+			LastExitCode:      podLastExitCode(pod),
+			Logs:              podLogs,
+			Containers:        e.containerResources(pod),
+		})
+	}
+
+	return pods, nil
+}
+
+func sha(objectMeta metav1.ObjectMeta) string {
+	if hash, ok := objectMeta.GetAnnotations()[AnnotationGitSha]; ok {
+		return hash
+	}
+	return ""
+}
+
 func logs(e *KubeEnv, pod v1.Pod) string {
 	podLogs := ""
 	req := e.Client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{TailLines: fifty()})
@@ -347,11 +586,14 @@ func fifty() *int64 {
 }
 
 func podErrorCause(pod v1.Pod) string {
-	if v1.PodPending == pod.Status.Phase ||
-		v1.PodRunning == pod.Status.Phase {
+	if v1.PodPending == pod.Status.Phase {
+		return pendingCause(pod)
+	}
+
+	if v1.PodRunning == pod.Status.Phase {
 		for _, containerStatus := range pod.Status.ContainerStatuses {
 			if containerStatus.State.Waiting != nil {
-				return fmt.Sprint(containerStatus.State.Waiting.Message)
+				return waitingCause(containerStatus)
 			}
 		}
 	}
@@ -359,6 +601,46 @@ func podErrorCause(pod v1.Pod) string {
 	return ""
 }
 
+// pendingCause tells why a pod is not running yet: the scheduler's message while it is unschedulable,
+// or why its init or app containers are waiting, eg for an image pull or a volume to be mounted
+func pendingCause(pod v1.Pod) string {
+	if condition := unschedulable(pod); condition != nil {
+		return condition.Message
+	}
+
+	for _, containerStatus := range pod.Status.InitContainerStatuses {
+		if containerStatus.State.Waiting != nil {
+			return fmt.Sprintf("Init container %s: %s", containerStatus.Name, waitingCause(containerStatus))
+		}
+		if containerStatus.State.Running != nil {
+			return fmt.Sprintf("Init container %s is running", containerStatus.Name)
+		}
+	}
+
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.State.Waiting != nil {
+			return fmt.Sprintf("Container %s: %s", containerStatus.Name, waitingCause(containerStatus))
+		}
+	}
+
+	return "Pod is pending"
+}
+
+func waitingCause(containerStatus v1.ContainerStatus) string {
+	waiting := containerStatus.State.Waiting
+	if waiting.Reason == "CrashLoopBackOff" {
+		if oomKilled(containerStatus) {
+			return fmt.Sprintf("Container %s was OOMKilled, restarted %d times", containerStatus.Name, containerStatus.RestartCount)
+		}
+		return fmt.Sprintf("%s (restarted %d times, last exit code %d)",
+			waiting.Message, containerStatus.RestartCount, lastExitCode(containerStatus))
+	}
+	if waiting.Message == "" {
+		return waiting.Reason
+	}
+	return waiting.Message
+}
+
 func podStatus(pod v1.Pod) string {
 	if pod.DeletionTimestamp != nil {
 		return "Terminating" //https://github.com/kubernetes/kubernetes/issues/61376#issuecomment-374437926
@@ -368,14 +650,74 @@ func podStatus(pod v1.Pod) string {
 		v1.PodRunning == pod.Status.Phase {
 		for _, containerStatus := range pod.Status.ContainerStatuses {
 			if containerStatus.State.Waiting != nil {
+				if containerStatus.State.Waiting.Reason == "CrashLoopBackOff" && oomKilled(containerStatus) {
+					return "OOMKilled"
+				}
 				return fmt.Sprint(containerStatus.State.Waiting.Reason)
 			}
 		}
 	}
 
+	if v1.PodPending == pod.Status.Phase {
+		if condition := unschedulable(pod); condition != nil {
+			return condition.Reason
+		}
+	}
+
 	return fmt.Sprint(pod.Status.Phase)
 }
 
+// podRestartCount sums the restarts of all containers in the pod
+func podRestartCount(pod v1.Pod) int32 {
+	var restarts int32
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		restarts += containerStatus.RestartCount
+	}
+	return restarts
+}
+
+// podLastExitCode returns the exit code of the last terminated container in the pod
+func podLastExitCode(pod v1.Pod) int32 {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.LastTerminationState.Terminated != nil {
+			return lastExitCode(containerStatus)
+		}
+	}
+	return 0
+}
+
+func lastExitCode(containerStatus v1.ContainerStatus) int32 {
+	if containerStatus.LastTerminationState.Terminated == nil {
+		return 0
+	}
+	return containerStatus.LastTerminationState.Terminated.ExitCode
+}
+
+func oomKilled(containerStatus v1.ContainerStatus) bool {
+	return containerStatus.LastTerminationState.Terminated != nil &&
+		containerStatus.LastTerminationState.Terminated.Reason == "OOMKilled"
+}
+
+// unschedulable returns the PodScheduled condition if the scheduler could not place the pod
+func unschedulable(pod v1.Pod) *v1.PodCondition {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse {
+			c := condition
+			return &c
+		}
+	}
+	return nil
+}
+
+// eventReason tells readiness probe failures apart,
+// as Kubernetes reports all probe failures with the Unhealthy reason
+func eventReason(event v1.Event) string {
+	if event.Reason == "Unhealthy" && strings.HasPrefix(event.Message, "Readiness probe failed") {
+		return "ReadinessProbeFailed"
+	}
+	return event.Reason
+}
+
 func SelectorsMatch(first map[string]string, second map[string]string) bool {
 	if len(first) != len(second) {
 		return false
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestInScope(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments-api", Labels: map[string]string{"team": "payments"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments-worker", Labels: map[string]string{"team": "payments"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "search", Labels: map[string]string{"team": "search"}}},
+	)
+
+	clusterScoped := &KubeEnv{Name: "staging", Client: client}
+	assert.True(t, clusterScoped.InScope("search"))
+
+	namespaceScoped := &KubeEnv{Name: "staging", Namespace: "search", Client: client}
+	assert.True(t, namespaceScoped.InScope("search"))
+	assert.False(t, namespaceScoped.InScope("payments-api"))
+
+	selectorScoped := &KubeEnv{Name: "payments", NamespaceSelector: "team=payments", Client: client}
+	assert.True(t, selectorScoped.InScope("payments-api"))
+	assert.True(t, selectorScoped.InScope("payments-worker"))
+	assert.False(t, selectorScoped.InScope("search"))
+}
+
+func TestAsHelmRelease(t *testing.T) {
+	helmRelease := asHelmRelease(unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "redis", "namespace": "infrastructure"},
+		"spec": map[string]interface{}{
+			"chart": map[string]interface{}{
+				"spec": map[string]interface{}{"chart": "redis", "version": "17.3.2"},
+			},
+		},
+		"status": map[string]interface{}{
+			"lastAttemptedRevision": "17.3.2",
+			"lastAppliedRevision":   "17.3.1",
+			"conditions": []interface{}{
+				map[string]interface{}{
+					"type":               "Ready",
+					"status":             "False",
+					"reason":             "UpgradeFailed",
+					"message":            "Helm upgrade failed: timed out waiting for the condition",
+					"lastTransitionTime": "2023-06-01T10:00:00Z",
+				},
+			},
+		},
+	}})
+
+	assert.Equal(t, "redis", helmRelease.Chart)
+	assert.Equal(t, "17.3.2", helmRelease.ChartVersion)
+	assert.Equal(t, "17.3.1", helmRelease.LastAppliedRevision)
+	assert.Equal(t, "UpgradeFailed", helmRelease.Status)
+	assert.Equal(t, "Helm upgrade failed: timed out waiting for the condition", helmRelease.StatusDesc)
+
+	bucket := asFluxSource(unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "manifests", "namespace": "flux-system"},
+		"spec":     map[string]interface{}{"endpoint": "minio.example.com", "bucketName": "manifests"},
+		"status": map[string]interface{}{
+			"artifact": map[string]interface{}{"revision": "sha256:abc"},
+		},
+	}})
+	assert.Equal(t, "minio.example.com/manifests", bucket.URL)
+	assert.Equal(t, "sha256:abc", bucket.Revision)
+}
+
+func TestPendingPodCause(t *testing.T) {
+	unschedulable := v1.Pod{Status: v1.PodStatus{
+		Phase: v1.PodPending,
+		Conditions: []v1.PodCondition{
+			{Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: "Unschedulable", Message: "0/3 nodes are available: 3 Insufficient cpu."},
+		},
+	}}
+	assert.Equal(t, "Unschedulable", podStatus(unschedulable))
+	assert.Equal(t, "0/3 nodes are available: 3 Insufficient cpu.", podErrorCause(unschedulable))
+
+	waitingForVolume := v1.Pod{Status: v1.PodStatus{
+		Phase: v1.PodPending,
+		ContainerStatuses: []v1.ContainerStatus{
+			{Name: "app", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+		},
+	}}
+	assert.Equal(t, "ContainerCreating", podStatus(waitingForVolume))
+	assert.Equal(t, "Container app: ContainerCreating", podErrorCause(waitingForVolume))
+
+	initializing := v1.Pod{Status: v1.PodStatus{
+		Phase: v1.PodPending,
+		InitContainerStatuses: []v1.ContainerStatus{
+			{Name: "migrations", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+		},
+		ContainerStatuses: []v1.ContainerStatus{
+			{Name: "app", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+		},
+	}}
+	assert.Equal(t, "PodInitializing", podStatus(initializing))
+	assert.Equal(t, "Init container migrations is running", podErrorCause(initializing))
+
+	notScheduledYet := v1.Pod{Status: v1.PodStatus{Phase: v1.PodPending}}
+	assert.Equal(t, "Pending", podStatus(notScheduledYet))
+	assert.Equal(t, "Pod is pending", podErrorCause(notScheduledYet))
+}
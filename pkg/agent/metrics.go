@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var podMetricsResource = schema.GroupVersionResource{
+	Group:    "metrics.k8s.io",
+	Version:  "v1beta1",
+	Resource: "pods",
+}
+
+type containerUsage struct {
+	cpu    int64 // millicores
+	memory int64 // bytes
+}
+
+// PollPodMetrics periodically fetches the pod usage figures from metrics-server
+// and sends the state upstream, so utilization stays fresh between cluster changes
+func PollPodMetrics(kubeEnv *KubeEnv, gimletHost string, agentKey string, interval time.Duration, stopCh chan struct{}) {
+	for {
+		err := kubeEnv.FetchPodMetrics()
+		if err != nil {
+			logrus.Debugf("could not get pod metrics, is metrics-server installed? %s", err)
+		} else {
+			SendState(kubeEnv, gimletHost, agentKey)
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// FetchPodMetrics caches the current container usage of every pod in the env
+func (e *KubeEnv) FetchPodMetrics() error {
+	podMetrics, err := e.DynamicClient.
+		Resource(podMetricsResource).
+		Namespace(e.Namespace).
+		List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	usage := map[string]containerUsage{}
+	for _, p := range podMetrics.Items {
+		if !e.InScope(p.GetNamespace()) {
+			continue
+		}
+
+		containers, _, _ := unstructured.NestedSlice(p.Object, "containers")
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(container, "name")
+			cpu, _, _ := unstructured.NestedString(container, "usage", "cpu")
+			memory, _, _ := unstructured.NestedString(container, "usage", "memory")
+
+			usage[p.GetNamespace()+"/"+p.GetName()+"/"+name] = containerUsage{
+				cpu:    milliValue(cpu),
+				memory: value(memory),
+			}
+		}
+	}
+
+	e.metricsLock.Lock()
+	e.podMetrics = usage
+	e.metricsLock.Unlock()
+
+	return nil
+}
+
+// containerResources returns the requests and limits of the pod's containers,
+// with the usage figures from the last metrics fetch
+func (e *KubeEnv) containerResources(pod v1.Pod) []*api.ContainerResources {
+	e.metricsLock.Lock()
+	defer e.metricsLock.Unlock()
+
+	var resources []*api.ContainerResources
+	for _, c := range pod.Spec.Containers {
+		usage := e.podMetrics[pod.Namespace+"/"+pod.Name+"/"+c.Name]
+		resources = append(resources, &api.ContainerResources{
+			Name:          c.Name,
+			CPURequest:    c.Resources.Requests.Cpu().MilliValue(),
+			CPULimit:      c.Resources.Limits.Cpu().MilliValue(),
+			CPUUsage:      usage.cpu,
+			MemoryRequest: c.Resources.Requests.Memory().Value(),
+			MemoryLimit:   c.Resources.Limits.Memory().Value(),
+			MemoryUsage:   usage.memory,
+		})
+	}
+
+	return resources
+}
+
+func milliValue(quantity string) int64 {
+	q, err := resource.ParseQuantity(quantity)
+	if err != nil {
+		return 0
+	}
+	return q.MilliValue()
+}
+
+func value(quantity string) int64 {
+	q, err := resource.ParseQuantity(quantity)
+	if err != nil {
+		return 0
+	}
+	return q.Value()
+}
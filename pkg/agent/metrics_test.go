@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestContainerResources(t *testing.T) {
+	podMetrics := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata": map[string]interface{}{
+			"name":      "myapp-abc",
+			"namespace": "default",
+		},
+		"containers": []interface{}{
+			map[string]interface{}{
+				"name": "myapp",
+				"usage": map[string]interface{}{
+					"cpu":    "125000000n",
+					"memory": "256Mi",
+				},
+			},
+		},
+	}}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{podMetricsResource: "PodMetricsList"},
+	)
+	// metrics.k8s.io serves PodMetrics as pods, so the object is created on the resource, not guessed from its kind
+	_, err := dynamicClient.Resource(podMetricsResource).Namespace("default").Create(context.TODO(), podMetrics, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	kubeEnv := &KubeEnv{Name: "staging", DynamicClient: dynamicClient}
+
+	err = kubeEnv.FetchPodMetrics()
+	assert.Nil(t, err)
+
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "myapp-abc", Namespace: "default"},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{
+				Name: "myapp",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("100m"),
+						v1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+					Limits: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse("500m"),
+						v1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+				},
+			},
+			{Name: "sidecar"},
+		}},
+	}
+
+	resources := kubeEnv.containerResources(pod)
+	assert.Equal(t, 2, len(resources))
+	assert.Equal(t, int64(100), resources[0].CPURequest)
+	assert.Equal(t, int64(500), resources[0].CPULimit)
+	assert.Equal(t, int64(125), resources[0].CPUUsage)
+	assert.Equal(t, int64(512*1024*1024), resources[0].MemoryLimit)
+	assert.Equal(t, int64(256*1024*1024), resources[0].MemoryUsage)
+	assert.Equal(t, int64(0), resources[1].MemoryUsage, "sidecar has no usage figures")
+}
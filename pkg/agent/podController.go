@@ -49,7 +49,7 @@ func PodController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Contro
 									Status:     string(createdPod.Status.Phase),
 									Deployment: deployment.Namespace + "/" + deployment.Name,
 								}
-								sendUpdate(gimletHost, agentKey, kubeEnv.Name, update)
+								sendUpdate(kubeEnv, gimletHost, agentKey, update)
 							}
 						}
 					}
@@ -77,9 +77,10 @@ func PodController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Contro
 								updatedPod.Namespace == deployment.Namespace {
 								podStatus := podStatus(*updatedPod)
 								podLogs := ""
-								if "CrashLoopBackOff" == podStatus {
+								if "CrashLoopBackOff" == podStatus || "OOMKilled" == podStatus {
 									podLogs = logs(kubeEnv, *updatedPod)
 								}
+								crashLogs := kubeEnv.crashLogs(*updatedPod)
 
 								update := &api.StackUpdate{
 									Event:   EventPodUpdated,
@@ -88,26 +89,31 @@ func PodController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Contro
 									Subject: objectMeta.Namespace + "/" + objectMeta.Name,
 									Svc:     svc.Namespace + "/" + svc.Name,
 
-									Status:     podStatus,
-									Deployment: deployment.Namespace + "/" + deployment.Name,
-									ErrorCause: podErrorCause(*updatedPod),
-									Logs:       podLogs,
+									Status:       podStatus,
+									Deployment:   deployment.Namespace + "/" + deployment.Name,
+									ErrorCause:   podErrorCause(*updatedPod),
+									RestartCount: podRestartCount(*updatedPod),
+									LastExitCode: podLastExitCode(*updatedPod),
+									Logs:         podLogs,
+									CrashLogs:    crashLogs,
 								}
-								sendUpdate(gimletHost, agentKey, kubeEnv.Name, update)
+								sendUpdate(kubeEnv, gimletHost, agentKey, update)
 							}
 						}
 					}
 				}
 			case "delete":
+				kubeEnv.forgetCrashLogs(informerEvent.key)
 				update := &api.StackUpdate{
 					Event:   EventPodDeleted,
 					Env:     kubeEnv.Name,
 					Subject: informerEvent.key,
 				}
-				sendUpdate(gimletHost, agentKey, kubeEnv.Name, update)
+				sendUpdate(kubeEnv, gimletHost, agentKey, update)
 			}
 			return nil
 		})
+	podController.kubeEnv = kubeEnv
 	return podController
 }
 
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/sirupsen/logrus"
+)
+
+// maxBufferedUpdates caps the stack updates kept while the dashboard is unreachable
+const maxBufferedUpdates = 1000
+
+// stateSync tracks what state the dashboard has acknowledged, so only the changes
+// are sent upstream. Changes that could not be delivered stay in the next delta,
+// and stack updates are buffered until the dashboard is reachable again
+type stateSync struct {
+	lock sync.Mutex
+
+	session string
+	version int64
+	acked   map[string]string // serialized stacks by key, as of the acknowledged version
+
+	updates  []bufferedUpdate
+	sequence int64 // of the last buffered update
+}
+
+type bufferedUpdate struct {
+	sequence int64
+	update   interface{}
+}
+
+// errStaleState is returned when the dashboard doesn't know the version the delta is based on
+var errStaleState = fmt.Errorf("dashboard has a different state version")
+
+// delta returns the changes since the acknowledged state, and the serialized stacks
+// that become the acknowledged state once the dashboard accepts the delta
+func (s *stateSync) delta(stacks []*api.Stack) (*api.StateDelta, map[string]string, error) {
+	if s.session == "" {
+		s.session = newSession()
+	}
+
+	current := map[string]string{}
+	delta := &api.StateDelta{
+		Session:     s.session,
+		BaseVersion: s.version,
+		Version:     s.version + 1,
+		Full:        s.acked == nil,
+	}
+
+	for _, stack := range stacks {
+		serialized, err := json.Marshal(stack)
+		if err != nil {
+			return nil, nil, err
+		}
+		key := stack.Key()
+		current[key] = string(serialized)
+
+		if delta.Full || s.acked[key] != string(serialized) {
+			delta.Upserted = append(delta.Upserted, stack)
+		}
+	}
+
+	if !delta.Full {
+		for key := range s.acked {
+			if _, ok := current[key]; !ok {
+				delta.Deleted = append(delta.Deleted, key)
+			}
+		}
+	}
+
+	return delta, current, nil
+}
+
+// mark returns the sequence of the last buffered update.
+// Take it before reading the stacks, as the stacks cover the updates buffered up to the mark
+func (s *stateSync) mark() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sequence
+}
+
+// send syncs the stacks, then sends the buffered updates that happened after the stacks were read.
+// Older updates are dropped, as they would apply stale events on top of the synced state
+func (s *stateSync) send(kubeEnv *KubeEnv, stacks []*api.Stack, mark int64, gimletHost string, agentKey string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delta, current, err := s.delta(stacks)
+	if err != nil {
+		return fmt.Errorf("could not compute state delta: %s", err)
+	}
+
+	err = postDelta(kubeEnv.Name, delta, gimletHost, agentKey)
+	if err == errStaleState {
+		logrus.Infof("dashboard lost track of the %s state, sending it in full", kubeEnv.Name)
+		s.acked = nil
+		delta, current, err = s.delta(stacks)
+		if err != nil {
+			return fmt.Errorf("could not compute state delta: %s", err)
+		}
+		err = postDelta(kubeEnv.Name, delta, gimletHost, agentKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.version = delta.Version
+	s.acked = current
+
+	s.dropUpdates(mark)
+	s.flushUpdates(kubeEnv, gimletHost, agentKey)
+	return nil
+}
+
+func (s *stateSync) bufferUpdate(update interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sequence++
+	s.updates = append(s.updates, bufferedUpdate{sequence: s.sequence, update: update})
+	if len(s.updates) > maxBufferedUpdates {
+		s.updates = s.updates[len(s.updates)-maxBufferedUpdates:]
+	}
+}
+
+// dropUpdates removes the buffered updates up to the mark, as the synced state already covers them
+func (s *stateSync) dropUpdates(mark int64) {
+	for len(s.updates) > 0 && s.updates[0].sequence <= mark {
+		s.updates = s.updates[1:]
+	}
+}
+
+// flushUpdates sends the buffered updates in order, and keeps the ones that failed again
+func (s *stateSync) flushUpdates(kubeEnv *KubeEnv, gimletHost string, agentKey string) {
+	for len(s.updates) > 0 {
+		err := postUpdate(gimletHost, agentKey, kubeEnv.Name, s.updates[0].update)
+		if err != nil {
+			logrus.Warnf("could not send buffered update: %s", err)
+			return
+		}
+		s.updates = s.updates[1:]
+	}
+}
+
+func postDelta(env string, delta *api.StateDelta, gimletHost string, agentKey string) error {
+	deltaString, err := json.Marshal(delta)
+	if err != nil {
+		return fmt.Errorf("could not serialize state delta: %s", err)
+	}
+
+	params := url.Values{}
+	params.Add("name", env)
+	reqUrl := fmt.Sprintf("%s/agent/state/delta?%s", gimletHost, params.Encode())
+	req, err := http.NewRequest("POST", reqUrl, bytes.NewBuffer(deltaString))
+	if err != nil {
+		return fmt.Errorf("could not create http request: %s", err)
+	}
+	req.Header.Set("Authorization", "BEARER "+agentKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := httpClient()
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not send state delta: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict {
+		return errStaleState
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("could not send state delta: %d - %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+func newSession() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
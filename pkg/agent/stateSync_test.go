@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func stack(name string, sha string) *api.Stack {
+	return &api.Stack{
+		Repo:       "gimlet-io/" + name,
+		Service:    &api.Service{Name: name, Namespace: "default"},
+		Deployment: &api.Deployment{Name: name, Namespace: "default", SHA: sha},
+	}
+}
+
+// dashboard mimics the state delta endpoint of the dashboard
+func dashboard(t *testing.T, connectedAgent *streaming.ConnectedAgent, deltas *[]*api.StateDelta, updates *[]api.StackUpdate, reachable *bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !*reachable {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.URL.Path == "/agent/state/staging/update" {
+			var update api.StackUpdate
+			err := json.NewDecoder(r.Body).Decode(&update)
+			assert.Nil(t, err)
+			*updates = append(*updates, update)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.URL.Path != "/agent/state/delta" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		var delta api.StateDelta
+		err := json.NewDecoder(r.Body).Decode(&delta)
+		assert.Nil(t, err)
+		*deltas = append(*deltas, &delta)
+
+		err = connectedAgent.ApplyStateDelta(&delta)
+		if err != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestStateSync(t *testing.T) {
+	connectedAgent := &streaming.ConnectedAgent{Name: "staging"}
+	var deltas []*api.StateDelta
+	var updates []api.StackUpdate
+	reachable := true
+	server := dashboard(t, connectedAgent, &deltas, &updates, &reachable)
+	defer server.Close()
+
+	kubeEnv := &KubeEnv{Name: "staging"}
+
+	err := kubeEnv.stateSync.send(kubeEnv, []*api.Stack{stack("app1", "a"), stack("app2", "a")}, kubeEnv.stateSync.mark(), server.URL, "")
+	assert.Nil(t, err)
+	assert.True(t, deltas[0].Full, "first sync should send the state in full")
+	assert.Equal(t, 2, len(connectedAgent.Stacks))
+
+	err = kubeEnv.stateSync.send(kubeEnv, []*api.Stack{stack("app1", "b"), stack("app2", "a")}, kubeEnv.stateSync.mark(), server.URL, "")
+	assert.Nil(t, err)
+	assert.False(t, deltas[1].Full)
+	assert.Equal(t, 1, len(deltas[1].Upserted), "only the changed stack should be sent")
+	assert.Equal(t, "b", connectedAgent.Stacks[0].Deployment.SHA)
+	assert.Equal(t, "staging", connectedAgent.Stacks[0].Env)
+
+	reachable = false
+	err = kubeEnv.stateSync.send(kubeEnv, []*api.Stack{stack("app1", "b")}, kubeEnv.stateSync.mark(), server.URL, "")
+	assert.NotNil(t, err)
+	kubeEnv.stateSync.bufferUpdate(api.StackUpdate{Event: EventPodDeleted})
+	assert.Equal(t, 1, len(kubeEnv.stateSync.updates))
+
+	reachable = true
+	err = kubeEnv.stateSync.send(kubeEnv, []*api.Stack{stack("app1", "b")}, kubeEnv.stateSync.mark(), server.URL, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default/app2"}, deltas[2].Deleted, "changes missed while disconnected should be sent")
+	assert.Equal(t, 1, len(connectedAgent.Stacks))
+	assert.Equal(t, 0, len(kubeEnv.stateSync.updates), "buffered updates covered by the synced state should be dropped")
+	assert.Equal(t, 0, len(updates), "stale updates should not be sent")
+
+	restartedDashboard := &streaming.ConnectedAgent{Name: "staging"}
+	*connectedAgent = *restartedDashboard
+	err = kubeEnv.stateSync.send(kubeEnv, []*api.Stack{stack("app1", "b")}, kubeEnv.stateSync.mark(), server.URL, "")
+	assert.Nil(t, err)
+	assert.False(t, deltas[3].Full)
+	assert.True(t, deltas[4].Full, "state should be resent in full after the dashboard lost it")
+	assert.Equal(t, 1, len(connectedAgent.Stacks))
+}
+
+func TestStateSyncDropsStaleUpdates(t *testing.T) {
+	connectedAgent := &streaming.ConnectedAgent{Name: "staging"}
+	var deltas []*api.StateDelta
+	var updates []api.StackUpdate
+	reachable := false
+	server := dashboard(t, connectedAgent, &deltas, &updates, &reachable)
+	defer server.Close()
+
+	kubeEnv := &KubeEnv{Name: "staging"}
+	kubeEnv.stateSync.bufferUpdate(api.StackUpdate{Event: EventPodUpdated, Subject: "default/app1-1", Status: "CrashLoopBackOff"})
+
+	reachable = true
+	mark := kubeEnv.stateSync.mark()
+	stacks := []*api.Stack{stack("app1", "a")}
+	// the pod changes after the stacks were read
+	kubeEnv.stateSync.bufferUpdate(api.StackUpdate{Event: EventPodDeleted, Subject: "default/app1-1"})
+
+	err := kubeEnv.stateSync.send(kubeEnv, stacks, mark, server.URL, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(updates), "only the updates newer than the synced state should be sent")
+	assert.Equal(t, EventPodDeleted, updates[0].Event)
+	assert.Equal(t, 0, len(kubeEnv.stateSync.updates))
+}
+
+func TestRequestStateCoalesces(t *testing.T) {
+	defer func(delay time.Duration) { stateSendDelay = delay }(stateSendDelay)
+	stateSendDelay = 100 * time.Millisecond
+
+	var sends int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/agent/state/delta" {
+			atomic.AddInt32(&sends, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	kubeEnv := &KubeEnv{Name: "staging", Namespace: "default", Client: fake.NewSimpleClientset()}
+	for i := 0; i < 20; i++ {
+		RequestState(kubeEnv, server.URL, "")
+	}
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&sends), "a burst of changes should be sent once")
+
+	RequestState(kubeEnv, server.URL, "")
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&sends), "a later change should be sent again")
+}
@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	apps_v1 "k8s.io/api/apps/v1"
+	autoscaling_v2 "k8s.io/api/autoscaling/v2"
+	batch_v1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	rntme "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+func StatefulSetController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Controller {
+	return stateController(kubeEnv, gimletHost, agentKey, "statefulset",
+		kubeEnv.Client.AppsV1().RESTClient(), "statefulsets", &apps_v1.StatefulSet{})
+}
+
+func DaemonSetController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Controller {
+	return stateController(kubeEnv, gimletHost, agentKey, "daemonset",
+		kubeEnv.Client.AppsV1().RESTClient(), "daemonsets", &apps_v1.DaemonSet{})
+}
+
+func CronJobController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Controller {
+	return stateController(kubeEnv, gimletHost, agentKey, "cronjob",
+		kubeEnv.Client.BatchV1().RESTClient(), "cronjobs", &batch_v1.CronJob{})
+}
+
+// JobController keeps the last job outcome of cronjobs up to date
+func JobController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Controller {
+	return stateController(kubeEnv, gimletHost, agentKey, "job",
+		kubeEnv.Client.BatchV1().RESTClient(), "jobs", &batch_v1.Job{})
+}
+
+func HPAController(kubeEnv *KubeEnv, gimletHost string, agentKey string) *Controller {
+	return stateController(kubeEnv, gimletHost, agentKey, "hpa",
+		kubeEnv.Client.AutoscalingV2().RESTClient(), "horizontalpodautoscalers", &autoscaling_v2.HorizontalPodAutoscaler{})
+}
+
+// stateController sends the state upstream on the changes of a resource.
+// The changes are coalesced, as HPAs and jobs change constantly on busy clusters
+func stateController(
+	kubeEnv *KubeEnv,
+	gimletHost string,
+	agentKey string,
+	name string,
+	restClient rest.Interface,
+	resource string,
+	objType rntme.Object,
+) *Controller {
+	listWatcher := cache.NewListWatchFromClient(restClient, resource, v1.NamespaceAll, fields.Everything())
+	controller := NewController(
+		name,
+		listWatcher,
+		objType,
+		func(informerEvent Event, objectMeta meta_v1.ObjectMeta, obj interface{}) error {
+			switch informerEvent.eventType {
+			case "create":
+				fallthrough
+			case "update":
+				fallthrough
+			case "delete":
+				RequestState(kubeEnv, gimletHost, agentKey)
+			}
+			return nil
+		})
+	controller.kubeEnv = kubeEnv
+	return controller
+}
+
+// stateSendDelay is how long state requests are collected before the state is sent
+var stateSendDelay = 5 * time.Second
+
+// RequestState schedules a state send. Requests that arrive until the send are served by the same one,
+// so a burst of changes lists the env's workloads only once
+func RequestState(kubeEnv *KubeEnv, gimletHost string, agentKey string) {
+	kubeEnv.stateRequestLock.Lock()
+	defer kubeEnv.stateRequestLock.Unlock()
+
+	if kubeEnv.stateSendScheduled {
+		return
+	}
+	kubeEnv.stateSendScheduled = true
+
+	time.AfterFunc(stateSendDelay, func() {
+		kubeEnv.stateRequestLock.Lock()
+		kubeEnv.stateSendScheduled = false
+		kubeEnv.stateRequestLock.Unlock()
+
+		SendState(kubeEnv, gimletHost, agentKey)
+	})
+}
+
+// SendState sends the changes of the env's state since the version the dashboard acknowledged
+func SendState(kubeEnv *KubeEnv, gimletHost string, agentKey string) {
+	mark := kubeEnv.stateSync.mark()
+	stacks, err := kubeEnv.Services("")
+	if err != nil {
+		logrus.Errorf("could not get state from k8s apiServer: %v", err)
+		return
+	}
+
+	err = kubeEnv.stateSync.send(kubeEnv, stacks, mark, gimletHost, agentKey)
+	if err != nil {
+		logrus.Errorf("could not sync k8s state: %v", err)
+	}
+}
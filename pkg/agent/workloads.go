@@ -0,0 +1,307 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// workloads holds the cluster objects that are paired with annotated services next to deployments
+type workloads struct {
+	statefulSets []appsv1.StatefulSet
+	daemonSets   []appsv1.DaemonSet
+	cronJobs     []batchv1.CronJob
+	jobs         []batchv1.Job
+	hpas         []autoscalingv2.HorizontalPodAutoscaler
+}
+
+func (e *KubeEnv) workloads() (*workloads, error) {
+	s, err := e.Client.AppsV1().StatefulSets(e.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get statefulsets: %s", err)
+	}
+
+	ds, err := e.Client.AppsV1().DaemonSets(e.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get daemonsets: %s", err)
+	}
+
+	c, err := e.Client.BatchV1().CronJobs(e.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get cronjobs: %s", err)
+	}
+
+	j, err := e.Client.BatchV1().Jobs(e.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get jobs: %s", err)
+	}
+
+	h, err := e.Client.AutoscalingV2().HorizontalPodAutoscalers(e.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get horizontal pod autoscalers: %s", err)
+	}
+
+	return &workloads{
+		statefulSets: s.Items,
+		daemonSets:   ds.Items,
+		cronJobs:     c.Items,
+		jobs:         j.Items,
+		hpas:         h.Items,
+	}, nil
+}
+
+func (e *KubeEnv) statefulSetForService(service v1.Service, statefulSets []appsv1.StatefulSet) (*api.StatefulSet, error) {
+	for _, s := range statefulSets {
+		if s.Namespace != service.Namespace ||
+			!SelectorsMatch(s.Spec.Selector.MatchLabels, service.Spec.Selector) {
+			continue
+		}
+
+		return e.asStatefulSet(s)
+	}
+
+	return nil, nil
+}
+
+func (e *KubeEnv) asStatefulSet(s appsv1.StatefulSet) (*api.StatefulSet, error) {
+	pods, err := e.pods(s.Namespace, s.Spec.Selector.MatchLabels, s.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var replicas int32 = 1
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+
+	return &api.StatefulSet{
+		Name:          s.Name,
+		Namespace:     s.Namespace,
+		Pods:          pods,
+		SHA:           sha(s.ObjectMeta),
+		Replicas:      replicas,
+		ReadyReplicas: s.Status.ReadyReplicas,
+	}, nil
+}
+
+func (e *KubeEnv) daemonSetForService(service v1.Service, daemonSets []appsv1.DaemonSet) (*api.DaemonSet, error) {
+	for _, d := range daemonSets {
+		if d.Namespace != service.Namespace ||
+			!SelectorsMatch(d.Spec.Selector.MatchLabels, service.Spec.Selector) {
+			continue
+		}
+
+		return e.asDaemonSet(d)
+	}
+
+	return nil, nil
+}
+
+func (e *KubeEnv) asDaemonSet(d appsv1.DaemonSet) (*api.DaemonSet, error) {
+	pods, err := e.pods(d.Namespace, d.Spec.Selector.MatchLabels, d.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.DaemonSet{
+		Name:      d.Name,
+		Namespace: d.Namespace,
+		Pods:      pods,
+		SHA:       sha(d.ObjectMeta),
+		Desired:   d.Status.DesiredNumberScheduled,
+		Ready:     d.Status.NumberReady,
+	}, nil
+}
+
+// cronJobsForService returns the cronjobs whose job pods the service would select
+func cronJobsForService(service v1.Service, cronJobs []batchv1.CronJob, jobs []batchv1.Job) []*api.CronJob {
+	var result []*api.CronJob
+	for _, c := range cronJobs {
+		if c.Namespace != service.Namespace ||
+			len(service.Spec.Selector) == 0 ||
+			!HasLabels(service.Spec.Selector, c.Spec.JobTemplate.Spec.Template.Labels) {
+			continue
+		}
+
+		result = append(result, asCronJob(c, jobs))
+	}
+
+	return result
+}
+
+func asCronJob(c batchv1.CronJob, jobs []batchv1.Job) *api.CronJob {
+	return &api.CronJob{
+		Name:      c.Name,
+		Namespace: c.Namespace,
+		Schedule:  c.Spec.Schedule,
+		Suspended: c.Spec.Suspend != nil && *c.Spec.Suspend,
+		SHA:       sha(c.ObjectMeta),
+		LastJob:   lastJob(c, jobs),
+	}
+}
+
+// lastJob returns the most recently created job of a cronjob
+func lastJob(c batchv1.CronJob, jobs []batchv1.Job) *api.Job {
+	var last *batchv1.Job
+	for idx, j := range jobs {
+		if j.Namespace != c.Namespace || !ownedBy(j.ObjectMeta, "CronJob", c.Name) {
+			continue
+		}
+		if last == nil || last.CreationTimestamp.Before(&j.CreationTimestamp) {
+			last = &jobs[idx]
+		}
+	}
+
+	if last == nil {
+		return nil
+	}
+
+	job := &api.Job{
+		Name:      last.Name,
+		Namespace: last.Namespace,
+		Status:    jobStatus(*last),
+	}
+	if last.Status.StartTime != nil {
+		job.StartTime = last.Status.StartTime.Unix()
+	}
+	if last.Status.CompletionTime != nil {
+		job.CompletionTime = last.Status.CompletionTime.Unix()
+	}
+	return job
+}
+
+func jobStatus(job batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return api.JobSucceeded
+		case batchv1.JobFailed:
+			return api.JobFailed
+		}
+	}
+
+	return api.JobRunning
+}
+
+func ownedBy(objectMeta metav1.ObjectMeta, kind string, name string) bool {
+	for _, owner := range objectMeta.OwnerReferences {
+		if owner.Kind == kind && owner.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hpaForWorkloads returns the autoscaler that targets the deployment or the statefulset of a stack
+func hpaForWorkloads(
+	hpas []autoscalingv2.HorizontalPodAutoscaler,
+	deployment *api.Deployment,
+	statefulSet *api.StatefulSet,
+) *api.HPA {
+	for _, h := range hpas {
+		target := h.Spec.ScaleTargetRef
+		if !(deployment != nil && target.Kind == "Deployment" && h.Namespace+"/"+target.Name == deployment.FQN()) &&
+			!(statefulSet != nil && target.Kind == "StatefulSet" && h.Namespace+"/"+target.Name == statefulSet.FQN()) {
+			continue
+		}
+
+		var minReplicas int32 = 1
+		if h.Spec.MinReplicas != nil {
+			minReplicas = *h.Spec.MinReplicas
+		}
+
+		return &api.HPA{
+			Name:            h.Name,
+			Namespace:       h.Namespace,
+			MinReplicas:     minReplicas,
+			MaxReplicas:     h.Spec.MaxReplicas,
+			CurrentReplicas: h.Status.CurrentReplicas,
+			DesiredReplicas: h.Status.DesiredReplicas,
+		}
+	}
+
+	return nil
+}
+
+// serviceLessStacks makes stacks of the annotated statefulsets, daemonsets and cronjobs
+// that no annotated service selects. Log shippers and batch jobs seldom have a service,
+// so these stacks are named after the workload
+func (e *KubeEnv) serviceLessStacks(repo string, w *workloads, matched map[string]bool) ([]*api.Stack, error) {
+	var stacks []*api.Stack
+
+	for _, s := range w.statefulSets {
+		workloadRepo, ok := e.annotatedWith(s.ObjectMeta, repo)
+		if !ok || matched["statefulset/"+s.Namespace+"/"+s.Name] {
+			continue
+		}
+
+		statefulSet, err := e.asStatefulSet(s)
+		if err != nil {
+			return nil, fmt.Errorf("could not get statefulset: %s", err)
+		}
+		stacks = append(stacks, &api.Stack{
+			Repo:        workloadRepo,
+			Service:     &api.Service{Name: s.Name, Namespace: s.Namespace},
+			StatefulSet: statefulSet,
+			HPA:         hpaForWorkloads(w.hpas, nil, statefulSet),
+		})
+	}
+
+	for _, d := range w.daemonSets {
+		workloadRepo, ok := e.annotatedWith(d.ObjectMeta, repo)
+		if !ok || matched["daemonset/"+d.Namespace+"/"+d.Name] {
+			continue
+		}
+
+		daemonSet, err := e.asDaemonSet(d)
+		if err != nil {
+			return nil, fmt.Errorf("could not get daemonset: %s", err)
+		}
+		stacks = append(stacks, &api.Stack{
+			Repo:      workloadRepo,
+			Service:   &api.Service{Name: d.Name, Namespace: d.Namespace},
+			DaemonSet: daemonSet,
+		})
+	}
+
+	for _, c := range w.cronJobs {
+		workloadRepo, ok := e.annotatedWith(c.ObjectMeta, repo)
+		if !ok || matched["cronjob/"+c.Namespace+"/"+c.Name] {
+			continue
+		}
+
+		stacks = append(stacks, &api.Stack{
+			Repo:     workloadRepo,
+			Service:  &api.Service{Name: c.Name, Namespace: c.Namespace},
+			CronJobs: []*api.CronJob{asCronJob(c, w.jobs)},
+		})
+	}
+
+	return stacks, nil
+}
+
+// annotatedWith returns the git repository annotation of an object, if it is enabled for Gimlet
+// and belongs to the env and the given repo. An empty repo matches every repository
+func (e *KubeEnv) annotatedWith(objectMeta metav1.ObjectMeta, repo string) (string, bool) {
+	if !e.InScope(objectMeta.Namespace) {
+		return "", false
+	}
+
+	workloadRepo, ok := objectMeta.GetAnnotations()[AnnotationGitRepository]
+	if !ok {
+		return "", false
+	}
+	if repo != "" && repo != workloadRepo {
+		return "", false
+	}
+	return workloadRepo, true
+}
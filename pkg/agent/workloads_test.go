@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServicesWithWorkloads(t *testing.T) {
+	annotations := map[string]string{
+		AnnotationGitRepository: "gimlet-io/app",
+		AnnotationGitSha:        "abc123",
+	}
+	selector := map[string]string{"app": "db"}
+	replicas := int32(3)
+
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Annotations: annotations},
+			Spec:       v1.ServiceSpec{Selector: selector},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Annotations: annotations},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: selector},
+				Replicas: &replicas,
+			},
+			Status: appsv1.StatefulSetStatus{ReadyReplicas: 2},
+		},
+		&autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "StatefulSet", Name: "db"},
+				MaxReplicas:    5,
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 3, DesiredReplicas: 4},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "log-shipper", Namespace: "default", Annotations: annotations},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "log-shipper"}},
+			},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default", Annotations: annotations},
+			Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *"},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "backup-1",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+				OwnerReferences:   []metav1.OwnerReference{{Kind: "CronJob", Name: "backup"}},
+			},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "backup-2",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(now.Add(-1 * time.Hour)),
+				OwnerReferences:   []metav1.OwnerReference{{Kind: "CronJob", Name: "backup"}},
+			},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}}},
+		},
+	)
+
+	kubeEnv := &KubeEnv{Name: "staging", Client: client}
+	stacks, err := kubeEnv.Services("")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(stacks))
+
+	db := stacks[0]
+	assert.Equal(t, "db", db.Service.Name)
+	assert.Nil(t, db.Deployment)
+	assert.Equal(t, "abc123", db.StatefulSet.SHA)
+	assert.Equal(t, int32(3), db.StatefulSet.Replicas)
+	assert.Equal(t, int32(2), db.StatefulSet.ReadyReplicas)
+	assert.Equal(t, int32(4), db.HPA.DesiredReplicas)
+	assert.Equal(t, int32(1), db.HPA.MinReplicas)
+
+	logShipper := stacks[1]
+	assert.Equal(t, "log-shipper", logShipper.Service.Name, "workloads without a service get their own stack")
+	assert.Equal(t, "gimlet-io/app", logShipper.Repo)
+	assert.NotNil(t, logShipper.DaemonSet)
+
+	backup := stacks[2]
+	assert.Equal(t, 1, len(backup.CronJobs))
+	assert.Equal(t, "backup-2", backup.CronJobs[0].LastJob.Name)
+	assert.Equal(t, api.JobFailed, backup.CronJobs[0].LastJob.Status)
+}
@@ -16,7 +16,9 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -27,39 +29,92 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/gorilla/websocket"
+	"golang.org/x/oauth2"
 )
 
 const (
 	pathArtifact           = "%s/api/artifact"
 	pathArtifacts          = "%s/api/artifacts"
 	pathReleases           = "%s/api/releases"
+	pathReleaseDiff        = "%s/api/releases/diff"
 	pathStatus             = "%s/api/status"
+	pathUsage              = "%s/api/usage"
+	pathPromotions         = "%s/api/promotions"
 	pathRollback           = "%s/api/rollback"
 	pathDelete             = "%s/api/delete"
 	pathEventReleaseTrack  = "%s/api/eventReleaseTrack"
+	pathEventReleaseFollow = "%s/api/eventReleaseTrack/follow"
 	pathEventArtifactTrack = "%s/api/eventArtifactTrack"
 	pathUser               = "%s/api/user"
 	pathUsers              = "%s/api/users"
 	pathGitopsRepo         = "%s/api/gitopsRepo"
 	pathGitopsCommits      = "%s/api/gitopsCommits"
 	pathGitopsManifests    = "%s/api/gitopsManifests"
+	pathEnvs               = "%s/api/envs"
+	pathAlerts             = "%s/api/alerts"
+	pathAlertAcknowledge   = "%s/api/alerts/acknowledge"
+	pathSilences           = "%s/api/silences"
+	pathSilenceDelete      = "%s/api/silences/%d/delete"
+	pathArtifactSigning    = "%s/api/environments/%s/artifactSigning"
+	pathInsights           = "%s/api/insights"
+	pathWorkloadActions    = "%s/api/actions"
+	pathWorkloadAction     = "%s/api/actions/%d"
+	pathCrashLogs          = "%s/api/crashLogs"
+	pathPodLogsFollow      = "%s/api/podLogs/follow"
+	pathAgents             = "%s/api/agents"
+	pathSaveUser           = "%s/api/saveUser"
+	pathDeleteUser         = "%s/api/deleteUser"
+	pathSettings           = "%s/api/settings"
+	pathApp                = "%s/api/app"
+	pathGitRepos           = "%s/api/gitRepos"
+	pathRefreshRepos       = "%s/api/refreshRepos"
+	pathFavoriteRepos      = "%s/api/saveFavoriteRepos"
+	pathFavoriteServices   = "%s/api/saveFavoriteServices"
+	pathCommits            = "%s/api/repo/%s/commits"
+	pathCommitSync         = "%s/api/repo/%s/triggerCommitSync"
+	pathBranches           = "%s/api/repo/%s/branches"
+	pathMetas              = "%s/api/repo/%s/metas"
+	pathPullRequests       = "%s/api/repo/%s/pullRequests"
+	pathChartUpdatePRs     = "%s/api/chartUpdatePullRequests"
+	pathInfraRepoPRs       = "%s/api/infraRepoPullRequests"
+	pathEnvConfigs         = "%s/api/repo/%s/envConfigs"
+	pathEnvConfig          = "%s/api/repo/%s/env/%s/config/%s"
+	pathEnvConfigDelete    = "%s/api/repo/%s/env/%s/config/%s/delete"
+	pathDeploymentTemplate = "%s/api/repo/%s/env/%s/config/%s/deploymentTemplates"
+	pathSaveEnv            = "%s/api/saveEnvToDB"
+	pathDeleteEnv          = "%s/api/deleteEnvFromDB"
+	pathSpinOutBuiltInEnv  = "%s/api/spinOutBuiltInEnv"
+	pathEnvironments       = "%s/api/environments"
+	pathBootstrapGitops    = "%s/api/bootstrapGitops"
+	pathDeploy             = "%s/api/deploy"
 )
 
 type client struct {
 	client *http.Client
 	addr   string
+	ctx    context.Context
 }
 
 // New returns a client at the specified url.
 func New(uri string) Client {
-	return &client{http.DefaultClient, strings.TrimSuffix(uri, "/")}
+	return &client{http.DefaultClient, strings.TrimSuffix(uri, "/"), context.Background()}
 }
 
 // NewClient returns a client at the specified url.
 func NewClient(uri string, cli *http.Client) Client {
-	return &client{cli, strings.TrimSuffix(uri, "/")}
+	return &client{cli, strings.TrimSuffix(uri, "/"), context.Background()}
+}
+
+// WithContext returns a copy of the client that makes its requests with ctx.
+func (c *client) WithContext(ctx context.Context) Client {
+	withContext := *c
+	withContext.ctx = ctx
+	return &withContext
 }
 
 // SetClient sets the http.Client.
@@ -227,6 +282,46 @@ func (c *client) ReleasesGet(
 	return out, err
 }
 
+// UsageGet returns the pods of each app in an env with their resource requests, limits and usage
+func (c *client) UsageGet(app string, env string) (map[string][]*api.Pod, error) {
+	params := url.Values{}
+	params.Add("env", env)
+	if app != "" {
+		params.Add("app", app)
+	}
+	uri := fmt.Sprintf(pathUsage, c.addr) + "?" + params.Encode()
+
+	var usage map[string][]*api.Pod
+	err := c.get(uri, &usage)
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// ReleaseDiffGet returns what changed between two releases of an app
+func (c *client) ReleaseDiffGet(env string, app string, fromRef string, toRef string) (*api.ReleaseDiff, error) {
+	params := url.Values{}
+	params.Add("env", env)
+	params.Add("app", app)
+	if fromRef != "" {
+		params.Add("from", fromRef)
+	}
+	if toRef != "" {
+		params.Add("to", toRef)
+	}
+	uri := fmt.Sprintf(pathReleaseDiff, c.addr) + "?" + params.Encode()
+
+	var diff *api.ReleaseDiff
+	err := c.get(uri, &diff)
+	if err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
 // StatusGet returns release status for all apps in an env
 func (c *client) StatusGet(
 	app string,
@@ -289,6 +384,18 @@ func (c *client) ReleasesPost(request dx.ReleaseRequest) (string, error) {
 	return res["id"].(string), nil
 }
 
+// PromotionPost releases several apps to an environment in a single gitops commit
+func (c *client) PromotionPost(request dx.PromotionRequest) (string, error) {
+	uri := fmt.Sprintf(pathPromotions, c.addr)
+	result := new(map[string]interface{})
+	err := c.post(uri, request, result)
+	if err != nil {
+		return "", err
+	}
+	res := *result
+	return res["id"].(string), nil
+}
+
 // RollbackPost rolls back to a specific gitops commit
 func (c *client) RollbackPost(env string, app string, targetSHA string) (string, error) {
 	uri := fmt.Sprintf(pathRollback+"?env=%s&app=%s&sha=%s", c.addr, env, app, targetSHA)
@@ -325,6 +432,65 @@ func (c *client) TrackRelease(trackingID string) (*dx.ReleaseStatus, error) {
 	return result, nil
 }
 
+// TrackReleaseFollow streams the progress of a release request, calling fn with each streaming event
+// until the release reaches its final state
+func (c *client) TrackReleaseFollow(trackingID string, fn func(message []byte)) error {
+	uri, err := url.Parse(fmt.Sprintf(pathEventReleaseFollow, c.addr) + "?id=" + url.QueryEscape(trackingID))
+	if err != nil {
+		return err
+	}
+	switch uri.Scheme {
+	case "https":
+		uri.Scheme = "wss"
+	default:
+		uri.Scheme = "ws"
+	}
+
+	header := http.Header{}
+	if transport, ok := c.client.Transport.(*oauth2.Transport); ok {
+		token, err := transport.Source.Token()
+		if err != nil {
+			return err
+		}
+		header.Set("Authorization", token.Type()+" "+token.AccessToken)
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(c.ctx, uri.String(), header)
+	if err != nil {
+		if resp != nil {
+			defer resp.Body.Close()
+			out, _ := ioutil.ReadAll(resp.Body)
+			return newError(resp.StatusCode, out)
+		}
+		return err
+	}
+	defer conn.Close()
+
+	// closing the connection unblocks the read when ctx is cancelled
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			conn.Close()
+		case <-finished:
+		}
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return nil
+		} else if err != nil {
+			if c.ctx.Err() != nil {
+				return c.ctx.Err()
+			}
+			return err
+		}
+		fn(message)
+	}
+}
+
 // TrackArtifact gets the status of an event
 func (c *client) TrackArtifact(artifactID string) (*dx.ReleaseStatus, error) {
 	uri := fmt.Sprintf(pathEventArtifactTrack, c.addr)
@@ -425,6 +591,569 @@ func (c *client) GitopsManifestsGet(envName string) (map[string]map[string]strin
 	return res, nil
 }
 
+type EnvsResult struct {
+	Envs []*api.GitopsEnv `json:"envs"`
+}
+
+// EnvsGet returns the environments
+func (c *client) EnvsGet() ([]*api.GitopsEnv, error) {
+	uri := fmt.Sprintf(pathEnvs, c.addr)
+
+	envs := new(EnvsResult)
+	err := c.get(uri, envs)
+	if err != nil {
+		return nil, err
+	}
+
+	return envs.Envs, nil
+}
+
+// AlertsGet returns the firing and acknowledged alerts
+func (c *client) AlertsGet() ([]*model.Alert, error) {
+	uri := fmt.Sprintf(pathAlerts, c.addr)
+
+	var alerts []*model.Alert
+	err := c.get(uri, &alerts)
+	if err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+// InsightsGet returns the DORA metrics per env and app
+func (c *client) InsightsGet(env string, app string, since, until *time.Time) ([]*model.Insights, error) {
+	uri := fmt.Sprintf(pathInsights, c.addr)
+
+	params := url.Values{}
+	if env != "" {
+		params.Add("env", env)
+	}
+	if app != "" {
+		params.Add("app", app)
+	}
+	if since != nil {
+		params.Add("since", since.Format(time.RFC3339))
+	}
+	if until != nil {
+		params.Add("until", until.Format(time.RFC3339))
+	}
+	if len(params) > 0 {
+		uri = uri + "?" + params.Encode()
+	}
+
+	var insights []*model.Insights
+	err := c.get(uri, &insights)
+	if err != nil {
+		return nil, err
+	}
+
+	return insights, nil
+}
+
+// AlertAcknowledgePost acknowledges a firing alert
+func (c *client) AlertAcknowledgePost(name string, alertType string) (*model.Alert, error) {
+	uri := fmt.Sprintf(pathAlertAcknowledge+"?name=%s&type=%s", c.addr, url.QueryEscape(name), url.QueryEscape(alertType))
+
+	alert := new(model.Alert)
+	err := c.post(uri, nil, alert)
+	if err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// SilencesGet returns the active alert silences
+func (c *client) SilencesGet() ([]*model.Silence, error) {
+	uri := fmt.Sprintf(pathSilences, c.addr)
+
+	var silences []*model.Silence
+	err := c.get(uri, &silences)
+	if err != nil {
+		return nil, err
+	}
+
+	return silences, nil
+}
+
+// SilencePost creates an alert silence
+func (c *client) SilencePost(toSave *model.Silence) (*model.Silence, error) {
+	uri := fmt.Sprintf(pathSilences, c.addr)
+
+	silence := new(model.Silence)
+	err := c.post(uri, toSave, silence)
+	if err != nil {
+		return nil, err
+	}
+
+	return silence, nil
+}
+
+// SilenceDeletePost deletes an alert silence
+func (c *client) SilenceDeletePost(id int64) error {
+	uri := fmt.Sprintf(pathSilenceDelete, c.addr, id)
+	return c.post(uri, nil, nil)
+}
+
+// ArtifactSigningGet returns the artifact signature policy of an env
+func (c *client) ArtifactSigningGet(env string) (*api.ArtifactSigning, error) {
+	uri := fmt.Sprintf(pathArtifactSigning, c.addr, url.PathEscape(env))
+
+	signing := new(api.ArtifactSigning)
+	err := c.get(uri, signing)
+	if err != nil {
+		return nil, err
+	}
+
+	return signing, nil
+}
+
+// ArtifactSigningPost sets the artifact signature policy of an env
+func (c *client) ArtifactSigningPost(env string, signing *api.ArtifactSigning) error {
+	uri := fmt.Sprintf(pathArtifactSigning, c.addr, url.PathEscape(env))
+	return c.post(uri, signing, nil)
+}
+
+// WorkloadActionPost sends a restart, scale, pod deletion or suspend action to the agent of an env
+func (c *client) WorkloadActionPost(toSave *model.WorkloadAction) (*model.WorkloadAction, error) {
+	uri := fmt.Sprintf(pathWorkloadActions, c.addr)
+
+	action := new(model.WorkloadAction)
+	err := c.post(uri, toSave, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return action, nil
+}
+
+// WorkloadActionGet returns a workload action with the outcome that the agent reported
+func (c *client) WorkloadActionGet(id int64) (*model.WorkloadAction, error) {
+	uri := fmt.Sprintf(pathWorkloadAction, c.addr, id)
+
+	action := new(model.WorkloadAction)
+	err := c.get(uri, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return action, nil
+}
+
+// WorkloadActionsGet returns the latest workload actions
+func (c *client) WorkloadActionsGet() ([]*model.WorkloadAction, error) {
+	uri := fmt.Sprintf(pathWorkloadActions, c.addr)
+
+	var actions []*model.WorkloadAction
+	err := c.get(uri, &actions)
+	if err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
+
+// CrashLogsGet returns the stored logs of the crashed containers of an app
+func (c *client) CrashLogsGet(env string, app string, since, until *time.Time) ([]*model.CrashLog, error) {
+	params := url.Values{}
+	params.Add("env", env)
+	params.Add("app", app)
+	if since != nil {
+		params.Add("since", since.Format(time.RFC3339))
+	}
+	if until != nil {
+		params.Add("until", until.Format(time.RFC3339))
+	}
+	uri := fmt.Sprintf(pathCrashLogs, c.addr) + "?" + params.Encode()
+
+	var crashLogs []*model.CrashLog
+	err := c.get(uri, &crashLogs)
+	if err != nil {
+		return nil, err
+	}
+
+	return crashLogs, nil
+}
+
+// PodLogsFollow streams the live logs of all pods of an app, calling fn for each line until the stream ends
+func (c *client) PodLogsFollow(env string, app string, fn func(line *streaming.PodLogsEvent)) error {
+	params := url.Values{}
+	params.Add("env", env)
+	params.Add("app", app)
+	uri := fmt.Sprintf(pathPodLogsFollow, c.addr) + "?" + params.Encode()
+
+	body, err := c.open(uri, "GET", nil)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue // heartbeat
+		}
+		var line streaming.PodLogsEvent
+		err := json.Unmarshal(scanner.Bytes(), &line)
+		if err != nil {
+			return fmt.Errorf("cannot parse log line: %s", err)
+		}
+		fn(&line)
+	}
+
+	return scanner.Err()
+}
+
+type AgentsResult struct {
+	Agents []string `json:"agents"`
+}
+
+// AgentsGet returns the names of the connected agents
+func (c *client) AgentsGet() ([]string, error) {
+	uri := fmt.Sprintf(pathAgents, c.addr)
+
+	agents := new(AgentsResult)
+	err := c.get(uri, agents)
+	if err != nil {
+		return nil, err
+	}
+
+	return agents.Agents, nil
+}
+
+// CurrentUserGet returns the user that the client is authenticated with
+func (c *client) CurrentUserGet() (*model.User, error) {
+	uri := fmt.Sprintf(pathUser, c.addr)
+
+	user := new(model.User)
+	err := c.get(uri, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SaveUserPost creates a user with the given login and returns it with its API token
+func (c *client) SaveUserPost(login string) (*model.User, error) {
+	uri := fmt.Sprintf(pathSaveUser, c.addr)
+
+	user := new(model.User)
+	err := c.post(uri, login, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// UserDeletePost deletes the user with the given login
+func (c *client) UserDeletePost(login string) error {
+	uri := fmt.Sprintf(pathDeleteUser, c.addr)
+	return c.post(uri, login, nil)
+}
+
+type Settings struct {
+	ReleaseHistorySinceDays int    `json:"releaseHistorySinceDays"`
+	PosthogFeatureFlag      bool   `json:"posthogFeatureFlag"`
+	PosthogIdentifyUser     bool   `json:"posthogIdentifyUser"`
+	PosthogApiKey           string `json:"posthogApiKey"`
+	ScmURL                  string `json:"scmUrl"`
+	Host                    string `json:"host"`
+	Provider                string `json:"provider"`
+}
+
+// SettingsGet returns the dashboard settings
+func (c *client) SettingsGet() (*Settings, error) {
+	uri := fmt.Sprintf(pathSettings, c.addr)
+
+	settings := new(Settings)
+	err := c.get(uri, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+type AppInfo struct {
+	AppName          string `json:"appName"`
+	InstallationURL  string `json:"installationURL"`
+	AppSettingsURL   string `json:"appSettingsURL"`
+	DashboardVersion string `json:"dashboardVersion"`
+}
+
+// AppGet returns the source control application that the dashboard is installed with
+func (c *client) AppGet() (*AppInfo, error) {
+	uri := fmt.Sprintf(pathApp, c.addr)
+
+	app := new(AppInfo)
+	err := c.get(uri, app)
+	if err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// GitReposGet returns the repositories that the user has access to
+func (c *client) GitReposGet() ([]string, error) {
+	uri := fmt.Sprintf(pathGitRepos, c.addr)
+
+	var repos []string
+	err := c.get(uri, &repos)
+	if err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+type RefreshReposResult struct {
+	UserRepos []string `json:"userRepos"`
+	Added     []string `json:"added"`
+	Deleted   []string `json:"deleted"`
+}
+
+// RefreshReposGet reloads the repositories from source control that the user has access to
+func (c *client) RefreshReposGet() (*RefreshReposResult, error) {
+	uri := fmt.Sprintf(pathRefreshRepos, c.addr)
+
+	repos := new(RefreshReposResult)
+	err := c.get(uri, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+// FavoriteReposPost saves the favorite repositories of the user
+func (c *client) FavoriteReposPost(repos []string) error {
+	uri := fmt.Sprintf(pathFavoriteRepos, c.addr)
+	return c.post(uri, &api.FavoriteRepos{FavoriteRepos: repos}, nil)
+}
+
+// FavoriteServicesPost saves the favorite services of the user
+func (c *client) FavoriteServicesPost(services []string) error {
+	uri := fmt.Sprintf(pathFavoriteServices, c.addr)
+	return c.post(uri, &api.FavoriteServices{FavoriteServices: services}, nil)
+}
+
+// CommitsGet returns the commits of a repository branch, decorated with their CI statuses and artifacts.
+// The commits start from fromHash, or from the head of the branch if fromHash is empty
+func (c *client) CommitsGet(repo string, branch string, fromHash string) ([]*api.Commit, error) {
+	params := url.Values{}
+	params.Add("branch", branch)
+	if fromHash != "" {
+		params.Add("fromHash", fromHash)
+	}
+	uri := fmt.Sprintf(pathCommits, c.addr, repo) + "?" + params.Encode()
+
+	var commits []*api.Commit
+	err := c.get(uri, &commits)
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+// TriggerCommitSync makes the dashboard fetch the latest commits of a repository
+func (c *client) TriggerCommitSync(repo string) error {
+	uri := fmt.Sprintf(pathCommitSync, c.addr, repo)
+	return c.get(uri, nil)
+}
+
+// BranchesGet returns the branches of a repository
+func (c *client) BranchesGet(repo string) ([]string, error) {
+	uri := fmt.Sprintf(pathBranches, c.addr, repo)
+
+	var branches []string
+	err := c.get(uri, &branches)
+	if err != nil {
+		return nil, err
+	}
+
+	return branches, nil
+}
+
+// MetasGet returns the CI setup and the Gimlet manifest files of a repository
+func (c *client) MetasGet(repo string) (*api.GitRepoMetas, error) {
+	uri := fmt.Sprintf(pathMetas, c.addr, repo)
+
+	metas := new(api.GitRepoMetas)
+	err := c.get(uri, metas)
+	if err != nil {
+		return nil, err
+	}
+
+	return metas, nil
+}
+
+// PullRequestsGet returns the open env config pull requests of a repository, per env
+func (c *client) PullRequestsGet(repo string) (map[string][]*api.PR, error) {
+	uri := fmt.Sprintf(pathPullRequests, c.addr, repo)
+
+	pullRequests := map[string][]*api.PR{}
+	err := c.get(uri, &pullRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	return pullRequests, nil
+}
+
+// ChartUpdatePullRequestsGet returns the open chart version update pull requests, per repository
+func (c *client) ChartUpdatePullRequestsGet() (map[string]*api.PR, error) {
+	uri := fmt.Sprintf(pathChartUpdatePRs, c.addr)
+
+	pullRequests := map[string]*api.PR{}
+	err := c.get(uri, &pullRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	return pullRequests, nil
+}
+
+// InfraRepoPullRequestsGet returns the open stack change pull requests of the infrastructure repositories, per env
+func (c *client) InfraRepoPullRequestsGet() (map[string][]*api.PR, error) {
+	uri := fmt.Sprintf(pathInfraRepoPRs, c.addr)
+
+	pullRequests := map[string][]*api.PR{}
+	err := c.get(uri, &pullRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	return pullRequests, nil
+}
+
+// EnvConfigsGet returns the Gimlet manifests of a repository, per env
+func (c *client) EnvConfigsGet(repo string) (map[string][]dx.Manifest, error) {
+	uri := fmt.Sprintf(pathEnvConfigs, c.addr, repo)
+
+	envConfigs := map[string][]dx.Manifest{}
+	err := c.get(uri, &envConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	return envConfigs, nil
+}
+
+type CreatedPRResult struct {
+	CreatedPR *api.PR `json:"createdPr"`
+}
+
+// EnvConfigPost opens a pull request that creates or updates an env config in the repository
+func (c *client) EnvConfigPost(repo string, env string, config string, envConfig *api.EnvConfig) (*api.PR, error) {
+	uri := fmt.Sprintf(pathEnvConfig, c.addr, repo, url.PathEscape(env), url.PathEscape(config))
+
+	result := new(CreatedPRResult)
+	err := c.post(uri, envConfig, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.CreatedPR, nil
+}
+
+// EnvConfigDeletePost opens a pull request that deletes an env config from the repository
+func (c *client) EnvConfigDeletePost(repo string, env string, config string) (*api.PR, error) {
+	uri := fmt.Sprintf(pathEnvConfigDelete, c.addr, repo, url.PathEscape(env), url.PathEscape(config))
+
+	result := new(CreatedPRResult)
+	err := c.post(uri, nil, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.CreatedPR, nil
+}
+
+// DeploymentTemplatesGet returns the Helm charts with their schemas that an env config can use
+func (c *client) DeploymentTemplatesGet(repo string, env string, config string) ([]*api.DeploymentTemplate, error) {
+	uri := fmt.Sprintf(pathDeploymentTemplate, c.addr, repo, url.PathEscape(env), url.PathEscape(config))
+
+	var templates []*api.DeploymentTemplate
+	err := c.get(uri, &templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// EnvPost creates an environment. Environment names are lower cased
+func (c *client) EnvPost(name string) error {
+	uri := fmt.Sprintf(pathSaveEnv, c.addr)
+	return c.post(uri, name, nil)
+}
+
+// EnvDeletePost deletes an environment
+func (c *client) EnvDeletePost(name string) error {
+	uri := fmt.Sprintf(pathDeleteEnv, c.addr)
+	return c.post(uri, name, nil)
+}
+
+// SpinOutBuiltInEnvPost moves the built-in environment to gitops repositories in source control
+func (c *client) SpinOutBuiltInEnvPost() (*model.Environment, error) {
+	uri := fmt.Sprintf(pathSpinOutBuiltInEnv, c.addr)
+
+	env := new(model.Environment)
+	err := c.post(uri, nil, env)
+	if err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+type InfrastructureComponentsResult struct {
+	EnvName     string          `json:"envName"`
+	CreatedPR   *api.PR         `json:"createdPr"`
+	StackConfig *dx.StackConfig `json:"stackConfig"`
+}
+
+// InfrastructureComponentsPost opens a pull request that changes the infrastructure components of an env
+func (c *client) InfrastructureComponentsPost(env string, components map[string]interface{}) (*InfrastructureComponentsResult, error) {
+	uri := fmt.Sprintf(pathEnvironments, c.addr)
+
+	result := new(InfrastructureComponentsResult)
+	err := c.post(uri, &api.InfrastructureComponents{
+		Env:                      env,
+		InfrastructureComponents: components,
+	}, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// BootstrapGitopsPost creates and bootstraps the gitops repositories of an env
+func (c *client) BootstrapGitopsPost(config *api.GitopsBootstrapConfig) error {
+	uri := fmt.Sprintf(pathBootstrapGitops, c.addr)
+	return c.post(uri, config, nil)
+}
+
+// DeployPost builds and deploys a commit without CI, returning the id of the image build
+func (c *client) DeployPost(request dx.MagicDeployRequest) (string, error) {
+	uri := fmt.Sprintf(pathDeploy, c.addr)
+
+	result := map[string]string{}
+	err := c.post(uri, request, &result)
+	if err != nil {
+		return "", err
+	}
+
+	return result["buildId"], nil
+}
+
 func (c *client) get(rawURL string, out interface{}) error {
 	return c.do(rawURL, "GET", nil, out)
 }
@@ -469,7 +1198,7 @@ func (c *client) open(rawURL, method string, in interface{}) (io.ReadCloser, err
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest(method, uri.String(), nil)
+	req, err := http.NewRequestWithContext(c.ctx, method, uri.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -491,7 +1220,7 @@ func (c *client) open(rawURL, method string, in interface{}) (io.ReadCloser, err
 	if resp.StatusCode > http.StatusPartialContent {
 		defer resp.Body.Close()
 		out, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("client error %d: %s", resp.StatusCode, string(out))
+		return nil, newError(resp.StatusCode, out)
 	}
 	return resp.Body, nil
 }
@@ -1,12 +1,17 @@
 package client
 
 import (
+	"context"
 	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/config"
 	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/dynamicconfig"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
@@ -19,6 +24,130 @@ import (
 )
 
 func Test_artifact(t *testing.T) {
+	client, _, close := dashboardClient(t)
+	defer close()
+
+	savedArtifact, err := client.ArtifactPost(&dx.Artifact{
+		Version: dx.Version{
+			SHA:            "sha",
+			RepositoryName: "my-app",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "sha", savedArtifact.Version.SHA)
+
+	artifacts, err := client.ArtifactsGet(
+		"", "",
+		nil,
+		"",
+		[]string{},
+		0, 0,
+		nil, nil,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(artifacts))
+}
+
+func Test_usersAndEnvs(t *testing.T) {
+	client, store, close := dashboardClient(t)
+	defer close()
+
+	user, err := client.CurrentUserGet()
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", user.Login)
+	assert.NotEmpty(t, user.Token)
+
+	err = client.FavoriteServicesPost([]string{"staging/my-app"})
+	assert.Nil(t, err)
+	saved, err := store.User("admin")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"staging/my-app"}, saved.FavoriteServices)
+
+	err = client.UserDeletePost("admin")
+	var clientErr *Error
+	assert.True(t, errors.As(err, &clientErr), "deleting users should need an admin user")
+	assert.Equal(t, http.StatusForbidden, clientErr.StatusCode)
+	assert.Contains(t, clientErr.Message, "admin user is required")
+
+	err = client.EnvPost("Staging")
+	assert.Nil(t, err)
+	envs, err := store.GetEnvironments()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(envs))
+	assert.Equal(t, "staging", envs[0].Name)
+}
+
+func Test_repoAPI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/repo/gimlet-io/my-app/commits", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "main", r.URL.Query().Get("branch"))
+		assert.Equal(t, "abcd", r.URL.Query().Get("fromHash"))
+		json.NewEncoder(w).Encode([]*api.Commit{{
+			SHA:           "abcd",
+			DeployTargets: []*api.DeployTarget{{App: "my-app", Env: "staging"}},
+		}})
+	})
+	mux.HandleFunc("/api/repo/gimlet-io/my-app/env/staging/config/my-app", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		var envConfig api.EnvConfig
+		err := json.NewDecoder(r.Body).Decode(&envConfig)
+		assert.Nil(t, err)
+		assert.Equal(t, "default", envConfig.Namespace)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"createdPr": &api.PR{Number: 42},
+		})
+	})
+	mux.HandleFunc("/api/deploy", func(w http.ResponseWriter, r *http.Request) {
+		var request dx.MagicDeployRequest
+		err := json.NewDecoder(r.Body).Decode(&request)
+		assert.Nil(t, err)
+		assert.Equal(t, "abcd", request.Sha)
+		json.NewEncoder(w).Encode(map[string]string{"buildId": "build-1"})
+	})
+	mux.HandleFunc("/api/agents", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"agents": []string{"staging"}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient(server.URL, http.DefaultClient)
+
+	commits, err := client.CommitsGet("gimlet-io/my-app", "main", "abcd")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(commits))
+	assert.Equal(t, "staging", commits[0].DeployTargets[0].Env)
+
+	pr, err := client.EnvConfigPost("gimlet-io/my-app", "staging", "my-app", &api.EnvConfig{Namespace: "default"})
+	assert.Nil(t, err)
+	assert.Equal(t, 42, pr.Number)
+
+	buildID, err := client.DeployPost(dx.MagicDeployRequest{Sha: "abcd"})
+	assert.Nil(t, err)
+	assert.Equal(t, "build-1", buildID)
+
+	agents, err := client.AgentsGet()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"staging"}, agents)
+
+	_, err = client.BranchesGet("gimlet-io/my-app")
+	assert.True(t, IsNotFound(err), err)
+}
+
+func Test_withContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := NewClient(server.URL, http.DefaultClient)
+	_, err := client.WithContext(ctx).SettingsGet()
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
+
+func dashboardClient(t *testing.T) (Client, *store.Store, func()) {
 	encryptionKey := "the-key-has-to-be-32-bytes-long!"
 	encryptionKeyNew := ""
 	store := store.NewTest(encryptionKey, encryptionKeyNew)
@@ -26,7 +155,6 @@ func Test_artifact(t *testing.T) {
 
 	router := server.SetupRouter(&config.Config{}, &dynamicconfig.DynamicConfig{}, nil, nil, nil, store, nil, nil, nil, nil, nil, nil, &logger, nil, nil, nil)
 	server := httptest.NewServer(router)
-	defer server.Close()
 
 	user := &model.User{
 		Login: "admin",
@@ -49,25 +177,5 @@ func Test_artifact(t *testing.T) {
 		},
 	)
 
-	client := NewClient(server.URL, auther)
-
-	savedArtifact, err := client.ArtifactPost(&dx.Artifact{
-		Version: dx.Version{
-			SHA:            "sha",
-			RepositoryName: "my-app",
-		},
-	})
-	assert.Nil(t, err)
-	assert.Equal(t, "sha", savedArtifact.Version.SHA)
-
-	artifacts, err := client.ArtifactsGet(
-		"", "",
-		nil,
-		"",
-		[]string{},
-		0, 0,
-		nil, nil,
-	)
-	assert.Nil(t, err)
-	assert.Equal(t, 1, len(artifacts))
+	return NewClient(server.URL, auther), store, server.Close
 }
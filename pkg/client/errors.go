@@ -0,0 +1,46 @@
+// Copyright 2021 Laszlo Fogas
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is returned when the dashboard responds with an error status.
+// Use errors.As to check the status code
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("client error %d: %s", e.StatusCode, e.Message)
+}
+
+func newError(statusCode int, body []byte) *Error {
+	return &Error{
+		StatusCode: statusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+}
+
+// IsNotFound tells if err is a 404 response of the dashboard
+func IsNotFound(err error) bool {
+	var clientErr *Error
+	return errors.As(err, &clientErr) && clientErr.StatusCode == http.StatusNotFound
+}
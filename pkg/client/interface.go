@@ -16,10 +16,13 @@
 package client
 
 import (
+	"context"
 	"net/http"
 	"time"
 
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 )
 
@@ -31,6 +34,10 @@ type Client interface {
 	// SetAddress sets the server address.
 	SetAddress(string)
 
+	// WithContext returns a copy of the client that makes its requests with ctx.
+	// Cancelling ctx aborts the in-flight requests and streams
+	WithContext(ctx context.Context) Client
+
 	// ArtifactPost creates a new artifact.
 	ArtifactPost(artifact *dx.Artifact) (*dx.Artifact, error)
 
@@ -53,15 +60,25 @@ type Client interface {
 		since, until *time.Time,
 	) ([]*dx.Release, error)
 
+	// ReleaseDiffGet returns what changed between two releases of an app.
+	// Empty refs compare the latest release with the one before it
+	ReleaseDiffGet(env string, app string, fromRef string, toRef string) (*api.ReleaseDiff, error)
+
 	// StatusGet returns release status for all apps in an env
 	StatusGet(
 		app string,
 		env string,
 	) (map[string]*dx.Release, error)
 
+	// UsageGet returns the pods of each app in an env with their resource requests, limits and usage
+	UsageGet(app string, env string) (map[string][]*api.Pod, error)
+
 	// ReleasesPost releases the given artifact to the given environment
 	ReleasesPost(request dx.ReleaseRequest) (string, error)
 
+	// PromotionPost releases several apps to an environment in a single gitops commit
+	PromotionPost(request dx.PromotionRequest) (string, error)
+
 	// RollbackPost rolls back to the given sha
 	RollbackPost(env string, app string, targetSHA string) (string, error)
 
@@ -71,6 +88,9 @@ type Client interface {
 	// TrackRelease returns the state of an event by the tracking id
 	TrackRelease(trackingID string) (*dx.ReleaseStatus, error)
 
+	// TrackReleaseFollow streams the progress of a release request until it reaches its final state
+	TrackReleaseFollow(trackingID string, fn func(message []byte)) error
+
 	// TrackArtifact returns the state of an event by the artifact id
 	TrackArtifact(artifactID string) (*dx.ReleaseStatus, error)
 
@@ -91,4 +111,127 @@ type Client interface {
 
 	//GitopsManifestsGet retrieve the gitops manifests from the infrastructure and applications repository of the environment
 	GitopsManifestsGet(envName string) (map[string]map[string]string, error)
+
+	// EnvsGet returns the environments
+	EnvsGet() ([]*api.GitopsEnv, error)
+
+	// AlertsGet returns the firing and acknowledged alerts
+	AlertsGet() ([]*model.Alert, error)
+
+	// AlertAcknowledgePost acknowledges a firing alert
+	AlertAcknowledgePost(name string, alertType string) (*model.Alert, error)
+
+	// ArtifactSigningGet returns the artifact signature policy of an env
+	ArtifactSigningGet(env string) (*api.ArtifactSigning, error)
+
+	// ArtifactSigningPost sets the artifact signature policy of an env
+	ArtifactSigningPost(env string, signing *api.ArtifactSigning) error
+
+	// SilencesGet returns the active alert silences
+	SilencesGet() ([]*model.Silence, error)
+
+	// SilencePost creates an alert silence
+	SilencePost(silence *model.Silence) (*model.Silence, error)
+
+	// SilenceDeletePost deletes an alert silence
+	SilenceDeletePost(id int64) error
+
+	// InsightsGet returns the DORA metrics per env and app
+	InsightsGet(env string, app string, since, until *time.Time) ([]*model.Insights, error)
+
+	// WorkloadActionPost sends a restart, scale, pod deletion or suspend action to the agent of an env
+	WorkloadActionPost(action *model.WorkloadAction) (*model.WorkloadAction, error)
+
+	// WorkloadActionGet returns a workload action with the outcome that the agent reported
+	WorkloadActionGet(id int64) (*model.WorkloadAction, error)
+
+	// WorkloadActionsGet returns the latest workload actions
+	WorkloadActionsGet() ([]*model.WorkloadAction, error)
+
+	// CrashLogsGet returns the stored logs of the crashed containers of an app
+	CrashLogsGet(env string, app string, since, until *time.Time) ([]*model.CrashLog, error)
+
+	// PodLogsFollow streams the live logs of all pods of an app, calling fn for each line until the stream ends
+	PodLogsFollow(env string, app string, fn func(line *streaming.PodLogsEvent)) error
+
+	// AgentsGet returns the names of the connected agents
+	AgentsGet() ([]string, error)
+
+	// CurrentUserGet returns the user that the client is authenticated with
+	CurrentUserGet() (*model.User, error)
+
+	// SaveUserPost creates a user with the given login and returns it with its API token
+	SaveUserPost(login string) (*model.User, error)
+
+	// UserDeletePost deletes the user with the given login
+	UserDeletePost(login string) error
+
+	// SettingsGet returns the dashboard settings
+	SettingsGet() (*Settings, error)
+
+	// AppGet returns the source control application that the dashboard is installed with
+	AppGet() (*AppInfo, error)
+
+	// GitReposGet returns the repositories that the user has access to
+	GitReposGet() ([]string, error)
+
+	// RefreshReposGet reloads the repositories from source control that the user has access to
+	RefreshReposGet() (*RefreshReposResult, error)
+
+	// FavoriteReposPost saves the favorite repositories of the user
+	FavoriteReposPost(repos []string) error
+
+	// FavoriteServicesPost saves the favorite services of the user
+	FavoriteServicesPost(services []string) error
+
+	// CommitsGet returns the commits of a repository branch, starting from fromHash or the head of the branch
+	CommitsGet(repo string, branch string, fromHash string) ([]*api.Commit, error)
+
+	// TriggerCommitSync makes the dashboard fetch the latest commits of a repository
+	TriggerCommitSync(repo string) error
+
+	// BranchesGet returns the branches of a repository
+	BranchesGet(repo string) ([]string, error)
+
+	// MetasGet returns the CI setup and the Gimlet manifest files of a repository
+	MetasGet(repo string) (*api.GitRepoMetas, error)
+
+	// PullRequestsGet returns the open env config pull requests of a repository, per env
+	PullRequestsGet(repo string) (map[string][]*api.PR, error)
+
+	// ChartUpdatePullRequestsGet returns the open chart version update pull requests, per repository
+	ChartUpdatePullRequestsGet() (map[string]*api.PR, error)
+
+	// InfraRepoPullRequestsGet returns the open stack change pull requests of the infrastructure repositories, per env
+	InfraRepoPullRequestsGet() (map[string][]*api.PR, error)
+
+	// EnvConfigsGet returns the Gimlet manifests of a repository, per env
+	EnvConfigsGet(repo string) (map[string][]dx.Manifest, error)
+
+	// EnvConfigPost opens a pull request that creates or updates an env config in the repository
+	EnvConfigPost(repo string, env string, config string, envConfig *api.EnvConfig) (*api.PR, error)
+
+	// EnvConfigDeletePost opens a pull request that deletes an env config from the repository
+	EnvConfigDeletePost(repo string, env string, config string) (*api.PR, error)
+
+	// DeploymentTemplatesGet returns the Helm charts with their schemas that an env config can use
+	DeploymentTemplatesGet(repo string, env string, config string) ([]*api.DeploymentTemplate, error)
+
+	// EnvPost creates an environment
+	EnvPost(name string) error
+
+	// EnvDeletePost deletes an environment
+	EnvDeletePost(name string) error
+
+	// SpinOutBuiltInEnvPost moves the built-in environment to gitops repositories in source control
+	SpinOutBuiltInEnvPost() (*model.Environment, error)
+
+	// InfrastructureComponentsPost opens a pull request that changes the infrastructure components of an env
+	InfrastructureComponentsPost(env string, components map[string]interface{}) (*InfrastructureComponentsResult, error)
+
+	// BootstrapGitopsPost creates and bootstraps the gitops repositories of an env
+	BootstrapGitopsPost(config *api.GitopsBootstrapConfig) error
+
+	// DeployPost builds and deploys a commit without CI, returning the id of the image build
+	DeployPost(request dx.MagicDeployRequest) (string, error)
 }
@@ -0,0 +1,48 @@
+package alert
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/enescakir/emoji"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/urfave/cli/v2"
+)
+
+var alertAckCmd = cli.Command{
+	Name:  "ack",
+	Usage: "Acknowledges a firing alert",
+	UsageText: `gimlet alert ack \
+     --name my-namespace/my-pod-5d8f9c7b4-x2x9z \
+     --type pod \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "name",
+			Usage:    "the alerted object in namespace/name format",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "the alert type, pod or event",
+			Value: "pod",
+		},
+	}, commands.ServerFlags...),
+	Action: ack,
+}
+
+func ack(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+	alert, err := client.AlertAcknowledgePost(c.String("name"), c.String("type"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v Alert %s acknowledged\n", emoji.WomanGesturingOk, alert.Name)
+
+	return nil
+}
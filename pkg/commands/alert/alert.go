@@ -0,0 +1,13 @@
+package alert
+
+import "github.com/urfave/cli/v2"
+
+var Command = cli.Command{
+	Name:  "alert",
+	Usage: "Manages alerts and alert silences",
+	Subcommands: []*cli.Command{
+		&alertListCmd,
+		&alertAckCmd,
+		&alertSilenceCmd,
+	},
+}
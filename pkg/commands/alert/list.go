@@ -0,0 +1,87 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/rvflash/elapsed"
+	"github.com/urfave/cli/v2"
+)
+
+var alertListCmd = cli.Command{
+	Name:  "list",
+	Usage: "Lists firing and acknowledged alerts",
+	UsageText: `gimlet alert list \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "env",
+			Usage: "filter alerts to an environment",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output format, eg.: json",
+		},
+	}, commands.ServerFlags...),
+	Action: list,
+}
+
+func list(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	alerts, err := client.AlertsGet()
+	if err != nil {
+		return err
+	}
+
+	filtered := []*model.Alert{}
+	for _, alert := range alerts {
+		if c.String("env") != "" && c.String("env") != alert.Env {
+			continue
+		}
+		filtered = append(filtered, alert)
+	}
+
+	if c.String("output") == "json" {
+		alertsStr := bytes.NewBufferString("")
+		e := json.NewEncoder(alertsStr)
+		e.SetIndent("", "  ")
+		err = e.Encode(filtered)
+		if err != nil {
+			return fmt.Errorf("cannot deserialize alerts %s", err)
+		}
+		fmt.Println(alertsStr)
+		return nil
+	}
+
+	red := color.New(color.FgRed, color.Bold).SprintFunc()
+	yellow := color.New(color.FgYellow, color.Bold).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	for _, alert := range filtered {
+		status := red(alert.Status)
+		if alert.Status == model.AlertAcknowledged {
+			status = yellow(fmt.Sprintf("%s by %s", alert.Status, alert.AcknowledgedBy))
+		}
+
+		fmt.Printf("%s %s %s %s\n",
+			gray(fmt.Sprintf("%s %s -> %s", alert.Type, alert.Name, alert.Env)),
+			status,
+			alert.StatusDesc,
+			green(fmt.Sprintf("(%s)", elapsed.Time(time.Unix(alert.LastStateChange, 0)))),
+		)
+	}
+
+	return nil
+}
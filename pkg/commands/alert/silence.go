@@ -0,0 +1,170 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/enescakir/emoji"
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/urfave/cli/v2"
+)
+
+var alertSilenceCmd = cli.Command{
+	Name:  "silence",
+	Usage: "Manages time-boxed alert silences",
+	Subcommands: []*cli.Command{
+		&silenceAddCmd,
+		&silenceListCmd,
+		&silenceDeleteCmd,
+	},
+}
+
+var silenceAddCmd = cli.Command{
+	Name:  "add",
+	Usage: "Mutes alert notifications of an env, namespace or deployment",
+	UsageText: `gimlet alert silence add \
+     --env staging \
+     --deployment my-namespace/my-app \
+     --duration 2h \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "env",
+			Usage: "silence alerts in this environment",
+		},
+		&cli.StringFlag{
+			Name:  "namespace",
+			Usage: "silence alerts in this namespace",
+		},
+		&cli.StringFlag{
+			Name:  "deployment",
+			Usage: "silence alerts of this deployment, in namespace/name format",
+		},
+		&cli.DurationFlag{
+			Name:     "duration",
+			Usage:    "how long the silence lasts, eg.: 30m, 2h",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "reason",
+			Usage: "why the alerts are silenced",
+		},
+	}, commands.ServerFlags...),
+	Action: silenceAdd,
+}
+
+var silenceListCmd = cli.Command{
+	Name:  "list",
+	Usage: "Lists active alert silences",
+	UsageText: `gimlet alert silence list \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output format, eg.: json",
+		},
+	}, commands.ServerFlags...),
+	Action: silenceList,
+}
+
+var silenceDeleteCmd = cli.Command{
+	Name:  "delete",
+	Usage: "Deletes an alert silence",
+	UsageText: `gimlet alert silence delete \
+     --id 3 \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.Int64Flag{
+			Name:     "id",
+			Usage:    "the id of the silence",
+			Required: true,
+		},
+	}, commands.ServerFlags...),
+	Action: silenceDelete,
+}
+
+func silenceAdd(c *cli.Context) error {
+	if c.String("env") == "" && c.String("namespace") == "" && c.String("deployment") == "" {
+		return fmt.Errorf("at least one of --env, --namespace or --deployment is required")
+	}
+
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+	silence, err := client.SilencePost(&model.Silence{
+		Env:        c.String("env"),
+		Namespace:  c.String("namespace"),
+		Deployment: c.String("deployment"),
+		Until:      time.Now().Add(c.Duration("duration")).Unix(),
+		Reason:     c.String("reason"),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v Alerts silenced until %s (id: %d)\n", emoji.WomanGesturingOk, time.Unix(silence.Until, 0).Format(time.RFC3339), silence.ID)
+
+	return nil
+}
+
+func silenceList(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+	silences, err := client.SilencesGet()
+	if err != nil {
+		return err
+	}
+
+	if c.String("output") == "json" {
+		silencesStr := bytes.NewBufferString("")
+		e := json.NewEncoder(silencesStr)
+		e.SetIndent("", "  ")
+		err = e.Encode(silences)
+		if err != nil {
+			return fmt.Errorf("cannot deserialize silences %s", err)
+		}
+		fmt.Println(silencesStr)
+		return nil
+	}
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	blue := color.New(color.FgBlue, color.Bold).SprintFunc()
+
+	for _, s := range silences {
+		fmt.Printf("%s %s %s %s\n",
+			blue(fmt.Sprintf("#%d", s.ID)),
+			fmt.Sprintf("env=%s namespace=%s deployment=%s", s.Env, s.Namespace, s.Deployment),
+			gray(fmt.Sprintf("until %s by %s", time.Unix(s.Until, 0).Format(time.RFC3339), s.CreatedBy)),
+			s.Reason,
+		)
+	}
+
+	return nil
+}
+
+func silenceDelete(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+	err = client.SilenceDeletePost(c.Int64("id"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v Silence deleted\n", emoji.WomanGesturingOk)
+
+	return nil
+}
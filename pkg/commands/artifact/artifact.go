@@ -8,6 +8,8 @@ var Command = cli.Command{
 	Subcommands: []*cli.Command{
 		&artifactCreateCmd,
 		&artifactAddCmd,
+		&artifactSignCmd,
+		&artifactVerifyCmd,
 		&artifactPushCmd,
 		&artifactListCmd,
 		&artifactTrackCmd,
@@ -8,11 +8,10 @@ import (
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 	"github.com/rvflash/elapsed"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/oauth2"
 )
 
 var artifactListCmd = cli.Command{
@@ -22,19 +21,7 @@ var artifactListCmd = cli.Command{
      --repo my-company/my-app \
      --server http://gimlet.mycompany.com
      --token c012367f6e6f71de17ae4c6a7baac2e9`,
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name:     "server",
-			Usage:    "Gimlet server URL, GIMLET_SERVER environment variable alternatively",
-			EnvVars:  []string{"GIMLET_SERVER"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "token",
-			Usage:    "Gimlet server api token, GIMLET_TOKEN environment variable alternatively",
-			EnvVars:  []string{"GIMLET_TOKEN"},
-			Required: true,
-		},
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:    "repository",
 			Aliases: []string{"repo"},
@@ -77,26 +64,17 @@ var artifactListCmd = cli.Command{
 			Aliases: []string{"r"},
 			Usage:   "reverse the chronological order of the displayed artifacts",
 		},
-	},
+	}, commands.ServerFlags...),
 	Action: list,
 }
 
 func list(c *cli.Context) error {
-	serverURL := c.String("server")
-	token := c.String("token")
-
-	config := new(oauth2.Config)
-	auth := config.Client(
-		oauth2.NoContext,
-		&oauth2.Token{
-			AccessToken: token,
-		},
-	)
-
-	client := client.NewClient(serverURL, auth)
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
 
 	var since, until *time.Time
-	var err error
 	if c.String("since") != "" {
 		t, err := time.Parse(time.RFC3339, c.String("since"))
 		if err != nil {
@@ -6,10 +6,9 @@ import (
 	"fmt"
 	"io/ioutil"
 
-	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/oauth2"
 )
 
 var artifactPushCmd = cli.Command{
@@ -19,31 +18,19 @@ var artifactPushCmd = cli.Command{
      -f artifact.json \
      --server http://gimlet.mycompany.com
      --token c012367f6e6f71de17ae4c6a7baac2e9`,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:     "file",
 			Aliases:  []string{"f"},
 			Usage:    "artifact file to push (mandatory)",
 			Required: true,
 		},
-		&cli.StringFlag{
-			Name:     "server",
-			Usage:    "Gimlet server URL, GIMLET_SERVER environment variable alternatively",
-			EnvVars:  []string{"GIMLET_SERVER"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "token",
-			Usage:    "Gimlet server api token, GIMLET_TOKEN environment variable alternatively",
-			EnvVars:  []string{"GIMLET_TOKEN"},
-			Required: true,
-		},
 		&cli.StringFlag{
 			Name:    "output",
 			Aliases: []string{"o"},
 			Usage:   "Output format",
 		},
-	},
+	}, commands.ServerFlags...),
 	Action: push,
 }
 
@@ -58,19 +45,12 @@ func push(c *cli.Context) error {
 		return fmt.Errorf("cannot parse artifact file %s", err)
 	}
 
-	serverURL := c.String("server")
-	token := c.String("token")
 	output := c.String("output")
 
-	config := new(oauth2.Config)
-	auth := config.Client(
-		oauth2.NoContext,
-		&oauth2.Token{
-			AccessToken: token,
-		},
-	)
-
-	client := client.NewClient(serverURL, auth)
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
 
 	savedArtifact, err := client.ArtifactPost(&a)
 	if err != nil {
@@ -0,0 +1,145 @@
+package artifact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/enescakir/emoji"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/urfave/cli/v2"
+)
+
+var artifactSignCmd = cli.Command{
+	Name:  "sign",
+	Usage: "Signs a release artifact with an ECDSA or Ed25519 private key, so environments can verify it before deploying",
+	UsageText: `gimlet artifact sign \
+     --key gimlet.key \
+     -f artifact.json
+
+   To generate a key pair:
+     openssl ecparam -genkey -name prime256v1 -noout | openssl pkcs8 -topk8 -nocrypt -out gimlet.key
+     openssl ec -in gimlet.key -pubout -out gimlet.pub`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "file",
+			Aliases:  []string{"f"},
+			Usage:    "artifact file to sign",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "key",
+			Usage: "the PEM encoded private key file",
+		},
+		&cli.StringFlag{
+			Name:    "key-data",
+			Usage:   "the PEM encoded private key, for CI systems that pass secrets in variables",
+			EnvVars: []string{"GIMLET_SIGNING_KEY"},
+		},
+	},
+	Action: sign,
+}
+
+var artifactVerifyCmd = cli.Command{
+	Name:  "verify",
+	Usage: "Verifies the signature of a release artifact with a public key",
+	UsageText: `gimlet artifact verify \
+     --key gimlet.pub \
+     -f artifact.json`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "file",
+			Aliases:  []string{"f"},
+			Usage:    "artifact file to verify",
+			Required: true,
+		},
+		&cli.StringSliceFlag{
+			Name:     "key",
+			Usage:    "the PEM encoded public key file of a trusted signer (can be repeated)",
+			Required: true,
+		},
+	},
+	Action: verify,
+}
+
+func sign(c *cli.Context) error {
+	var key []byte
+	if c.String("key") != "" {
+		var err error
+		key, err = ioutil.ReadFile(c.String("key"))
+		if err != nil {
+			return fmt.Errorf("cannot read key file %s", err)
+		}
+	} else if c.String("key-data") != "" {
+		key = []byte(c.String("key-data"))
+	} else {
+		return fmt.Errorf("either --key or --key-data is mandatory")
+	}
+
+	a, err := readArtifact(c.String("file"))
+	if err != nil {
+		return err
+	}
+
+	err = a.Sign(key)
+	if err != nil {
+		return err
+	}
+
+	jsonString := bytes.NewBufferString("")
+	e := json.NewEncoder(jsonString)
+	e.SetIndent("", "  ")
+	e.Encode(a)
+
+	err = ioutil.WriteFile(c.String("file"), jsonString.Bytes(), 0666)
+	if err != nil {
+		return fmt.Errorf("cannot write artifact json %s", err)
+	}
+
+	signature := a.Signatures[len(a.Signatures)-1]
+	fmt.Fprintf(os.Stderr, "%v Artifact signed with key %s\n", emoji.CheckMark, signature.KeyID)
+	return nil
+}
+
+func verify(c *cli.Context) error {
+	var trustedKeys []string
+	for _, keyFile := range c.StringSlice("key") {
+		key, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return fmt.Errorf("cannot read key file %s", err)
+		}
+		trustedKeys = append(trustedKeys, string(key))
+	}
+
+	a, err := readArtifact(c.String("file"))
+	if err != nil {
+		return err
+	}
+
+	payload, err := a.CanonicalJSON()
+	if err != nil {
+		return fmt.Errorf("cannot serialize artifact %s", err)
+	}
+	keyID, err := dx.VerifySignatures(payload, a.Signatures, trustedKeys)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v Artifact verified with key %s\n", emoji.CheckMark, keyID)
+	return nil
+}
+
+func readArtifact(file string) (*dx.Artifact, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file %s", err)
+	}
+	var a dx.Artifact
+	err = json.Unmarshal(content, &a)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse artifact file %s", err)
+	}
+	return &a, nil
+}
@@ -0,0 +1,50 @@
+package artifact
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+)
+
+func Test_signAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	artifactFile := filepath.Join(dir, "artifact.json")
+	ioutil.WriteFile(artifactFile, []byte(artifactToExtend), commands.File_RW_RW_R)
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(private)
+	publicDER, _ := x509.MarshalPKIXPublicKey(public)
+	keyFile := filepath.Join(dir, "gimlet.key")
+	pubFile := filepath.Join(dir, "gimlet.pub")
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), commands.File_RW_RW_R)
+	ioutil.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), commands.File_RW_RW_R)
+
+	args := strings.Split("gimlet artifact sign", " ")
+	args = append(args, "-f", artifactFile, "--key", keyFile)
+	if err := commands.Run(&Command, args); err != nil {
+		t.Fatalf("Error signing artifact: %s", err)
+	}
+
+	args = strings.Split("gimlet artifact verify", " ")
+	args = append(args, "-f", artifactFile, "--key", pubFile)
+	if err := commands.Run(&Command, args); err != nil {
+		t.Fatalf("Error verifying artifact: %s", err)
+	}
+
+	signed, _ := ioutil.ReadFile(artifactFile)
+	tampered := strings.Replace(string(signed), "Bugfix 123", "Bugfix 124", 1)
+	ioutil.WriteFile(artifactFile, []byte(tampered), commands.File_RW_RW_R)
+
+	args = strings.Split("gimlet artifact verify", " ")
+	args = append(args, "-f", artifactFile, "--key", pubFile)
+	if err := commands.Run(&Command, args); err == nil {
+		t.Errorf("Verification should fail on an artifact modified after signing")
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/oauth2"
+)
+
+// ServerFlags are the flags of the commands that talk to the Gimlet server.
+// Without them, the server and token of the current context are used
+var ServerFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "server",
+		Usage:   "Gimlet server URL, GIMLET_SERVER environment variable or the current context alternatively",
+		EnvVars: []string{"GIMLET_SERVER"},
+	},
+	&cli.StringFlag{
+		Name:    "token",
+		Usage:   "Gimlet server api token, GIMLET_TOKEN environment variable or the current context alternatively",
+		EnvVars: []string{"GIMLET_TOKEN"},
+	},
+}
+
+// ContextFlag selects the context of a command, instead of the current one
+var ContextFlag = &cli.StringFlag{
+	Name:    "context",
+	Usage:   "the name of the context to use, GIMLET_CONTEXT environment variable alternatively",
+	EnvVars: []string{"GIMLET_CONTEXT"},
+}
+
+// NewClient returns a Gimlet api client for the server given in flags, or in the selected context
+func NewClient(c *cli.Context) (client.Client, error) {
+	serverURL, token, err := ServerAndToken(c)
+	if err != nil {
+		return nil, err
+	}
+
+	config := new(oauth2.Config)
+	auth := config.Client(
+		oauth2.NoContext,
+		&oauth2.Token{
+			AccessToken: token,
+		},
+	)
+
+	return client.NewClient(serverURL, auth), nil
+}
+
+// ServerAndToken resolves the Gimlet server and api token.
+// Flags and environment variables take precedence over the selected context.
+// The context fills in only for its own server, so its token is not sent to another one
+func ServerAndToken(c *cli.Context) (string, string, error) {
+	serverURL := c.String("server")
+	token := c.String("token")
+	if serverURL != "" && token != "" {
+		return serverURL, token, nil
+	}
+
+	context, err := selectedContext(c.String("context"))
+	if err != nil {
+		return "", "", err
+	}
+	if context != nil && (serverURL == "" || sameServer(serverURL, context.Server)) {
+		if serverURL == "" {
+			serverURL = context.Server
+		}
+		if token == "" {
+			token, err = context.APIToken()
+			if err != nil {
+				return "", "", err
+			}
+		}
+	}
+
+	if serverURL == "" {
+		return "", "", fmt.Errorf("no Gimlet server set. Use the --server flag, or add a context with `gimlet context add`")
+	}
+	if token == "" {
+		return "", "", fmt.Errorf("no api token set. Use the --token flag, or add a context with `gimlet context add`")
+	}
+	return serverURL, token, nil
+}
+
+func sameServer(a string, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+func selectedContext(name string) (*Context, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = config.CurrentContext
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	context := config.Context(name)
+	if context == nil {
+		return nil, fmt.Errorf("no such context: %s", name)
+	}
+	return context, nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestServerAndToken(t *testing.T) {
+	os.Setenv("GIMLET_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	defer os.Unsetenv("GIMLET_CONFIG")
+
+	config, _ := LoadConfig()
+	config.SetContext(&Context{Name: "staging", Server: "https://gimlet.staging.example.com", Token: "abc"})
+	config.CurrentContext = "staging"
+	err := config.Save()
+	assert.Nil(t, err)
+
+	serverURL, token, err := ServerAndToken(cliContext(t))
+	assert.Nil(t, err)
+	assert.Equal(t, "https://gimlet.staging.example.com", serverURL)
+	assert.Equal(t, "abc", token)
+
+	_, token, err = ServerAndToken(cliContext(t, "--server", "https://gimlet.staging.example.com/"))
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", token)
+
+	_, _, err = ServerAndToken(cliContext(t, "--server", "https://gimlet.example.com"))
+	assert.NotNil(t, err, "the context's token should not be sent to another server")
+
+	serverURL, token, err = ServerAndToken(cliContext(t, "--server", "https://gimlet.example.com", "--token", "def"))
+	assert.Nil(t, err)
+	assert.Equal(t, "https://gimlet.example.com", serverURL)
+	assert.Equal(t, "def", token)
+}
+
+func cliContext(t *testing.T, args ...string) *cli.Context {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range append(ServerFlags, ContextFlag) {
+		err := f.Apply(flags)
+		assert.Nil(t, err)
+	}
+	err := flags.Parse(args)
+	assert.Nil(t, err)
+	return cli.NewContext(cli.NewApp(), flags, nil)
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const File_RW = 0600
+const Dir_RWX = 0700
+
+// Config is the CLI configuration with the Gimlet servers the user works with
+type Config struct {
+	CurrentContext string     `yaml:"currentContext,omitempty"`
+	Contexts       []*Context `yaml:"contexts,omitempty"`
+}
+
+// Context is a named Gimlet server with the credentials to access it
+type Context struct {
+	Name   string `yaml:"name"`
+	Server string `yaml:"server"`
+	Token  string `yaml:"token,omitempty"`
+	// CredentialHelper is a command that prints the api token on its standard output,
+	// so the token doesn't have to be stored in the config file
+	CredentialHelper string `yaml:"credentialHelper,omitempty"`
+}
+
+// ConfigPath returns the location of the CLI configuration,
+// ~/.config/gimlet/config.yaml unless the GIMLET_CONFIG environment variable points elsewhere
+func ConfigPath() (string, error) {
+	if path := os.Getenv("GIMLET_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate home directory: %s", err)
+	}
+	return filepath.Join(home, ".config", "gimlet", "config.yaml"), nil
+}
+
+// LoadConfig reads the CLI configuration, returning an empty one if it doesn't exist yet
+func LoadConfig() (*Config, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %s", path, err)
+	}
+
+	err = yaml.Unmarshal(content, config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %s", path, err)
+	}
+	return config, nil
+}
+
+// Save writes the CLI configuration, readable only by the user as it may hold tokens
+func (c *Config) Save() error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	content, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("cannot serialize config: %s", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), Dir_RWX)
+	if err != nil {
+		return fmt.Errorf("cannot create %s: %s", filepath.Dir(path), err)
+	}
+	err = ioutil.WriteFile(path, content, File_RW)
+	if err != nil {
+		return fmt.Errorf("cannot write %s: %s", path, err)
+	}
+	// WriteFile keeps the permissions of an existing file
+	return os.Chmod(path, File_RW)
+}
+
+// Context returns the context with the given name, or nil
+func (c *Config) Context(name string) *Context {
+	for _, context := range c.Contexts {
+		if context.Name == name {
+			return context
+		}
+	}
+	return nil
+}
+
+// SetContext adds a context, or replaces the one with the same name
+func (c *Config) SetContext(context *Context) {
+	for i, existing := range c.Contexts {
+		if existing.Name == context.Name {
+			c.Contexts[i] = context
+			return
+		}
+	}
+	c.Contexts = append(c.Contexts, context)
+}
+
+// DeleteContext removes a context, and reports whether it existed
+func (c *Config) DeleteContext(name string) bool {
+	for i, context := range c.Contexts {
+		if context.Name == name {
+			c.Contexts = append(c.Contexts[:i], c.Contexts[i+1:]...)
+			if c.CurrentContext == name {
+				c.CurrentContext = ""
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// APIToken returns the stored token of the context, or runs its credential helper to get one
+func (c *Context) APIToken() (string, error) {
+	if c.CredentialHelper == "" {
+		return c.Token, nil
+	}
+
+	cmd := exec.Command("sh", "-c", c.CredentialHelper)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("credential helper of context %s failed: %s", c.Name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gimlet", "config.yaml")
+	os.Setenv("GIMLET_CONFIG", path)
+	defer os.Unsetenv("GIMLET_CONFIG")
+
+	config, err := LoadConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(config.Contexts), "missing config should be empty")
+
+	config.SetContext(&Context{Name: "staging", Server: "http://staging", Token: "abc"})
+	config.SetContext(&Context{Name: "production", Server: "http://production", CredentialHelper: "echo def"})
+	config.SetContext(&Context{Name: "staging", Server: "http://staging2", Token: "abc"})
+	config.CurrentContext = "production"
+	err = config.Save()
+	assert.Nil(t, err)
+
+	stat, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(File_RW), stat.Mode().Perm(), "config holds tokens, should be readable only by the user")
+
+	config, err = LoadConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(config.Contexts))
+	assert.Equal(t, "http://staging2", config.Context("staging").Server)
+
+	token, err := config.Context("production").APIToken()
+	assert.Nil(t, err)
+	assert.Equal(t, "def", token)
+
+	assert.True(t, config.DeleteContext("production"))
+	assert.Equal(t, "", config.CurrentContext)
+	assert.False(t, config.DeleteContext("production"))
+}
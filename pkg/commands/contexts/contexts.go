@@ -0,0 +1,221 @@
+package contexts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/enescakir/emoji"
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/urfave/cli/v2"
+)
+
+var Command = cli.Command{
+	Name:  "context",
+	Usage: "Manages the Gimlet servers the CLI talks to, so commands don't need --server and --token",
+	Subcommands: []*cli.Command{
+		&contextAddCmd,
+		&contextUseCmd,
+		&contextListCmd,
+		&contextDeleteCmd,
+	},
+}
+
+var contextAddCmd = cli.Command{
+	Name:      "add",
+	Usage:     "Adds or updates a context",
+	ArgsUsage: "<name>",
+	UsageText: `gimlet context add \
+     --server http://gimlet.mycompany.com \
+     --token c012367f6e6f71de17ae4c6a7baac2e9 \
+     production`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "server",
+			Usage:    "Gimlet server URL",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "token",
+			Usage: "Gimlet server api token, stored in the config file",
+		},
+		&cli.StringFlag{
+			Name:  "credential-helper",
+			Usage: "a command that prints the api token, so it is not stored in the config file, eg.: \"pass show gimlet\"",
+		},
+		&cli.BoolFlag{
+			Name:  "use",
+			Usage: "make it the current context",
+		},
+	},
+	Action: add,
+}
+
+var contextUseCmd = cli.Command{
+	Name:      "use",
+	Usage:     "Sets the current context",
+	ArgsUsage: "<name>",
+	UsageText: `gimlet context use production`,
+	Action:    use,
+}
+
+var contextListCmd = cli.Command{
+	Name:      "list",
+	Usage:     "Lists the contexts",
+	UsageText: `gimlet context list`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output format, eg.: json",
+		},
+	},
+	Action: list,
+}
+
+var contextDeleteCmd = cli.Command{
+	Name:      "delete",
+	Usage:     "Deletes a context",
+	ArgsUsage: "<name>",
+	UsageText: `gimlet context delete production`,
+	Action:    delete,
+}
+
+func add(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("context name is mandatory")
+	}
+	if c.String("token") == "" && c.String("credential-helper") == "" {
+		return fmt.Errorf("either --token or --credential-helper is mandatory")
+	}
+	if c.String("token") != "" && c.String("credential-helper") != "" {
+		return fmt.Errorf("--token and --credential-helper are mutually exclusive")
+	}
+
+	config, err := commands.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	config.SetContext(&commands.Context{
+		Name:             name,
+		Server:           c.String("server"),
+		Token:            c.String("token"),
+		CredentialHelper: c.String("credential-helper"),
+	})
+	if c.Bool("use") || config.CurrentContext == "" {
+		config.CurrentContext = name
+	}
+
+	err = config.Save()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v Context %s saved\n", emoji.WomanGesturingOk, name)
+	if config.CurrentContext == name {
+		fmt.Fprintf(os.Stderr, "It is the current context\n")
+	}
+	return nil
+}
+
+func use(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("context name is mandatory")
+	}
+
+	config, err := commands.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if config.Context(name) == nil {
+		return fmt.Errorf("no such context: %s", name)
+	}
+
+	config.CurrentContext = name
+	err = config.Save()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v Switched to context %s\n", emoji.WomanGesturingOk, name)
+	return nil
+}
+
+func list(c *cli.Context) error {
+	config, err := commands.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	if c.String("output") == "json" {
+		contexts := []map[string]interface{}{}
+		for _, context := range config.Contexts {
+			contexts = append(contexts, map[string]interface{}{
+				"name":    context.Name,
+				"server":  context.Server,
+				"current": context.Name == config.CurrentContext,
+			})
+		}
+
+		contextsStr := bytes.NewBufferString("")
+		e := json.NewEncoder(contextsStr)
+		e.SetIndent("", "  ")
+		err = e.Encode(contexts)
+		if err != nil {
+			return fmt.Errorf("cannot deserialize contexts %s", err)
+		}
+		fmt.Println(contextsStr)
+		return nil
+	}
+
+	if len(config.Contexts) == 0 {
+		fmt.Println("No contexts. Add one with `gimlet context add`")
+		return nil
+	}
+
+	bold := color.New(color.Bold).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	for _, context := range config.Contexts {
+		credentials := "token"
+		if context.CredentialHelper != "" {
+			credentials = "credential helper"
+		}
+
+		if context.Name == config.CurrentContext {
+			fmt.Printf("* %s %s %s\n", bold(context.Name), context.Server, gray(credentials))
+		} else {
+			fmt.Printf("  %s %s %s\n", context.Name, context.Server, gray(credentials))
+		}
+	}
+
+	return nil
+}
+
+func delete(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("context name is mandatory")
+	}
+
+	config, err := commands.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if !config.DeleteContext(name) {
+		return fmt.Errorf("no such context: %s", name)
+	}
+
+	err = config.Save()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v Context %s deleted\n", emoji.WomanGesturingOk, name)
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/gimlet-io/gimlet-cli/pkg/agent"
 	"github.com/urfave/cli/v2"
 	v1 "k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -78,6 +79,14 @@ func check(c *cli.Context) error {
 	}
 	spinner.Success()
 
+	for _, resource := range []*schema.GroupVersionResource{&gitRepositoryResource, &kustomizationResource} {
+		version, err := agent.ServedFluxVersion(clientSet.Discovery(), resource.Group, resource.Resource)
+		if err != nil {
+			return err
+		}
+		resource.Version = version
+	}
+
 	envName := c.String("env")
 	spinner = NewSpinner("Setting up git connection")
 	err = waitForResources(client, gitRepositoryResource, envName, spinner)
@@ -1,7 +1,6 @@
 package environment
 
 import (
-	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,34 +8,21 @@ import (
 	"path/filepath"
 	"sort"
 
-	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/oauth2"
 )
 
 var environmentConnectCmd = cli.Command{
 	Name:      "connect",
 	Usage:     "Applies the environment gitops manifests on the cluster",
 	UsageText: `gimlet environment connect --env staging`,
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:     "env",
 			Usage:    "environment to connect with the cluster",
 			Required: true,
 		},
-		&cli.StringFlag{
-			Name:     "server",
-			Usage:    "Gimlet server URL, GIMLET_SERVER environment variable alternatively",
-			EnvVars:  []string{"GIMLET_SERVER"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "token",
-			Usage:    "Gimlet server api token, GIMLET_TOKEN environment variable alternatively",
-			EnvVars:  []string{"GIMLET_TOKEN"},
-			Required: true,
-		},
-	},
+	}, commands.ServerFlags...),
 	Action: connect,
 }
 
@@ -49,18 +35,11 @@ func connect(c *cli.Context) error {
 	}()
 
 	envName := c.String("env")
-	serverURL := c.String("server")
-	token := c.String("token")
-
-	config := new(oauth2.Config)
-	auth := config.Client(
-		context.Background(),
-		&oauth2.Token{
-			AccessToken: token,
-		},
-	)
 
-	client := client.NewClient(serverURL, auth)
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
 
 	files, err := client.GitopsManifestsGet(envName)
 	if err != nil {
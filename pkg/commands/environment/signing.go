@@ -0,0 +1,126 @@
+package environment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/enescakir/emoji"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/urfave/cli/v2"
+)
+
+var environmentSigningCmd = cli.Command{
+	Name:  "signing",
+	Usage: "Shows or sets the keys an environment trusts, and whether it deploys only signed artifacts",
+	UsageText: `gimlet environment signing \
+     --env production \
+     --trusted-key gimlet.pub \
+     --require \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "env",
+			Usage:    "the environment to configure",
+			Required: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "trusted-key",
+			Usage: "a PEM encoded public key file whose signatures the environment trusts (can be repeated), replaces the trusted keys",
+		},
+		&cli.BoolFlag{
+			Name:  "require",
+			Usage: "deploy only artifacts signed by a trusted key, --require=false to allow unsigned artifacts again",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output format, eg.: json",
+		},
+	}, commands.ServerFlags...),
+	Action: signing,
+}
+
+func signing(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	env := c.String("env")
+	signing, err := client.ArtifactSigningGet(env)
+	if err != nil {
+		return err
+	}
+
+	if c.IsSet("trusted-key") || c.IsSet("require") {
+		if c.IsSet("trusted-key") {
+			signing.TrustedKeys = []string{}
+			for _, keyFile := range c.StringSlice("trusted-key") {
+				key, err := ioutil.ReadFile(keyFile)
+				if err != nil {
+					return fmt.Errorf("cannot read key file %s", err)
+				}
+				signing.TrustedKeys = append(signing.TrustedKeys, string(key))
+			}
+		}
+		if c.IsSet("require") {
+			signing.RequireSignedArtifacts = c.Bool("require")
+		}
+
+		err = client.ArtifactSigningPost(env, signing)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%v Artifact signing of %s saved\n", emoji.WomanGesturingOk, env)
+	}
+
+	if c.String("output") == "json" {
+		signingStr := bytes.NewBufferString("")
+		e := json.NewEncoder(signingStr)
+		e.SetIndent("", "  ")
+		err = e.Encode(signing)
+		if err != nil {
+			return fmt.Errorf("cannot deserialize artifact signing %s", err)
+		}
+		fmt.Println(signingStr)
+		return nil
+	}
+
+	fmt.Print(renderSigning(env, signing))
+	return nil
+}
+
+func renderSigning(env string, signing *api.ArtifactSigning) string {
+	var out bytes.Buffer
+	if signing.RequireSignedArtifacts {
+		fmt.Fprintf(&out, "%s deploys only artifacts signed by a trusted key\n", env)
+	} else {
+		fmt.Fprintf(&out, "%s deploys unsigned artifacts\n", env)
+	}
+
+	if len(signing.TrustedKeys) == 0 {
+		fmt.Fprintf(&out, "No trusted keys\n")
+		return out.String()
+	}
+	fmt.Fprintf(&out, "Trusted keys:\n")
+	for _, key := range signing.TrustedKeys {
+		publicKey, err := dx.ParsePublicKey([]byte(key))
+		if err != nil {
+			fmt.Fprintf(&out, "  invalid key: %s\n", err)
+			continue
+		}
+		keyID, err := dx.KeyID(publicKey)
+		if err != nil {
+			fmt.Fprintf(&out, "  invalid key: %s\n", err)
+			continue
+		}
+		fmt.Fprintf(&out, "  %s\n", keyID)
+	}
+	return out.String()
+}
@@ -50,6 +50,10 @@ var gitopsBootstrapCmd = cli.Command{
 			Name:  "kustomization-per-app",
 			Usage: "to apply only the flux/ folder in gitops. Separate kustomization objects must be created to apply other folders. Used in `*-apps` repos",
 		},
+		&cli.StringFlag{
+			Name:  "flux-api-version",
+			Usage: "API version of the generated Flux resources (v1, v1beta2, v1beta1), default: the version Gimlet installs",
+		},
 	},
 }
 
@@ -84,6 +88,7 @@ func Bootstrap(c *cli.Context) error {
 		ShouldGenerateDeployKey:            true,
 		GitopsRepoUrl:                      c.String("gitops-repo-url"),
 		Branch:                             branch,
+		FluxAPIVersion:                     c.String("flux-api-version"),
 	})
 	if err != nil {
 		return err
@@ -56,6 +56,10 @@ var gitopsUpgradeCmd = cli.Command{
 			Name:  "no-deploykey",
 			Usage: "if you don't want re-generate your deploy key",
 		},
+		&cli.StringFlag{
+			Name:  "flux-api-version",
+			Usage: "API version of the generated Flux resources (v1, v1beta2, v1beta1), default: the version Gimlet installs",
+		},
 	},
 }
 
@@ -99,6 +103,7 @@ func Upgrade(c *cli.Context) error {
 			ShouldGenerateDeployKey:            !noDeployKey,
 			GitopsRepoUrl:                      c.String("gitops-repo-url"),
 			Branch:                             branch,
+			FluxAPIVersion:                     c.String("flux-api-version"),
 		})
 	if err != nil {
 		return err
@@ -0,0 +1,101 @@
+package insights
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/urfave/cli/v2"
+)
+
+var Command = cli.Command{
+	Name:  "insights",
+	Usage: "Summarizes deployment frequency, lead time, change failure rate and time to restore",
+	UsageText: `gimlet insights \
+     --env staging \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "env",
+			Usage: "filter insights to an environment",
+		},
+		&cli.StringFlag{
+			Name:  "app",
+			Usage: "filter insights to an application",
+		},
+		&cli.IntFlag{
+			Name:  "days",
+			Usage: "the number of days to compute the insights for",
+			Value: 30,
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output format, eg.: json",
+		},
+	}, commands.ServerFlags...),
+	Action: insights,
+}
+
+func insights(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	until := time.Now()
+	since := until.Add(-time.Duration(c.Int("days")) * 24 * time.Hour)
+	metrics, err := client.InsightsGet(c.String("env"), c.String("app"), &since, &until)
+	if err != nil {
+		return err
+	}
+
+	if c.String("output") == "json" {
+		metricsStr := bytes.NewBufferString("")
+		e := json.NewEncoder(metricsStr)
+		e.SetIndent("", "  ")
+		err = e.Encode(metrics)
+		if err != nil {
+			return fmt.Errorf("cannot deserialize insights %s", err)
+		}
+		fmt.Println(metricsStr)
+		return nil
+	}
+
+	if len(metrics) == 0 {
+		fmt.Printf("No deployments in the last %d days\n", c.Int("days"))
+		return nil
+	}
+
+	bold := color.New(color.Bold).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	for _, m := range metrics {
+		fmt.Printf("%s %s\n", bold(m.App), gray(fmt.Sprintf("-> %s", m.Env)))
+		fmt.Printf("  Deployment frequency:  %.2f / day %s\n", m.DeploymentFrequency, gray(fmt.Sprintf("(%d deployments, %d rollbacks)", m.Deployments, m.Rollbacks)))
+		fmt.Printf("  Lead time for changes: %s\n", duration(m.LeadTime))
+
+		changeFailureRate := green(fmt.Sprintf("%.0f%%", m.ChangeFailureRate*100))
+		if m.Failures > 0 {
+			changeFailureRate = red(fmt.Sprintf("%.0f%%", m.ChangeFailureRate*100))
+		}
+		fmt.Printf("  Change failure rate:   %s %s\n", changeFailureRate, gray(fmt.Sprintf("(%d failed)", m.Failures)))
+		fmt.Printf("  Time to restore:       %s\n", duration(m.TimeToRestore))
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func duration(seconds int64) string {
+	if seconds == 0 {
+		return "-"
+	}
+	return (time.Duration(seconds) * time.Second).Round(time.Minute).String()
+}
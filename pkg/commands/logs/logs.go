@@ -0,0 +1,149 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/urfave/cli/v2"
+)
+
+var Command = cli.Command{
+	Name:  "logs",
+	Usage: "Follows the live logs of an application, or prints the logs of its crashed containers",
+	UsageText: `gimlet logs \
+     --env staging \
+     --app my-app \
+     --previous \
+     --since 24h \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "env",
+			Usage:    "the environment of the application",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "app",
+			Usage:    "the name of the application",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "previous",
+			Usage: "print the logs of the crashed containers, instead of following the live logs",
+		},
+		&cli.DurationFlag{
+			Name:  "since",
+			Usage: "only print crashes that happened in this duration, eg.: 1h",
+		},
+		&cli.TimestampFlag{
+			Name:   "until",
+			Usage:  "only print crashes that happened before this time, eg.: 2022-01-31T13:04:05Z",
+			Layout: time.RFC3339,
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output format, eg.: json",
+		},
+	}, commands.ServerFlags...),
+	Action: logs,
+}
+
+func logs(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("previous") {
+		return previous(c, client)
+	}
+	return follow(c, client)
+}
+
+func previous(c *cli.Context, client client.Client) error {
+	var since *time.Time
+	if c.IsSet("since") {
+		t := time.Now().Add(-c.Duration("since"))
+		since = &t
+	}
+	until := c.Timestamp("until")
+
+	crashLogs, err := client.CrashLogsGet(c.String("env"), c.String("app"), since, until)
+	if err != nil {
+		return err
+	}
+
+	if c.String("output") == "json" {
+		crashLogsStr := bytes.NewBufferString("")
+		e := json.NewEncoder(crashLogsStr)
+		e.SetIndent("", "  ")
+		err = e.Encode(crashLogs)
+		if err != nil {
+			return fmt.Errorf("cannot deserialize crash logs %s", err)
+		}
+		fmt.Println(crashLogsStr)
+		return nil
+	}
+
+	if len(crashLogs) == 0 {
+		fmt.Println("No crashed containers")
+		return nil
+	}
+
+	bold := color.New(color.Bold).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	// oldest first, so the most recent crash ends up at the bottom of the terminal
+	for i := len(crashLogs) - 1; i >= 0; i-- {
+		crashLog := crashLogs[i]
+		reason := fmt.Sprintf("exit code %d", crashLog.ExitCode)
+		if crashLog.Reason != "" {
+			reason = fmt.Sprintf("%s, %s", crashLog.Reason, reason)
+		}
+		fmt.Printf("%s %s %s\n",
+			bold(fmt.Sprintf("%s/%s", crashLog.Pod, crashLog.Container)),
+			red(reason),
+			gray(fmt.Sprintf("restart #%d at %s", crashLog.RestartCount, time.Unix(crashLog.FinishedAt, 0).Format(time.RFC3339))),
+		)
+		fmt.Println(strings.TrimRight(crashLog.Logs, "\n"))
+		fmt.Println()
+	}
+
+	return nil
+}
+
+var prefixColors = []color.Attribute{color.FgCyan, color.FgMagenta, color.FgYellow, color.FgGreen, color.FgBlue}
+
+func follow(c *cli.Context, client client.Client) error {
+	jsonOutput := c.String("output") == "json"
+	colors := map[string]*color.Color{}
+
+	return client.PodLogsFollow(c.String("env"), c.String("app"), func(line *streaming.PodLogsEvent) {
+		if jsonOutput {
+			lineStr, _ := encode(line)
+			fmt.Println(lineStr)
+			return
+		}
+
+		prefix := line.PodName + "/" + line.Container
+		if _, ok := colors[prefix]; !ok {
+			colors[prefix] = color.New(prefixColors[len(colors)%len(prefixColors)])
+		}
+		fmt.Printf("%s %s\n", colors[prefix].Sprint(prefix), strings.TrimRight(line.Message, "\n"))
+	})
+}
+
+func encode(line *streaming.PodLogsEvent) (string, error) {
+	lineBytes, err := json.Marshal(line)
+	return string(lineBytes), err
+}
@@ -0,0 +1,925 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/enescakir/emoji"
+	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/config"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var manifestImportCmd = cli.Command{
+	Name:  "import",
+	Usage: "Imports plain Kubernetes yaml or a docker-compose file as a OneChart based Gimlet manifest",
+	UsageText: `gimlet manifest import \
+     -f deployment.yaml \
+     -f service.yaml \
+     -f ingress.yaml \
+     --env staging \
+     -o .gimlet/staging.yaml
+
+   gimlet manifest import \
+     --compose docker-compose.yml \
+     --service web \
+     --env staging \
+     --namespace my-team \
+     -o .gimlet/staging.yaml`,
+	Action: importManifest,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "file",
+			Aliases: []string{"f"},
+			Usage:   "Kubernetes yaml file to import, with a Deployment and optionally a Service and Ingress (can be repeated)",
+		},
+		&cli.StringFlag{
+			Name:  "compose",
+			Usage: "docker-compose file to import",
+		},
+		&cli.StringFlag{
+			Name:  "service",
+			Usage: "the docker-compose service to import, mandatory if the compose file has more than one",
+		},
+		&cli.StringFlag{
+			Name:     "env",
+			Usage:    "environment your application is deployed to (mandatory)",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "app",
+			Usage: "name of the application, defaults to the name of the Deployment or compose service",
+		},
+		&cli.StringFlag{
+			Name:    "namespace",
+			Aliases: []string{"n"},
+			Usage:   "the Kubernetes namespace to deploy to, defaults to the namespace of the Deployment or \"default\"",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-verify",
+			Usage: "skip rendering the manifest and comparing it with the imported yaml",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output manifest file",
+		},
+	},
+}
+
+func importManifest(c *cli.Context) error {
+	files := c.StringSlice("file")
+	composeFile := c.String("compose")
+	if len(files) == 0 && composeFile == "" {
+		return fmt.Errorf("either --file or --compose is mandatory")
+	}
+	if len(files) > 0 && composeFile != "" {
+		return fmt.Errorf("--file and --compose cannot be used together")
+	}
+
+	defaultChart, err := config.DefaultChart()
+	if err != nil {
+		return fmt.Errorf("cannot get default chart from config: %s", err)
+	}
+	chart := dx.Chart{
+		Repository: defaultChart.Repository,
+		Name:       defaultChart.Name,
+		Version:    defaultChart.Version,
+	}
+
+	var m *dx.Manifest
+	var objects []map[string]interface{}
+	var warnings []string
+	if composeFile != "" {
+		content, err := ioutil.ReadFile(composeFile)
+		if err != nil {
+			return fmt.Errorf("cannot read compose file %s", err)
+		}
+		m, warnings, err = importCompose(string(content), c.String("service"), chart)
+		if err != nil {
+			return err
+		}
+	} else {
+		var content string
+		for _, file := range files {
+			fileContent, err := ioutil.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("cannot read file %s", err)
+			}
+			content += "---\n" + string(fileContent) + "\n"
+		}
+		objects, err = parseObjects(content)
+		if err != nil {
+			return err
+		}
+		m, warnings, err = importKubernetes(objects, c.String("app"), chart)
+		if err != nil {
+			return err
+		}
+	}
+
+	m.Env = c.String("env")
+	if c.String("app") != "" {
+		m.App = c.String("app")
+	}
+	if c.String("namespace") != "" {
+		m.Namespace = c.String("namespace")
+	}
+	if m.Namespace == "" {
+		m.Namespace = "default"
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "%v %s\n", emoji.Warning, warning)
+	}
+
+	if !c.Bool("skip-verify") {
+		rendered, err := m.Render()
+		if err != nil {
+			return fmt.Errorf("cannot render the imported manifest, use --skip-verify to import without rendering: %s", err)
+		}
+		if len(objects) > 0 {
+			differences, err := compareRendered(objects, rendered)
+			if err != nil {
+				return err
+			}
+			if len(differences) > 0 {
+				fmt.Fprintf(os.Stderr, "%v The rendered manifest differs from the imported yaml:\n", emoji.Warning)
+				for _, difference := range differences {
+					fmt.Fprintf(os.Stderr, "  %s\n", difference)
+				}
+				fmt.Fprintf(os.Stderr, "Review the differences, and adjust the values or strategicMergePatches of the manifest\n")
+			} else {
+				fmt.Fprintf(os.Stderr, "%v The rendered manifest is equivalent to the imported yaml\n", emoji.CheckMark)
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "%v The imported manifest renders\n", emoji.CheckMark)
+		}
+	}
+
+	yamlString := bytes.NewBufferString("")
+	e := yaml.NewEncoder(yamlString)
+	e.SetIndent(2)
+	e.Encode(m)
+
+	outputPath := c.String("output")
+	if outputPath != "" {
+		err := ioutil.WriteFile(outputPath, yamlString.Bytes(), 0666)
+		if err != nil {
+			return fmt.Errorf("cannot write manifest file %s", err)
+		}
+	} else {
+		fmt.Println("---")
+		fmt.Println(yamlString.String())
+	}
+
+	return nil
+}
+
+// parseObjects parses a multi document yaml into Kubernetes objects
+func parseObjects(content string) ([]map[string]interface{}, error) {
+	objects := []map[string]interface{}{}
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var object map[string]interface{}
+		err := decoder.Decode(&object)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse yaml %s", err)
+		}
+		if object == nil {
+			continue
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// importKubernetes maps a Deployment, the Service that selects it and the Ingress that routes to that Service
+// to OneChart values. Container and pod fields that OneChart has no value for become strategic merge patches,
+// all other objects are kept as raw manifests
+func importKubernetes(objects []map[string]interface{}, app string, chart dx.Chart) (*dx.Manifest, []string, error) {
+	m := &dx.Manifest{}
+	warnings := []string{}
+
+	var deployment map[string]interface{}
+	for _, object := range objects {
+		if kind(object) == "Deployment" {
+			deployment = object
+			break
+		}
+	}
+	if deployment == nil {
+		warnings = append(warnings, "no Deployment found, all objects are kept as raw manifests")
+		raw, err := marshalObjects(objects)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.App = app
+		m.Manifests = raw
+		return m, warnings, nil
+	}
+
+	if app == "" {
+		app = name(deployment)
+	}
+	m.App = app
+	m.Namespace = stringAt(deployment, "metadata", "namespace")
+	m.Chart = chart
+
+	values := map[string]interface{}{}
+	patches := []map[string]interface{}{}
+
+	deploymentPatch, err := importDeployment(deployment, app, values)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deploymentPatch != nil {
+		patches = append(patches, deploymentPatch)
+	}
+	consumed := []map[string]interface{}{deployment}
+
+	podLabels := mapAt(deployment, "spec", "template", "metadata", "labels")
+	var service map[string]interface{}
+	for _, object := range objects {
+		if kind(object) == "Service" && selects(mapAt(object, "spec", "selector"), podLabels) {
+			service = object
+			break
+		}
+	}
+	if service != nil {
+		consumed = append(consumed, service)
+		servicePatch := importService(service, app)
+		if servicePatch != nil {
+			patches = append(patches, servicePatch)
+		}
+
+		for _, object := range objects {
+			if kind(object) == "Ingress" && routesTo(object, name(service)) {
+				consumed = append(consumed, object)
+				warnings = append(warnings, importIngress(object, values)...)
+				break
+			}
+		}
+	}
+
+	rest := []map[string]interface{}{}
+	for _, object := range objects {
+		if !containsObject(consumed, object) {
+			rest = append(rest, object)
+		}
+	}
+	m.Manifests, err = marshalObjects(rest)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.StrategicMergePatches, err = marshalObjects(patches)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.Values = values
+
+	return m, warnings, nil
+}
+
+// importDeployment sets the OneChart values from the Deployment,
+// and returns a strategic merge patch for the fields OneChart has no value for
+func importDeployment(deployment map[string]interface{}, app string, values map[string]interface{}) (map[string]interface{}, error) {
+	spec := mapAt(deployment, "spec")
+	podSpec := mapAt(deployment, "spec", "template", "spec")
+	containers, _ := podSpec["containers"].([]interface{})
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("deployment %s has no containers", name(deployment))
+	}
+
+	if replicas, ok := spec["replicas"]; ok {
+		values["replicas"] = replicas
+	}
+	if annotations := mapAt(deployment, "spec", "template", "metadata", "annotations"); len(annotations) > 0 {
+		values["podAnnotations"] = annotations
+	}
+
+	container, _ := containers[0].(map[string]interface{})
+	containerPatch := map[string]interface{}{}
+	for key, value := range container {
+		switch key {
+		case "name":
+		case "image":
+			image := fmt.Sprint(value)
+			repository, tag, ok := splitImage(image)
+			if !ok {
+				containerPatch[key] = value
+				continue
+			}
+			values["image"] = map[string]interface{}{
+				"repository": repository,
+				"tag":        tag,
+			}
+		case "ports":
+			ports, _ := value.([]interface{})
+			for i, port := range ports {
+				if i == 0 {
+					portMap, _ := port.(map[string]interface{})
+					values["containerPort"] = portMap["containerPort"]
+					continue
+				}
+				containerPatch["ports"] = append(listAt(containerPatch, "ports"), port)
+			}
+		case "env":
+			vars := map[string]interface{}{}
+			env, _ := value.([]interface{})
+			for _, e := range env {
+				envVar, _ := e.(map[string]interface{})
+				if plain, ok := envVar["value"]; ok && len(envVar) == 2 {
+					vars[fmt.Sprint(envVar["name"])] = fmt.Sprint(plain)
+					continue
+				}
+				containerPatch["env"] = append(listAt(containerPatch, "env"), envVar)
+			}
+			if len(vars) > 0 {
+				values["vars"] = vars
+			}
+		case "resources":
+			values["resources"] = value
+		default:
+			containerPatch[key] = value
+		}
+	}
+
+	podPatch := map[string]interface{}{}
+	if len(containerPatch) > 0 {
+		containerPatch["name"] = app
+		podPatch["containers"] = []interface{}{containerPatch}
+	}
+	for _, sidecar := range containers[1:] {
+		podPatch["containers"] = append(listAt(podPatch, "containers"), sidecar)
+	}
+	for key, value := range podSpec {
+		switch key {
+		case "containers":
+		case "nodeSelector", "tolerations", "affinity":
+			values[key] = value
+		default:
+			podPatch[key] = value
+		}
+	}
+
+	specPatch := map[string]interface{}{}
+	if len(podPatch) > 0 {
+		specPatch["template"] = map[string]interface{}{"spec": podPatch}
+	}
+	for key, value := range spec {
+		switch key {
+		case "replicas", "selector", "template":
+		default:
+			specPatch[key] = value
+		}
+	}
+
+	if len(specPatch) == 0 {
+		return nil, nil
+	}
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": app},
+		"spec":       specPatch,
+	}, nil
+}
+
+// importService returns a patch for the ports of the Service beyond the first one, that OneChart renders
+func importService(service map[string]interface{}, app string) map[string]interface{} {
+	ports := listAt(mapAt(service, "spec"), "ports")
+	if len(ports) <= 1 {
+		return nil
+	}
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": app},
+		"spec":       map[string]interface{}{"ports": ports[1:]},
+	}
+}
+
+// importIngress maps the host, TLS and annotations of the Ingress to OneChart
+func importIngress(ingress map[string]interface{}, values map[string]interface{}) []string {
+	warnings := []string{}
+	spec := mapAt(ingress, "spec")
+	rules := listAt(spec, "rules")
+
+	ingressValues := map[string]interface{}{}
+	if len(rules) > 0 {
+		rule, _ := rules[0].(map[string]interface{})
+		ingressValues["host"] = rule["host"]
+	}
+	if len(rules) > 1 {
+		warnings = append(warnings, fmt.Sprintf("only the first host of ingress %s is imported", name(ingress)))
+	}
+	if len(listAt(spec, "tls")) > 0 {
+		ingressValues["tlsEnabled"] = true
+	}
+	if annotations := mapAt(ingress, "metadata", "annotations"); len(annotations) > 0 {
+		ingressValues["annotations"] = annotations
+	}
+	values["ingress"] = ingressValues
+
+	return warnings
+}
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string        `yaml:"image"`
+	Ports       []interface{} `yaml:"ports"`
+	Environment interface{}   `yaml:"environment"`
+	Command     interface{}   `yaml:"command"`
+	Entrypoint  interface{}   `yaml:"entrypoint"`
+	Volumes     []interface{} `yaml:"volumes"`
+	Deploy      struct {
+		Replicas  interface{} `yaml:"replicas"`
+		Resources struct {
+			Limits       composeResources `yaml:"limits"`
+			Reservations composeResources `yaml:"reservations"`
+		} `yaml:"resources"`
+	} `yaml:"deploy"`
+}
+
+type composeResources struct {
+	Cpus   interface{} `yaml:"cpus"`
+	Memory string      `yaml:"memory"`
+}
+
+// importCompose maps a docker-compose service to OneChart values.
+// The command and entrypoint of the service become a strategic merge patch
+func importCompose(content string, serviceName string, chart dx.Chart) (*dx.Manifest, []string, error) {
+	var compose composeFile
+	err := yaml.Unmarshal([]byte(content), &compose)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse compose file %s", err)
+	}
+	if len(compose.Services) == 0 {
+		return nil, nil, fmt.Errorf("no services found in the compose file")
+	}
+
+	if serviceName == "" {
+		if len(compose.Services) > 1 {
+			names := []string{}
+			for name := range compose.Services {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, nil, fmt.Errorf("the compose file has more than one service, pick one with --service: %s", strings.Join(names, ", "))
+		}
+		for name := range compose.Services {
+			serviceName = name
+		}
+	}
+	service, ok := compose.Services[serviceName]
+	if !ok {
+		return nil, nil, fmt.Errorf("service %s not found in the compose file", serviceName)
+	}
+
+	warnings := []string{}
+	values := map[string]interface{}{}
+
+	if service.Image == "" {
+		warnings = append(warnings, fmt.Sprintf("service %s has no image, set image.repository and image.tag to a pushed image", serviceName))
+	} else if repository, tag, ok := splitImage(service.Image); ok {
+		values["image"] = map[string]interface{}{
+			"repository": repository,
+			"tag":        tag,
+		}
+	} else {
+		warnings = append(warnings, fmt.Sprintf("image %s cannot be imported, set image.repository and image.tag", service.Image))
+	}
+
+	if service.Deploy.Replicas != nil {
+		values["replicas"] = service.Deploy.Replicas
+	}
+
+	for i, port := range service.Ports {
+		containerPort, err := composeContainerPort(port)
+		if err != nil {
+			return nil, nil, err
+		}
+		if i == 0 {
+			values["containerPort"] = containerPort
+		} else {
+			warnings = append(warnings, fmt.Sprintf("only the first port of %s is imported, port %d is not", serviceName, containerPort))
+		}
+	}
+
+	vars, err := composeEnvironment(service.Environment)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(vars) > 0 {
+		values["vars"] = vars
+	}
+
+	resources := map[string]interface{}{}
+	if requests := composeToResources(service.Deploy.Resources.Reservations); len(requests) > 0 {
+		resources["requests"] = requests
+	}
+	if limits := composeToResources(service.Deploy.Resources.Limits); len(limits) > 0 {
+		resources["limits"] = limits
+	}
+	if len(resources) > 0 {
+		values["resources"] = resources
+	}
+
+	if len(service.Volumes) > 0 {
+		warnings = append(warnings, fmt.Sprintf("volumes of %s are not imported, configure them in the volumes value of OneChart", serviceName))
+	}
+
+	m := &dx.Manifest{
+		App:    serviceName,
+		Chart:  chart,
+		Values: values,
+	}
+
+	// compose command overrides the image CMD, that is args in Kubernetes. Entrypoint is command
+	containerPatch := map[string]interface{}{}
+	if service.Entrypoint != nil {
+		containerPatch["command"] = composeCommand(service.Entrypoint)
+	}
+	if service.Command != nil {
+		containerPatch["args"] = composeCommand(service.Command)
+	}
+	if len(containerPatch) > 0 {
+		containerPatch["name"] = serviceName
+		m.StrategicMergePatches, err = marshalObjects([]map[string]interface{}{{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": serviceName},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{containerPatch},
+					},
+				},
+			},
+		}})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return m, warnings, nil
+}
+
+// composeContainerPort parses the container port from the short ("8080:80/tcp") or long ({target: 80}) port syntax
+func composeContainerPort(port interface{}) (int, error) {
+	var portString string
+	switch p := port.(type) {
+	case map[string]interface{}:
+		portString = fmt.Sprint(p["target"])
+	default:
+		portString = fmt.Sprint(p)
+	}
+
+	parts := strings.Split(portString, ":")
+	portString = strings.Split(parts[len(parts)-1], "/")[0]
+	containerPort, err := strconv.Atoi(portString)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse port %v", port)
+	}
+	return containerPort, nil
+}
+
+// composeEnvironment parses the list ("KEY=value") or map syntax of compose environments
+func composeEnvironment(environment interface{}) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	switch env := environment.(type) {
+	case nil:
+	case []interface{}:
+		for _, e := range env {
+			pair := strings.SplitN(fmt.Sprint(e), "=", 2)
+			if len(pair) == 2 {
+				vars[pair[0]] = pair[1]
+			} else {
+				vars[pair[0]] = ""
+			}
+		}
+	case map[string]interface{}:
+		for key, value := range env {
+			if value == nil {
+				vars[key] = ""
+			} else {
+				vars[key] = fmt.Sprint(value)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("cannot parse environment %v", environment)
+	}
+	return vars, nil
+}
+
+func composeCommand(command interface{}) []interface{} {
+	switch c := command.(type) {
+	case []interface{}:
+		return c
+	default:
+		return []interface{}{"/bin/sh", "-c", fmt.Sprint(c)}
+	}
+}
+
+// composeToResources converts compose cpus and memory to Kubernetes quantities.
+// Compose memory units are b, k, m, g, that are the binary Ki, Mi, Gi in Kubernetes
+func composeToResources(resources composeResources) map[string]interface{} {
+	quantities := map[string]interface{}{}
+	if resources.Cpus != nil {
+		cpus, err := strconv.ParseFloat(fmt.Sprint(resources.Cpus), 64)
+		if err == nil {
+			quantities["cpu"] = fmt.Sprintf("%dm", int(cpus*1000))
+		}
+	}
+	if resources.Memory != "" {
+		memory := strings.ToLower(strings.TrimSpace(resources.Memory))
+		memory = strings.TrimSuffix(memory, "b")
+		units := map[string]string{"k": "Ki", "m": "Mi", "g": "Gi"}
+		if len(memory) > 0 {
+			if unit, ok := units[memory[len(memory)-1:]]; ok {
+				memory = memory[:len(memory)-1] + unit
+			}
+		}
+		quantities["memory"] = memory
+	}
+	return quantities
+}
+
+// compareRendered checks if every field of the original objects is present in the rendered manifests with the same value.
+// Fields that OneChart sets on its own, like labels and selectors, are not compared
+func compareRendered(objects []map[string]interface{}, rendered string) ([]string, error) {
+	renderedObjects, err := parseObjects(rendered)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse rendered manifest %s", err)
+	}
+	expandConfigMapEnv(renderedObjects)
+
+	differences := []string{}
+	for _, object := range objects {
+		counterpart := findCounterpart(object, renderedObjects)
+		objectName := fmt.Sprintf("%s/%s", kind(object), name(object))
+		if counterpart == nil {
+			differences = append(differences, fmt.Sprintf("%s is missing from the rendered manifest", objectName))
+			continue
+		}
+		differences = append(differences, compareFields(objectName, "", object, counterpart)...)
+	}
+	return differences, nil
+}
+
+var ignoredFields = []string{
+	"status",
+	"metadata.name",
+	"metadata.namespace",
+	"metadata.labels",
+	"metadata.annotations",
+	"metadata.creationTimestamp",
+	"spec.selector",
+	"spec.template.metadata.labels",
+	"spec.template.spec.containers[0].name",
+	"spec.rules[0].http.paths[0].backend",
+}
+
+func compareFields(objectName string, path string, original interface{}, rendered interface{}) []string {
+	if ignored(path) {
+		return nil
+	}
+
+	differences := []string{}
+	switch o := original.(type) {
+	case map[string]interface{}:
+		r, ok := rendered.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: %s is %v, rendered as %v", objectName, path, original, rendered)}
+		}
+		keys := []string{}
+		for key := range o {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+			if _, exists := r[key]; !exists {
+				if ignored(fieldPath) {
+					continue
+				}
+				differences = append(differences, fmt.Sprintf("%s: %s is missing", objectName, fieldPath))
+				continue
+			}
+			differences = append(differences, compareFields(objectName, fieldPath, o[key], r[key])...)
+		}
+	case []interface{}:
+		r, ok := rendered.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: %s is %v, rendered as %v", objectName, path, original, rendered)}
+		}
+		for i, item := range o {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			counterpart := findListItem(item, i, r)
+			if counterpart == nil {
+				differences = append(differences, fmt.Sprintf("%s: %s is missing", objectName, itemPath))
+				continue
+			}
+			differences = append(differences, compareFields(objectName, itemPath, item, counterpart)...)
+		}
+	default:
+		if fmt.Sprint(original) != fmt.Sprint(rendered) {
+			differences = append(differences, fmt.Sprintf("%s: %s is %v, rendered as %v", objectName, path, original, rendered))
+		}
+	}
+	return differences
+}
+
+func ignored(path string) bool {
+	for _, ignored := range ignoredFields {
+		if path == ignored {
+			return true
+		}
+	}
+	return false
+}
+
+// findListItem matches list items by name, or by index if the item has no name
+func findListItem(item interface{}, index int, list []interface{}) interface{} {
+	if itemMap, ok := item.(map[string]interface{}); ok {
+		if itemName, ok := itemMap["name"]; ok {
+			for _, candidate := range list {
+				if candidateMap, ok := candidate.(map[string]interface{}); ok && candidateMap["name"] == itemName {
+					return candidate
+				}
+			}
+		}
+	}
+	if index < len(list) {
+		return list[index]
+	}
+	return nil
+}
+
+// findCounterpart finds the rendered object of the same kind and name,
+// or the only rendered object of the same kind, as OneChart names objects after the app
+func findCounterpart(object map[string]interface{}, rendered []map[string]interface{}) map[string]interface{} {
+	sameKind := []map[string]interface{}{}
+	for _, candidate := range rendered {
+		if kind(candidate) != kind(object) {
+			continue
+		}
+		if name(candidate) == name(object) {
+			return candidate
+		}
+		sameKind = append(sameKind, candidate)
+	}
+	if len(sameKind) == 1 {
+		return sameKind[0]
+	}
+	return nil
+}
+
+// expandConfigMapEnv adds the variables of the ConfigMaps referenced in envFrom to the container env,
+// as OneChart renders vars to a ConfigMap
+func expandConfigMapEnv(objects []map[string]interface{}) {
+	configMaps := map[string]map[string]interface{}{}
+	for _, object := range objects {
+		if kind(object) == "ConfigMap" {
+			configMaps[name(object)] = mapAt(object, "data")
+		}
+	}
+
+	for _, object := range objects {
+		podSpec := mapAt(object, "spec", "template", "spec")
+		for _, c := range listAt(podSpec, "containers") {
+			container, _ := c.(map[string]interface{})
+			env := listAt(container, "env")
+			for _, e := range listAt(container, "envFrom") {
+				envFrom, _ := e.(map[string]interface{})
+				data := configMaps[stringAt(envFrom, "configMapRef", "name")]
+				keys := []string{}
+				for key := range data {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				for _, key := range keys {
+					env = append(env, map[string]interface{}{"name": key, "value": data[key]})
+				}
+			}
+			if len(env) > 0 {
+				container["env"] = env
+			}
+		}
+	}
+}
+
+func splitImage(image string) (string, string, bool) {
+	if strings.Contains(image, "@") {
+		return "", "", false
+	}
+	lastSlash := strings.LastIndex(image, "/")
+	lastColon := strings.LastIndex(image, ":")
+	if lastColon <= lastSlash {
+		return image, "latest", true
+	}
+	return image[:lastColon], image[lastColon+1:], true
+}
+
+func selects(selector map[string]interface{}, labels map[string]interface{}) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func routesTo(ingress map[string]interface{}, serviceName string) bool {
+	for _, r := range listAt(mapAt(ingress, "spec"), "rules") {
+		rule, _ := r.(map[string]interface{})
+		for _, p := range listAt(mapAt(rule, "http"), "paths") {
+			path, _ := p.(map[string]interface{})
+			if stringAt(path, "backend", "service", "name") == serviceName ||
+				stringAt(path, "backend", "serviceName") == serviceName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsObject(objects []map[string]interface{}, object map[string]interface{}) bool {
+	for _, o := range objects {
+		if reflect.ValueOf(o).Pointer() == reflect.ValueOf(object).Pointer() {
+			return true
+		}
+	}
+	return false
+}
+
+func marshalObjects(objects []map[string]interface{}) (string, error) {
+	var sb strings.Builder
+	for _, object := range objects {
+		yamlString := bytes.NewBufferString("")
+		e := yaml.NewEncoder(yamlString)
+		e.SetIndent(2)
+		err := e.Encode(object)
+		if err != nil {
+			return "", fmt.Errorf("cannot serialize %s/%s %s", kind(object), name(object), err)
+		}
+		sb.WriteString("---\n")
+		sb.WriteString(yamlString.String())
+	}
+	return sb.String(), nil
+}
+
+func kind(object map[string]interface{}) string {
+	return stringAt(object, "kind")
+}
+
+func name(object map[string]interface{}) string {
+	return stringAt(object, "metadata", "name")
+}
+
+func mapAt(object map[string]interface{}, path ...string) map[string]interface{} {
+	current := object
+	for _, key := range path {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return map[string]interface{}{}
+		}
+		current = next
+	}
+	return current
+}
+
+func listAt(object map[string]interface{}, key string) []interface{} {
+	list, _ := object[key].([]interface{})
+	return list
+}
+
+func stringAt(object map[string]interface{}, path ...string) string {
+	parent := mapAt(object, path[:len(path)-1]...)
+	value, ok := parent[path[len(path)-1]]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,198 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/stretchr/testify/assert"
+)
+
+const plainYaml = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+  namespace: my-team
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: myapp
+  template:
+    metadata:
+      labels:
+        app: myapp
+    spec:
+      serviceAccountName: myapp
+      containers:
+      - name: web
+        image: ghcr.io/myorg/myapp:1.0.0
+        args: ["serve"]
+        ports:
+        - containerPort: 8080
+        env:
+        - name: LOG_LEVEL
+          value: debug
+        - name: DB_PASSWORD
+          valueFrom:
+            secretKeyRef:
+              name: db
+              key: password
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: myapp
+spec:
+  selector:
+    app: myapp
+  ports:
+  - port: 80
+    targetPort: 8080
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: myapp
+  annotations:
+    cert-manager.io/cluster-issuer: letsencrypt
+spec:
+  tls:
+  - hosts: [myapp.example.com]
+    secretName: tls-myapp
+  rules:
+  - host: myapp.example.com
+    http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: myapp
+            port:
+              number: 80
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: extra
+data:
+  key: value
+`
+
+func Test_importKubernetes(t *testing.T) {
+	objects, err := parseObjects(plainYaml)
+	assert.Nil(t, err)
+
+	m, warnings, err := importKubernetes(objects, "", dx.Chart{Name: "onechart"})
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+
+	assert.Equal(t, "myapp", m.App)
+	assert.Equal(t, "my-team", m.Namespace)
+	assert.Equal(t, map[string]interface{}{"repository": "ghcr.io/myorg/myapp", "tag": "1.0.0"}, m.Values["image"])
+	assert.Equal(t, 2, m.Values["replicas"])
+	assert.Equal(t, 8080, m.Values["containerPort"])
+	assert.Equal(t, map[string]interface{}{"LOG_LEVEL": "debug"}, m.Values["vars"])
+	assert.Equal(t, map[string]interface{}{
+		"host":        "myapp.example.com",
+		"tlsEnabled":  true,
+		"annotations": map[string]interface{}{"cert-manager.io/cluster-issuer": "letsencrypt"},
+	}, m.Values["ingress"])
+
+	assert.True(t, strings.Contains(m.StrategicMergePatches, "serviceAccountName: myapp"), m.StrategicMergePatches)
+	assert.True(t, strings.Contains(m.StrategicMergePatches, "DB_PASSWORD"), m.StrategicMergePatches)
+	assert.True(t, strings.Contains(m.StrategicMergePatches, "- serve"), m.StrategicMergePatches)
+	assert.False(t, strings.Contains(m.StrategicMergePatches, "LOG_LEVEL"), m.StrategicMergePatches)
+
+	assert.True(t, strings.Contains(m.Manifests, "kind: ConfigMap"), m.Manifests)
+	assert.False(t, strings.Contains(m.Manifests, "kind: Deployment"), m.Manifests)
+}
+
+func Test_importCompose(t *testing.T) {
+	compose := `
+services:
+  web:
+    image: nginx:1.25
+    command: ["nginx", "-g", "daemon off;"]
+    ports:
+    - "8080:80"
+    environment:
+      - MODE=production
+    deploy:
+      resources:
+        limits:
+          cpus: "0.5"
+          memory: 512M
+  db:
+    image: postgres
+`
+	_, _, err := importCompose(compose, "", dx.Chart{Name: "onechart"})
+	assert.NotNil(t, err, "should ask for a service when there are more than one")
+
+	m, warnings, err := importCompose(compose, "web", dx.Chart{Name: "onechart"})
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "web", m.App)
+	assert.Equal(t, map[string]interface{}{"repository": "nginx", "tag": "1.25"}, m.Values["image"])
+	assert.Equal(t, 80, m.Values["containerPort"])
+	assert.Equal(t, map[string]interface{}{"MODE": "production"}, m.Values["vars"])
+	assert.Equal(t, map[string]interface{}{
+		"limits": map[string]interface{}{"cpu": "500m", "memory": "512Mi"},
+	}, m.Values["resources"])
+	assert.True(t, strings.Contains(m.StrategicMergePatches, "daemon off;"), m.StrategicMergePatches)
+}
+
+func Test_compareRendered(t *testing.T) {
+	objects, err := parseObjects(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: myapp:1.0.0
+        env:
+        - name: LOG_LEVEL
+          value: debug
+`)
+	assert.Nil(t, err)
+
+	rendered := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: myapp
+data:
+  LOG_LEVEL: debug
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+spec:
+  template:
+    spec:
+      containers:
+      - name: myapp
+        image: myapp:1.0.0
+        envFrom:
+        - configMapRef:
+            name: myapp
+`
+	differences, err := compareRendered(objects, rendered)
+	assert.Nil(t, err)
+	assert.Empty(t, differences, "env from the ConfigMap should match")
+
+	differences, err = compareRendered(objects, strings.Replace(rendered, "myapp:1.0.0", "myapp:2.0.0", 1))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Deployment/myapp: spec.template.spec.containers[0].image is myapp:1.0.0, rendered as myapp:2.0.0"}, differences)
+}
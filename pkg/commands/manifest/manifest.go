@@ -10,5 +10,7 @@ var Command = cli.Command{
 		&manifestTemplateCmd,
 		&manifestLintCmd,
 		&manifestConfigureCmd,
+		&manifestImportCmd,
+		&manifestTestCmd,
 	},
 }
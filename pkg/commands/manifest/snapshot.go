@@ -0,0 +1,305 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/enescakir/emoji"
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/gitops/sync"
+	"github.com/joho/godotenv"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/urfave/cli/v2"
+)
+
+const snapshotsDir = "__snapshots__"
+
+var manifestTestCmd = cli.Command{
+	Name:  "test",
+	Usage: "Renders every Gimlet manifest and compares them with their snapshots",
+	UsageText: `gimlet manifest test
+
+   To create or refresh the snapshots:
+     gimlet manifest test --update
+
+   The manifests are rendered with the variables of .gimlet/__snapshots__/vars.env,
+   so the snapshots don't depend on the environment they are tested in.`,
+	Action: snapshotTest,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "dir",
+			Usage: "the directory of the Gimlet manifests",
+			Value: ".gimlet",
+		},
+		&cli.StringFlag{
+			Name:    "vars",
+			Aliases: []string{"v"},
+			Usage:   "an .env file with the variable fixtures, defaults to <dir>/__snapshots__/vars.env",
+		},
+		&cli.BoolFlag{
+			Name:  "update",
+			Usage: "write the rendered manifests to the snapshots",
+		},
+		&cli.StringFlag{
+			Name:  "flux-api-version",
+			Usage: "API version of the rendered Flux resources (v1, v1beta2, v1beta1)",
+		},
+	},
+}
+
+func snapshotTest(c *cli.Context) error {
+	fluxAPIVersion := c.String("flux-api-version")
+	err := sync.ValidateFluxAPIVersion(fluxAPIVersion)
+	if err != nil {
+		return err
+	}
+
+	dir := c.String("dir")
+	varsPath := c.String("vars")
+	if varsPath == "" {
+		varsPath = filepath.Join(dir, snapshotsDir, "vars.env")
+	}
+	vars, err := snapshotVars(varsPath, c.IsSet("vars"))
+	if err != nil {
+		return err
+	}
+
+	failed, err := testSnapshots(dir, vars, fluxAPIVersion, c.Bool("update"), os.Stdout)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d snapshot(s) failed, run with --update if the changes are intended", failed)
+	}
+	return nil
+}
+
+// snapshotVars reads the variable fixtures. The default fixture file is optional
+func snapshotVars(varsPath string, mandatory bool) (map[string]string, error) {
+	varsString, err := ioutil.ReadFile(varsPath)
+	if err != nil {
+		if os.IsNotExist(err) && !mandatory {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("cannot read vars file: %s", err.Error())
+	}
+
+	vars, err := godotenv.Parse(strings.NewReader(string(varsString)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse vars: %s", err.Error())
+	}
+	return vars, nil
+}
+
+// testSnapshots renders every manifest in dir and compares it with its snapshot, or writes the snapshot on update.
+// It returns the number of failed snapshots
+func testSnapshots(dir string, vars map[string]string, fluxAPIVersion string, update bool, out io.Writer) (int, error) {
+	files, err := manifestFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+	if len(files) == 0 {
+		return 0, fmt.Errorf("no Gimlet manifests found in %s", dir)
+	}
+
+	if update {
+		err = os.MkdirAll(filepath.Join(dir, snapshotsDir), 0755)
+		if err != nil {
+			return 0, fmt.Errorf("cannot create snapshot directory: %s", err)
+		}
+	}
+
+	failed := 0
+	snapshots := map[string]bool{}
+	for _, file := range files {
+		snapshotPath := filepath.Join(dir, snapshotsDir, filepath.Base(file)+".snap")
+		snapshots[snapshotPath] = true
+
+		fileContent, err := ioutil.ReadFile(file)
+		if err != nil {
+			return 0, fmt.Errorf("cannot read file: %s", err.Error())
+		}
+		rendered, err := renderManifestFile(file, fileContent, vars, fluxAPIVersion)
+		if err != nil {
+			fmt.Fprintf(out, "%v %s cannot be rendered: %s\n", emoji.CrossMark, file, err)
+			failed++
+			continue
+		}
+
+		if update {
+			err = ioutil.WriteFile(snapshotPath, []byte(rendered), 0666)
+			if err != nil {
+				return 0, fmt.Errorf("cannot write snapshot %s", err)
+			}
+			fmt.Fprintf(out, "%v %s snapshot written\n", emoji.Pencil, file)
+			continue
+		}
+
+		snapshot, err := ioutil.ReadFile(snapshotPath)
+		if os.IsNotExist(err) {
+			fmt.Fprintf(out, "%v %s has no snapshot, run with --update to create it\n", emoji.CrossMark, file)
+			failed++
+			continue
+		} else if err != nil {
+			return 0, fmt.Errorf("cannot read snapshot %s", err)
+		}
+
+		diff, err := snapshotDiff(string(snapshot), rendered)
+		if err != nil {
+			return 0, err
+		}
+		if diff == "" {
+			fmt.Fprintf(out, "%v %s\n", emoji.CheckMark, file)
+			continue
+		}
+		fmt.Fprintf(out, "%v %s differs from its snapshot\n%s", emoji.CrossMark, file, diff)
+		failed++
+	}
+
+	// snapshots of deleted manifests
+	existing, _ := filepath.Glob(filepath.Join(dir, snapshotsDir, "*.snap"))
+	for _, snapshotPath := range existing {
+		if snapshots[snapshotPath] {
+			continue
+		}
+		if update {
+			err = os.Remove(snapshotPath)
+			if err != nil {
+				return 0, fmt.Errorf("cannot remove obsolete snapshot %s", err)
+			}
+			fmt.Fprintf(out, "%v %s obsolete snapshot removed\n", emoji.Wastebasket, snapshotPath)
+		} else {
+			fmt.Fprintf(out, "%v %s is obsolete, run with --update to remove it\n", emoji.Warning, snapshotPath)
+		}
+	}
+
+	return failed, nil
+}
+
+func manifestFiles(dir string) ([]string, error) {
+	files := []string{}
+	for _, pattern := range []string{"*.yaml", "*.yml", "*.cue"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// snapshotDiff compares the rendered manifests object by object.
+// It returns the unified diff of the changed objects, and lists the added and removed ones
+func snapshotDiff(expected string, actual string) (string, error) {
+	if expected == actual {
+		return "", nil
+	}
+
+	expectedObjects, expectedOrder := splitObjects(expected)
+	actualObjects, actualOrder := splitObjects(actual)
+
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	var sb strings.Builder
+	for _, key := range expectedOrder {
+		if _, exists := actualObjects[key]; !exists {
+			sb.WriteString(red(fmt.Sprintf("  - %s removed\n", key)))
+		}
+	}
+	for _, key := range actualOrder {
+		if _, exists := expectedObjects[key]; !exists {
+			sb.WriteString(green(fmt.Sprintf("  + %s added\n", key)))
+		}
+	}
+	for _, key := range actualOrder {
+		expectedObject, exists := expectedObjects[key]
+		if !exists || expectedObject == actualObjects[key] {
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:       objectLines(expectedObject),
+			B:       objectLines(actualObjects[key]),
+			Context: 3,
+		})
+		if err != nil {
+			return "", fmt.Errorf("cannot diff %s: %s", key, err)
+		}
+
+		sb.WriteString(bold(fmt.Sprintf("  ~ %s changed\n", key)))
+		for _, line := range objectLines(diff) {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				sb.WriteString("    " + cyan(line))
+			case strings.HasPrefix(line, "+"):
+				sb.WriteString("    " + green(line))
+			case strings.HasPrefix(line, "-"):
+				sb.WriteString("    " + red(line))
+			default:
+				sb.WriteString("    " + line)
+			}
+		}
+	}
+
+	if sb.Len() == 0 { // only the document separators or the order of the objects changed
+		sb.WriteString("  the order or formatting of the objects changed\n")
+	}
+	return sb.String(), nil
+}
+
+// objectLines splits the newline terminated object to lines, without the empty line difflib.SplitLines adds
+func objectLines(object string) []string {
+	lines := strings.SplitAfter(object, "\n")
+	return lines[:len(lines)-1]
+}
+
+// splitObjects splits multi document yaml to objects keyed by kind, namespace and name
+func splitObjects(manifests string) (map[string]string, []string) {
+	objects := map[string]string{}
+	order := []string{}
+
+	var documents []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(manifests, "\n") {
+		if strings.HasPrefix(line, "---") {
+			documents = append(documents, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteString(line)
+	}
+	documents = append(documents, current.String())
+
+	for _, document := range documents {
+		parsed, err := parseObjects(document)
+		if err != nil || len(parsed) == 0 {
+			continue
+		}
+		object := parsed[0]
+
+		key := fmt.Sprintf("%s/%s", kind(object), name(object))
+		if namespace := stringAt(object, "metadata", "namespace"); namespace != "" {
+			key = fmt.Sprintf("%s/%s/%s", kind(object), namespace, name(object))
+		}
+		uniqueKey := key
+		for i := 2; ; i++ {
+			if _, exists := objects[uniqueKey]; !exists {
+				break
+			}
+			uniqueKey = fmt.Sprintf("%s#%d", key, i)
+		}
+
+		objects[uniqueKey] = strings.TrimSpace(document) + "\n"
+		order = append(order, uniqueKey)
+	}
+	return objects, order
+}
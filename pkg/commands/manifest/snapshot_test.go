@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const rawManifest = `
+app: myapp
+env: staging
+namespace: default
+manifests: |
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: myapp
+  data:
+    version: "{{ .VERSION }}"
+  ---
+  apiVersion: v1
+  kind: Service
+  metadata:
+    name: myapp
+  spec:
+    ports:
+    - port: 80
+`
+
+func Test_testSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gimlet-cli-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	manifestPath := filepath.Join(dir, "staging.yaml")
+	err = ioutil.WriteFile(manifestPath, []byte(rawManifest), 0666)
+	assert.Nil(t, err)
+	vars := map[string]string{"VERSION": "1.0.0"}
+
+	var out bytes.Buffer
+	failed, err := testSnapshots(dir, vars, "", false, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, failed, "missing snapshot should fail")
+
+	out.Reset()
+	failed, err = testSnapshots(dir, vars, "", true, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, failed)
+	snapshot, err := ioutil.ReadFile(filepath.Join(dir, snapshotsDir, "staging.yaml.snap"))
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(snapshot), `version: "1.0.0"`), string(snapshot))
+
+	out.Reset()
+	failed, err = testSnapshots(dir, vars, "", false, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, failed, out.String())
+
+	changed := strings.Replace(rawManifest, "port: 80", "port: 8080", 1)
+	err = ioutil.WriteFile(manifestPath, []byte(changed), 0666)
+	assert.Nil(t, err)
+
+	out.Reset()
+	failed, err = testSnapshots(dir, vars, "", false, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, failed)
+	assert.True(t, strings.Contains(out.String(), "Service/myapp changed"), out.String())
+	assert.False(t, strings.Contains(out.String(), "ConfigMap/myapp"), out.String())
+	assert.True(t, strings.Contains(out.String(), "+  - port: 8080"), out.String())
+}
+
+func Test_snapshotDiff(t *testing.T) {
+	expected := `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`
+	actual := `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: c
+`
+	diff, err := snapshotDiff(expected, actual)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(diff, "ConfigMap/b removed"), diff)
+	assert.True(t, strings.Contains(diff, "Secret/c added"), diff)
+	assert.False(t, strings.Contains(diff, "ConfigMap/a"), diff)
+
+	diff, err = snapshotDiff(expected, expected)
+	assert.Nil(t, err)
+	assert.Equal(t, "", diff)
+}
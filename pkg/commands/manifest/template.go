@@ -1,9 +1,11 @@
 package manifest
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/gimlet-io/gimlet-cli/pkg/gitops/sync"
 	"github.com/joho/godotenv"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/yaml.v3"
@@ -19,14 +21,19 @@ var manifestTemplateCmd = cli.Command{
 	UsageText: `gimlet manifest template \
     -f .gimlet/staging.yaml \
     -o manifests.yaml \
-    --vars ci.env`,
+    --vars ci.env
+
+   To render every manifest of the .gimlet folder that the artifact would deploy:
+     gimlet manifest template \
+       --all \
+       --artifact artifact.json \
+       --output-dir rendered`,
 	Action: templateCmd,
 	Flags: []cli.Flag{
 		&cli.StringFlag{
-			Name:     "file",
-			Aliases:  []string{"f"},
-			Required: true,
-			Usage:    "Gimlet manifest file to template, or \"-\" for stdin",
+			Name:    "file",
+			Aliases: []string{"f"},
+			Usage:   "Gimlet manifest file to template, or \"-\" for stdin",
 		},
 		&cli.StringFlag{
 			Name:    "vars",
@@ -38,6 +45,35 @@ var manifestTemplateCmd = cli.Command{
 			Aliases: []string{"o"},
 			Usage:   "output file",
 		},
+		&cli.StringFlag{
+			Name:  "flux-api-version",
+			Usage: "API version of the rendered Flux resources (v1, v1beta2, v1beta1)",
+		},
+		&cli.BoolFlag{
+			Name:  "all",
+			Usage: "template every manifest of the manifest directory into --output-dir",
+		},
+		&cli.StringFlag{
+			Name:  "dir",
+			Usage: "the directory of the Gimlet manifests, used with --all",
+			Value: ".gimlet",
+		},
+		&cli.StringFlag{
+			Name:  "artifact",
+			Usage: "an artifact json file to resolve the variables from. With --all, only the manifests the artifact would deploy are templated",
+		},
+		&cli.StringFlag{
+			Name:  "output-dir",
+			Usage: "the directory to write the manifests to, in the gitops repository layout, used with --all",
+		},
+		&cli.StringFlag{
+			Name:  "env",
+			Usage: "template only the manifests of this environment, used with --all",
+		},
+		&cli.BoolFlag{
+			Name:  "repo-per-env",
+			Usage: "the output directory is the gitops repository of a single environment, used with --all",
+		},
 	},
 }
 
@@ -56,6 +92,24 @@ func templateCmd(c *cli.Context) error {
 		}
 	}
 
+	var artifact *dx.Artifact
+	if c.String("artifact") != "" {
+		artifactString, err := ioutil.ReadFile(c.String("artifact"))
+		if err != nil {
+			return fmt.Errorf("cannot read artifact file: %s", err.Error())
+		}
+		artifact = &dx.Artifact{}
+		err = json.Unmarshal(artifactString, artifact)
+		if err != nil {
+			return fmt.Errorf("cannot parse artifact: %s", err.Error())
+		}
+		for k, v := range artifact.CollectVariables() {
+			if _, exists := vars[k]; !exists {
+				vars[k] = v
+			}
+		}
+	}
+
 	for _, v := range os.Environ() {
 		pair := strings.SplitN(v, "=", 2)
 		if _, exists := vars[pair[0]]; !exists {
@@ -63,7 +117,30 @@ func templateCmd(c *cli.Context) error {
 		}
 	}
 
-	var templatedManifests string
+	fluxAPIVersion := c.String("flux-api-version")
+	err := sync.ValidateFluxAPIVersion(fluxAPIVersion)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("all") {
+		if c.String("output-dir") == "" {
+			return fmt.Errorf("--output-dir is mandatory with --all")
+		}
+		return templateAll(
+			c.String("dir"),
+			c.String("output-dir"),
+			c.String("env"),
+			c.Bool("repo-per-env"),
+			artifact,
+			vars,
+			fluxAPIVersion,
+			os.Stdout,
+		)
+	}
+	if c.String("file") == "" {
+		return fmt.Errorf("either --file or --all is mandatory")
+	}
 
 	filePath := c.String("file")
 	fileContent, err := ioutil.ReadFile(filePath)
@@ -71,41 +148,52 @@ func templateCmd(c *cli.Context) error {
 		return fmt.Errorf("cannot read file: %s", err.Error())
 	}
 
+	templatedManifests, err := renderManifestFile(filePath, fileContent, vars, fluxAPIVersion)
+	if err != nil {
+		return err
+	}
+
+	outputPath := c.String("output")
+	if outputPath != "" {
+		err := ioutil.WriteFile(outputPath, []byte(templatedManifests), 0666)
+		if err != nil {
+			return fmt.Errorf("cannot write values file %s", err)
+		}
+	} else {
+		fmt.Println(templatedManifests)
+	}
+
+	return nil
+}
+
+// renderManifestFile renders a Gimlet manifest file, in YAML or CUE format
+func renderManifestFile(filePath string, fileContent []byte, vars map[string]string, fluxAPIVersion string) (string, error) {
+	var templatedManifests string
 	if strings.HasSuffix(filePath, ".cue") { // handling CUE format
 		manifests, err := dx.RenderCueToManifests(string(fileContent))
 		if err != nil {
-			return fmt.Errorf("cannot parse cue file: %s", err.Error())
+			return "", fmt.Errorf("cannot parse cue file: %s", err.Error())
 		}
 
 		for _, m := range manifests {
-			tm, err := parseResolveAndRenderManifest([]byte(m), vars)
+			tm, err := parseResolveAndRenderManifest([]byte(m), vars, fluxAPIVersion)
 			if err != nil {
-				return fmt.Errorf(err.Error())
+				return "", err
 			}
 
 			templatedManifests += tm
 		}
 	} else { // handling YAML format
-		templatedManifests, err = parseResolveAndRenderManifest(fileContent, vars)
-		if err != nil {
-			return fmt.Errorf(err.Error())
-		}
-	}
-
-	outputPath := c.String("output")
-	if outputPath != "" {
-		err := ioutil.WriteFile(outputPath, []byte(templatedManifests), 0666)
+		var err error
+		templatedManifests, err = parseResolveAndRenderManifest(fileContent, vars, fluxAPIVersion)
 		if err != nil {
-			return fmt.Errorf("cannot write values file %s", err)
+			return "", err
 		}
-	} else {
-		fmt.Println(templatedManifests)
 	}
-
-	return nil
+	return templatedManifests, nil
 }
 
-func parseResolveAndRenderManifest(manifestString []byte, vars map[string]string) (string, error) {
+func parseResolveAndRenderManifest(manifestString []byte, vars map[string]string, fluxAPIVersion string) (string, error) {
 	var m dx.Manifest
 	err := yaml.Unmarshal(manifestString, &m)
 	if err != nil {
@@ -117,5 +205,5 @@ func parseResolveAndRenderManifest(manifestString []byte, vars map[string]string
 		return "", fmt.Errorf("cannot resolve manifest vars %s", err.Error())
 	}
 
-	return m.Render()
+	return m.RenderWithFluxAPIVersion(fluxAPIVersion)
 }
@@ -0,0 +1,197 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/enescakir/emoji"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"gopkg.in/yaml.v3"
+)
+
+// templateAll renders every manifest of dir into outputDir, in the layout of the gitops repository.
+// With an artifact, only the manifests that the artifact would deploy are rendered, the same way the dashboard decides
+func templateAll(
+	dir string,
+	outputDir string,
+	env string,
+	repoPerEnv bool,
+	artifact *dx.Artifact,
+	vars map[string]string,
+	fluxAPIVersion string,
+	out io.Writer,
+) error {
+	files, err := manifestFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	manifests := []*dx.Manifest{}
+	for _, file := range files {
+		fileContent, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("cannot read file: %s", err.Error())
+		}
+		fileManifests, err := manifestsInFile(file, fileContent)
+		if err != nil {
+			return fmt.Errorf("%s: %s", file, err)
+		}
+		for _, m := range fileManifests {
+			if env == "" || m.Env == env {
+				manifests = append(manifests, m)
+			}
+		}
+	}
+	if len(manifests) == 0 {
+		return fmt.Errorf("no Gimlet manifests found in %s", dir)
+	}
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return manifests[i].Env+"/"+manifests[i].App < manifests[j].Env+"/"+manifests[j].App
+	})
+
+	envs := map[string]bool{}
+	apps := map[string]bool{}
+	for _, m := range manifests {
+		envs[m.Env] = true
+		if apps[m.Env+"/"+m.App] {
+			return fmt.Errorf("%s is defined in more than one manifest", m.Env+"/"+m.App)
+		}
+		apps[m.Env+"/"+m.App] = true
+	}
+	if repoPerEnv && len(envs) > 1 {
+		return fmt.Errorf("every environment has its own gitops repository with --repo-per-env, pick one with --env")
+	}
+
+	for _, m := range manifests {
+		if artifact != nil && !dx.DeployTrigger(artifact, m.Deploy) {
+			fmt.Fprintf(out, "%v %s/%s would not deploy\n", emoji.NoEntry, m.Env, m.App)
+			continue
+		}
+
+		err = m.ResolveVars(vars)
+		if err != nil {
+			return fmt.Errorf("cannot resolve manifest vars of %s/%s: %s", m.Env, m.App, err)
+		}
+		rendered, err := m.RenderWithFluxAPIVersion(fluxAPIVersion)
+		if err != nil {
+			return fmt.Errorf("cannot render %s/%s: %s", m.Env, m.App, err)
+		}
+
+		appDir := filepath.Join(outputDir, m.Env, m.App)
+		if repoPerEnv {
+			appDir = filepath.Join(outputDir, m.App)
+		}
+		renderedFiles, err := writeAppFiles(appDir, rendered)
+		if err != nil {
+			return err
+		}
+
+		if artifact != nil {
+			fmt.Fprintf(out, "%v %s/%s would deploy, %d file(s) written to %s\n", emoji.CheckMark, m.Env, m.App, renderedFiles, appDir)
+		} else {
+			fmt.Fprintf(out, "%v %s/%s %d file(s) written to %s\n", emoji.CheckMark, m.Env, m.App, renderedFiles, appDir)
+		}
+	}
+
+	return nil
+}
+
+// manifestsInFile parses the manifests of a Gimlet manifest file, in YAML or CUE format
+func manifestsInFile(filePath string, fileContent []byte) ([]*dx.Manifest, error) {
+	manifestStrings := []string{string(fileContent)}
+	if strings.HasSuffix(filePath, ".cue") {
+		var err error
+		manifestStrings, err = dx.RenderCueToManifests(string(fileContent))
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse cue file: %s", err.Error())
+		}
+	}
+
+	manifests := []*dx.Manifest{}
+	for _, manifestString := range manifestStrings {
+		var m dx.Manifest
+		err := yaml.Unmarshal([]byte(manifestString), &m)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal manifest: %s", err.Error())
+		}
+		manifests = append(manifests, &m)
+	}
+	return manifests, nil
+}
+
+// renderedFilesMarker lists the files that an earlier run wrote to an app directory.
+// Only those are removed on the next run, so files of the user are never touched
+const renderedFilesMarker = ".gimlet-template"
+
+// writeAppFiles writes the rendered manifests of an app, split to files like in the gitops repository.
+// Stale files of earlier runs are removed
+func writeAppFiles(appDir string, rendered string) (int, error) {
+	err := removeRenderedFiles(appDir)
+	if err != nil {
+		return 0, err
+	}
+	err = os.MkdirAll(appDir, 0755)
+	if err != nil {
+		return 0, fmt.Errorf("cannot create %s: %s", appDir, err)
+	}
+
+	files := dx.SplitHelmOutput(map[string]string{"manifest.yaml": rendered})
+	names := []string{}
+	for path, content := range files {
+		if !strings.HasSuffix(content, "\n") {
+			content = content + "\n"
+		}
+		name := filepath.Base(path)
+		err = ioutil.WriteFile(filepath.Join(appDir, name), []byte(content), 0666)
+		if err != nil {
+			return 0, fmt.Errorf("cannot write %s: %s", path, err)
+		}
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	marker := filepath.Join(appDir, renderedFilesMarker)
+	err = ioutil.WriteFile(marker, []byte(strings.Join(names, "\n")+"\n"), 0666)
+	if err != nil {
+		return 0, fmt.Errorf("cannot write %s: %s", marker, err)
+	}
+	return len(files), nil
+}
+
+// removeRenderedFiles removes the files that an earlier run wrote to appDir.
+// It refuses a non-empty directory that was not written by this command
+func removeRenderedFiles(appDir string) error {
+	entries, err := ioutil.ReadDir(appDir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot read %s: %s", appDir, err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	marker, err := ioutil.ReadFile(filepath.Join(appDir, renderedFilesMarker))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s is not empty and was not written by this command, use another --output-dir", appDir)
+	} else if err != nil {
+		return fmt.Errorf("cannot read %s: %s", renderedFilesMarker, err)
+	}
+
+	for _, name := range strings.Split(strings.TrimSpace(string(marker)), "\n") {
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(appDir, filepath.Base(name))
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot remove %s: %s", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package manifest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/stretchr/testify/assert"
+)
+
+const stagingManifest = `
+app: myapp
+env: staging
+namespace: default
+deploy:
+  branch: main
+  event: push
+manifests: |
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: myapp
+  data:
+    sha: "{{ .SHA }}"
+`
+
+const productionManifest = `
+app: myapp
+env: production
+namespace: default
+deploy:
+  tag: v*
+  event: tag
+manifests: |
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: myapp
+`
+
+func Test_templateAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gimlet-cli-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	outputDir := filepath.Join(dir, "rendered")
+
+	err = ioutil.WriteFile(filepath.Join(dir, "staging.yaml"), []byte(stagingManifest), 0666)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "production.yaml"), []byte(productionManifest), 0666)
+	assert.Nil(t, err)
+
+	artifact := &dx.Artifact{
+		Version: dx.Version{
+			Branch: "main",
+			Event:  dx.Push,
+		},
+	}
+	vars := map[string]string{"SHA": "abcd"}
+
+	var out bytes.Buffer
+	err = templateAll(dir, outputDir, "", false, artifact, vars, "", &out)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(out.String(), "production/myapp would not deploy"), out.String())
+	assert.True(t, strings.Contains(out.String(), "staging/myapp would deploy"), out.String())
+
+	content, err := ioutil.ReadFile(filepath.Join(outputDir, "staging", "myapp", "manifest.yaml"))
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(content), `sha: "abcd"`), string(content))
+	_, err = os.Stat(filepath.Join(outputDir, "production"))
+	assert.True(t, os.IsNotExist(err), "not triggered environments should not be rendered")
+
+	out.Reset()
+	err = templateAll(dir, outputDir, "", false, nil, vars, "", &out)
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(outputDir, "production", "myapp", "manifest.yaml"))
+	assert.Nil(t, err, "without an artifact every manifest should be rendered")
+
+	err = templateAll(dir, outputDir, "", true, nil, vars, "", &out)
+	assert.NotNil(t, err, "a repo per env output should have one env only")
+
+	repoDir := filepath.Join(dir, "gitops-staging")
+	err = templateAll(dir, repoDir, "staging", true, nil, vars, "", &out)
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(repoDir, "myapp", "manifest.yaml"))
+	assert.Nil(t, err, "apps should be in the repository root with a repo per env")
+
+	notes := filepath.Join(repoDir, "myapp", "notes.txt")
+	err = ioutil.WriteFile(notes, []byte("notes"), 0666)
+	assert.Nil(t, err)
+	err = templateAll(dir, repoDir, "staging", true, nil, vars, "", &out)
+	assert.Nil(t, err)
+	_, err = os.Stat(notes)
+	assert.Nil(t, err, "only the files written by an earlier run should be removed")
+
+	workingTree := filepath.Join(dir, "working-tree")
+	source := filepath.Join(workingTree, "myapp", "main.go")
+	err = os.MkdirAll(filepath.Dir(source), 0755)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(source, []byte("package main"), 0666)
+	assert.Nil(t, err)
+	err = templateAll(dir, workingTree, "staging", true, nil, vars, "", &out)
+	assert.NotNil(t, err, "a directory that was not written by the command should be refused")
+	_, err = os.Stat(source)
+	assert.Nil(t, err)
+}
@@ -1,14 +1,12 @@
 package release
 
 import (
-	"context"
 	"fmt"
 	"os"
 
 	"github.com/enescakir/emoji"
-	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/oauth2"
 )
 
 var releaseDeleteCmd = cli.Command{
@@ -19,19 +17,7 @@ var releaseDeleteCmd = cli.Command{
      --app my-app \
      --server http://gimlet.mycompany.com
      --token c012367f6e6f71de17ae4c6a7baac2e9`,
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name:     "server",
-			Usage:    "Gimlet server URL, GIMLET_SERVER environment variable alternatively",
-			EnvVars:  []string{"GIMLET_SERVER"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "token",
-			Usage:    "Gimlet server api token, GIMLET_TOKEN environment variable alternatively",
-			EnvVars:  []string{"GIMLET_TOKEN"},
-			Required: true,
-		},
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:     "env",
 			Usage:    "rollback in this environment",
@@ -42,24 +28,17 @@ var releaseDeleteCmd = cli.Command{
 			Usage:    "rollback this app",
 			Required: true,
 		},
-	},
+	}, commands.ServerFlags...),
 	Action: delete,
 }
 
 func delete(c *cli.Context) error {
-	serverURL := c.String("server")
-	token := c.String("token")
-
-	config := new(oauth2.Config)
-	auth := config.Client(
-		context.Background(),
-		&oauth2.Token{
-			AccessToken: token,
-		},
-	)
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
 
-	client := client.NewClient(serverURL, auth)
-	err := client.DeletePost(
+	err = client.DeletePost(
 		c.String("env"),
 		c.String("app"),
 	)
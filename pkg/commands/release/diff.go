@@ -0,0 +1,162 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/rvflash/elapsed"
+	"github.com/urfave/cli/v2"
+)
+
+var releaseDiffCmd = cli.Command{
+	Name:  "diff",
+	Usage: "Shows what changed between two releases of an app: source commits, values and rendered manifests",
+	UsageText: `gimlet release diff \
+     --env production \
+     --app my-app \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "env",
+			Usage:    "the environment of the releases",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "app",
+			Usage:    "the app of the releases",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "the gitops ref of the release to compare from, defaults to the release before --to",
+		},
+		&cli.StringFlag{
+			Name:  "to",
+			Usage: "the gitops ref of the release to compare to, defaults to the latest release",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "output format, eg.: json",
+		},
+	}, commands.ServerFlags...),
+	Action: diff,
+}
+
+func diff(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	releaseDiff, err := client.ReleaseDiffGet(c.String("env"), c.String("app"), c.String("from"), c.String("to"))
+	if err != nil {
+		return err
+	}
+
+	if c.String("output") == "json" {
+		diffStr := bytes.NewBufferString("")
+		e := json.NewEncoder(diffStr)
+		e.SetIndent("", "  ")
+		err = e.Encode(releaseDiff)
+		if err != nil {
+			return fmt.Errorf("cannot deserialize release diff %s", err)
+		}
+		fmt.Println(diffStr)
+		return nil
+	}
+
+	fmt.Print(renderReleaseDiff(releaseDiff))
+	return nil
+}
+
+func renderReleaseDiff(releaseDiff *api.ReleaseDiff) string {
+	bold := color.New(color.Bold).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s -> %s\n", releaseDiff.App, releaseDiff.Env))
+	sb.WriteString(fmt.Sprintf("  from %s\n", releaseLine(releaseDiff.From)))
+	sb.WriteString(fmt.Sprintf("  to   %s\n\n", releaseLine(releaseDiff.To)))
+
+	sb.WriteString(bold("Source commits\n"))
+	if releaseDiff.CommitsUnavailable != "" {
+		sb.WriteString(gray(fmt.Sprintf("  unavailable: %s\n", releaseDiff.CommitsUnavailable)))
+	} else if len(releaseDiff.Commits) == 0 {
+		sb.WriteString(gray("  no commits found between the two versions\n"))
+	}
+	for _, commit := range releaseDiff.Commits {
+		sb.WriteString(fmt.Sprintf("  %s %s %s\n",
+			yellow(shortSHA(commit.SHA)),
+			firstLine(commit.Message),
+			gray(fmt.Sprintf("%s, %s", commit.Author, elapsed.Time(time.Unix(commit.Created, 0)))),
+		))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString(bold("Values\n"))
+	if releaseDiff.ValuesUnavailable != "" {
+		sb.WriteString(gray(fmt.Sprintf("  unavailable: %s\n", releaseDiff.ValuesUnavailable)))
+	} else {
+		sb.WriteString(colorizeDiff(releaseDiff.Values, "  no changes\n"))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString(bold("Rendered manifests\n"))
+	sb.WriteString(colorizeDiff(releaseDiff.Manifests, "  no changes\n"))
+
+	return sb.String()
+}
+
+func releaseLine(release *dx.Release) string {
+	if release == nil {
+		return ""
+	}
+
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	line := fmt.Sprintf("%s %s", shortSHA(release.GitopsRef), release.ArtifactID)
+	if release.Version != nil {
+		line += gray(fmt.Sprintf(" %s@%s", release.Version.RepositoryName, shortSHA(release.Version.SHA)))
+	}
+	if release.RolledBack {
+		line += " **ROLLED BACK**"
+	}
+	return line
+}
+
+func colorizeDiff(diff string, empty string) string {
+	if diff == "" {
+		return color.New(color.FgHiBlack).Sprint(empty)
+	}
+
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			sb.WriteString(bold(line))
+		case strings.HasPrefix(line, "@@"):
+			sb.WriteString(cyan(line))
+		case strings.HasPrefix(line, "+"):
+			sb.WriteString(green(line))
+		case strings.HasPrefix(line, "-"):
+			sb.WriteString(red(line))
+		default:
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
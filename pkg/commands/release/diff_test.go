@@ -0,0 +1,45 @@
+package release
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"gotest.tools/assert"
+)
+
+func Test_renderReleaseDiff(t *testing.T) {
+	color.NoColor = true
+
+	rendered := renderReleaseDiff(&api.ReleaseDiff{
+		Env:       "production",
+		App:       "my-app",
+		From:      &dx.Release{GitopsRef: "aaaaaaaaaaaa", ArtifactID: "my-app-1"},
+		To:        &dx.Release{GitopsRef: "bbbbbbbbbbbb", ArtifactID: "my-app-2"},
+		Commits:   []*model.Commit{{SHA: "cccccccccccc", Message: "Fix the thing\n\nLonger description", Author: "laszlo"}},
+		Values:    "--- a/values.yaml\n+++ b/values.yaml\n@@ -1 +1 @@\n-replicas: 1\n+replicas: 2\n",
+		Manifests: "",
+	})
+
+	assert.Assert(t, strings.Contains(rendered, "from aaaaaaaa my-app-1"))
+	assert.Assert(t, strings.Contains(rendered, "to   bbbbbbbb my-app-2"))
+	assert.Assert(t, strings.Contains(rendered, "cccccccc Fix the thing"))
+	assert.Assert(t, !strings.Contains(rendered, "Longer description"), "only the commit title should be shown")
+	assert.Assert(t, strings.Contains(rendered, "+replicas: 2"))
+	assert.Assert(t, strings.Contains(rendered, "Rendered manifests\n  no changes"))
+
+	rendered = renderReleaseDiff(&api.ReleaseDiff{
+		Env:                "production",
+		App:                "my-app",
+		From:               &dx.Release{GitopsRef: "aaaaaaaaaaaa", ArtifactID: "my-app-1"},
+		To:                 &dx.Release{GitopsRef: "bbbbbbbbbbbb", ArtifactID: "my-app-2"},
+		CommitsUnavailable: "cannot find commit cccccccc",
+		ValuesUnavailable:  "cannot find artifact my-app-1",
+	})
+
+	assert.Assert(t, strings.Contains(rendered, "Source commits\n  unavailable: cannot find commit cccccccc"))
+	assert.Assert(t, strings.Contains(rendered, "Values\n  unavailable: cannot find artifact my-app-1"))
+}
@@ -0,0 +1,176 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enescakir/emoji"
+	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+)
+
+// follow prints the progress of a release as the dashboard streams it,
+// and returns an error if the release didn't succeed
+func follow(client client.Client, trackingID string, timeout time.Duration, jsonOutput bool) error {
+	progress := newReleaseProgress()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := client.WithContext(ctx).TrackReleaseFollow(trackingID, func(message []byte) {
+		lines := progress.handle(message)
+		if jsonOutput {
+			fmt.Println(string(message))
+			return
+		}
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("process timed out")
+	} else if err != nil {
+		return err
+	}
+
+	return progress.result()
+}
+
+// releaseProgress turns the streamed events of a release into the lines that changed since the last event
+type releaseProgress struct {
+	status *dx.ReleaseStatus
+	// printed holds the last line printed about a result, gitops commit, kustomization or pod
+	printed map[string]string
+}
+
+func newReleaseProgress() *releaseProgress {
+	return &releaseProgress{printed: map[string]string{}}
+}
+
+func (p *releaseProgress) handle(message []byte) []string {
+	var event streaming.StreamingEvent
+	err := json.Unmarshal(message, &event)
+	if err != nil {
+		return nil
+	}
+
+	var lines []string
+	changed := func(key string, line string) {
+		if p.printed[key] != line {
+			p.printed[key] = line
+			lines = append(lines, line)
+		}
+	}
+
+	switch {
+	case event.Event == streaming.ReleaseStatusEventString:
+		var statusEvent streaming.ReleaseStatusEvent
+		if json.Unmarshal(message, &statusEvent) != nil || statusEvent.ReleaseStatus == nil {
+			return nil
+		}
+		p.status = statusEvent.ReleaseStatus
+
+		switch p.status.Status {
+		case model.StatusNew:
+			changed("request", fmt.Sprintf("%v The release is not processed yet...", emoji.HourglassNotDone))
+		case model.StatusError:
+			changed("request", fmt.Sprintf("%v The release failed: %s", emoji.ExclamationMark, p.status.StatusDesc))
+		default:
+			changed("request", fmt.Sprintf("%v The release is processed", emoji.BackhandIndexPointingRight))
+			if len(p.status.Results) == 0 {
+				changed("results", fmt.Sprintf("\t%v The release didn't generate any gitops commits", emoji.Bookmark))
+			}
+		}
+		for _, result := range p.status.Results {
+			changed("result/"+result.Env+"/"+result.App, resultLine(result))
+		}
+	case event.Event == streaming.GitopsCommitEventString:
+		var gitopsEvent struct {
+			GitopsCommit model.GitopsCommit `json:"gitopsCommit"`
+		}
+		if json.Unmarshal(message, &gitopsEvent) != nil {
+			return nil
+		}
+		commit := gitopsEvent.GitopsCommit
+		changed("commit/"+commit.Sha, fmt.Sprintf("\t%v gitops commit %s %s %s", emoji.Package, shortSHA(commit.Sha), commit.Status, commit.StatusDesc))
+	case event.Event == streaming.FluxStateUpdatedEventString:
+		var fluxStateEvent streaming.FluxStateUpdatedEvent
+		if json.Unmarshal(message, &fluxStateEvent) != nil || fluxStateEvent.FluxState == nil {
+			return nil
+		}
+		for _, k := range fluxStateEvent.FluxState.Kustomizations {
+			if !p.mentionsGitopsCommit(k.StatusDesc) {
+				continue
+			}
+			changed("kustomization/"+k.Namespace+"/"+k.Name, fmt.Sprintf("\t%v kustomization %s/%s %s %s", emoji.Gear, k.Namespace, k.Name, k.Status, k.StatusDesc))
+		}
+	case strings.HasPrefix(event.Event, "pod"):
+		var update api.StackUpdate
+		if json.Unmarshal(message, &update) != nil {
+			return nil
+		}
+		line := fmt.Sprintf("\t%v pod %s %s", emoji.Rocket, update.Subject, update.Status)
+		if update.Event == "podDeleted" {
+			line = fmt.Sprintf("\t%v pod %s deleted", emoji.Rocket, update.Subject)
+		} else if update.ErrorCause != "" {
+			line = fmt.Sprintf("%s: %s", line, update.ErrorCause)
+		}
+		changed("pod/"+update.Subject, line)
+	case strings.HasPrefix(event.Event, "deployment"):
+		var update api.StackUpdate
+		if json.Unmarshal(message, &update) != nil || update.SHA == "" {
+			return nil
+		}
+		changed("deployment/"+update.Subject, fmt.Sprintf("\t%v deployment %s rolling out %s", emoji.Rocket, update.Subject, shortSHA(update.SHA)))
+	}
+
+	return lines
+}
+
+func (p *releaseProgress) mentionsGitopsCommit(statusDesc string) bool {
+	if p.status == nil {
+		return false
+	}
+	for _, result := range p.status.Results {
+		if result.Hash != "" && strings.Contains(statusDesc, result.Hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// result returns an error if the release failed, or the stream ended before the release finished
+func (p *releaseProgress) result() error {
+	if p.status == nil {
+		return fmt.Errorf("the release status was not received")
+	}
+
+	switch p.status.Status {
+	case model.StatusError:
+		return errors.New(p.status.StatusDesc)
+	case model.StatusProcessed:
+		allGitopsCommitsApplied, gitopsCommitsHaveFailed := p.status.ExtractGitopsEndState()
+		if gitopsCommitsHaveFailed {
+			return fmt.Errorf("gitops commits have failed to apply")
+		} else if allGitopsCommitsApplied {
+			return nil
+		}
+	}
+	return fmt.Errorf("the stream ended before the release finished")
+}
+
+func resultLine(result dx.Result) string {
+	if result.Status == model.Failure.String() {
+		return fmt.Sprintf("\t%v %s -> %s, status is %s, %s", emoji.ExclamationMark, result.App, result.Env, result.Status, result.StatusDesc)
+	} else if strings.Contains(result.GitopsCommitStatus, "Failed") {
+		return fmt.Sprintf("\t%v %s -> %s, gitops hash: %s, status: %s, %s", emoji.ExclamationMark, result.App, result.Env, result.Hash, result.GitopsCommitStatus, result.GitopsCommitStatusDesc)
+	}
+	return fmt.Sprintf("\t%v %s -> %s, gitops hash %s, status is %s", emoji.OpenBook, result.App, result.Env, result.Hash, result.GitopsCommitStatus)
+}
@@ -0,0 +1,58 @@
+package release
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"gotest.tools/assert"
+)
+
+func statusMessage(status *dx.ReleaseStatus) []byte {
+	b, _ := json.Marshal(streaming.ReleaseStatusEvent{
+		StreamingEvent: streaming.StreamingEvent{Event: streaming.ReleaseStatusEventString},
+		ReleaseStatus:  status,
+	})
+	return b
+}
+
+func Test_releaseProgress(t *testing.T) {
+	progress := newReleaseProgress()
+	assert.ErrorContains(t, progress.result(), "not received")
+
+	lines := progress.handle(statusMessage(&dx.ReleaseStatus{Status: model.StatusNew}))
+	assert.Equal(t, len(lines), 1)
+	lines = progress.handle(statusMessage(&dx.ReleaseStatus{Status: model.StatusNew}))
+	assert.Equal(t, len(lines), 0, "unchanged status should not be printed again")
+
+	processing := &dx.ReleaseStatus{
+		Status:  model.StatusProcessed,
+		Results: []dx.Result{{App: "myapp", Env: "staging", Hash: "abc123", GitopsCommitStatus: dx.NotReconciled}},
+	}
+	lines = progress.handle(statusMessage(processing))
+	assert.Equal(t, len(lines), 2)
+	assert.ErrorContains(t, progress.result(), "stream ended")
+
+	podMessage, _ := json.Marshal(api.StackUpdate{Event: "podUpdated", Subject: "default/myapp-xyz", Status: "Running"})
+	lines = progress.handle(podMessage)
+	assert.Equal(t, len(lines), 1)
+	lines = progress.handle(podMessage)
+	assert.Equal(t, len(lines), 0)
+
+	processing.Results[0].GitopsCommitStatus = dx.ReconciliationSucceeded
+	lines = progress.handle(statusMessage(processing))
+	assert.Equal(t, len(lines), 1, "only the changed result should be printed")
+	assert.NilError(t, progress.result())
+
+	processing.Results[0].GitopsCommitStatus = dx.ReconciliationFailed
+	progress.handle(statusMessage(processing))
+	assert.ErrorContains(t, progress.result(), "failed to apply")
+
+	progress.handle(statusMessage(&dx.ReleaseStatus{Status: model.StatusError, StatusDesc: "disk 100% full"}))
+	err := progress.result()
+	assert.Assert(t, err != nil)
+	assert.Equal(t, err.Error(), "disk 100% full")
+}
@@ -7,12 +7,11 @@ import (
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
 	"github.com/gimlet-io/gimlet-cli/pkg/commands/artifact"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 	"github.com/rvflash/elapsed"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/oauth2"
 )
 
 var releaseListCmd = cli.Command{
@@ -23,19 +22,7 @@ var releaseListCmd = cli.Command{
      --env staging \
      --server http://gimlet.mycompany.com
      --token c012367f6e6f71de17ae4c6a7baac2e9`,
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name:     "server",
-			Usage:    "Gimlet server URL, GIMLET_SERVER environment variable alternatively",
-			EnvVars:  []string{"GIMLET_SERVER"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "token",
-			Usage:    "Gimlet server api token, GIMLET_TOKEN environment variable alternatively",
-			EnvVars:  []string{"GIMLET_TOKEN"},
-			Required: true,
-		},
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:  "app",
 			Usage: "filter releases to an application",
@@ -72,26 +59,17 @@ var releaseListCmd = cli.Command{
 			Name:  "repo",
 			Usage: "filter envs to a source code git repository eg.: laszlocph/myapp",
 		},
-	},
+	}, commands.ServerFlags...),
 	Action: list,
 }
 
 func list(c *cli.Context) error {
-	serverURL := c.String("server")
-	token := c.String("token")
-
-	config := new(oauth2.Config)
-	auth := config.Client(
-		oauth2.NoContext,
-		&oauth2.Token{
-			AccessToken: token,
-		},
-	)
-
-	client := client.NewClient(serverURL, auth)
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
 
 	var since, until *time.Time
-	var err error
 	if c.String("since") != "" {
 		t, err := time.Parse(time.RFC3339, c.String("since"))
 		if err != nil {
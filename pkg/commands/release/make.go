@@ -2,7 +2,6 @@ package release
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -10,9 +9,8 @@ import (
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 
 	"github.com/enescakir/emoji"
-	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/oauth2"
 )
 
 var releaseMakeCmd = cli.Command{
@@ -23,19 +21,7 @@ var releaseMakeCmd = cli.Command{
      --artifact an-artifact-id \
      --server http://gimlet.mycompany.com
      --token c012367f6e6f71de17ae4c6a7baac2e9`,
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name:     "server",
-			Usage:    "Gimlet server URL, GIMLET_SERVER environment variable alternatively",
-			EnvVars:  []string{"GIMLET_SERVER"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "token",
-			Usage:    "Gimlet server api token, GIMLET_TOKEN environment variable alternatively",
-			EnvVars:  []string{"GIMLET_TOKEN"},
-			Required: true,
-		},
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:     "env",
 			Usage:    "make a release to this environment",
@@ -56,23 +42,15 @@ var releaseMakeCmd = cli.Command{
 			Aliases: []string{"o"},
 			Usage:   "Format the output as json with the \"-o json\" switch",
 		},
-	},
+	}, commands.ServerFlags...),
 	Action: make,
 }
 
 func make(c *cli.Context) error {
-	serverURL := c.String("server")
-	token := c.String("token")
-
-	config := new(oauth2.Config)
-	auth := config.Client(
-		context.Background(),
-		&oauth2.Token{
-			AccessToken: token,
-		},
-	)
-
-	client := client.NewClient(serverURL, auth)
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
 	trackingID, err := client.ReleasesPost(
 		dx.ReleaseRequest{
 			Env:        c.String("env"),
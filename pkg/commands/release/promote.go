@@ -0,0 +1,232 @@
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/enescakir/emoji"
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/urfave/cli/v2"
+)
+
+var releasePromoteCmd = cli.Command{
+	Name:  "promote",
+	Usage: "Releases the artifacts deployed in one environment to another",
+	UsageText: `gimlet release promote \
+     --from staging \
+     --to production \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "the environment to take the deployed artifacts from",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "to",
+			Usage:    "the environment to release the artifacts to",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "app",
+			Usage: "promote only a specific app",
+		},
+		&cli.BoolFlag{
+			Name:  "atomic",
+			Usage: "write all apps in a single gitops commit, so either all or none of them are released",
+		},
+		&cli.BoolFlag{
+			Name:    "yes",
+			Aliases: []string{"y"},
+			Usage:   "don't ask for confirmation, for use in CI",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Format the output as json with the \"-o json\" switch",
+		},
+	}, commands.ServerFlags...),
+	Action: promote,
+}
+
+// promotion is an app whose artifact is released from the source env to the target env
+type promotion struct {
+	App        string      `json:"app"`
+	ArtifactID string      `json:"artifactId"`
+	Version    *dx.Version `json:"version,omitempty"`
+	Current    string      `json:"current,omitempty"`
+}
+
+func promote(c *cli.Context) error {
+	from := c.String("from")
+	to := c.String("to")
+
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	source, err := client.StatusGet(c.String("app"), from)
+	if err != nil {
+		return fmt.Errorf("cannot get the releases of %s: %s", from, err)
+	}
+	target, err := client.StatusGet(c.String("app"), to)
+	if err != nil {
+		return fmt.Errorf("cannot get the releases of %s: %s", to, err)
+	}
+
+	plan := promotionPlan(source, target, c.String("app"))
+	if len(plan) == 0 {
+		fmt.Fprintf(os.Stderr, "%v %s is up to date with %s, nothing to promote\n", emoji.CheckMark, to, from)
+		return nil
+	}
+
+	fmt.Fprint(os.Stderr, renderPlan(plan, from, to))
+	if !c.Bool("yes") {
+		ok, err := confirm(os.Stdin, "Do you want to perform the promotion?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("promotion cancelled")
+		}
+	}
+
+	var trackingIDs []string
+	if c.Bool("atomic") {
+		request := dx.PromotionRequest{Env: to}
+		for _, p := range plan {
+			request.Releases = append(request.Releases, dx.ReleaseRequest{App: p.App, ArtifactID: p.ArtifactID})
+		}
+		trackingID, err := client.PromotionPost(request)
+		if err != nil {
+			return err
+		}
+		trackingIDs = append(trackingIDs, trackingID)
+	} else {
+		for _, p := range plan {
+			trackingID, err := client.ReleasesPost(dx.ReleaseRequest{
+				Env:        to,
+				App:        p.App,
+				ArtifactID: p.ArtifactID,
+			})
+			if err != nil {
+				return fmt.Errorf("cannot release %s: %s", p.App, err)
+			}
+			trackingIDs = append(trackingIDs, trackingID)
+		}
+	}
+
+	if c.String("output") == "json" {
+		jsonString := bytes.NewBufferString("")
+		e := json.NewEncoder(jsonString)
+		e.SetIndent("", "  ")
+		err = e.Encode(map[string]interface{}{
+			"ids":        trackingIDs,
+			"promotions": plan,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot deserialize json %s", err)
+		}
+		fmt.Println(jsonString)
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "%v Promotion is now added to the release queue\n", emoji.WomanGesturingOk)
+	fmt.Fprintf(os.Stderr, "Track it with:\n")
+	for _, trackingID := range trackingIDs {
+		fmt.Fprintf(os.Stderr, "gimlet release track %s\n", trackingID)
+	}
+	fmt.Fprintln(os.Stderr)
+
+	return nil
+}
+
+// promotionPlan lists the apps whose artifact deployed in the source env differs from the target env's
+func promotionPlan(source, target map[string]*dx.Release, app string) []promotion {
+	var apps []string
+	for a := range source {
+		apps = append(apps, a)
+	}
+	sort.Strings(apps)
+
+	plan := []promotion{}
+	for _, a := range apps {
+		if app != "" && a != app {
+			continue
+		}
+		release := source[a]
+		if release == nil || release.ArtifactID == "" {
+			continue
+		}
+
+		current := ""
+		if t, ok := target[a]; ok && t != nil {
+			current = t.ArtifactID
+		}
+		if current == release.ArtifactID {
+			continue
+		}
+
+		plan = append(plan, promotion{
+			App:        a,
+			ArtifactID: release.ArtifactID,
+			Version:    release.Version,
+			Current:    current,
+		})
+	}
+
+	return plan
+}
+
+func renderPlan(plan []promotion, from, to string) string {
+	bold := color.New(color.Bold).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Promoting from %s to %s:\n\n", bold(from), bold(to)))
+	for _, p := range plan {
+		current := p.Current
+		if current == "" {
+			current = "not deployed"
+		}
+		sb.WriteString(fmt.Sprintf("  %s %s -> %s\n", bold(p.App), gray(current), green(p.ArtifactID)))
+		if p.Version != nil {
+			sb.WriteString(gray(fmt.Sprintf("    %s %s\n", shortSHA(p.Version.SHA), firstLine(p.Version.Message))))
+		}
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+func confirm(in io.Reader, question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+func firstLine(message string) string {
+	return strings.SplitN(message, "\n", 2)[0]
+}
@@ -0,0 +1,44 @@
+package release
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"gotest.tools/assert"
+)
+
+func Test_promotionPlan(t *testing.T) {
+	source := map[string]*dx.Release{
+		"app1": {App: "app1", ArtifactID: "artifact-2"},
+		"app2": {App: "app2", ArtifactID: "artifact-3"},
+		"app3": {App: "app3", ArtifactID: "artifact-4"},
+		"app4": nil,
+	}
+	target := map[string]*dx.Release{
+		"app1": {App: "app1", ArtifactID: "artifact-1"},
+		"app2": {App: "app2", ArtifactID: "artifact-3"},
+	}
+
+	plan := promotionPlan(source, target, "")
+	assert.Equal(t, len(plan), 2)
+	assert.Equal(t, plan[0].App, "app1")
+	assert.Equal(t, plan[0].ArtifactID, "artifact-2")
+	assert.Equal(t, plan[0].Current, "artifact-1")
+	assert.Equal(t, plan[1].App, "app3")
+	assert.Equal(t, plan[1].Current, "")
+
+	plan = promotionPlan(source, target, "app3")
+	assert.Equal(t, len(plan), 1)
+	assert.Equal(t, plan[0].App, "app3")
+}
+
+func Test_confirm(t *testing.T) {
+	ok, err := confirm(strings.NewReader("y\n"), "Promote?")
+	assert.NilError(t, err)
+	assert.Equal(t, ok, true)
+
+	ok, err = confirm(strings.NewReader(""), "Promote?")
+	assert.NilError(t, err)
+	assert.Equal(t, ok, false)
+}
@@ -8,8 +8,10 @@ var Command = cli.Command{
 	Subcommands: []*cli.Command{
 		&releaseListCmd,
 		&releaseMakeCmd,
+		&releasePromoteCmd,
 		&releaseRollbackCmd,
 		&releaseTrackCmd,
+		&releaseDiffCmd,
 		&releaseStatusCmd,
 		&releaseDeleteCmd,
 	},
@@ -1,14 +1,12 @@
 package release
 
 import (
-	"context"
 	"fmt"
 	"os"
 
 	"github.com/enescakir/emoji"
-	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/oauth2"
 )
 
 var releaseRollbackCmd = cli.Command{
@@ -20,19 +18,7 @@ var releaseRollbackCmd = cli.Command{
      --to a-release-sha \
      --server http://gimlet.mycompany.com
      --token c012367f6e6f71de17ae4c6a7baac2e9`,
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name:     "server",
-			Usage:    "Gimlet server URL, GIMLET_SERVER environment variable alternatively",
-			EnvVars:  []string{"GIMLET_SERVER"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "token",
-			Usage:    "Gimlet server api token, GIMLET_TOKEN environment variable alternatively",
-			EnvVars:  []string{"GIMLET_TOKEN"},
-			Required: true,
-		},
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:     "env",
 			Usage:    "rollback in this environment",
@@ -49,23 +35,15 @@ var releaseRollbackCmd = cli.Command{
 			Aliases:  []string{"t"},
 			Required: true,
 		},
-	},
+	}, commands.ServerFlags...),
 	Action: rollback,
 }
 
 func rollback(c *cli.Context) error {
-	serverURL := c.String("server")
-	token := c.String("token")
-
-	config := new(oauth2.Config)
-	auth := config.Client(
-		context.Background(),
-		&oauth2.Token{
-			AccessToken: token,
-		},
-	)
-
-	client := client.NewClient(serverURL, auth)
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
 	trackingID, err := client.RollbackPost(
 		c.String("env"),
 		c.String("app"),
@@ -5,14 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
 	"github.com/gimlet-io/gimlet-cli/pkg/commands/artifact"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/rvflash/elapsed"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/oauth2"
 )
 
 var releaseStatusCmd = cli.Command{
@@ -22,19 +23,7 @@ var releaseStatusCmd = cli.Command{
      --env staging \
      --server http://gimlet.mycompany.com
      --token c012367f6e6f71de17ae4c6a7baac2e9`,
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name:     "server",
-			Usage:    "Gimlet server URL, GIMLET_SERVER environment variable alternatively",
-			EnvVars:  []string{"GIMLET_SERVER"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "token",
-			Usage:    "Gimlet server api token, GIMLET_TOKEN environment variable alternatively",
-			EnvVars:  []string{"GIMLET_TOKEN"},
-			Required: true,
-		},
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:  "app",
 			Usage: "filter releases to an application",
@@ -49,25 +38,15 @@ var releaseStatusCmd = cli.Command{
 			Aliases: []string{"o"},
 			Usage:   "output format, eg.: json",
 		},
-	},
+	}, commands.ServerFlags...),
 	Action: status,
 }
 
 func status(c *cli.Context) error {
-	serverURL := c.String("server")
-	token := c.String("token")
-
-	config := new(oauth2.Config)
-	auth := config.Client(
-		oauth2.NoContext,
-		&oauth2.Token{
-			AccessToken: token,
-		},
-	)
-
-	client := client.NewClient(serverURL, auth)
-
-	var err error
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
 
 	appReleases, err := client.StatusGet(
 		c.String("app"),
@@ -77,6 +56,11 @@ func status(c *cli.Context) error {
 		return err
 	}
 
+	usage, err := client.UsageGet(c.String("app"), c.String("env"))
+	if err != nil {
+		usage = map[string][]*api.Pod{} // the agent of the env may be disconnected
+	}
+
 	if c.String("output") == "json" {
 		appReleasesString := bytes.NewBufferString("")
 		e := json.NewEncoder(appReleasesString)
@@ -120,6 +104,7 @@ func status(c *cli.Context) error {
 			} else {
 				fmt.Println(gray("Release data not available"))
 			}
+			fmt.Print(renderUsage(usage[app], "  "))
 
 			fmt.Println()
 		}
@@ -127,3 +112,52 @@ func status(c *cli.Context) error {
 
 	return nil
 }
+
+// renderUsage prints the CPU and memory usage of pods next to their limits,
+// highlighting the pods that have a container close to its limit
+func renderUsage(pods []*api.Pod, padding string) string {
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed, color.Bold).SprintFunc()
+
+	highlight := func(utilization int, text string) string {
+		if utilization >= 90 {
+			return red(text)
+		} else if utilization >= 75 {
+			return yellow(text)
+		}
+		return text
+	}
+
+	var sb strings.Builder
+	for _, pod := range pods {
+		var cpuUsage, cpuLimit, memoryUsage, memoryLimit int64
+		for _, c := range pod.Containers {
+			cpuUsage += c.CPUUsage
+			cpuLimit += c.CPULimit
+			memoryUsage += c.MemoryUsage
+			memoryLimit += c.MemoryLimit
+		}
+		if cpuUsage == 0 && memoryUsage == 0 {
+			continue
+		}
+
+		cpu := fmt.Sprintf("cpu %dm", cpuUsage)
+		if cpuLimit != 0 {
+			cpu = fmt.Sprintf("%s/%dm (%d%%)", cpu, cpuLimit, cpuUsage*100/cpuLimit)
+		}
+		memory := fmt.Sprintf("memory %dMi", memoryUsage/1024/1024)
+		if memoryLimit != 0 {
+			memory = fmt.Sprintf("%s/%dMi (%d%%)", memory, memoryLimit/1024/1024, memoryUsage*100/memoryLimit)
+		}
+
+		sb.WriteString(fmt.Sprintf("%s%s %s %s\n",
+			padding,
+			gray(pod.Name),
+			highlight(pod.CPUUtilization(), cpu),
+			highlight(pod.MemoryUtilization(), memory),
+		))
+	}
+
+	return sb.String()
+}
@@ -8,11 +8,10 @@ import (
 	"time"
 
 	"github.com/enescakir/emoji"
-	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/oauth2"
 )
 
 var releaseTrackCmd = cli.Command{
@@ -21,19 +20,7 @@ var releaseTrackCmd = cli.Command{
 	UsageText: `gimlet release track <id>
      --server http://gimlet.mycompany.com
      --token c012367f6e6f71de17ae4c6a7baac2e9`,
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name:     "server",
-			Usage:    "Gimlet server URL, GIMLET_SERVER environment variable alternatively",
-			EnvVars:  []string{"GIMLET_SERVER"},
-			Required: true,
-		},
-		&cli.StringFlag{
-			Name:     "token",
-			Usage:    "Gimlet server api token, GIMLET_TOKEN environment variable alternatively",
-			EnvVars:  []string{"GIMLET_TOKEN"},
-			Required: true,
-		},
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{
 			Name:    "output",
 			Aliases: []string{"o"},
@@ -44,19 +31,22 @@ var releaseTrackCmd = cli.Command{
 			Aliases: []string{"w"},
 			Usage:   "Wait until the artifact is processed",
 		},
+		&cli.BoolFlag{
+			Name:    "follow",
+			Aliases: []string{"f"},
+			Usage:   "Stream the gitops commit, Flux reconciliation and pod rollout progress until the release finishes",
+		},
 		&cli.StringFlag{
 			Name:    "timeout",
 			Aliases: []string{"t"},
-			Usage:   "If you specified the wait flag, the wait will time out by this specified value. The default is 10m (minutes)",
+			Usage:   "If you specified the wait or follow flag, the wait will time out by this specified value. The default is 10m (minutes)",
 			Value:   "10m",
 		},
-	},
+	}, commands.ServerFlags...),
 	Action: track,
 }
 
 func track(c *cli.Context) error {
-	serverURL := c.String("server")
-	token := c.String("token")
 	output := c.String("output")
 	wait := c.Bool("wait")
 	timeoutString := c.String("timeout")
@@ -68,17 +58,16 @@ func track(c *cli.Context) error {
 	}
 	timeoutTime = &t
 
-	config := new(oauth2.Config)
-	auth := config.Client(
-		oauth2.NoContext,
-		&oauth2.Token{
-			AccessToken: token,
-		},
-	)
-
 	trackingID := c.Args().First()
 
-	client := client.NewClient(serverURL, auth)
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("follow") {
+		return follow(client, trackingID, *timeoutTime, output == "json")
+	}
 
 	if output == "json" {
 		releaseStatus, err := client.TrackRelease(trackingID)
@@ -156,5 +145,8 @@ func printGitopsStatuses(releaseStatus *dx.ReleaseStatus) {
 		} else {
 			fmt.Printf("\t%v %s -> %s, gitops hash %s, status is %s\n", emoji.OpenBook, result.App, result.Env, result.Hash, result.GitopsCommitStatus)
 		}
+		if result.Signature != "" {
+			fmt.Printf("\t\t%v signature %s\n", emoji.Locked, result.Signature)
+		}
 	}
 }
@@ -0,0 +1,459 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"atomicgo.dev/keyboard"
+	"atomicgo.dev/keyboard/keys"
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/client"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/pterm/pterm"
+	"github.com/rvflash/elapsed"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	optionRefresh  = "Refresh"
+	optionQuit     = "Quit"
+	optionBack     = "Back"
+	optionHistory  = "Release history"
+	optionRelease  = "Release an artifact"
+	optionRollback = "Rollback"
+	optionLogs     = "Tail logs"
+	optionDelete   = "Delete"
+)
+
+var Command = cli.Command{
+	Name:  "ui",
+	Usage: "Browses environments and releases in an interactive terminal UI",
+	UsageText: `gimlet ui \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9
+
+   Use the arrow keys to move, type to filter, Enter to select and Ctrl+C to quit.`,
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:  "env",
+			Usage: "show only this environment",
+		},
+	}, commands.ServerFlags...),
+	Action: ui,
+}
+
+func ui(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	for {
+		envs, statuses, err := overview(client, c.String("env"))
+		if err != nil {
+			return err
+		}
+		pterm.Println(renderOverview(envs, statuses))
+
+		options := []string{}
+		for _, env := range envs {
+			for _, app := range sortedApps(statuses[env]) {
+				options = append(options, env+"/"+app)
+			}
+		}
+		options = append(options, optionRefresh, optionQuit)
+
+		selected, err := pterm.DefaultInteractiveSelect.
+			WithOptions(options).
+			WithMaxHeight(15).
+			Show("Pick an app")
+		if err != nil {
+			return err
+		}
+
+		switch selected {
+		case optionQuit:
+			return nil
+		case optionRefresh:
+			continue
+		}
+
+		envAndApp := strings.SplitN(selected, "/", 2)
+		err = appScreen(client, envAndApp[0], envAndApp[1])
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// overview returns the environments and the current release of their apps
+func overview(client client.Client, envFilter string) ([]string, map[string]map[string]*dx.Release, error) {
+	gitopsEnvs, err := client.EnvsGet()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	envs := []string{}
+	statuses := map[string]map[string]*dx.Release{}
+	for _, env := range gitopsEnvs {
+		if envFilter != "" && env.Name != envFilter {
+			continue
+		}
+		status, err := client.StatusGet("", env.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		envs = append(envs, env.Name)
+		statuses[env.Name] = status
+	}
+	sort.Strings(envs)
+
+	return envs, statuses, nil
+}
+
+func appScreen(client client.Client, env string, app string) error {
+	for {
+		status, err := client.StatusGet(app, env)
+		if err != nil {
+			return err
+		}
+		current := status[app]
+		pterm.DefaultSection.Println(fmt.Sprintf("%s -> %s", app, env))
+		pterm.Println(releaseLine(current))
+
+		selected, err := pterm.DefaultInteractiveSelect.
+			WithOptions([]string{optionHistory, optionRelease, optionRollback, optionLogs, optionDelete, optionBack}).
+			Show("What do you want to do")
+		if err != nil {
+			return err
+		}
+
+		switch selected {
+		case optionBack:
+			return nil
+		case optionHistory:
+			err = history(client, env, app)
+		case optionRelease:
+			err = release(client, env, app, current)
+		case optionRollback:
+			err = rollback(client, env, app)
+		case optionLogs:
+			err = tailLogs(client, env, app)
+		case optionDelete:
+			var deleted bool
+			deleted, err = deleteApp(client, env, app)
+			if err == nil && deleted {
+				return nil
+			}
+		}
+		if err != nil {
+			pterm.Error.Println(err)
+		}
+	}
+}
+
+func history(client client.Client, env string, app string) error {
+	releases, err := client.ReleasesGet(app, env, 10, 0, "", nil, nil)
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		pterm.Info.Println("No releases found")
+		return nil
+	}
+
+	for _, release := range releases {
+		pterm.Println(releaseLine(release))
+	}
+	return nil
+}
+
+func release(client client.Client, env string, app string, current *dx.Release) error {
+	artifactID := ""
+	if current != nil && current.Version != nil {
+		artifacts, err := client.ArtifactsGet(current.Version.RepositoryName, "", nil, "", nil, 10, 0, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		options := []string{}
+		ids := map[string]string{}
+		for _, artifact := range artifacts {
+			if !deploysTo(artifact, env, app) {
+				continue
+			}
+			option := artifactOption(artifact)
+			options = append(options, option)
+			ids[option] = artifact.ID
+		}
+		if len(options) > 0 {
+			options = append(options, optionBack)
+			selected, err := pterm.DefaultInteractiveSelect.
+				WithOptions(options).
+				Show("Pick the artifact to release")
+			if err != nil {
+				return err
+			}
+			if selected == optionBack {
+				return nil
+			}
+			artifactID = ids[selected]
+		}
+	}
+
+	if artifactID == "" {
+		var err error
+		artifactID, err = pterm.DefaultInteractiveTextInput.Show("Artifact ID to release")
+		if err != nil {
+			return err
+		}
+		if artifactID == "" {
+			return nil
+		}
+	}
+
+	confirmed, err := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("Release %s to %s?", app, env))
+	if err != nil || !confirmed {
+		return err
+	}
+
+	trackingID, err := client.ReleasesPost(dx.ReleaseRequest{
+		Env:        env,
+		App:        app,
+		ArtifactID: artifactID,
+	})
+	if err != nil {
+		return err
+	}
+	pterm.Success.Printf("Release is now added to the release queue with ID %s\n", trackingID)
+	return nil
+}
+
+func rollback(client client.Client, env string, app string) error {
+	releases, err := client.ReleasesGet(app, env, 10, 0, "", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	options := []string{}
+	refs := map[string]string{}
+	for i, release := range releases {
+		if i == 0 || release.RolledBack { // the current release, and the ones already rolled back
+			continue
+		}
+		option := releaseOption(release)
+		options = append(options, option)
+		refs[option] = release.GitopsRef
+	}
+	if len(options) == 0 {
+		pterm.Info.Println("No earlier release to roll back to")
+		return nil
+	}
+	options = append(options, optionBack)
+
+	selected, err := pterm.DefaultInteractiveSelect.
+		WithOptions(options).
+		Show("Pick the release to roll back to")
+	if err != nil || selected == optionBack {
+		return err
+	}
+
+	confirmed, err := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("Roll back %s in %s to %s?", app, env, shortSHA(refs[selected])))
+	if err != nil || !confirmed {
+		return err
+	}
+
+	trackingID, err := client.RollbackPost(env, app, refs[selected])
+	if err != nil {
+		return err
+	}
+	pterm.Success.Printf("Rollback is now added to the release queue with ID %s\n", trackingID)
+	return nil
+}
+
+func deleteApp(client client.Client, env string, app string) (bool, error) {
+	confirmed, err := pterm.DefaultInteractiveConfirm.
+		WithDefaultValue(false).
+		Show(fmt.Sprintf("Delete %s from %s? This removes it from the gitops repository", app, env))
+	if err != nil || !confirmed {
+		return false, err
+	}
+
+	err = client.DeletePost(env, app)
+	if err != nil {
+		return false, err
+	}
+	pterm.Success.Printf("%s is deleted from %s\n", app, env)
+	return true, nil
+}
+
+var prefixColors = []color.Attribute{color.FgCyan, color.FgMagenta, color.FgYellow, color.FgGreen, color.FgBlue}
+
+// tailLogs follows the pod logs of an app until q or Escape is pressed, or the stream ends
+func tailLogs(client client.Client, env string, app string) error {
+	pterm.Info.Printf("Tailing the logs of %s in %s, press q to stop\n", app, env)
+
+	var mu sync.Mutex
+	stopped := false
+	ended := false
+	colors := map[string]*color.Color{}
+	done := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		done <- client.WithContext(ctx).PodLogsFollow(env, app, func(line *streaming.PodLogsEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			if stopped {
+				return
+			}
+
+			prefix := line.PodName + "/" + line.Container
+			if _, ok := colors[prefix]; !ok {
+				colors[prefix] = color.New(prefixColors[len(colors)%len(prefixColors)])
+			}
+			// the terminal is in raw mode while listening to the keyboard
+			fmt.Printf("%s %s\r\n", colors[prefix].Sprint(prefix), strings.TrimRight(line.Message, "\n"))
+		})
+
+		// the stream ended, stopping the keyboard listener
+		mu.Lock()
+		defer mu.Unlock()
+		ended = true
+		if !stopped {
+			go keyboard.SimulateKeyPress(keys.Escape)
+		}
+	}()
+
+	err := keyboard.Listen(func(key keys.Key) (stop bool, err error) {
+		switch key.Code {
+		case keys.CtrlC, keys.Escape:
+			return true, nil
+		case keys.RuneKey:
+			return key.String() == "q", nil
+		}
+		return false, nil
+	})
+
+	mu.Lock()
+	stopped = true
+	streamEnded := ended
+	mu.Unlock()
+	cancel()
+
+	if err != nil {
+		return err
+	}
+	streamErr := <-done
+	if !streamEnded {
+		// stopped by the user, the stream error is the cancellation
+		return nil
+	}
+	return streamErr
+}
+
+func renderOverview(envs []string, statuses map[string]map[string]*dx.Release) string {
+	bold := color.New(color.Bold).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	var sb strings.Builder
+	for _, env := range envs {
+		sb.WriteString(bold(env) + "\n")
+		apps := sortedApps(statuses[env])
+		if len(apps) == 0 {
+			sb.WriteString(gray("  no apps\n"))
+		}
+		for _, app := range apps {
+			sb.WriteString(fmt.Sprintf("  %s %s\n", app, releaseLine(statuses[env][app])))
+		}
+	}
+	return sb.String()
+}
+
+func releaseLine(release *dx.Release) string {
+	gray := color.New(color.FgHiBlack).SprintFunc()
+	blue := color.New(color.FgBlue).SprintFunc()
+	red := color.New(color.FgRed, color.Bold).SprintFunc()
+
+	if release == nil {
+		return gray("release data not available")
+	}
+
+	line := blue(shortSHA(release.GitopsRef))
+	if release.Version != nil {
+		line += fmt.Sprintf(" %s@%s", release.Version.RepositoryName, shortSHA(release.Version.SHA))
+		if release.Version.Message != "" {
+			line += " " + firstLine(release.Version.Message)
+		}
+	}
+	if release.RolledBack {
+		line += " " + red("**ROLLED BACK**")
+	}
+	if release.Created != 0 {
+		line += " " + gray(fmt.Sprintf("(%s)", elapsed.Time(time.Unix(release.Created, 0))))
+	}
+	return line
+}
+
+func releaseOption(release *dx.Release) string {
+	option := shortSHA(release.GitopsRef)
+	if release.Version != nil {
+		option += fmt.Sprintf(" %s %s", shortSHA(release.Version.SHA), firstLine(release.Version.Message))
+	}
+	if release.Created != 0 {
+		option += fmt.Sprintf(" (%s)", elapsed.Time(time.Unix(release.Created, 0)))
+	}
+	return option
+}
+
+func artifactOption(artifact *dx.Artifact) string {
+	option := fmt.Sprintf("%s %s", shortSHA(artifact.Version.SHA), firstLine(artifact.Version.Message))
+	if artifact.Version.Branch != "" {
+		option += fmt.Sprintf(" [%s]", artifact.Version.Branch)
+	}
+	if artifact.Created != 0 {
+		option += fmt.Sprintf(" (%s)", elapsed.Time(time.Unix(artifact.Created, 0)))
+	}
+	return option
+}
+
+// deploysTo tells if the artifact has a manifest for the app in the env.
+// CUE environments are only known after rendering, so artifacts with them are kept
+func deploysTo(artifact *dx.Artifact, env string, app string) bool {
+	if len(artifact.CueEnvironments) > 0 {
+		return true
+	}
+	for _, manifest := range artifact.Environments {
+		if manifest.Env == env && manifest.App == app {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedApps(releases map[string]*dx.Release) []string {
+	apps := []string{}
+	for app := range releases {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+	return apps
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+func firstLine(message string) string {
+	return strings.Split(strings.TrimSpace(message), "\n")[0]
+}
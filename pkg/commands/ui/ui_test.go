@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"gotest.tools/assert"
+)
+
+func Test_renderOverview(t *testing.T) {
+	color.NoColor = true
+
+	rendered := renderOverview(
+		[]string{"production", "staging"},
+		map[string]map[string]*dx.Release{
+			"production": {},
+			"staging": {
+				"my-app": &dx.Release{
+					GitopsRef: "aaaaaaaaaaaa",
+					Version: &dx.Version{
+						RepositoryName: "gimlet-io/my-app",
+						SHA:            "bbbbbbbbbbbb",
+						Message:        "Fix the login\n\nDetails",
+					},
+					RolledBack: true,
+				},
+				"other-app": nil,
+			},
+		},
+	)
+
+	assert.Assert(t, strings.Contains(rendered, "production\n  no apps\n"), rendered)
+	assert.Assert(t, strings.Contains(rendered, "  my-app aaaaaaaa gimlet-io/my-app@bbbbbbbb Fix the login **ROLLED BACK**\n"), rendered)
+	assert.Assert(t, strings.Contains(rendered, "  other-app release data not available\n"), rendered)
+	assert.Assert(t, strings.Index(rendered, "  my-app") < strings.Index(rendered, "  other-app"), "apps should be sorted")
+}
+
+func Test_deploysTo(t *testing.T) {
+	artifact := &dx.Artifact{
+		Environments: []*dx.Manifest{
+			{Env: "staging", App: "my-app"},
+		},
+	}
+	assert.Assert(t, deploysTo(artifact, "staging", "my-app"))
+	assert.Assert(t, !deploysTo(artifact, "production", "my-app"))
+	assert.Assert(t, !deploysTo(artifact, "staging", "other-app"))
+
+	artifact.CueEnvironments = []string{"..."}
+	assert.Assert(t, deploysTo(artifact, "production", "my-app"), "artifacts with CUE environments should be kept")
+}
@@ -0,0 +1,62 @@
+package workload
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/rvflash/elapsed"
+	"github.com/urfave/cli/v2"
+)
+
+var historyCmd = cli.Command{
+	Name:  "history",
+	Usage: "Lists the latest workload actions with who triggered them and their outcome",
+	UsageText: `gimlet workload history \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags:  commands.ServerFlags,
+	Action: history,
+}
+
+func history(c *cli.Context) error {
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	actions, err := client.WorkloadActionsGet()
+	if err != nil {
+		return err
+	}
+
+	red := color.New(color.FgRed, color.Bold).SprintFunc()
+	yellow := color.New(color.FgYellow, color.Bold).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	gray := color.New(color.FgHiBlack).SprintFunc()
+
+	for _, action := range actions {
+		status := yellow(action.Status)
+		switch action.Status {
+		case model.WorkloadActionSucceeded:
+			status = green(action.Status)
+		case model.WorkloadActionFailed:
+			status = red(action.Status)
+		}
+
+		target := fmt.Sprintf("%s %s/%s", action.Kind, action.Namespace, action.Name)
+		if action.Action == model.WorkloadActionScale {
+			target = fmt.Sprintf("%s to %d", target, action.Replicas)
+		}
+
+		fmt.Printf("#%d %s %s %s on %s\n", action.ID, status, action.Action, target, action.Env)
+		fmt.Printf("   %s\n", gray(fmt.Sprintf("by %s %s", action.TriggeredBy, elapsed.Time(time.Unix(action.Created, 0)))))
+		if action.StatusDesc != "" {
+			fmt.Printf("   %s\n", action.StatusDesc)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package workload
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/enescakir/emoji"
+	"github.com/fatih/color"
+	"github.com/gimlet-io/gimlet-cli/pkg/commands"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/urfave/cli/v2"
+)
+
+var Command = cli.Command{
+	Name:  "workload",
+	Usage: "Restarts, scales workloads, deletes pods and suspends Flux reconciliation through the Gimlet agent",
+	Subcommands: []*cli.Command{
+		&restartCmd,
+		&scaleCmd,
+		&deletePodCmd,
+		&suspendCmd,
+		&resumeCmd,
+		&reconcileCmd,
+		&historyCmd,
+	},
+}
+
+var commonFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:     "env",
+		Usage:    "the environment of the workload",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:  "namespace",
+		Usage: "the namespace of the workload",
+		Value: "default",
+	},
+	&cli.StringFlag{
+		Name:     "name",
+		Usage:    "the name of the workload",
+		Required: true,
+	},
+	&cli.BoolFlag{
+		Name:  "no-wait",
+		Usage: "don't wait for the agent to report the outcome",
+	},
+}
+
+var kindFlag = &cli.StringFlag{
+	Name:  "kind",
+	Usage: "the kind of the workload: deployment, statefulset or daemonset",
+	Value: "deployment",
+}
+
+var restartCmd = cli.Command{
+	Name:  "restart",
+	Usage: "Performs a rolling restart of a deployment, statefulset or daemonset",
+	UsageText: `gimlet workload restart \
+     --env staging \
+     --namespace default \
+     --name my-app \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{kindFlag}, commonFlags...),
+	Action: func(c *cli.Context) error {
+		return perform(c, &model.WorkloadAction{
+			Action: model.WorkloadActionRestart,
+			Kind:   c.String("kind"),
+		})
+	},
+}
+
+var scaleCmd = cli.Command{
+	Name:  "scale",
+	Usage: "Sets the replica count of a deployment or statefulset",
+	UsageText: `gimlet workload scale \
+     --env staging \
+     --namespace default \
+     --name my-app \
+     --replicas 3 \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: append([]cli.Flag{
+		kindFlag,
+		&cli.IntFlag{
+			Name:     "replicas",
+			Usage:    "the desired replica count",
+			Required: true,
+		},
+	}, commonFlags...),
+	Action: func(c *cli.Context) error {
+		return perform(c, &model.WorkloadAction{
+			Action:   model.WorkloadActionScale,
+			Kind:     c.String("kind"),
+			Replicas: int32(c.Int("replicas")),
+		})
+	},
+}
+
+var deletePodCmd = cli.Command{
+	Name:  "delete-pod",
+	Usage: "Deletes a pod, so its controller replaces it",
+	UsageText: `gimlet workload delete-pod \
+     --env staging \
+     --namespace default \
+     --name my-app-5d8f9c7b4-x2x9z \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: commonFlags,
+	Action: func(c *cli.Context) error {
+		return perform(c, &model.WorkloadAction{
+			Action: model.WorkloadActionDeletePod,
+		})
+	},
+}
+
+var suspendCmd = cli.Command{
+	Name:  "suspend",
+	Usage: "Suspends the reconciliation of a Flux Kustomization",
+	UsageText: `gimlet workload suspend \
+     --env staging \
+     --namespace flux-system \
+     --name gitops-repo-staging \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: commonFlags,
+	Action: func(c *cli.Context) error {
+		return perform(c, &model.WorkloadAction{
+			Action: model.WorkloadActionSuspend,
+		})
+	},
+}
+
+var resumeCmd = cli.Command{
+	Name:  "resume",
+	Usage: "Resumes the reconciliation of a Flux Kustomization",
+	UsageText: `gimlet workload resume \
+     --env staging \
+     --namespace flux-system \
+     --name gitops-repo-staging \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: commonFlags,
+	Action: func(c *cli.Context) error {
+		return perform(c, &model.WorkloadAction{
+			Action: model.WorkloadActionResume,
+		})
+	},
+}
+
+var reconcileCmd = cli.Command{
+	Name:  "reconcile",
+	Usage: "Applies a Flux Kustomization right away, reverting drifts from the gitops repo",
+	UsageText: `gimlet workload reconcile \
+     --env staging \
+     --namespace flux-system \
+     --name gitops-repo-staging \
+     --server http://gimlet.mycompany.com
+     --token c012367f6e6f71de17ae4c6a7baac2e9`,
+	Flags: commonFlags,
+	Action: func(c *cli.Context) error {
+		return perform(c, &model.WorkloadAction{
+			Action: model.WorkloadActionReconcile,
+		})
+	},
+}
+
+func perform(c *cli.Context, action *model.WorkloadAction) error {
+	action.Env = c.String("env")
+	action.Namespace = c.String("namespace")
+	action.Name = c.String("name")
+
+	client, err := commands.NewClient(c)
+	if err != nil {
+		return err
+	}
+	action, err = client.WorkloadActionPost(action)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v %s of %s/%s sent to the %s agent (#%d)\n", emoji.WomanGesturingOk, action.Action, action.Namespace, action.Name, action.Env, action.ID)
+
+	if c.Bool("no-wait") {
+		return nil
+	}
+
+	for i := 0; i < 30; i++ {
+		action, err = client.WorkloadActionGet(action.ID)
+		if err != nil {
+			return err
+		}
+
+		switch action.Status {
+		case model.WorkloadActionSucceeded:
+			fmt.Fprintf(os.Stderr, "%v %s\n", emoji.CheckMark, color.New(color.FgGreen).Sprintf("%s of %s/%s succeeded", action.Action, action.Namespace, action.Name))
+			return nil
+		case model.WorkloadActionFailed:
+			return fmt.Errorf("%s of %s/%s failed: %s", action.Action, action.Namespace, action.Name, action.StatusDesc)
+		}
+
+		time.Sleep(time.Second)
+	}
+
+	return fmt.Errorf("the agent didn't report the outcome in time, check it later with `gimlet workload history`")
+}
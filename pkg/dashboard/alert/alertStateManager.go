@@ -3,6 +3,7 @@ package alert
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
@@ -12,19 +13,76 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	podAlert                 = "pod"
+	crashLoopBackOffAlert    = "crashLoopBackOff"
+	oomKilledAlert           = "oomKilled"
+	pendingPodAlert          = "pendingPod"
+	eventAlert               = "event"
+	readinessProbeAlert      = "readinessProbe"
+	driftAlert               = "drift"
+	certificateExpiryAlert   = "certificateExpiry"
+	certificateNotReadyAlert = "certificateNotReady"
+)
+
+// certificateExpiryWarning is how long before its expiry a certificate is alerted on
+const certificateExpiryWarning = 14 * 24 * time.Hour
+
+// crashLoopRecoveryWindow is how long a crash looping or OOMKilled pod has to stay Running
+// for its alert to resolve. Between restarts the pod is briefly Running
+const crashLoopRecoveryWindow = 10 * time.Minute
+
+// podAlertTypes are the alert types a pod failure is classified into, a pod has one of them open at a time
+var podAlertTypes = []string{
+	podAlert,
+	crashLoopBackOffAlert,
+	oomKilledAlert,
+	pendingPodAlert,
+}
+
+var alertTypes = []string{
+	podAlert,
+	crashLoopBackOffAlert,
+	oomKilledAlert,
+	pendingPodAlert,
+	eventAlert,
+	readinessProbeAlert,
+	driftAlert,
+	certificateExpiryAlert,
+	certificateNotReadyAlert,
+}
+
 func getExpectedNumbers() map[string]expected {
 	return map[string]expected{
-		"ImagePullBackOff": {
-			waitTime:       2,
-			Count:          6,
-			CountPerMinute: 1,
+		podAlert: {
+			waitTime: 2,
+		},
+		crashLoopBackOffAlert: {
+			Count: 3, // restarts
+		},
+		oomKilledAlert: {
+			waitTime: 0,
+		},
+		pendingPodAlert: {
+			waitTime: 5,
 		},
-		"Failed": {
-			waitTime:       2,
+		eventAlert: {
 			Count:          6,
 			CountPerMinute: 1,
 		},
-		// TODO insert different type of errors
+		readinessProbeAlert: {
+			Count:          10,
+			CountPerMinute: 2,
+		},
+		driftAlert: {
+			waitTime: 10,
+		},
+		certificateExpiryAlert: {
+			waitTime: 0,
+		},
+		certificateNotReadyAlert: {
+			waitTime: 10, // leaves time for issuance and renewal
+		},
 	}
 }
 
@@ -46,18 +104,18 @@ func NewAlertStateManager(notifManager notifications.Manager, store store.Store,
 
 func (a AlertStateManager) Run() {
 	for {
+		err := a.resolveRecoveredRestarts()
+		if err != nil {
+			logrus.Errorf("couldn't resolve recovered restarting pods: %s", err)
+		}
+
 		var thresholds []threshold
 		alerts, err := a.store.PendingAlerts()
 		if err != nil {
 			logrus.Errorf("couldn't get pending alerts: %s", err)
 		}
 		for _, alert := range alerts {
-			status, err := a.status(alert.Name, alert.Type)
-			if err != nil {
-				logrus.Errorf("couldn't get status from alert: %s", err)
-				continue
-			}
-			expected := getExpectedNumbers()[status]
+			expected := getExpectedNumbers()[alert.Type]
 			thresholds = append(thresholds, ToThreshold(alert, expected.waitTime, expected.Count, expected.CountPerMinute))
 		}
 
@@ -70,52 +128,94 @@ func (a AlertStateManager) Run() {
 	}
 }
 
-func (a AlertStateManager) TrackPods(pods []*api.Pod) error {
+func (a AlertStateManager) TrackPods(env string, pods []*api.Pod) error {
 	for _, pod := range pods {
 		podName := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
 		deploymentName := fmt.Sprintf("%s/%s", pod.Namespace, pod.DeploymentName)
 		currentTime := time.Now().Unix()
-		alertState := "Pending"
-		alertType := "pod"
+		alertType := podAlertType(pod.Status)
 
-		if a.alreadyAlerted(podName, alertType) || a.statusNotChanged(podName, pod.Status) {
+		if a.statusNotChanged(podName, pod.Status) {
 			continue
 		}
 
 		err := a.store.SaveOrUpdatePod(&model.Pod{
-			Name:       podName,
-			Status:     pod.Status,
-			StatusDesc: pod.StatusDescription,
+			Name:            podName,
+			Status:          pod.Status,
+			StatusDesc:      pod.StatusDescription,
+			LastStateChange: currentTime,
 		})
 		if err != nil {
 			return err
 		}
 
-		if podErrorState(pod.Status) {
-			err := a.store.SaveOrUpdateAlert(&model.Alert{
-				Type:            alertType,
-				Name:            podName,
-				DeploymentName:  deploymentName,
-				Status:          alertState,
-				StatusDesc:      pod.StatusDescription,
-				LastStateChange: currentTime,
-			})
+		if !podErrorState(pod.Status) {
+			err := a.resolveRecovered(podName)
 			if err != nil {
 				return err
 			}
+			continue
 		}
+
+		err = a.resolveOtherPodAlerts(podName, alertType)
+		if err != nil {
+			return err
+		}
+
+		alert, err := a.store.Alert(podName, alertType)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == nil && alert.Status != model.AlertResolved {
+			// the pod fails the same way again, the open alert is kept with the latest details
+			alert.StatusDesc = pod.StatusDescription
+			alert.Count = pod.RestartCount
+			err = a.store.SaveOrUpdateAlert(alert)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = a.store.SaveOrUpdateAlert(&model.Alert{
+			Type:            alertType,
+			Name:            podName,
+			DeploymentName:  deploymentName,
+			Env:             env,
+			Status:          model.AlertPending,
+			StatusDesc:      pod.StatusDescription,
+			LastStateChange: currentTime,
+			Count:           pod.RestartCount,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PodDeleted resolves the alerts of a pod that is gone
+func (a AlertStateManager) PodDeleted(podName string) error {
+	err := a.resolve(podName)
+	if err != nil {
+		return err
+	}
+
+	err = a.store.DeletePod(podName)
+	if err != nil && err != sql.ErrNoRows {
+		return err
 	}
 	return nil
 }
 
-func (a AlertStateManager) TrackEvents(events []api.Event) error {
+func (a AlertStateManager) TrackEvents(env string, events []api.Event) error {
 	for _, event := range events {
 		eventName := fmt.Sprintf("%s/%s", event.Namespace, event.Name)
 		deploymentName := fmt.Sprintf("%s/%s", event.Namespace, event.DeploymentName)
-		alertState := "Pending"
-		alertType := "event"
+		alertType := eventAlertType(event.Status)
 
-		if a.alreadyAlerted(eventName, alertType) {
+		if a.alreadyAlerted(eventName, alertType) ||
+			a.alreadyResolved(eventName, alertType, event.Count) {
 			continue
 		}
 
@@ -132,7 +232,8 @@ func (a AlertStateManager) TrackEvents(events []api.Event) error {
 			Type:            alertType,
 			Name:            eventName,
 			DeploymentName:  deploymentName,
-			Status:          alertState,
+			Env:             env,
+			Status:          model.AlertPending,
 			StatusDesc:      event.StatusDesc,
 			LastStateChange: event.FirstTimestamp,
 			Count:           event.Count,
@@ -144,18 +245,153 @@ func (a AlertStateManager) TrackEvents(events []api.Event) error {
 	return nil
 }
 
+// TrackDrift raises an alert for each drifted object of an env,
+// and resolves the drift alerts of the objects that are in sync again.
+// Replicas set by a scale action are intended, they are not alerted on
+func (a AlertStateManager) TrackDrift(env string, drifts []*api.Drift) error {
+	driftsByObject := map[string][]*api.Drift{}
+	for _, drift := range drifts {
+		if drift.ScaledBy != "" {
+			continue
+		}
+		name := driftAlertName(drift)
+		driftsByObject[name] = append(driftsByObject[name], drift)
+	}
+
+	for name, objectDrifts := range driftsByObject {
+		alert, err := a.store.Alert(name, driftAlert)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == nil && alert.Status != model.AlertResolved {
+			continue
+		}
+
+		drift := objectDrifts[0]
+		err = a.store.SaveOrUpdateAlert(&model.Alert{
+			Type:            driftAlert,
+			Name:            name,
+			DeploymentName:  fmt.Sprintf("%s/%s", drift.Namespace, drift.App),
+			Env:             env,
+			Status:          model.AlertPending,
+			StatusDesc:      driftDescription(objectDrifts),
+			LastStateChange: time.Now().Unix(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	drifted := map[string]bool{}
+	for name := range driftsByObject {
+		drifted[name] = true
+	}
+	return a.resolveGone(env, driftAlert, drifted)
+}
+
+// TrackCertificates raises an alert for the certificates of an env that are not ready
+// or expire soon, and resolves the alerts of the certificates that are fine again
+func (a AlertStateManager) TrackCertificates(env string, certificates []*api.Certificate) error {
+	firing := map[string]map[string]bool{
+		certificateExpiryAlert:   {},
+		certificateNotReadyAlert: {},
+	}
+
+	for _, certificate := range certificates {
+		name := certificateAlertName(certificate)
+
+		alertType := ""
+		statusDesc := ""
+		if !certificate.Ready {
+			alertType = certificateNotReadyAlert
+			statusDesc = fmt.Sprintf("certificate %s is not ready: %s", certificate.SecretName, certificate.StatusDesc)
+		} else if certificate.NotAfter != 0 && time.Until(time.Unix(certificate.NotAfter, 0)) < certificateExpiryWarning {
+			alertType = certificateExpiryAlert
+			statusDesc = fmt.Sprintf(
+				"certificate %s expires in less than %d days, on %s",
+				certificate.SecretName,
+				int(certificateExpiryWarning.Hours()/24),
+				time.Unix(certificate.NotAfter, 0).UTC().Format("2006-01-02"),
+			)
+		}
+		if alertType == "" {
+			continue
+		}
+		firing[alertType][name] = true
+
+		alert, err := a.store.Alert(name, alertType)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == nil && alert.Status != model.AlertResolved {
+			continue
+		}
+
+		err = a.store.SaveOrUpdateAlert(&model.Alert{
+			Type:            alertType,
+			Name:            name,
+			DeploymentName:  certificate.Ingress,
+			Env:             env,
+			Status:          model.AlertPending,
+			StatusDesc:      statusDesc,
+			LastStateChange: time.Now().Unix(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for alertType, names := range firing {
+		err := a.resolveGone(env, alertType, names)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveGone resolves the open alerts of a type in an env, except the ones still firing
+func (a AlertStateManager) resolveGone(env string, alertType string, firing map[string]bool) error {
+	pending, err := a.store.PendingAlerts()
+	if err != nil {
+		return err
+	}
+	active, err := a.store.ActiveAlerts()
+	if err != nil {
+		return err
+	}
+	for _, alert := range append(pending, active...) {
+		if alert.Type != alertType || alert.Env != env {
+			continue
+		}
+		if firing[alert.Name] {
+			continue
+		}
+
+		err = a.resolveType(alert.Name, alertType)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (a AlertStateManager) setFiringState(thresholds []threshold) error {
 	for _, t := range thresholds {
 		if t.isFired() {
 			alert := t.toAlert()
-			msg := notifications.MessageFromAlert(alert)
-			a.notifManager.Broadcast(msg)
+			if !a.silenced(&alert) {
+				msg := notifications.MessageFromAlert(alert)
+				a.notifManager.Broadcast(msg)
+			}
 
 			err := a.store.SaveOrUpdateAlert(&model.Alert{
 				Type:            alert.Type,
 				Name:            alert.Name,
 				DeploymentName:  alert.DeploymentName,
-				Status:          "Firing",
+				Env:             alert.Env,
+				Status:          model.AlertFiring,
 				StatusDesc:      alert.StatusDesc,
 				LastStateChange: time.Now().Unix(),
 				Count:           alert.Count,
@@ -168,20 +404,112 @@ func (a AlertStateManager) setFiringState(thresholds []threshold) error {
 	return nil
 }
 
-func (a AlertStateManager) status(name string, alertType string) (string, error) {
-	if alertType == "pod" {
-		pod, err := a.store.Pod(name)
+// resolve closes all alerts of the given object.
+// Only alerts that were already notified about send a resolved notification.
+func (a AlertStateManager) resolve(name string) error {
+	for _, alertType := range alertTypes {
+		err := a.resolveType(name, alertType)
 		if err != nil {
-			return "", err
+			return err
 		}
-		return pod.Status, nil
 	}
+	return nil
+}
+
+// resolveRecovered closes the alerts of a pod that is out of its error state.
+// Crash loop and OOMKilled alerts are kept open, as restarting pods are Running between restarts:
+// resolveRecoveredRestarts resolves them once the pod stays Running
+func (a AlertStateManager) resolveRecovered(podName string) error {
+	for _, alertType := range alertTypes {
+		if restartAlertType(alertType) {
+			continue
+		}
 
-	event, err := a.store.KubeEvent(name)
+		err := a.resolveType(podName, alertType)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveOtherPodAlerts closes the pod's alerts of other failure types when its failure changes,
+// eg from pending to failing to pull the image, so only the alert of the current failure stays open
+func (a AlertStateManager) resolveOtherPodAlerts(podName string, alertType string) error {
+	for _, t := range podAlertTypes {
+		if t == alertType {
+			continue
+		}
+
+		err := a.resolveType(podName, t)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveRecoveredRestarts resolves the crash loop and OOMKilled alerts of the pods
+// that have been out of their error state for the recovery window
+func (a AlertStateManager) resolveRecoveredRestarts() error {
+	pending, err := a.store.PendingAlerts()
+	if err != nil {
+		return err
+	}
+	active, err := a.store.ActiveAlerts()
 	if err != nil {
-		return "", err
+		return err
+	}
+	for _, alert := range append(pending, active...) {
+		if !restartAlertType(alert.Type) {
+			continue
+		}
+
+		pod, err := a.store.Pod(alert.Name)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == nil && (podErrorState(pod.Status) ||
+			time.Since(time.Unix(pod.LastStateChange, 0)) < crashLoopRecoveryWindow) {
+			continue
+		}
+
+		err = a.resolveType(alert.Name, alert.Type)
+		if err != nil {
+			return err
+		}
 	}
-	return event.Status, nil
+
+	return nil
+}
+
+func (a AlertStateManager) resolveType(name string, alertType string) error {
+	alert, err := a.store.Alert(name, alertType)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if alert.Status == model.AlertResolved {
+		return nil
+	}
+	notified := alert.Status == model.AlertFiring || alert.Status == model.AlertAcknowledged
+
+	currentTime := time.Now().Unix()
+	alert.Status = model.AlertResolved
+	alert.LastStateChange = currentTime
+	alert.ResolvedAt = currentTime
+	err = a.store.SaveOrUpdateAlert(alert)
+	if err != nil {
+		return err
+	}
+
+	if notified && !a.silenced(alert) {
+		msg := notifications.MessageFromAlert(*alert)
+		a.notifManager.Broadcast(msg)
+	}
+	return nil
 }
 
 func (a AlertStateManager) alreadyAlerted(name string, alertType string) bool {
@@ -193,7 +521,20 @@ func (a AlertStateManager) alreadyAlerted(name string, alertType string) bool {
 		return false
 	}
 
-	return alert.Status == "Firing"
+	return alert.Status == model.AlertFiring || alert.Status == model.AlertAcknowledged
+}
+
+// alreadyResolved tells if a resolved alert's event has not occurred again since
+func (a AlertStateManager) alreadyResolved(name string, alertType string, count int32) bool {
+	alert, err := a.store.Alert(name, alertType)
+	if err == sql.ErrNoRows {
+		return false
+	} else if err != nil {
+		logrus.Errorf("couldn't get alert from db: %s", err)
+		return false
+	}
+
+	return alert.Status == model.AlertResolved && count <= alert.Count
 }
 
 func (a AlertStateManager) statusNotChanged(podName string, podStatus string) bool {
@@ -208,8 +549,77 @@ func (a AlertStateManager) statusNotChanged(podName string, podStatus string) bo
 	return podStatus == podFromDb.Status
 }
 
+func (a AlertStateManager) silenced(alert *model.Alert) bool {
+	silences, err := a.store.ActiveSilences()
+	if err != nil {
+		logrus.Errorf("couldn't get silences: %s", err)
+		return false
+	}
+
+	for _, s := range silences {
+		if s.Matches(alert) {
+			return true
+		}
+	}
+	return false
+}
+
 func podErrorState(status string) bool {
-	return status != "Running" && status != "Pending" && status != "Terminating" &&
-		status != "Succeeded" && status != "Unknown" && status != "ContainerCreating" &&
-		status != "PodInitializing"
+	return status != "Running" && status != "Terminating" &&
+		status != "Succeeded" && status != "Unknown"
+}
+
+// podAlertType classifies pod failures so each can have its own threshold.
+// Pods that are still being scheduled, initialized or created are alerted on once they are stuck in it
+func podAlertType(status string) string {
+	switch status {
+	case "CrashLoopBackOff":
+		return crashLoopBackOffAlert
+	case "OOMKilled":
+		return oomKilledAlert
+	case "Pending", "Unschedulable", "ContainerCreating", "PodInitializing":
+		return pendingPodAlert
+	}
+	return podAlert
+}
+
+// restartAlertType tells if the alert type is of a pod that restarts,
+// and is briefly Running between the restarts
+func restartAlertType(alertType string) bool {
+	return alertType == crashLoopBackOffAlert || alertType == oomKilledAlert
+}
+
+// driftAlertName identifies the drifted object. The kind is part of it
+// as an app's service and deployment are often named the same
+func driftAlertName(drift *api.Drift) string {
+	return fmt.Sprintf("%s/%s/%s", drift.Namespace, strings.ToLower(drift.Kind), drift.Name)
+}
+
+// certificateAlertName identifies the certificate by its secret,
+// as ingresses that share a secret share the certificate too
+func certificateAlertName(certificate *api.Certificate) string {
+	return fmt.Sprintf("%s/certificate/%s", certificate.Namespace, certificate.SecretName)
+}
+
+func driftDescription(drifts []*api.Drift) string {
+	drift := drifts[0]
+	switch drift.Type {
+	case api.DriftMissing:
+		return fmt.Sprintf("%s is in the gitops repo but missing from the cluster", drift.Kind)
+	case api.DriftUnmanaged:
+		return fmt.Sprintf("%s is not in the gitops repo", drift.Kind)
+	}
+
+	fields := []string{}
+	for _, d := range drifts {
+		fields = append(fields, d.Field)
+	}
+	return fmt.Sprintf("%s differs from the gitops repo in %s", drift.Kind, strings.Join(fields, ", "))
+}
+
+func eventAlertType(reason string) string {
+	if reason == "ReadinessProbeFailed" {
+		return readinessProbeAlert
+	}
+	return eventAlert
 }
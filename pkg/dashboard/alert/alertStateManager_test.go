@@ -29,7 +29,7 @@ func TestTrackPods(t *testing.T) {
 	pods := []*api.Pod{&pod1, &pod2, &pod3}
 
 	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
-	p.TrackPods(pods)
+	p.TrackPods("staging", pods)
 
 	expectedPods := []model.Pod{
 		{Name: "ns1/pod1"},
@@ -44,6 +44,7 @@ func TestTrackPods(t *testing.T) {
 
 	expectedAlerts := []model.Alert{
 		{Name: "ns1/pod2", Type: "pod", Status: "Pending"},
+		{Name: "ns2/pod3", Type: "pendingPod", Status: "Pending"},
 	}
 	for _, alert := range expectedAlerts {
 		a, _ := store.Alert(alert.Name, alert.Type)
@@ -64,7 +65,7 @@ func TestTrackEvents(t *testing.T) {
 	events := []api.Event{event1, event2}
 
 	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
-	p.TrackEvents(events)
+	p.TrackEvents("staging", events)
 
 	expectedEvents := []model.KubeEvent{
 		{Name: "ns1/pod1"},
@@ -173,3 +174,391 @@ func TestNotificationSending(t *testing.T) {
 
 	time.Sleep(5 * time.Second)
 }
+
+func TestResolvePods(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		store.Close()
+	}()
+
+	dummyNotificationsManager := notifications.NewDummyManager()
+	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
+
+	failingPod := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "ImagePullBackOff"}
+	err := p.TrackPods("staging", []*api.Pod{&failingPod})
+	assert.Nil(t, err)
+
+	a, _ := store.Alert("ns1/pod1", "pod")
+	assert.Equal(t, model.AlertPending, a.Status)
+	assert.Equal(t, "staging", a.Env)
+
+	a.Status = model.AlertFiring
+	store.SaveOrUpdateAlert(a)
+
+	recoveredPod := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "Running"}
+	err = p.TrackPods("staging", []*api.Pod{&recoveredPod})
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns1/pod1", "pod")
+	assert.Equal(t, model.AlertResolved, a.Status)
+	assert.True(t, a.ResolvedAt > 0)
+
+	err = p.TrackPods("staging", []*api.Pod{&failingPod})
+	assert.Nil(t, err)
+	a, _ = store.Alert("ns1/pod1", "pod")
+	assert.Equal(t, model.AlertPending, a.Status)
+
+	err = p.PodDeleted("ns1/pod1")
+	assert.Nil(t, err)
+	a, _ = store.Alert("ns1/pod1", "pod")
+	assert.Equal(t, model.AlertResolved, a.Status)
+}
+
+func TestTrackDrift(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		store.Close()
+	}()
+
+	dummyNotificationsManager := notifications.NewDummyManager()
+	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
+
+	replicas := &api.Drift{App: "app", Type: api.DriftModified, Kind: "Deployment", Namespace: "ns1", Name: "app", Field: "spec.replicas"}
+	image := &api.Drift{App: "app", Type: api.DriftModified, Kind: "Deployment", Namespace: "ns1", Name: "app", Field: "spec.template.spec.containers[0].image"}
+	missing := &api.Drift{App: "app", Type: api.DriftMissing, Kind: "Service", Namespace: "ns1", Name: "app"}
+	err := p.TrackDrift("staging", []*api.Drift{replicas, image, missing})
+	assert.Nil(t, err)
+
+	a, _ := store.Alert("ns1/deployment/app", "drift")
+	assert.Equal(t, model.AlertPending, a.Status)
+	assert.Equal(t, "ns1/app", a.DeploymentName)
+	assert.Equal(t, "Deployment differs from the gitops repo in spec.replicas, spec.template.spec.containers[0].image", a.StatusDesc)
+	a, _ = store.Alert("ns1/service/app", "drift")
+	assert.Equal(t, model.AlertPending, a.Status)
+
+	err = p.TrackDrift("production", []*api.Drift{})
+	assert.Nil(t, err)
+	a, _ = store.Alert("ns1/service/app", "drift")
+	assert.Equal(t, model.AlertPending, a.Status, "drifts of other envs should be left alone")
+
+	err = p.TrackDrift("staging", []*api.Drift{replicas})
+	assert.Nil(t, err)
+	a, _ = store.Alert("ns1/deployment/app", "drift")
+	assert.Equal(t, model.AlertPending, a.Status)
+	a, _ = store.Alert("ns1/service/app", "drift")
+	assert.Equal(t, model.AlertResolved, a.Status)
+
+	replicas.ScaledBy = "laszlo"
+	err = p.TrackDrift("staging", []*api.Drift{replicas})
+	assert.Nil(t, err)
+	a, _ = store.Alert("ns1/deployment/app", "drift")
+	assert.Equal(t, model.AlertResolved, a.Status, "replicas set by a scale action should not be alerted on")
+}
+
+func TestTrackCertificates(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		store.Close()
+	}()
+
+	dummyNotificationsManager := notifications.NewDummyManager()
+	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
+
+	expiring := &api.Certificate{Ingress: "ns1/app", Namespace: "ns1", SecretName: "app-tls", Ready: true, NotAfter: time.Now().Add(3 * 24 * time.Hour).Unix()}
+	notReady := &api.Certificate{Ingress: "ns1/api", Namespace: "ns1", SecretName: "api-tls", StatusDesc: "Issuing certificate as Secret does not exist"}
+	valid := &api.Certificate{Ingress: "ns1/web", Namespace: "ns1", SecretName: "web-tls", Ready: true, NotAfter: time.Now().Add(60 * 24 * time.Hour).Unix()}
+	err := p.TrackCertificates("staging", []*api.Certificate{expiring, notReady, valid})
+	assert.Nil(t, err)
+
+	a, _ := store.Alert("ns1/certificate/app-tls", "certificateExpiry")
+	assert.Equal(t, model.AlertPending, a.Status)
+	assert.Equal(t, "ns1/app", a.DeploymentName)
+	a, _ = store.Alert("ns1/certificate/api-tls", "certificateNotReady")
+	assert.Equal(t, model.AlertPending, a.Status)
+	_, err = store.Alert("ns1/certificate/web-tls", "certificateExpiry")
+	assert.NotNil(t, err, "certificates far from expiry should not be alerted on")
+
+	renewed := &api.Certificate{Ingress: "ns1/app", Namespace: "ns1", SecretName: "app-tls", Ready: true, NotAfter: time.Now().Add(90 * 24 * time.Hour).Unix()}
+	err = p.TrackCertificates("staging", []*api.Certificate{renewed, notReady, valid})
+	assert.Nil(t, err)
+	a, _ = store.Alert("ns1/certificate/app-tls", "certificateExpiry")
+	assert.Equal(t, model.AlertResolved, a.Status)
+	a, _ = store.Alert("ns1/certificate/api-tls", "certificateNotReady")
+	assert.Equal(t, model.AlertPending, a.Status)
+}
+
+func TestSilenced(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		store.Close()
+	}()
+
+	dummyNotificationsManager := notifications.NewDummyManager()
+	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
+
+	alert := &model.Alert{Name: "ns1/pod1", Type: "pod", DeploymentName: "ns1/app", Env: "staging"}
+	assert.False(t, p.silenced(alert))
+
+	store.CreateSilence(&model.Silence{Env: "production", Until: time.Now().Add(time.Hour).Unix()})
+	assert.False(t, p.silenced(alert))
+
+	store.CreateSilence(&model.Silence{Deployment: "app", Until: time.Now().Add(-time.Hour).Unix()})
+	assert.False(t, p.silenced(alert))
+
+	store.CreateSilence(&model.Silence{Env: "staging", Deployment: "app", Until: time.Now().Add(time.Hour).Unix()})
+	assert.True(t, p.silenced(alert))
+}
+
+func TestPodResolvedMessage(t *testing.T) {
+	msgPodResolved := notifications.AlertMessage{
+		Alert: model.Alert{
+			Type:       "pod",
+			Name:       "ns1/pod1",
+			Status:     model.AlertResolved,
+			StatusDesc: "Container failed",
+		},
+	}
+
+	discordMsg, err := msgPodResolved.AsDiscordMessage()
+	assert.Nil(t, err)
+	assert.Contains(t, discordMsg.Text, "pod ns1/pod1 recovered")
+
+	slackMsg, err := msgPodResolved.AsSlackMessage()
+	assert.Nil(t, err)
+	assert.Contains(t, slackMsg.Text, "pod ns1/pod1 recovered")
+}
+
+func TestPodFailureTypes(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		store.Close()
+	}()
+
+	dummyNotificationsManager := notifications.NewDummyManager()
+	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
+
+	pods := []*api.Pod{
+		{Namespace: "ns1", Name: "pod1", Status: "CrashLoopBackOff", RestartCount: 4, LastExitCode: 1},
+		{Namespace: "ns1", Name: "pod2", Status: "OOMKilled", RestartCount: 1, LastExitCode: 137},
+		{Namespace: "ns1", Name: "pod3", Status: "Unschedulable", StatusDescription: "0/3 nodes are available: 3 Insufficient cpu."},
+		{Namespace: "ns1", Name: "pod4", Status: "ImagePullBackOff"},
+	}
+	err := p.TrackPods("staging", pods)
+	assert.Nil(t, err)
+
+	events := []api.Event{
+		{Namespace: "ns1", Name: "pod5", Status: "ReadinessProbeFailed", Count: 12},
+	}
+	err = p.TrackEvents("staging", events)
+	assert.Nil(t, err)
+
+	expected := []model.Alert{
+		{Name: "ns1/pod1", Type: "crashLoopBackOff", Count: 4},
+		{Name: "ns1/pod2", Type: "oomKilled", Count: 1},
+		{Name: "ns1/pod3", Type: "pendingPod"},
+		{Name: "ns1/pod4", Type: "pod"},
+		{Name: "ns1/pod5", Type: "readinessProbe", Count: 12},
+	}
+	for _, alert := range expected {
+		a, err := store.Alert(alert.Name, alert.Type)
+		assert.Nil(t, err)
+		assert.Equal(t, model.AlertPending, a.Status)
+		assert.Equal(t, alert.Count, a.Count)
+	}
+
+	err = p.PodDeleted("ns1/pod1")
+	assert.Nil(t, err)
+	a, _ := store.Alert("ns1/pod1", "crashLoopBackOff")
+	assert.Equal(t, model.AlertResolved, a.Status)
+}
+
+func TestCrashLoopThreshold(t *testing.T) {
+	expected := getExpectedNumbers()[crashLoopBackOffAlert]
+	lastStateChange := time.Now().Add(-1 * time.Minute).Unix()
+
+	fewRestarts := model.Alert{Name: "n/p1", Type: "crashLoopBackOff", LastStateChange: lastStateChange, Count: 1}
+	assert.False(t, ToThreshold(&fewRestarts, expected.waitTime, expected.Count, expected.CountPerMinute).isFired())
+
+	manyRestarts := model.Alert{Name: "n/p2", Type: "crashLoopBackOff", LastStateChange: lastStateChange, Count: 5}
+	assert.True(t, ToThreshold(&manyRestarts, expected.waitTime, expected.Count, expected.CountPerMinute).isFired())
+
+	pending := getExpectedNumbers()[pendingPodAlert]
+	recentlyPending := model.Alert{Name: "n/p3", Type: "pendingPod", LastStateChange: lastStateChange}
+	assert.False(t, ToThreshold(&recentlyPending, pending.waitTime, pending.Count, pending.CountPerMinute).isFired())
+}
+
+func TestCrashLoopFlapping(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		store.Close()
+	}()
+
+	dummyNotificationsManager := notifications.NewDummyManager()
+	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
+
+	crashing := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "CrashLoopBackOff", RestartCount: 3}
+	err := p.TrackPods("staging", []*api.Pod{&crashing})
+	assert.Nil(t, err)
+
+	a, _ := store.Alert("ns1/pod1", "crashLoopBackOff")
+	err = p.setFiringState([]threshold{ToThreshold(a, 0, 3, 0)})
+	assert.Nil(t, err)
+	a, _ = store.Alert("ns1/pod1", "crashLoopBackOff")
+	assert.Equal(t, model.AlertFiring, a.Status)
+	firingSince := a.LastStateChange
+
+	runningBetweenRestarts := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "Running", RestartCount: 4}
+	err = p.TrackPods("staging", []*api.Pod{&runningBetweenRestarts})
+	assert.Nil(t, err)
+	err = p.resolveRecoveredRestarts()
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns1/pod1", "crashLoopBackOff")
+	assert.Equal(t, model.AlertFiring, a.Status)
+	assert.Equal(t, int64(0), a.ResolvedAt)
+
+	crashing.RestartCount = 4
+	err = p.TrackPods("staging", []*api.Pod{&crashing})
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns1/pod1", "crashLoopBackOff")
+	assert.Equal(t, model.AlertFiring, a.Status)
+	assert.Equal(t, int32(4), a.Count)
+	assert.Equal(t, firingSince, a.LastStateChange)
+
+	err = p.TrackPods("staging", []*api.Pod{&runningBetweenRestarts})
+	assert.Nil(t, err)
+	pod, _ := store.Pod("ns1/pod1")
+	pod.LastStateChange = time.Now().Add(-crashLoopRecoveryWindow - time.Minute).Unix()
+	store.SaveOrUpdatePod(pod)
+	err = p.resolveRecoveredRestarts()
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns1/pod1", "crashLoopBackOff")
+	assert.Equal(t, model.AlertResolved, a.Status)
+}
+
+func TestPendingPods(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		store.Close()
+	}()
+
+	dummyNotificationsManager := notifications.NewDummyManager()
+	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
+
+	creating := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "ContainerCreating", StatusDescription: "Container app: ContainerCreating"}
+	err := p.TrackPods("staging", []*api.Pod{&creating})
+	assert.Nil(t, err)
+
+	a, err := store.Alert("ns1/pod1", "pendingPod")
+	assert.Nil(t, err)
+	assert.Equal(t, model.AlertPending, a.Status)
+	assert.Equal(t, "Container app: ContainerCreating", a.StatusDesc)
+
+	a.LastStateChange = time.Now().Add(-3 * time.Minute).Unix()
+	store.SaveOrUpdateAlert(a)
+	pendingSince := a.LastStateChange
+
+	initializing := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "PodInitializing", StatusDescription: "Init container migrations is running"}
+	err = p.TrackPods("staging", []*api.Pod{&initializing})
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns1/pod1", "pendingPod")
+	assert.Equal(t, model.AlertPending, a.Status)
+	assert.Equal(t, "Init container migrations is running", a.StatusDesc)
+	assert.Equal(t, pendingSince, a.LastStateChange)
+
+	expected := getExpectedNumbers()[pendingPodAlert]
+	assert.False(t, ToThreshold(a, expected.waitTime, expected.Count, expected.CountPerMinute).isFired())
+
+	running := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "Running"}
+	err = p.TrackPods("staging", []*api.Pod{&running})
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns1/pod1", "pendingPod")
+	assert.Equal(t, model.AlertResolved, a.Status)
+}
+
+func TestOOMKilledFlapping(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		store.Close()
+	}()
+
+	dummyNotificationsManager := notifications.NewDummyManager()
+	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
+
+	oomKilled := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "OOMKilled", RestartCount: 1}
+	err := p.TrackPods("staging", []*api.Pod{&oomKilled})
+	assert.Nil(t, err)
+
+	a, _ := store.Alert("ns1/pod1", "oomKilled")
+	err = p.setFiringState([]threshold{ToThreshold(a, 0, 0, 0)})
+	assert.Nil(t, err)
+
+	runningBetweenRestarts := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "Running", RestartCount: 1}
+	err = p.TrackPods("staging", []*api.Pod{&runningBetweenRestarts})
+	assert.Nil(t, err)
+	err = p.resolveRecoveredRestarts()
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns1/pod1", "oomKilled")
+	assert.Equal(t, model.AlertFiring, a.Status)
+
+	oomKilled.RestartCount = 2
+	err = p.TrackPods("staging", []*api.Pod{&oomKilled})
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns1/pod1", "oomKilled")
+	assert.Equal(t, model.AlertFiring, a.Status)
+	assert.Equal(t, int32(2), a.Count)
+
+	err = p.TrackPods("staging", []*api.Pod{&runningBetweenRestarts})
+	assert.Nil(t, err)
+	pod, _ := store.Pod("ns1/pod1")
+	pod.LastStateChange = time.Now().Add(-crashLoopRecoveryWindow - time.Minute).Unix()
+	store.SaveOrUpdatePod(pod)
+	err = p.resolveRecoveredRestarts()
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns1/pod1", "oomKilled")
+	assert.Equal(t, model.AlertResolved, a.Status)
+}
+
+func TestPodFailureTypeChange(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		store.Close()
+	}()
+
+	dummyNotificationsManager := notifications.NewDummyManager()
+	p := NewAlertStateManager(dummyNotificationsManager, *store, 2)
+
+	pending := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "ContainerCreating"}
+	err := p.TrackPods("staging", []*api.Pod{&pending})
+	assert.Nil(t, err)
+
+	imagePull := api.Pod{Namespace: "ns1", Name: "pod1", DeploymentName: "app", Status: "ImagePullBackOff"}
+	err = p.TrackPods("staging", []*api.Pod{&imagePull})
+	assert.Nil(t, err)
+
+	a, _ := store.Alert("ns1/pod1", "pendingPod")
+	assert.Equal(t, model.AlertResolved, a.Status)
+	a, _ = store.Alert("ns1/pod1", "pod")
+	assert.Equal(t, model.AlertPending, a.Status)
+
+	crashing := api.Pod{Namespace: "ns2", Name: "pod2", DeploymentName: "app", Status: "CrashLoopBackOff", RestartCount: 3}
+	err = p.TrackPods("staging", []*api.Pod{&crashing})
+	assert.Nil(t, err)
+
+	oomKilled := api.Pod{Namespace: "ns2", Name: "pod2", DeploymentName: "app", Status: "OOMKilled", RestartCount: 4}
+	err = p.TrackPods("staging", []*api.Pod{&oomKilled})
+	assert.Nil(t, err)
+
+	a, _ = store.Alert("ns2/pod2", "crashLoopBackOff")
+	assert.Equal(t, model.AlertResolved, a.Status)
+	a, _ = store.Alert("ns2/pod2", "oomKilled")
+	assert.Equal(t, model.AlertPending, a.Status)
+	assert.Equal(t, int32(4), a.Count)
+}
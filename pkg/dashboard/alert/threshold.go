@@ -16,6 +16,13 @@ type podStrategy struct {
 	waitTime time.Duration
 }
 
+// crashLoopStrategy fires once the pod restarted enough times
+type crashLoopStrategy struct {
+	pod              model.Alert
+	waitTime         time.Duration
+	expectedRestarts int32
+}
+
 type eventStrategy struct {
 	event                  model.Alert
 	expectedCountPerMinute float64
@@ -29,6 +36,13 @@ func (s podStrategy) isFired() bool {
 	return podLastStateChangeTime.Before(waitTime)
 }
 
+func (s crashLoopStrategy) isFired() bool {
+	podLastStateChangeTime := time.Unix(s.pod.LastStateChange, 0)
+	waitTime := time.Now().Add(-time.Minute * s.waitTime)
+
+	return podLastStateChangeTime.Before(waitTime) && s.pod.Count >= s.expectedRestarts
+}
+
 func (s eventStrategy) isFired() bool {
 	lastStateChangeInMinutes := time.Since(time.Unix(s.event.LastStateChange, 0)).Minutes()
 	countPerMinute := float64(s.event.Count) / lastStateChangeInMinutes
@@ -40,21 +54,32 @@ func (s podStrategy) toAlert() model.Alert {
 	return s.pod
 }
 
+func (s crashLoopStrategy) toAlert() model.Alert {
+	return s.pod
+}
+
 func (s eventStrategy) toAlert() model.Alert {
 	return s.event
 }
 
 func ToThreshold(a *model.Alert, waitTime time.Duration, expectedCount int32, expectedCountPerMinute float64) threshold {
-	if a.Type == "pod" {
-		return &podStrategy{
-			pod:      *a,
-			waitTime: waitTime,
+	switch a.Type {
+	case eventAlert, readinessProbeAlert:
+		return &eventStrategy{
+			event:                  *a,
+			expectedCount:          expectedCount,
+			expectedCountPerMinute: expectedCountPerMinute,
+		}
+	case crashLoopBackOffAlert:
+		return &crashLoopStrategy{
+			pod:              *a,
+			waitTime:         waitTime,
+			expectedRestarts: expectedCount,
 		}
 	}
 
-	return &eventStrategy{
-		event:                  *a,
-		expectedCount:          expectedCount,
-		expectedCountPerMinute: expectedCountPerMinute,
+	return &podStrategy{
+		pod:      *a,
+		waitTime: waitTime,
 	}
 }
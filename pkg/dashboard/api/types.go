@@ -17,6 +17,7 @@ package api
 import (
 	"fmt"
 
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 )
 
@@ -31,13 +32,60 @@ type Pod struct {
 	Namespace         string `json:"namespace"`
 	Status            string `json:"status"`
 	StatusDescription string `json:"statusDescription"`
+	RestartCount      int32  `json:"restartCount"`
+	LastExitCode      int32  `json:"lastExitCode"`
 	Logs              string `json:"logs"`
+
+	Containers []*ContainerResources `json:"containers,omitempty"`
 }
 
 func (p *Pod) FQN() string {
 	return p.Namespace + "/" + p.Name
 }
 
+// MemoryUtilization returns the highest memory usage of the pod's containers
+// in percentage of their limits, 0 if no container has a limit or usage figures
+func (p *Pod) MemoryUtilization() int {
+	highest := 0
+	for _, c := range p.Containers {
+		if u := percentage(c.MemoryUsage, c.MemoryLimit); u > highest {
+			highest = u
+		}
+	}
+	return highest
+}
+
+// CPUUtilization returns the highest CPU usage of the pod's containers
+// in percentage of their limits, 0 if no container has a limit or usage figures
+func (p *Pod) CPUUtilization() int {
+	highest := 0
+	for _, c := range p.Containers {
+		if u := percentage(c.CPUUsage, c.CPULimit); u > highest {
+			highest = u
+		}
+	}
+	return highest
+}
+
+func percentage(usage int64, limit int64) int {
+	if limit == 0 {
+		return 0
+	}
+	return int(usage * 100 / limit)
+}
+
+// ContainerResources holds the resource requests, limits and the current usage of a container.
+// CPU is in millicores, memory is in bytes. Usage is only present if metrics-server runs in the cluster
+type ContainerResources struct {
+	Name          string `json:"name"`
+	CPURequest    int64  `json:"cpuRequest,omitempty"`
+	CPULimit      int64  `json:"cpuLimit,omitempty"`
+	CPUUsage      int64  `json:"cpuUsage,omitempty"`
+	MemoryRequest int64  `json:"memoryRequest,omitempty"`
+	MemoryLimit   int64  `json:"memoryLimit,omitempty"`
+	MemoryUsage   int64  `json:"memoryUsage,omitempty"`
+}
+
 type Deployment struct {
 	Name          string `json:"name"`
 	Namespace     string `json:"namespace"`
@@ -50,16 +98,123 @@ func (d *Deployment) FQN() string {
 	return d.Namespace + "/" + d.Name
 }
 
+type StatefulSet struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	Pods          []*Pod `json:"pods,omitempty"`
+	SHA           string `json:"sha"`
+	CommitMessage string `json:"commitMessage"`
+	Replicas      int32  `json:"replicas"`
+	ReadyReplicas int32  `json:"readyReplicas"`
+}
+
+func (s *StatefulSet) FQN() string {
+	return s.Namespace + "/" + s.Name
+}
+
+type DaemonSet struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	Pods          []*Pod `json:"pods,omitempty"`
+	SHA           string `json:"sha"`
+	CommitMessage string `json:"commitMessage"`
+	Desired       int32  `json:"desired"`
+	Ready         int32  `json:"ready"`
+}
+
+func (d *DaemonSet) FQN() string {
+	return d.Namespace + "/" + d.Name
+}
+
+type CronJob struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	Schedule      string `json:"schedule"`
+	Suspended     bool   `json:"suspended"`
+	SHA           string `json:"sha"`
+	CommitMessage string `json:"commitMessage"`
+	LastJob       *Job   `json:"lastJob,omitempty"`
+}
+
+func (c *CronJob) FQN() string {
+	return c.Namespace + "/" + c.Name
+}
+
+const JobRunning = "Running"
+const JobSucceeded = "Succeeded"
+const JobFailed = "Failed"
+
+type Job struct {
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	Status         string `json:"status"`
+	StartTime      int64  `json:"startTime,omitempty"`
+	CompletionTime int64  `json:"completionTime,omitempty"`
+}
+
+// HPA is a horizontal pod autoscaler that scales the deployment or statefulset of a stack
+type HPA struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	MinReplicas     int32  `json:"minReplicas"`
+	MaxReplicas     int32  `json:"maxReplicas"`
+	CurrentReplicas int32  `json:"currentReplicas"`
+	DesiredReplicas int32  `json:"desiredReplicas"`
+}
+
 type Ingress struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
-	URL       string `json:"url"`
+	Name         string         `json:"name"`
+	Namespace    string         `json:"namespace"`
+	URL          string         `json:"url"`
+	Certificates []*Certificate `json:"certificates,omitempty"`
+}
+
+// Certificate is the TLS certificate of an ingress, either managed by cert-manager
+// or read from the secret that the ingress tls section references
+type Certificate struct {
+	Ingress    string   `json:"ingress"` // namespace/name
+	Namespace  string   `json:"namespace"`
+	SecretName string   `json:"secretName"`
+	Hosts      []string `json:"hosts,omitempty"`
+	Issuer     string   `json:"issuer,omitempty"`
+	NotAfter   int64    `json:"notAfter,omitempty"`
+	Ready      bool     `json:"ready"`
+	StatusDesc string   `json:"statusDesc,omitempty"`
 }
 
 type ConnectedAgent struct {
 	Name      string     `json:"name"`
 	Stacks    []*Stack   `json:"stacks"`
 	FluxState *FluxState `json:"fluxState"`
+	Drifts    []*Drift   `json:"drifts,omitempty"`
+}
+
+const DriftModified = "modified"
+const DriftMissing = "missing"
+const DriftUnmanaged = "unmanaged"
+
+// Drift is a difference between an object in the gitops repo and its live counterpart.
+// Modified drifts are reported per field, missing objects are in the gitops repo only,
+// unmanaged objects belong to an app but no gitops file owns them
+type Drift struct {
+	App       string `json:"app"`
+	Type      string `json:"type"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Field     string `json:"field,omitempty"`
+	Desired   string `json:"desired,omitempty"`
+	Live      string `json:"live,omitempty"`
+
+	// Kustomization is the namespace/name of the Flux Kustomization that applies the object
+	Kustomization string `json:"kustomization,omitempty"`
+
+	// ScaledBy is who set the live replica count with a scale action, the drift is intended then
+	ScaledBy string `json:"scaledBy,omitempty"`
+}
+
+func (d *Drift) FQN() string {
+	return d.Namespace + "/" + d.Name
 }
 
 type GitRepository struct {
@@ -120,9 +275,46 @@ type GitopsBootstrapConfig struct {
 	AppsRepo           string `json:"appsRepo"`
 }
 
+// HelmRelease is a Flux managed Helm release. The attempted revision is the chart version
+// Flux last tried to install, the applied revision is the one that succeeded
+type HelmRelease struct {
+	Name                  string `json:"name"`
+	Namespace             string `json:"namespace"`
+	Chart                 string `json:"chart"`
+	ChartVersion          string `json:"chartVersion"`
+	LastAttemptedRevision string `json:"lastAttemptedRevision"`
+	LastAppliedRevision   string `json:"lastAppliedRevision"`
+	LastTransitionTime    int64  `json:"lastTransitionTime"`
+	Status                string `json:"status"`
+	StatusDesc            string `json:"statusDesc"`
+}
+
+func (h HelmRelease) String() string {
+	return fmt.Sprintf("HelmRelease (%s@%s) %s/%s - %d - %s: %s", h.Chart, h.ChartVersion, h.Namespace, h.Name, h.LastTransitionTime, h.Status, h.StatusDesc)
+}
+
+// FluxSource is a HelmRepository, OCIRepository or Bucket that Flux fetches artifacts from
+type FluxSource struct {
+	Name               string `json:"name"`
+	Namespace          string `json:"namespace"`
+	URL                string `json:"url"`
+	Revision           string `json:"revision"`
+	LastTransitionTime int64  `json:"lastTransitionTime"`
+	Status             string `json:"status"`
+	StatusDesc         string `json:"statusDesc"`
+}
+
+func (s FluxSource) String() string {
+	return fmt.Sprintf("%s (@%s) %s/%s - %d - %s: %s", s.URL, s.Revision, s.Namespace, s.Name, s.LastTransitionTime, s.Status, s.StatusDesc)
+}
+
 type FluxState struct {
-	GitReppsitories []*GitRepository `json:"gitRepositories"`
-	Kustomizations  []*Kustomization `json:"kustomizations"`
+	GitReppsitories  []*GitRepository `json:"gitRepositories"`
+	Kustomizations   []*Kustomization `json:"kustomizations"`
+	HelmReleases     []*HelmRelease   `json:"helmReleases"`
+	HelmRepositories []*FluxSource    `json:"helmRepositories"`
+	OCIRepositories  []*FluxSource    `json:"ociRepositories"`
+	Buckets          []*FluxSource    `json:"buckets"`
 }
 
 type FluxStateUpdate struct {
@@ -131,11 +323,35 @@ type FluxStateUpdate struct {
 }
 
 type Stack struct {
-	Repo       string      `json:"repo"`
-	Env        string      `json:"env"`
-	Service    *Service    `json:"service"`
-	Deployment *Deployment `json:"deployment,omitempty"`
-	Ingresses  []*Ingress  `json:"ingresses,omitempty"`
+	Repo        string       `json:"repo"`
+	Env         string       `json:"env"`
+	Service     *Service     `json:"service"`
+	Deployment  *Deployment  `json:"deployment,omitempty"`
+	StatefulSet *StatefulSet `json:"statefulSet,omitempty"`
+	DaemonSet   *DaemonSet   `json:"daemonSet,omitempty"`
+	CronJobs    []*CronJob   `json:"cronJobs,omitempty"`
+	HPA         *HPA         `json:"hpa,omitempty"`
+	Ingresses   []*Ingress   `json:"ingresses,omitempty"`
+}
+
+// Key identifies the stack within an env
+func (s *Stack) Key() string {
+	if s.Service == nil {
+		return ""
+	}
+	return s.Service.Namespace + "/" + s.Service.Name
+}
+
+// StateDelta carries the stacks of an env that changed since the state version that
+// the dashboard last acknowledged. Versions are scoped to the session of an agent process,
+// a full delta replaces the whole state and starts the versioning over
+type StateDelta struct {
+	Session     string   `json:"session"`
+	BaseVersion int64    `json:"baseVersion"`
+	Version     int64    `json:"version"`
+	Full        bool     `json:"full,omitempty"`
+	Upserted    []*Stack `json:"upserted,omitempty"`
+	Deleted     []string `json:"deleted,omitempty"`
 }
 
 type StackUpdate struct {
@@ -146,10 +362,13 @@ type StackUpdate struct {
 	Svc     string `json:"svc"`
 
 	// Pod
-	Status     string `json:"status"`
-	Deployment string `json:"deployment"`
-	ErrorCause string `json:"errorCause"`
-	Logs       string `json:"logs"`
+	Status       string      `json:"status"`
+	Deployment   string      `json:"deployment"`
+	ErrorCause   string      `json:"errorCause"`
+	RestartCount int32       `json:"restartCount"`
+	LastExitCode int32       `json:"lastExitCode"`
+	Logs         string      `json:"logs"`
+	CrashLogs    []*CrashLog `json:"crashLogs,omitempty"`
 
 	// Deployment
 	SHA           string `json:"sha"`
@@ -162,6 +381,42 @@ type StackUpdate struct {
 	Stacks []*Stack `json:"stacks"`
 }
 
+// CrashLog is the output of a container's previous run, captured when the container restarted
+type CrashLog struct {
+	Container    string `json:"container"`
+	RestartCount int32  `json:"restartCount"`
+	ExitCode     int32  `json:"exitCode"`
+	Reason       string `json:"reason"`
+	FinishedAt   int64  `json:"finishedAt"`
+	Logs         string `json:"logs"`
+}
+
+// ReleaseDiff is what changed between two releases of an app in an env
+type ReleaseDiff struct {
+	Env  string      `json:"env"`
+	App  string      `json:"app"`
+	From *dx.Release `json:"from"`
+	To   *dx.Release `json:"to"`
+	// Commits are the source commits between the two releases, newest first
+	Commits []*model.Commit `json:"commits"`
+	// CommitsUnavailable tells why the source commits could not be listed
+	CommitsUnavailable string `json:"commitsUnavailable,omitempty"`
+	// Values is the unified diff of the manifest values
+	Values string `json:"values"`
+	// ValuesUnavailable tells why the manifest values could not be compared, eg the artifact of a release is gone
+	ValuesUnavailable string `json:"valuesUnavailable,omitempty"`
+	// Manifests is the unified diff of the rendered manifests in the gitops repo
+	Manifests string `json:"manifests"`
+}
+
+// ArtifactSigning is the artifact signature policy of an env
+type ArtifactSigning struct {
+	// RequireSignedArtifacts blocks deploying artifacts that are not signed by one of the TrustedKeys
+	RequireSignedArtifacts bool `json:"requireSignedArtifacts"`
+	// TrustedKeys are PEM encoded ECDSA or Ed25519 public keys
+	TrustedKeys []string `json:"trustedKeys"`
+}
+
 type Tag struct {
 	SHA  string `json:"sha"`
 	Name string `json:"name"`
@@ -177,3 +432,80 @@ type PR struct {
 	Created int    `json:"created"`
 	Updated int    `json:"updated"`
 }
+
+// DeployTarget is an environment that an artifact of a commit deploys to
+type DeployTarget struct {
+	App        string `json:"app"`
+	Env        string `json:"env"`
+	Tenant     string `json:"tenant"`
+	ArtifactId string `json:"artifactId"`
+}
+
+// Commit represents a Github commit
+type Commit struct {
+	SHA           string               `json:"sha"`
+	URL           string               `json:"url"`
+	Author        string               `json:"author"`
+	AuthorName    string               `json:"authorName"`
+	AuthorPic     string               `json:"author_pic"`
+	Message       string               `json:"message"`
+	CreatedAt     int64                `json:"created_at"`
+	Tags          []string             `json:"tags,omitempty"`
+	Status        model.CombinedStatus `json:"status,omitempty"`
+	DeployTargets []*DeployTarget      `json:"deployTargets,omitempty"`
+}
+
+// FileInfo is a Gimlet manifest file in the .gimlet folder of a repo
+type FileInfo struct {
+	EnvName  string `json:"envName"`
+	AppName  string `json:"appName"`
+	FileName string `json:"fileName"`
+}
+
+// GitRepoMetas tells the CI and Gimlet configuration of a repo
+type GitRepoMetas struct {
+	GithubActions        bool       `json:"githubActions"`
+	CircleCi             bool       `json:"circleCi"`
+	GithubActionsShipper *string    `json:"githubActionsShipper"`
+	CircleCiShipper      *string    `json:"circleCiShipper"`
+	FileInfos            []FileInfo `json:"fileInfos"`
+}
+
+// EnvConfig is the environment config that the dashboard edits and saves as a Gimlet manifest
+type EnvConfig struct {
+	Values          map[string]interface{}
+	Namespace       string
+	Chart           Chart
+	AppName         string
+	UseDeployPolicy bool
+	DeployBranch    string
+	DeployTag       string
+	DeployEvent     *dx.GitEvent
+}
+
+type Chart struct {
+	Repository string
+	Name       string
+	Version    string
+}
+
+// DeploymentTemplate is a Helm chart with its values schema that an env config can be based on
+type DeploymentTemplate struct {
+	Reference dx.Chart    `json:"reference"`
+	Schema    interface{} `json:"schema"`
+	UISchema  interface{} `json:"uiSchema"`
+}
+
+// InfrastructureComponents is the stack config of an environment's infrastructure repository
+type InfrastructureComponents struct {
+	Env                      string                 `json:"env"`
+	InfrastructureComponents map[string]interface{} `json:"infrastructureComponents"`
+}
+
+type FavoriteRepos struct {
+	FavoriteRepos []string `json:"favoriteRepos"`
+}
+
+type FavoriteServices struct {
+	FavoriteServices []string `json:"favoriteServices"`
+}
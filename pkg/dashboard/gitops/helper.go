@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
@@ -193,6 +195,70 @@ func Status(
 	return appReleases, nil
 }
 
+// AppManifests returns the Kubernetes manifests of every app in an env, keyed by app and file name
+func AppManifests(
+	repo *git.Repository,
+	env string,
+	repoPerEnv bool,
+) (map[string]map[string]string, error) {
+	manifests := map[string]map[string]string{}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	fs := worktree.Filesystem
+
+	envPath := env
+	if repoPerEnv {
+		envPath = ""
+	}
+	paths, err := fs.ReadDir(envPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list files: %s", err)
+	}
+
+	for _, fileInfo := range paths {
+		if !fileInfo.IsDir() {
+			continue
+		}
+		if fileInfo.Name() == ".git" || fileInfo.Name() == "flux" {
+			continue
+		}
+
+		appPath := filepath.Join(envPath, fileInfo.Name())
+		files, err := fs.ReadDir(appPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list files: %s", err)
+		}
+
+		appManifests := map[string]string{}
+		for _, file := range files {
+			if file.IsDir() ||
+				!(strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml")) {
+				continue
+			}
+
+			f, err := fs.Open(filepath.Join(appPath, file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			content, err := ioutil.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			appManifests[file.Name()] = string(content)
+		}
+
+		if len(appManifests) > 0 {
+			manifests[fileInfo.Name()] = appManifests
+		}
+	}
+
+	return manifests, nil
+}
+
 func Envs(
 	repo *git.Repository,
 ) ([]string, error) {
@@ -354,3 +420,76 @@ func Manifest(
 
 	return nil, nil
 }
+
+// AppManifestsOnHash returns the yaml files of an app as they were in a gitops commit
+func AppManifestsOnHash(
+	repo *git.Repository,
+	hash string,
+	env string,
+	app string,
+	repoPerEnv bool,
+) (map[string]string, error) {
+	envPath := env
+	if repoPerEnv {
+		envPath = ""
+	}
+
+	files, err := nativeGit.RemoteFolderOnHashWithoutCheckout(repo, hash, filepath.Join(envPath, app))
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := map[string]string{}
+	for name, content := range files {
+		if strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+			manifests[name] = content
+		}
+	}
+	return manifests, nil
+}
+
+// UnifiedDiff returns the unified diff of two sets of files, keyed by file name.
+// Files that are the same in both sets are left out
+func UnifiedDiff(from, to map[string]string) (string, error) {
+	names := map[string]bool{}
+	for name := range from {
+		names[name] = true
+	}
+	for name := range to {
+		names[name] = true
+	}
+	sortedNames := []string{}
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	var sb strings.Builder
+	for _, name := range sortedNames {
+		if from[name] == to[name] {
+			continue
+		}
+
+		fromFile, toFile := "a/"+name, "b/"+name
+		if _, ok := from[name]; !ok {
+			fromFile = "/dev/null"
+		}
+		if _, ok := to[name]; !ok {
+			toFile = "/dev/null"
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(from[name]),
+			B:        difflib.SplitLines(to[name]),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(diff)
+	}
+
+	return sb.String(), nil
+}
@@ -86,6 +86,73 @@ func Test_Status(t *testing.T) {
 	assert.Equal(t, 2, len(status), "should get release status for all apps")
 }
 
+func Test_AppManifests(t *testing.T) {
+	repo := initHistory()
+	nativeGit.CommitFilesToGit(
+		repo,
+		map[string]string{
+			"deployment.yaml": `kind: Deployment`,
+			"service.yaml":    `kind: Service`,
+		},
+		"staging",
+		"my-app4",
+		false,
+		"5th commit",
+		"{}",
+	)
+
+	manifests, err := AppManifests(repo, "staging", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(manifests), "only apps with yaml files should be returned")
+	assert.Equal(t, "kind: Deployment\n", manifests["my-app4"]["deployment.yaml"])
+	assert.Equal(t, 2, len(manifests["my-app4"]), "release.json should be left out")
+}
+
+func Test_AppManifestsOnHash(t *testing.T) {
+	repo := initHistory()
+	from, _ := nativeGit.CommitFilesToGit(
+		repo,
+		map[string]string{
+			"deployment.yaml": "kind: Deployment\nreplicas: 1",
+			"service.yaml":    `kind: Service`,
+		},
+		"staging",
+		"my-app4",
+		false,
+		"5th commit",
+		"{}",
+	)
+	to, _ := nativeGit.CommitFilesToGit(
+		repo,
+		map[string]string{
+			"deployment.yaml": "kind: Deployment\nreplicas: 2",
+			"ingress.yaml":    `kind: Ingress`,
+		},
+		"staging",
+		"my-app4",
+		false,
+		"6th commit",
+		"{}",
+	)
+
+	fromManifests, err := AppManifestsOnHash(repo, from, "staging", "my-app4", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(fromManifests), "release.json should be left out")
+	toManifests, err := AppManifestsOnHash(repo, to, "staging", "my-app4", false)
+	assert.Nil(t, err)
+
+	diff, err := UnifiedDiff(fromManifests, toManifests)
+	assert.Nil(t, err)
+	assert.Contains(t, diff, "-replicas: 1")
+	assert.Contains(t, diff, "+replicas: 2")
+	assert.Contains(t, diff, "+++ b/ingress.yaml")
+	assert.Contains(t, diff, "+++ /dev/null")
+
+	diff, err = UnifiedDiff(fromManifests, fromManifests)
+	assert.Nil(t, err)
+	assert.Equal(t, "", diff, "unchanged files should be left out")
+}
+
 func initHistory() *git.Repository {
 	repo, _ := git.Init(memory.NewStorage(), memfs.New())
 
@@ -0,0 +1,192 @@
+package insights
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+)
+
+// deployment is a gitops write that rolled out an app to an env
+type deployment struct {
+	env       string
+	app       string
+	rollback  bool
+	committed int64 // source commit time, zero for rollbacks
+	deployed  int64
+	failed    bool
+}
+
+// Compute calculates the DORA metrics per env and app
+// from the processed release events and the gitops commits they wrote
+func Compute(
+	events []*model.Event,
+	gitopsCommits []*model.GitopsCommit,
+	since, until time.Time,
+) []*model.Insights {
+	commits := map[string]*model.GitopsCommit{}
+	for _, c := range gitopsCommits {
+		commits[c.Sha] = c
+	}
+
+	deploymentsByApp := map[string][]*deployment{}
+	keys := []string{}
+	for _, event := range events {
+		if event.Type != model.ArtifactCreatedEvent &&
+			event.Type != model.ReleaseRequestedEvent &&
+			event.Type != model.PromotionRequestedEvent &&
+			event.Type != model.RollbackRequestedEvent {
+			continue
+		}
+		if event.Created < since.Unix() || event.Created > until.Unix() {
+			continue
+		}
+
+		seen := map[string]bool{} // a rollback writes a gitops commit per reverted release
+		for _, result := range event.Results {
+			d := toDeployment(event, result, commits)
+			if d == nil {
+				continue
+			}
+
+			key := d.env + "/" + d.app
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if _, ok := deploymentsByApp[key]; !ok {
+				keys = append(keys, key)
+			}
+			deploymentsByApp[key] = append(deploymentsByApp[key], d)
+		}
+	}
+
+	sort.Strings(keys)
+	insights := []*model.Insights{}
+	for _, key := range keys {
+		insights = append(insights, compute(deploymentsByApp[key], since, until))
+	}
+	return insights
+}
+
+func toDeployment(event *model.Event, result model.Result, commits map[string]*model.GitopsCommit) *deployment {
+	if result.Status != model.Success || result.GitopsRef == "" {
+		return nil
+	}
+
+	d := &deployment{deployed: event.Created}
+	if result.RollbackRequest != nil {
+		d.env = result.RollbackRequest.Env
+		d.app = result.RollbackRequest.App
+		d.rollback = true
+	} else if result.Manifest != nil {
+		d.env = result.Manifest.Env
+		d.app = result.Manifest.App
+		if result.Artifact != nil {
+			d.committed = result.Artifact.Version.Created
+			if d.committed == 0 {
+				d.committed = result.Artifact.Created
+			}
+		}
+	} else {
+		return nil
+	}
+
+	if c, ok := commits[result.GitopsRef]; ok {
+		switch c.Status {
+		case model.ReconciliationSucceeded:
+			d.deployed = c.Created
+		case model.ReconciliationFailed, model.ValidationFailed, model.HealthCheckFailed:
+			d.deployed = c.Created
+			d.failed = true
+		}
+	}
+
+	return d
+}
+
+func compute(deployments []*deployment, since, until time.Time) *model.Insights {
+	sort.SliceStable(deployments, func(i, j int) bool {
+		return deployments[i].deployed < deployments[j].deployed
+	})
+
+	insights := &model.Insights{
+		Env:   deployments[0].env,
+		App:   deployments[0].app,
+		Since: since.Unix(),
+		Until: until.Unix(),
+	}
+
+	leadTimes := []int64{}
+	restoreTimes := []int64{}
+	var failingSince int64
+	for i, d := range deployments {
+		if d.rollback {
+			insights.Rollbacks++
+		} else {
+			insights.Deployments++
+			if d.committed > 0 && !d.failed {
+				leadTimes = append(leadTimes, d.deployed-d.committed)
+			}
+		}
+
+		rolledBack := i+1 < len(deployments) && deployments[i+1].rollback
+		if d.failed || rolledBack {
+			if !d.rollback {
+				insights.Failures++
+			}
+			if failingSince == 0 {
+				failingSince = d.deployed
+			}
+			continue
+		}
+
+		if failingSince != 0 {
+			restoreTimes = append(restoreTimes, d.deployed-failingSince)
+			failingSince = 0
+		}
+	}
+
+	days := until.Sub(since).Hours() / 24
+	if days > 0 {
+		insights.DeploymentFrequency = float64(insights.Deployments) / days
+	}
+	if insights.Deployments > 0 {
+		insights.ChangeFailureRate = float64(insights.Failures) / float64(insights.Deployments)
+	}
+	insights.LeadTime = median(leadTimes)
+	insights.TimeToRestore = median(restoreTimes)
+
+	return insights
+}
+
+func median(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
+
+// Load computes the DORA metrics from the event history in the store
+func Load(store *store.Store, since, until time.Time) ([]*model.Insights, error) {
+	events, err := store.DeploymentEvents(since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("cannot get events: %s", err)
+	}
+
+	gitopsCommits, err := store.GitopsCommitsSince(since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("cannot get gitops commits: %s", err)
+	}
+
+	return Compute(events, gitopsCommits, since, until), nil
+}
@@ -0,0 +1,77 @@
+package insights
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompute(t *testing.T) {
+	until := time.Now()
+	since := until.Add(-10 * 24 * time.Hour)
+	hour := int64(60 * 60)
+	start := since.Unix()
+
+	release := func(created int64, committed int64, gitopsRef string) *model.Event {
+		return &model.Event{
+			Type:    model.ReleaseRequestedEvent,
+			Created: created,
+			Results: []model.Result{{
+				Manifest:  &dx.Manifest{Env: "staging", App: "myapp"},
+				Artifact:  &dx.Artifact{Version: dx.Version{Created: committed}},
+				Status:    model.Success,
+				GitopsRef: gitopsRef,
+			}},
+		}
+	}
+
+	events := []*model.Event{
+		release(start+1*hour, start, "sha1"),
+		release(start+3*hour, start+2*hour, "sha2"),
+		release(start+5*hour, start+4*hour, "sha3"),
+		{
+			Type:    model.RollbackRequestedEvent,
+			Created: start + 6*hour,
+			Results: []model.Result{
+				{RollbackRequest: &dx.RollbackRequest{Env: "staging", App: "myapp"}, Status: model.Success, GitopsRef: "sha4"},
+				{RollbackRequest: &dx.RollbackRequest{Env: "staging", App: "myapp"}, Status: model.Success, GitopsRef: "sha5"},
+			},
+		},
+		release(start+7*hour, start+6*hour, "sha6"),
+		{
+			Type:    model.BranchDeletedEvent,
+			Created: start + 8*hour,
+			Results: []model.Result{{Manifest: &dx.Manifest{Env: "staging", App: "preview"}, GitopsRef: "sha7"}},
+		},
+	}
+
+	gitopsCommits := []*model.GitopsCommit{
+		{Sha: "sha1", Status: model.ReconciliationSucceeded, Created: start + 2*hour},
+		{Sha: "sha2", Status: model.ReconciliationFailed, Created: start + 3*hour},
+		{Sha: "sha3", Status: model.ReconciliationSucceeded, Created: start + 5*hour},
+		{Sha: "sha6", Status: model.ReconciliationSucceeded, Created: start + 8*hour},
+	}
+
+	metrics := Compute(events, gitopsCommits, since, until)
+	assert.Equal(t, 1, len(metrics))
+
+	m := metrics[0]
+	assert.Equal(t, "staging", m.Env)
+	assert.Equal(t, "myapp", m.App)
+	assert.Equal(t, 4, m.Deployments)
+	assert.Equal(t, 1, m.Rollbacks)
+	assert.Equal(t, 0.4, m.DeploymentFrequency)
+	assert.Equal(t, 2*hour, m.LeadTime, "median of 2h, 1h and 2h")
+	assert.Equal(t, 2, m.Failures, "sha2 failed to reconcile, sha3 was rolled back")
+	assert.Equal(t, 0.5, m.ChangeFailureRate)
+	assert.Equal(t, 3*hour, m.TimeToRestore, "from the failed sha2 to the rollback")
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, int64(0), median([]int64{}))
+	assert.Equal(t, int64(2), median([]int64{3, 1, 2}))
+	assert.Equal(t, int64(2), median([]int64{4, 1, 3, 1}))
+}
@@ -1,12 +1,31 @@
 package model
 
+import "strings"
+
+const AlertPending = "Pending"
+const AlertFiring = "Firing"
+const AlertAcknowledged = "Acknowledged"
+const AlertResolved = "Resolved"
+
 type Alert struct {
 	ID              int64  `json:"-"  meddler:"id,pk"`
 	Type            string `json:"type,omitempty"  meddler:"type"`
 	Name            string `json:"name,omitempty"  meddler:"name"`
 	DeploymentName  string `json:"deploymentName,omitempty"  meddler:"deployment_name"`
+	Env             string `json:"env,omitempty"  meddler:"env"`
 	Status          string `json:"status,omitempty"  meddler:"status"`
 	StatusDesc      string `json:"statusDesc,omitempty"  meddler:"status_desc"`
 	LastStateChange int64  `json:"lastStateChange,omitempty"  meddler:"last_state_change"`
 	Count           int32  `json:"count"  meddler:"count"`
+	AcknowledgedBy  string `json:"acknowledgedBy,omitempty"  meddler:"acknowledged_by"`
+	ResolvedAt      int64  `json:"resolvedAt,omitempty"  meddler:"resolved_at"`
+}
+
+// Namespace returns the namespace part of the alerted object's name
+func (a *Alert) Namespace() string {
+	parts := strings.SplitN(a.Name, "/", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[0]
 }
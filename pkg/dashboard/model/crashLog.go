@@ -0,0 +1,16 @@
+package model
+
+// CrashLog is the output of a container's run that ended with a restart
+type CrashLog struct {
+	ID           int64  `json:"id"  meddler:"id,pk"`
+	Env          string `json:"env"  meddler:"env"`
+	Namespace    string `json:"namespace"  meddler:"namespace"`
+	Pod          string `json:"pod"  meddler:"pod"`
+	App          string `json:"app"  meddler:"app"`
+	Container    string `json:"container"  meddler:"container"`
+	RestartCount int32  `json:"restartCount"  meddler:"restart_count"`
+	ExitCode     int32  `json:"exitCode"  meddler:"exit_code"`
+	Reason       string `json:"reason,omitempty"  meddler:"reason"`
+	FinishedAt   int64  `json:"finishedAt"  meddler:"finished_at"`
+	Logs         string `json:"logs"  meddler:"logs"`
+}
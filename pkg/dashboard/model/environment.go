@@ -8,4 +8,8 @@ type Environment struct {
 	InfraRepo           string `json:"infraRepo"  meddler:"infra_repo"`
 	AppsRepo            string `json:"appsRepo"  meddler:"apps_repo"`
 	BuiltIn             bool   `json:"builtIn"  meddler:"built_in"`
+
+	// RequireSignedArtifacts blocks deploying artifacts that are not signed by one of the TrustedKeys
+	RequireSignedArtifacts bool     `json:"requireSignedArtifacts"  meddler:"require_signed_artifacts"`
+	TrustedKeys            []string `json:"trustedKeys"  meddler:"trusted_keys,json"`
 }
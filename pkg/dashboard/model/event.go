@@ -14,6 +14,7 @@ const StatusError = "error"
 const ArtifactCreatedEvent = "artifact"
 const ReleaseRequestedEvent = "release"
 const RollbackRequestedEvent = "rollback"
+const PromotionRequestedEvent = "promotion"
 const BranchDeletedEvent = "branchDeleted"
 
 type Status int
@@ -45,6 +46,9 @@ type Result struct {
 
 	GitopsRef  string
 	GitopsRepo string
+
+	// Signature is the outcome of the artifact signature verification, if the env has trusted keys
+	Signature string
 }
 
 type Event struct {
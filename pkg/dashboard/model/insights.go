@@ -0,0 +1,24 @@
+package model
+
+// Insights holds the DORA metrics of an application in an environment
+type Insights struct {
+	Env string `json:"env"`
+	App string `json:"app"`
+
+	// Since and Until bound the time window of the metrics
+	Since int64 `json:"since"`
+	Until int64 `json:"until"`
+
+	Deployments int `json:"deployments"`
+	Failures    int `json:"failures"`
+	Rollbacks   int `json:"rollbacks"`
+
+	// DeploymentFrequency is the number of deployments per day
+	DeploymentFrequency float64 `json:"deploymentFrequency"`
+	// LeadTime is the median seconds from commit to reconciled deployment
+	LeadTime int64 `json:"leadTime"`
+	// ChangeFailureRate is the ratio of deployments that failed or were rolled back
+	ChangeFailureRate float64 `json:"changeFailureRate"`
+	// TimeToRestore is the median seconds from a failed deployment to the next healthy one
+	TimeToRestore int64 `json:"timeToRestore"`
+}
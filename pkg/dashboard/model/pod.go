@@ -5,4 +5,6 @@ type Pod struct {
 	Name       string `json:"name,omitempty"  meddler:"name"`
 	Status     string `json:"status,omitempty"  meddler:"status"`
 	StatusDesc string `json:"statusDesc,omitempty"  meddler:"status_desc"`
+	// LastStateChange is when the pod got into its current status
+	LastStateChange int64 `json:"lastStateChange,omitempty"  meddler:"last_state_change"`
 }
@@ -0,0 +1,16 @@
+package model
+
+// ScmDeployment is a deployment object that gimlet created in the SCM for a release,
+// and keeps updating until the gitops commit of the release is applied
+type ScmDeployment struct {
+	ID           int64  `json:"id"  meddler:"id,pk"`
+	Scm          string `json:"scm"  meddler:"scm"`
+	DeploymentID int64  `json:"deploymentId"  meddler:"deployment_id"`
+	Repo         string `json:"repo"  meddler:"repo"`
+	Ref          string `json:"ref,omitempty"  meddler:"ref"`
+	Env          string `json:"env"  meddler:"env"`
+	App          string `json:"app"  meddler:"app"`
+	GitopsRef    string `json:"gitopsRef,omitempty"  meddler:"gitops_ref"`
+	Pending      bool   `json:"pending"  meddler:"pending"`
+	Created      int64  `json:"created,omitempty"  meddler:"created"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "strings"
+
+// Silence mutes alert notifications until a given time.
+// Empty matcher fields match every alert.
+type Silence struct {
+	ID         int64  `json:"id"  meddler:"id,pk"`
+	Env        string `json:"env,omitempty"  meddler:"env"`
+	Namespace  string `json:"namespace,omitempty"  meddler:"namespace"`
+	Deployment string `json:"deployment,omitempty"  meddler:"deployment"`
+	Until      int64  `json:"until"  meddler:"until"`
+	Reason     string `json:"reason,omitempty"  meddler:"reason"`
+	CreatedBy  string `json:"createdBy,omitempty"  meddler:"created_by"`
+	Created    int64  `json:"created,omitempty"  meddler:"created"`
+}
+
+func (s *Silence) Matches(alert *Alert) bool {
+	if s.Env != "" && s.Env != alert.Env {
+		return false
+	}
+	if s.Namespace != "" && s.Namespace != alert.Namespace() {
+		return false
+	}
+	if s.Deployment != "" {
+		deployment := s.Deployment
+		if !strings.Contains(deployment, "/") {
+			deployment = alert.Namespace() + "/" + deployment
+		}
+		if deployment != alert.DeploymentName {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,57 @@
+package model
+
+import "fmt"
+
+const WorkloadActionRestart = "restart"
+const WorkloadActionScale = "scale"
+const WorkloadActionDeletePod = "deletePod"
+const WorkloadActionSuspend = "suspend"
+const WorkloadActionResume = "resume"
+const WorkloadActionReconcile = "reconcile"
+
+const WorkloadActionPending = "pending"
+const WorkloadActionSucceeded = "success"
+const WorkloadActionFailed = "error"
+
+// WorkloadAction is an audited command that the agent of an env performs on a workload
+type WorkloadAction struct {
+	ID          int64  `json:"id"  meddler:"id,pk"`
+	Env         string `json:"env"  meddler:"env"`
+	Action      string `json:"action"  meddler:"action"`
+	Kind        string `json:"kind,omitempty"  meddler:"kind"`
+	Namespace   string `json:"namespace"  meddler:"namespace"`
+	Name        string `json:"name"  meddler:"name"`
+	Replicas    int32  `json:"replicas,omitempty"  meddler:"replicas"`
+	TriggeredBy string `json:"triggeredBy,omitempty"  meddler:"triggered_by"`
+	Created     int64  `json:"created,omitempty"  meddler:"created"`
+	Status      string `json:"status,omitempty"  meddler:"status"`
+	StatusDesc  string `json:"statusDesc,omitempty"  meddler:"status_desc"`
+}
+
+// Validate checks the action and sets the default workload kind
+func (a *WorkloadAction) Validate() error {
+	if a.Env == "" || a.Namespace == "" || a.Name == "" {
+		return fmt.Errorf("env, namespace and name are mandatory")
+	}
+
+	switch a.Action {
+	case WorkloadActionRestart, WorkloadActionScale:
+		if a.Kind == "" {
+			a.Kind = "deployment"
+		}
+		if a.Kind != "deployment" && a.Kind != "statefulset" && !(a.Action == WorkloadActionRestart && a.Kind == "daemonset") {
+			return fmt.Errorf("cannot %s a %s", a.Action, a.Kind)
+		}
+		if a.Action == WorkloadActionScale && a.Replicas < 0 {
+			return fmt.Errorf("replicas must not be negative")
+		}
+	case WorkloadActionDeletePod:
+		a.Kind = "pod"
+	case WorkloadActionSuspend, WorkloadActionResume, WorkloadActionReconcile:
+		a.Kind = "kustomization"
+	default:
+		return fmt.Errorf("unknown action %s", a.Action)
+	}
+
+	return nil
+}
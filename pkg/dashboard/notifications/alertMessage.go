@@ -17,7 +17,11 @@ func (am *AlertMessage) AsSlackMessage() (*slackMessage, error) {
 		Blocks: []Block{},
 	}
 
-	msg.Text = fmt.Sprintf("%s %s failed", am.Alert.Type, am.Alert.Name)
+	if am.Alert.Status == model.AlertResolved {
+		msg.Text = fmt.Sprintf("%s %s recovered", am.Alert.Type, am.Alert.Name)
+	} else {
+		msg.Text = fmt.Sprintf("%s %s failed", am.Alert.Type, am.Alert.Name)
+	}
 	msg.Blocks = append(msg.Blocks,
 		Block{
 			Type: section,
@@ -33,7 +37,7 @@ func (am *AlertMessage) AsSlackMessage() (*slackMessage, error) {
 			Elements: []Text{
 				{
 					Type: markdown,
-					Text: fmt.Sprintf(":exclamation: %s", am.Alert.StatusDesc),
+					Text: am.statusLine(),
 				},
 			},
 		},
@@ -43,10 +47,10 @@ func (am *AlertMessage) AsSlackMessage() (*slackMessage, error) {
 }
 
 func (am *AlertMessage) Env() string {
-	return ""
+	return am.Alert.Env
 }
 
-func (am *AlertMessage) AsStatus() (*status, error) {
+func (am *AlertMessage) AsDeployment() (*deployment, error) {
 	return nil, nil
 }
 
@@ -60,13 +64,25 @@ func (am *AlertMessage) AsDiscordMessage() (*discordMessage, error) {
 		},
 	}
 
-	msg.Text = fmt.Sprintf("%s %s failed", am.Alert.Type, am.Alert.Name)
-	msg.Embed.Description += fmt.Sprintf(":exclamation: %s", am.Alert.StatusDesc)
-	msg.Embed.Color = 15158332
+	if am.Alert.Status == model.AlertResolved {
+		msg.Text = fmt.Sprintf("%s %s recovered", am.Alert.Type, am.Alert.Name)
+		msg.Embed.Color = 3066993
+	} else {
+		msg.Text = fmt.Sprintf("%s %s failed", am.Alert.Type, am.Alert.Name)
+		msg.Embed.Color = 15158332
+	}
+	msg.Embed.Description += am.statusLine()
 
 	return msg, nil
 }
 
+func (am *AlertMessage) statusLine() string {
+	if am.Alert.Status == model.AlertResolved {
+		return fmt.Sprintf(":white_check_mark: %s was resolved", am.Alert.StatusDesc)
+	}
+	return fmt.Sprintf(":exclamation: %s", am.Alert.StatusDesc)
+}
+
 func MessageFromAlert(alert model.Alert) Message {
 	return &AlertMessage{
 		Alert: alert,
@@ -80,3 +96,13 @@ func (am *AlertMessage) RepositoryName() string {
 func (am *AlertMessage) SHA() string {
 	return ""
 }
+
+func (am *AlertMessage) identity() identity {
+	return identity{
+		kind:   "alert",
+		env:    am.Alert.Env,
+		app:    am.Alert.DeploymentName,
+		ref:    am.Alert.Type + "/" + am.Alert.Name,
+		status: am.Alert.Status,
+	}
+}
@@ -0,0 +1,168 @@
+package notifications
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+)
+
+const (
+	deploymentInProgress = "in_progress"
+	deploymentSuccess    = "success"
+	deploymentFailure    = "failure"
+	deploymentInactive   = "inactive"
+)
+
+// deployment is a release of an app to an env, or a state change of the releases
+// that a gitops commit carries. Messages fill only the fields they know about:
+// a release knows the source repo, Flux events know only the gitops commit
+type deployment struct {
+	env         string
+	app         string
+	repo        string
+	ref         string
+	sha         string
+	gitopsRef   string
+	gitopsRepo  string
+	state       string
+	description string
+}
+
+// EnvironmentURL returns the public URL of an app in an env, or an empty string
+type EnvironmentURL func(env string, app string) string
+
+// IngressURL looks up the ingress host of an app from the connected agents
+func IngressURL(agentHub *streaming.AgentHub) EnvironmentURL {
+	return func(env string, app string) string {
+		agent, ok := agentHub.Agent(env)
+		if !ok {
+			return ""
+		}
+
+		for _, stack := range agent.Stacks {
+			if stack.Service == nil || stack.Service.Name != app {
+				continue
+			}
+			for _, ingress := range stack.Ingresses {
+				if ingress.URL != "" {
+					return "https://" + ingress.URL
+				}
+			}
+		}
+		return ""
+	}
+}
+
+// deploymentTracker remembers the deployment objects created in the SCM,
+// so the gitops commit lifecycle can update their statuses, also after a restart
+type deploymentTracker struct {
+	store      *store.Store
+	scm        string
+	reconciled map[string]*deployment
+}
+
+func newDeploymentTracker(store *store.Store, scm string) *deploymentTracker {
+	return &deploymentTracker{
+		store:      store,
+		scm:        scm,
+		reconciled: map[string]*deployment{},
+	}
+}
+
+// track remembers a deployment until its gitops commit is applied
+func (t *deploymentTracker) track(d *model.ScmDeployment) error {
+	err := t.store.DeleteAppliedScmDeployments(t.scm, d.Env, d.App)
+	if err != nil {
+		return err
+	}
+
+	d.Scm = t.scm
+	d.Pending = true
+	d.Created = time.Now().Unix()
+	return t.store.CreateScmDeployment(d)
+}
+
+// applied returns the pending deployments that the given gitops commit applied.
+// Flux reports only on the latest commit, so earlier pending deployments in the env are applied with it
+func (t *deploymentTracker) applied(env string, gitopsRef string) ([]*model.ScmDeployment, error) {
+	pending, err := t.store.PendingScmDeployments(t.scm, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var cutoff int64
+	for _, d := range pending {
+		if d.GitopsRef == gitopsRef && d.ID > cutoff {
+			cutoff = d.ID
+		}
+	}
+	if cutoff == 0 {
+		return nil, nil
+	}
+
+	applied := []*model.ScmDeployment{}
+	for _, d := range pending {
+		if d.ID > cutoff {
+			continue
+		}
+		err := t.store.ScmDeploymentApplied(d.ID)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, d)
+	}
+	return applied, nil
+}
+
+// forApp returns the latest deployment of an app in an env, or nil
+func (t *deploymentTracker) forApp(env string, app string) (*model.ScmDeployment, error) {
+	d, err := t.store.LatestScmDeployment(t.scm, env, app)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return d, err
+}
+
+// forget drops the deployments of an app that was deleted from an env
+func (t *deploymentTracker) forget(env string, app string) error {
+	return t.store.DeleteScmDeployments(t.scm, env, app)
+}
+
+// reconcile remembers the latest state that Flux reported on a gitops commit.
+// The deployment of a commit may be created only after Flux reported on it,
+// so the tracker replays the state on the deployments created later
+func (t *deploymentTracker) reconcile(d *deployment) {
+	t.reconciled[d.env] = d
+}
+
+// reconciledState returns the state that Flux already reported on the gitops commit of a new deployment, or nil
+func (t *deploymentTracker) reconciledState(env string, gitopsRef string) *deployment {
+	reconciled, ok := t.reconciled[env]
+	if !ok || gitopsRef == "" || reconciled.gitopsRef != gitopsRef {
+		return nil
+	}
+	return reconciled
+}
+
+// replayReconciled updates the deployments whose gitops commit Flux reported on before they were tracked
+func (t *deploymentTracker) replayReconciled(d *model.ScmDeployment, update func(tracked *model.ScmDeployment, d *deployment) error) error {
+	reconciled := t.reconciledState(d.Env, d.GitopsRef)
+	if reconciled == nil {
+		return nil
+	}
+
+	applied, err := t.applied(reconciled.env, reconciled.gitopsRef)
+	if err != nil {
+		return err
+	}
+	for _, tracked := range applied {
+		err := update(tracked, reconciled)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
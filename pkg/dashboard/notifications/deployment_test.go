@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	encryptionKey    = "the-key-has-to-be-32-bytes-long!"
+	encryptionKeyNew = ""
+)
+
+func TestDeploymentTrackerApplied(t *testing.T) {
+	s := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer s.Close()
+
+	tracker := newDeploymentTracker(s, "github")
+	assert.Nil(t, tracker.track(&model.ScmDeployment{DeploymentID: 1, Env: "staging", App: "app1", GitopsRef: "aaa"}))
+	assert.Nil(t, tracker.track(&model.ScmDeployment{DeploymentID: 2, Env: "production", App: "app1", GitopsRef: "bbb"}))
+	assert.Nil(t, tracker.track(&model.ScmDeployment{DeploymentID: 3, Env: "staging", App: "app2", GitopsRef: "ccc"}))
+	assert.Nil(t, tracker.track(&model.ScmDeployment{DeploymentID: 4, Env: "staging", App: "app1", GitopsRef: "ddd"}))
+
+	applied, err := tracker.applied("staging", "unknown")
+	assert.Nil(t, err)
+	assert.Nil(t, applied)
+
+	applied, err = tracker.applied("staging", "ccc")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(applied), "earlier deployments are applied with the reconciled commit")
+	assert.Equal(t, int64(1), applied[0].DeploymentID)
+	assert.Equal(t, int64(3), applied[1].DeploymentID)
+
+	applied, err = tracker.applied("staging", "aaa")
+	assert.Nil(t, err)
+	assert.Nil(t, applied, "applied deployments are not pending anymore")
+
+	restarted := newDeploymentTracker(s, "github")
+	applied, err = restarted.applied("staging", "ddd")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(applied), "tracked deployments survive a restart")
+	assert.Equal(t, int64(4), applied[0].DeploymentID)
+
+	latest, err := restarted.forApp("staging", "app1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), latest.DeploymentID)
+	assert.Nil(t, restarted.forget("staging", "app1"))
+	latest, err = restarted.forApp("staging", "app1")
+	assert.Nil(t, err)
+	assert.Nil(t, latest)
+
+	applied, err = newDeploymentTracker(s, "gitlab").applied("production", "bbb")
+	assert.Nil(t, err)
+	assert.Nil(t, applied, "deployments are tracked per SCM")
+	applied, err = restarted.applied("production", "bbb")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(applied))
+}
+
+func TestDeploymentTrackerReplaysReconciledState(t *testing.T) {
+	s := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer s.Close()
+
+	tracker := newDeploymentTracker(s, "github")
+	tracker.reconcile(&deployment{env: "staging", gitopsRef: "aaa", state: deploymentSuccess})
+
+	updated := []int64{}
+	update := func(tracked *model.ScmDeployment, d *deployment) error {
+		assert.Equal(t, deploymentSuccess, d.state)
+		updated = append(updated, tracked.DeploymentID)
+		return nil
+	}
+
+	other := &model.ScmDeployment{DeploymentID: 1, Env: "staging", App: "app1", GitopsRef: "bbb"}
+	assert.Nil(t, tracker.track(other))
+	assert.Nil(t, tracker.replayReconciled(other, update))
+	assert.Equal(t, 0, len(updated), "Flux did not report on this commit yet")
+
+	late := &model.ScmDeployment{DeploymentID: 2, Env: "staging", App: "app2", GitopsRef: "aaa"}
+	assert.Nil(t, tracker.track(late))
+	assert.Nil(t, tracker.replayReconciled(late, update))
+	assert.Equal(t, []int64{1, 2}, updated, "a deployment tracked after Flux reported on its commit gets the reported state")
+
+	applied, err := tracker.applied("staging", "aaa")
+	assert.Nil(t, err)
+	assert.Nil(t, applied)
+}
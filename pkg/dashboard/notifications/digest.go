@@ -0,0 +1,164 @@
+package notifications
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// slack allows 50 blocks in a message
+const maxDigestBlocks = 45
+
+// DigestRoute batches the chat notifications of the matching messages
+// into a single summary message in every window
+type DigestRoute struct {
+	// Env is a glob pattern of environment names, eg.: preview-*
+	Env string
+	// Kind is one of deploy, rollback, delete, flux or alert. Empty matches all kinds
+	Kind   string
+	Window time.Duration
+}
+
+func (r *DigestRoute) matches(id identity) bool {
+	if r.Kind != "" && r.Kind != id.kind {
+		return false
+	}
+
+	matched, err := path.Match(r.Env, id.env)
+	if err != nil {
+		return false
+	}
+	return matched
+}
+
+type digest struct {
+	route    *DigestRoute
+	env      string
+	started  time.Time
+	messages []Message
+}
+
+type digestMessage struct {
+	env      string
+	window   time.Duration
+	messages []Message
+}
+
+func (dm *digestMessage) AsSlackMessage() (*slackMessage, error) {
+	if len(dm.messages) == 1 {
+		return dm.messages[0].AsSlackMessage()
+	}
+
+	msg := &slackMessage{
+		Text:   dm.title(),
+		Blocks: []Block{},
+	}
+	msg.Blocks = append(msg.Blocks,
+		Block{
+			Type: section,
+			Text: &Text{
+				Type: markdown,
+				Text: msg.Text,
+			},
+		},
+	)
+
+	for idx, m := range dm.messages {
+		if idx == maxDigestBlocks {
+			msg.Blocks = append(msg.Blocks,
+				Block{
+					Type:     contextString,
+					Elements: []Text{{Type: markdown, Text: fmt.Sprintf("and %d more", len(dm.messages)-idx)}},
+				},
+			)
+			break
+		}
+
+		slackMessage, err := m.AsSlackMessage()
+		if err != nil {
+			return nil, err
+		}
+		if slackMessage == nil {
+			continue
+		}
+		msg.Blocks = append(msg.Blocks,
+			Block{
+				Type:     contextString,
+				Elements: []Text{{Type: markdown, Text: slackMessage.Text}},
+			},
+		)
+	}
+
+	return msg, nil
+}
+
+func (dm *digestMessage) AsDiscordMessage() (*discordMessage, error) {
+	if len(dm.messages) == 1 {
+		return dm.messages[0].AsDiscordMessage()
+	}
+
+	msg := &discordMessage{
+		Text: dm.title(),
+		Embed: &discordgo.MessageEmbed{
+			Type:        "article",
+			Description: "",
+			Color:       0,
+		},
+	}
+
+	for _, m := range dm.messages {
+		discordMessage, err := m.AsDiscordMessage()
+		if err != nil {
+			return nil, err
+		}
+		if discordMessage == nil {
+			continue
+		}
+		msg.Embed.Description += fmt.Sprintf("%s\n", discordMessage.Text)
+	}
+
+	return msg, nil
+}
+
+func (dm *digestMessage) title() string {
+	return fmt.Sprintf("%d notifications on %s in the last %s", len(dm.messages), dm.env, dm.window)
+}
+
+func (dm *digestMessage) AsDeployment() (*deployment, error) {
+	return nil, nil
+}
+
+func (dm *digestMessage) Env() string {
+	return dm.env
+}
+
+func (dm *digestMessage) RepositoryName() string {
+	return ""
+}
+
+func (dm *digestMessage) SHA() string {
+	return ""
+}
+
+func (dm *digestMessage) identity() identity {
+	return identity{
+		kind: "digest",
+		env:  dm.env,
+	}
+}
+
+// statusOnlyMessage hides a digested message from chat providers,
+// while commit statuses are still reported right away
+type statusOnlyMessage struct {
+	Message
+}
+
+func (m *statusOnlyMessage) AsSlackMessage() (*slackMessage, error) {
+	return nil, nil
+}
+
+func (m *statusOnlyMessage) AsDiscordMessage() (*discordMessage, error) {
+	return nil, nil
+}
@@ -31,6 +31,10 @@ func (s *DiscordProvider) send(msg Message) error {
 		return fmt.Errorf("cannot create slack message: %s", err)
 	}
 
+	if discordMessage == nil {
+		return nil
+	}
+
 	channel := s.ChannelID
 	if ch, ok := s.ChannelMapping[msg.Env()]; ok {
 		channel = ch
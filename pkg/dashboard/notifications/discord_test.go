@@ -223,3 +223,28 @@ func TestSendingGitopsRollbackMessage(t *testing.T) {
 	}
 
 }
+
+func TestSendingFluxResourceMessage(t *testing.T) {
+	failed := NewFluxResourceMessage("staging", "HelmRelease", "infrastructure", "redis", "17.3.2", "UpgradeFailed", "Helm upgrade failed")
+
+	discordMessage, err := failed.AsDiscordMessage()
+	if err != nil {
+		t.Errorf("Failed to create Discord message!")
+	}
+	if !strings.Contains(discordMessage.Embed.Description, "HelmRelease infrastructure/redis failed") {
+		t.Errorf("Failed HelmRelease message must contain 'HelmRelease infrastructure/redis failed'")
+	}
+	if !strings.Contains(discordMessage.Embed.Description, "Helm upgrade failed") {
+		t.Errorf("Failed HelmRelease message must contain the failure message")
+	}
+
+	recovered := NewFluxResourceMessage("staging", "HelmRelease", "infrastructure", "redis", "17.3.2", "UpgradeSucceeded", "Helm upgrade succeeded")
+
+	slackMessage, err := recovered.AsSlackMessage()
+	if err != nil {
+		t.Errorf("Failed to create Slack message!")
+	}
+	if !strings.Contains(slackMessage.Text, "recovered on 17.3.2") {
+		t.Errorf("Recovered HelmRelease message must contain 'recovered on 17.3.2'")
+	}
+}
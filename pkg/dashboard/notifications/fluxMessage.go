@@ -82,8 +82,34 @@ func (fm *fluxMessage) Env() string {
 	return fm.env
 }
 
-func (fm *fluxMessage) AsStatus() (*status, error) {
-	return nil, nil
+func (fm *fluxMessage) AsDeployment() (*deployment, error) {
+	var state string
+	switch fm.gitopsCommit.Status {
+	case model.ReconciliationSucceeded:
+		state = deploymentSuccess
+	case model.Progressing:
+		if !strings.Contains(fm.gitopsCommit.StatusDesc, "Health check passed") {
+			return nil, nil
+		}
+		state = deploymentSuccess
+	case model.ValidationFailed, model.ReconciliationFailed, model.HealthCheckFailed:
+		state = deploymentFailure
+	default:
+		return nil, nil
+	}
+
+	description := fm.gitopsCommit.StatusDesc
+	if len(description) > 140 {
+		description = description[:140]
+	}
+
+	return &deployment{
+		env:         fm.env,
+		gitopsRef:   fm.gitopsCommit.Sha,
+		gitopsRepo:  fm.gitopsRepo,
+		state:       state,
+		description: description,
+	}, nil
 }
 
 func (fm *fluxMessage) AsDiscordMessage() (*discordMessage, error) {
@@ -134,3 +160,17 @@ func (fm *fluxMessage) RepositoryName() string {
 func (fm *fluxMessage) SHA() string {
 	return ""
 }
+
+func (fm *fluxMessage) identity() identity {
+	status := fm.gitopsCommit.Status
+	if strings.Contains(fm.gitopsCommit.StatusDesc, "Health check passed") {
+		status = "HealthCheckPassed"
+	}
+
+	return identity{
+		kind:   "flux",
+		env:    fm.env,
+		ref:    fm.gitopsCommit.Sha,
+		status: status,
+	}
+}
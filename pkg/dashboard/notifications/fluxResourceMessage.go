@@ -0,0 +1,123 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fluxResourceMessage reports the failures and recoveries of HelmReleases and Flux sources
+type fluxResourceMessage struct {
+	env        string
+	kind       string
+	namespace  string
+	name       string
+	revision   string
+	status     string
+	statusDesc string
+}
+
+func (fm *fluxResourceMessage) failed() bool {
+	return strings.HasSuffix(fm.status, "Failed")
+}
+
+func (fm *fluxResourceMessage) title() string {
+	if fm.failed() {
+		return fmt.Sprintf(":exclamation: %s %s/%s failed", fm.kind, fm.namespace, fm.name)
+	}
+	if fm.revision != "" {
+		return fmt.Sprintf(":heavy_check_mark: %s %s/%s recovered on %s", fm.kind, fm.namespace, fm.name, fm.revision)
+	}
+	return fmt.Sprintf(":heavy_check_mark: %s %s/%s recovered", fm.kind, fm.namespace, fm.name)
+}
+
+func (fm *fluxResourceMessage) AsSlackMessage() (*slackMessage, error) {
+	msg := &slackMessage{
+		Text:   fm.title(),
+		Blocks: []Block{},
+	}
+
+	msg.Blocks = append(msg.Blocks,
+		Block{
+			Type: section,
+			Text: &Text{
+				Type: markdown,
+				Text: msg.Text,
+			},
+		},
+	)
+	if fm.failed() && fm.statusDesc != "" {
+		msg.Blocks = append(msg.Blocks,
+			Block{
+				Type: contextString,
+				Elements: []Text{
+					{
+						Type: markdown,
+						Text: fm.statusDesc,
+					},
+				},
+			},
+		)
+	}
+
+	return msg, nil
+}
+
+func (fm *fluxResourceMessage) AsDiscordMessage() (*discordMessage, error) {
+	msg := &discordMessage{
+		Text: fmt.Sprintf("%s %s", fm.kind, fm.name),
+		Embed: &discordgo.MessageEmbed{
+			Type:        "article",
+			Description: fm.title(),
+			Color:       3066993,
+		},
+	}
+
+	if fm.failed() {
+		msg.Embed.Color = 15158332
+		if fm.statusDesc != "" {
+			msg.Embed.Description += "\n" + fm.statusDesc
+		}
+	}
+
+	return msg, nil
+}
+
+func (fm *fluxResourceMessage) AsDeployment() (*deployment, error) {
+	return nil, nil
+}
+
+func (fm *fluxResourceMessage) Env() string {
+	return fm.env
+}
+
+func (fm *fluxResourceMessage) RepositoryName() string {
+	return ""
+}
+
+func (fm *fluxResourceMessage) SHA() string {
+	return ""
+}
+
+func (fm *fluxResourceMessage) identity() identity {
+	return identity{
+		kind:   "fluxResource",
+		env:    fm.env,
+		ref:    fm.kind + "/" + fm.namespace + "/" + fm.name + "@" + fm.revision,
+		status: fm.status,
+	}
+}
+
+// NewFluxResourceMessage is sent when a HelmRelease or a Flux source turns failed, or recovers
+func NewFluxResourceMessage(env, kind, namespace, name, revision, status, statusDesc string) Message {
+	return &fluxResourceMessage{
+		env:        env,
+		kind:       kind,
+		namespace:  namespace,
+		name:       name,
+		revision:   revision,
+		status:     status,
+		statusDesc: statusDesc,
+	}
+}
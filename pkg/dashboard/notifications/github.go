@@ -4,103 +4,201 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
 	"github.com/gimlet-io/gimlet-cli/pkg/git/customScm"
 	"github.com/google/go-github/v37/github"
-	githubLib "github.com/google/go-github/v37/github"
 	"golang.org/x/oauth2"
 )
 
 const githubCommitLink = "https://github.com/%s/commit/%s"
 
 type githubProvider struct {
-	tokenManager customScm.NonImpersonatedTokenManager
+	tokenManager   customScm.NonImpersonatedTokenManager
+	environmentURL EnvironmentURL
+	deployments    *deploymentTracker
+
+	// lock serializes the sends, so a deployment is tracked before a Flux status looks it up
+	lock sync.Mutex
 }
 
-func NewGithubProvider(tokenManager customScm.NonImpersonatedTokenManager) *githubProvider {
+func NewGithubProvider(
+	tokenManager customScm.NonImpersonatedTokenManager,
+	environmentURL EnvironmentURL,
+	store *store.Store,
+) *githubProvider {
 	return &githubProvider{
-		tokenManager: tokenManager,
+		tokenManager:   tokenManager,
+		environmentURL: environmentURL,
+		deployments:    newDeploymentTracker(store, "github"),
 	}
 }
 
 func (g *githubProvider) send(msg Message) error {
-	status, err := msg.AsStatus()
+	d, err := msg.AsDeployment()
 	if err != nil {
-		return fmt.Errorf("cannot create github status message: %s", err)
+		return fmt.Errorf("cannot create github deployment: %s", err)
 	}
 
-	if status == nil {
+	if d == nil {
 		return nil
 	}
 
-	repositoryName := msg.RepositoryName()
-	parts := strings.Split(repositoryName, "/")
-	if len(parts) != 2 {
-		return fmt.Errorf("cannot determine repo owner and name")
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	client, err := g.client(ctx)
+	if err != nil {
+		return err
 	}
-	owner := parts[0]
-	repo := parts[1]
 
-	sha := msg.SHA()
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	switch {
+	case d.repo != "" || d.sha != "":
+		return g.create(ctx, client, d)
+	case d.gitopsRef != "":
+		g.deployments.reconcile(d)
+		applied, err := g.deployments.applied(d.env, d.gitopsRef)
+		if err != nil {
+			return fmt.Errorf("cannot get tracked deployments: %s", err)
+		}
+		for _, tracked := range applied {
+			err := g.updateStatus(ctx, client, tracked, d)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tracked, err := g.deployments.forApp(d.env, d.app)
+		if err != nil {
+			return fmt.Errorf("cannot get tracked deployment: %s", err)
+		}
+		if tracked == nil {
+			return nil
+		}
+		err = g.deployments.forget(d.env, d.app)
+		if err != nil {
+			return fmt.Errorf("cannot forget tracked deployments: %s", err)
+		}
+		return g.updateStatus(ctx, client, tracked, d)
+	}
+}
 
-	targetURL := fmt.Sprintf(githubCommitLink, status.repo, status.sha)
-	if status.state == "failure" {
-		targetURL = ""
+// create opens a deployment in the source repo of the app
+func (g *githubProvider) create(ctx context.Context, client *github.Client, d *deployment) error {
+	repository := d.repo
+	ref := d.ref
+	if repository == "" { // rollbacks know only the target sha, the repo comes from the previous deployment
+		previous, err := g.deployments.forApp(d.env, d.app)
+		if err != nil {
+			return fmt.Errorf("cannot get tracked deployment: %s", err)
+		}
+		if previous == nil {
+			return nil
+		}
+		repository = previous.Repo
+		ref = previous.Ref
 	}
 
-	urlPtr := &targetURL
-	if targetURL == "" {
-		urlPtr = nil
+	owner, repo, err := splitRepositoryName(repository)
+	if err != nil {
+		return err
 	}
 
-	return g.post(owner, repo, sha, &githubLib.RepoStatus{
-		State:       &status.state,
-		Context:     &status.context,
-		Description: &status.description,
-		TargetURL:   urlPtr,
+	autoMerge := false
+	task := "deploy"
+	requiredContexts := []string{}
+	created, _, err := client.Repositories.CreateDeployment(ctx, owner, repo, &github.DeploymentRequest{
+		Ref:              &d.sha,
+		Task:             &task,
+		AutoMerge:        &autoMerge,
+		RequiredContexts: &requiredContexts,
+		Environment:      &d.env,
+		Description:      &d.description,
+		Payload: map[string]string{
+			"app":       d.app,
+			"gitopsRef": d.gitopsRef,
+		},
 	})
-}
+	if err != nil {
+		return fmt.Errorf("could not create deployment: %v", err)
+	}
 
-func (g *githubProvider) post(owner string, repo string, sha string, status *githubLib.RepoStatus) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
+	tracked := &model.ScmDeployment{
+		DeploymentID: created.GetID(),
+		Repo:         repository,
+		Ref:          ref,
+		Env:          d.env,
+		App:          d.app,
+		GitopsRef:    d.gitopsRef,
+	}
+	if d.state == deploymentFailure {
+		return g.updateStatus(ctx, client, tracked, d)
+	}
 
-	token, _, err := g.tokenManager.Token()
+	err = g.deployments.track(tracked)
 	if err != nil {
-		return fmt.Errorf("couldn't get scm token: %s", err)
+		return fmt.Errorf("cannot track deployment: %s", err)
+	}
+	err = g.updateStatus(ctx, client, tracked, d)
+	if err != nil {
+		return err
 	}
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
 
-	opts := &github.ListOptions{PerPage: 50}
-	statuses, _, err := client.Repositories.ListStatuses(ctx, owner, repo, sha, opts)
+	return g.deployments.replayReconciled(tracked, func(tracked *model.ScmDeployment, d *deployment) error {
+		return g.updateStatus(ctx, client, tracked, d)
+	})
+}
+
+func (g *githubProvider) updateStatus(ctx context.Context, client *github.Client, tracked *model.ScmDeployment, d *deployment) error {
+	owner, repo, err := splitRepositoryName(tracked.Repo)
 	if err != nil {
-		return fmt.Errorf("could not list commit statuses: %v", err)
+		return err
 	}
-	if statusExists(statuses, status) {
-		return nil
+
+	status := &github.DeploymentStatusRequest{
+		State:       &d.state,
+		Description: &d.description,
+		Environment: &tracked.Env,
+	}
+	if d.gitopsRepo != "" && d.gitopsRef != "" {
+		logURL := fmt.Sprintf(githubCommitLink, d.gitopsRepo, d.gitopsRef)
+		status.LogURL = &logURL
+	}
+	if d.state == deploymentSuccess && g.environmentURL != nil {
+		if environmentURL := g.environmentURL(tracked.Env, tracked.App); environmentURL != "" {
+			status.EnvironmentURL = &environmentURL
+		}
 	}
 
-	_, _, err = client.Repositories.CreateStatus(ctx, owner, repo, sha, status)
+	_, _, err = client.Repositories.CreateDeploymentStatus(ctx, owner, repo, tracked.DeploymentID, status)
 	if err != nil {
-		return fmt.Errorf("could not create commit status: %v", err)
+		return fmt.Errorf("could not create deployment status: %v", err)
 	}
 
 	return nil
 }
 
-func statusExists(statuses []*github.RepoStatus, status *github.RepoStatus) bool {
-	for _, s := range statuses {
-		if *s.Context == *status.Context {
-			if *s.State == *status.State && *s.Description == *status.Description {
-				return true
-			}
-
-			return false
-		}
+func (g *githubProvider) client(ctx context.Context) (*github.Client, error) {
+	token, _, err := g.tokenManager.Token()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get scm token: %s", err)
 	}
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	tc := oauth2.NewClient(ctx, ts)
+	return github.NewClient(tc), nil
+}
 
-	return false
+func splitRepositoryName(repositoryName string) (string, string, error) {
+	parts := strings.Split(repositoryName, "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("cannot determine repo owner and name")
+	}
+	return parts[0], parts[1], nil
 }
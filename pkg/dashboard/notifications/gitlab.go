@@ -2,8 +2,10 @@ package notifications
 
 import (
 	"fmt"
-	"strings"
+	"sync"
 
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
 	"github.com/gimlet-io/gimlet-cli/pkg/git/customScm"
 	"github.com/gimlet-io/go-scm/scm"
 	"github.com/xanzy/go-gitlab"
@@ -12,66 +14,207 @@ import (
 const gitlabCommitLink = "%s/%s/-/commit/%s"
 
 type gitlabProvider struct {
-	tokenManager customScm.NonImpersonatedTokenManager
-	baseUrl      string
+	tokenManager   customScm.NonImpersonatedTokenManager
+	baseUrl        string
+	environmentURL EnvironmentURL
+	deployments    *deploymentTracker
+
+	// lock serializes the sends, so a deployment is tracked before a Flux status looks it up
+	lock sync.Mutex
 }
 
 func NewGitlabProvider(
 	tokenManager customScm.NonImpersonatedTokenManager,
 	baseUrl string,
+	environmentURL EnvironmentURL,
+	store *store.Store,
 ) *gitlabProvider {
 	return &gitlabProvider{
-		tokenManager: tokenManager,
-		baseUrl:      baseUrl,
+		tokenManager:   tokenManager,
+		baseUrl:        baseUrl,
+		environmentURL: environmentURL,
+		deployments:    newDeploymentTracker(store, "gitlab"),
 	}
 }
 
 func (g *gitlabProvider) send(msg Message) error {
-	status, err := msg.AsStatus()
+	d, err := msg.AsDeployment()
 	if err != nil {
-		return fmt.Errorf("cannot create github status message: %s", err)
+		return fmt.Errorf("cannot create gitlab deployment: %s", err)
 	}
 
-	if status == nil {
+	if d == nil {
 		return nil
 	}
 
-	repositoryName := msg.RepositoryName()
-	parts := strings.Split(repositoryName, "/")
-	if len(parts) != 2 {
-		return fmt.Errorf("cannot determine repo owner and name")
+	token, _, _ := g.tokenManager.Token()
+	git, err := gitlab.NewClient(token, gitlab.WithBaseURL(g.baseUrl))
+	if err != nil {
+		return fmt.Errorf("couldn't create gitlab client: %s", err)
 	}
-	owner := parts[0]
-	repo := parts[1]
 
-	sha := msg.SHA()
+	g.lock.Lock()
+	defer g.lock.Unlock()
 
-	return g.post(owner, repo, sha, status)
+	switch {
+	case d.repo != "" || d.sha != "":
+		return g.create(git, d)
+	case d.gitopsRef != "":
+		g.deployments.reconcile(d)
+		applied, err := g.deployments.applied(d.env, d.gitopsRef)
+		if err != nil {
+			return fmt.Errorf("cannot get tracked deployments: %s", err)
+		}
+		for _, tracked := range applied {
+			err := g.updateStatus(git, tracked, d)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		// GitLab stops environments, not deployments. Environments are shared
+		// by every app of the project, so deleting one app must not stop them
+		err := g.deployments.forget(d.env, d.app)
+		if err != nil {
+			return fmt.Errorf("cannot forget tracked deployments: %s", err)
+		}
+		return nil
+	}
 }
 
-func (g *gitlabProvider) post(owner string, repo string, sha string, status *status) error {
-	token, _, _ := g.tokenManager.Token()
-	git, err := gitlab.NewClient(token, gitlab.WithBaseURL(g.baseUrl))
+// create opens a deployment on the environment in the source repo of the app
+func (g *gitlabProvider) create(git *gitlab.Client, d *deployment) error {
+	repository := d.repo
+	ref := d.ref
+	if repository == "" { // rollbacks know only the target sha, the repo comes from the previous deployment
+		previous, err := g.deployments.forApp(d.env, d.app)
+		if err != nil {
+			return fmt.Errorf("cannot get tracked deployment: %s", err)
+		}
+		if previous == nil {
+			return nil
+		}
+		repository = previous.Repo
+		ref = previous.Ref
+	}
+
+	owner, repo, err := splitRepositoryName(repository)
 	if err != nil {
-		return fmt.Errorf("couldn't create gitlab client: %s", err)
+		return err
+	}
+	project := scm.Join(owner, repo)
+
+	err = g.ensureEnvironment(git, project, d.env, d.app)
+	if err != nil {
+		return err
+	}
+
+	if ref == "" {
+		ref = d.sha
+	}
+	status := gitlab.DeploymentStatusRunning
+	if d.state == deploymentFailure {
+		status = gitlab.DeploymentStatusFailed
+	}
+	created, _, err := git.Deployments.CreateProjectDeployment(project, &gitlab.CreateProjectDeploymentOptions{
+		Environment: &d.env,
+		Ref:         &ref,
+		SHA:         &d.sha,
+		Status:      &status,
+	})
+	if err != nil {
+		return fmt.Errorf("could not create deployment: %v", err)
+	}
+
+	if d.state == deploymentFailure {
+		return nil
+	}
+
+	tracked := &model.ScmDeployment{
+		DeploymentID: int64(created.ID),
+		Repo:         repository,
+		Ref:          ref,
+		Env:          d.env,
+		App:          d.app,
+		GitopsRef:    d.gitopsRef,
+	}
+	err = g.deployments.track(tracked)
+	if err != nil {
+		return fmt.Errorf("cannot track deployment: %s", err)
 	}
 
-	targetURL := fmt.Sprintf(gitlabCommitLink, g.baseUrl, status.repo, status.sha)
-	if status.state == "failure" {
-		targetURL = ""
+	return g.deployments.replayReconciled(tracked, func(tracked *model.ScmDeployment, d *deployment) error {
+		return g.updateStatus(git, tracked, d)
+	})
+}
+
+func (g *gitlabProvider) updateStatus(git *gitlab.Client, tracked *model.ScmDeployment, d *deployment) error {
+	owner, repo, err := splitRepositoryName(tracked.Repo)
+	if err != nil {
+		return err
 	}
 
-	_, _, err = git.Commits.SetCommitStatus(
+	var status gitlab.DeploymentStatusValue
+	switch d.state {
+	case deploymentSuccess:
+		status = gitlab.DeploymentStatusSuccess
+	case deploymentFailure:
+		status = gitlab.DeploymentStatusFailed
+	default:
+		return nil
+	}
+
+	_, _, err = git.Deployments.UpdateProjectDeployment(
 		scm.Join(owner, repo),
-		sha,
-		&gitlab.SetCommitStatusOptions{
-			State:       gitlab.BuildStateValue(status.state),
-			Name:        &status.context,
-			Context:     &status.context,
-			TargetURL:   &targetURL,
-			Description: &status.description,
-		},
+		int(tracked.DeploymentID),
+		&gitlab.UpdateProjectDeploymentOptions{Status: &status},
 	)
+	if err != nil {
+		return fmt.Errorf("could not update deployment: %v", err)
+	}
+
+	return nil
+}
+
+// ensureEnvironment creates the environment in the project, and keeps its url up to date
+func (g *gitlabProvider) ensureEnvironment(git *gitlab.Client, project string, env string, app string) error {
+	var externalURL string
+	if g.environmentURL != nil {
+		externalURL = g.environmentURL(env, app)
+	}
+
+	environments, _, err := git.Environments.ListEnvironments(project, &gitlab.ListEnvironmentsOptions{
+		Name: &env,
+	})
+	if err != nil {
+		return fmt.Errorf("could not list environments: %v", err)
+	}
+
+	for _, environment := range environments {
+		if environment.Name != env {
+			continue
+		}
+		if externalURL == "" || environment.ExternalURL == externalURL {
+			return nil
+		}
+		_, _, err = git.Environments.EditEnvironment(project, environment.ID, &gitlab.EditEnvironmentOptions{
+			ExternalURL: &externalURL,
+		})
+		if err != nil {
+			return fmt.Errorf("could not update environment: %v", err)
+		}
+		return nil
+	}
+
+	options := &gitlab.CreateEnvironmentOptions{Name: &env}
+	if externalURL != "" {
+		options.ExternalURL = &externalURL
+	}
+	_, _, err = git.Environments.CreateEnvironment(project, options)
+	if err != nil {
+		return fmt.Errorf("could not create environment: %v", err)
+	}
 
-	return err
+	return nil
 }
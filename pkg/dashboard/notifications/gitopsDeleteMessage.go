@@ -69,8 +69,16 @@ func (gm *gitopsDeleteMessage) Env() string {
 	return gm.result.Manifest.Env
 }
 
-func (gm *gitopsDeleteMessage) AsStatus() (*status, error) {
-	return nil, nil
+func (gm *gitopsDeleteMessage) AsDeployment() (*deployment, error) {
+	if gm.result.Status == model.Failure {
+		return nil, nil
+	}
+
+	return &deployment{
+		env:   gm.result.Manifest.Env,
+		app:   gm.result.Manifest.App,
+		state: deploymentInactive,
+	}, nil
 }
 
 func (gm *gitopsDeleteMessage) AsDiscordMessage() (*discordMessage, error) {
@@ -112,3 +120,12 @@ func (gm *gitopsDeleteMessage) RepositoryName() string {
 func (gm *gitopsDeleteMessage) SHA() string {
 	return ""
 }
+
+func (gm *gitopsDeleteMessage) identity() identity {
+	return identity{
+		kind:   "delete",
+		env:    gm.result.Manifest.Env,
+		app:    gm.result.Manifest.App,
+		status: gm.result.Status.String(),
+	}
+}
@@ -3,14 +3,11 @@ package notifications
 import (
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 )
 
-const contextFormat = "gitops/%s@%s"
-
 type gitopsDeployMessage struct {
 	event model.Result
 }
@@ -86,24 +83,32 @@ func (gm *gitopsDeployMessage) Env() string {
 	return gm.event.Manifest.Env
 }
 
-func (gm *gitopsDeployMessage) AsStatus() (*status, error) {
-	context := fmt.Sprintf(contextFormat, gm.event.Manifest.Env, time.Now().Format(time.RFC3339))
-	desc := gm.event.StatusDesc
-	if len(desc) > 140 {
-		desc = desc[:140]
+func (gm *gitopsDeployMessage) AsDeployment() (*deployment, error) {
+	description := gm.event.StatusDesc
+	if len(description) > 140 {
+		description = description[:140]
 	}
 
-	state := "success"
+	state := deploymentInProgress
 	if gm.event.Status == model.Failure {
-		state = "failure"
+		state = deploymentFailure
+	}
+
+	ref := gm.event.Artifact.Version.Branch
+	if gm.event.Artifact.Version.Tag != "" {
+		ref = gm.event.Artifact.Version.Tag
 	}
 
-	return &status{
+	return &deployment{
+		env:         gm.event.Manifest.Env,
+		app:         gm.event.Manifest.App,
+		repo:        gm.event.Artifact.Version.RepositoryName,
+		ref:         ref,
+		sha:         gm.event.Artifact.Version.SHA,
+		gitopsRef:   gm.event.GitopsRef,
+		gitopsRepo:  gm.event.GitopsRepo,
 		state:       state,
-		context:     context,
-		description: desc,
-		repo:        gm.event.GitopsRepo,
-		sha:         gm.event.GitopsRef,
+		description: description,
 	}, nil
 }
 
@@ -159,3 +164,13 @@ func (gm *gitopsDeployMessage) RepositoryName() string {
 func (gm *gitopsDeployMessage) SHA() string {
 	return gm.event.Artifact.Version.SHA
 }
+
+func (gm *gitopsDeployMessage) identity() identity {
+	return identity{
+		kind:   "deploy",
+		env:    gm.event.Manifest.Env,
+		app:    gm.event.Manifest.App,
+		ref:    gm.event.Artifact.Version.SHA,
+		status: gm.event.Status.String(),
+	}
+}
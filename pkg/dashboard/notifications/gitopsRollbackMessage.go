@@ -57,8 +57,21 @@ func (gm *gitopsRollbackMessage) Env() string {
 	return gm.rollbackRequest.Env
 }
 
-func (gm *gitopsRollbackMessage) AsStatus() (*status, error) {
-	return nil, nil
+func (gm *gitopsRollbackMessage) AsDeployment() (*deployment, error) {
+	if len(gm.event.Results) == 0 {
+		return nil, nil
+	}
+	latest := gm.event.Results[0] // the gitops repo log is walked from HEAD
+
+	return &deployment{
+		env:         gm.rollbackRequest.Env,
+		app:         gm.rollbackRequest.App,
+		sha:         gm.rollbackRequest.TargetSHA,
+		gitopsRef:   latest.GitopsRef,
+		gitopsRepo:  latest.GitopsRepo,
+		state:       deploymentInProgress,
+		description: fmt.Sprintf("Rolled back by %s", gm.rollbackRequest.TriggeredBy),
+	}, nil
 }
 
 func (gm *gitopsRollbackMessage) AsDiscordMessage() (*discordMessage, error) {
@@ -107,3 +120,13 @@ func (gm *gitopsRollbackMessage) RepositoryName() string {
 func (gm *gitopsRollbackMessage) SHA() string {
 	return ""
 }
+
+func (gm *gitopsRollbackMessage) identity() identity {
+	return identity{
+		kind:   "rollback",
+		env:    gm.rollbackRequest.Env,
+		app:    gm.rollbackRequest.App,
+		ref:    gm.rollbackRequest.TargetSHA,
+		status: gm.event.Status,
+	}
+}
@@ -1,9 +1,14 @@
 package notifications
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/sirupsen/logrus"
 )
 
+const defaultDeduplicationWindow = 5 * time.Minute
+
 type Manager interface {
 	Broadcast(msg Message)
 	AddProvider(provider Provider)
@@ -12,6 +17,11 @@ type Manager interface {
 type ManagerImpl struct {
 	provider  []Provider
 	broadcast chan Message
+
+	digestRoutes        []*DigestRoute
+	deduplicationWindow time.Duration
+	sent                map[identity]time.Time
+	digests             map[string]*digest
 }
 
 type DummyManagerImpl struct {
@@ -19,8 +29,12 @@ type DummyManagerImpl struct {
 
 func NewManager() *ManagerImpl {
 	return &ManagerImpl{
-		provider:  []Provider{},
-		broadcast: make(chan Message),
+		provider:            []Provider{},
+		broadcast:           make(chan Message),
+		digestRoutes:        []*DigestRoute{},
+		deduplicationWindow: defaultDeduplicationWindow,
+		sent:                map[identity]time.Time{},
+		digests:             map[string]*digest{},
 	}
 }
 
@@ -42,18 +56,119 @@ func (m *ManagerImpl) AddProvider(provider Provider) {
 	m.provider = append(m.provider, provider)
 }
 
+// AddDigestRoute batches the matching messages into summaries. The first matching route wins
+func (m *ManagerImpl) AddDigestRoute(route DigestRoute) {
+	m.digestRoutes = append(m.digestRoutes, &route)
+}
+
+// SetDeduplicationWindow sets the time window in which identical messages are sent only once
+func (m *ManagerImpl) SetDeduplicationWindow(window time.Duration) {
+	m.deduplicationWindow = window
+}
+
 func (m *ManagerImpl) Run() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case message := <-m.broadcast:
-			for _, p := range m.provider {
-				go func(p Provider) {
-					err := p.send(message)
-					if err != nil {
-						logrus.Warnf("cannot send notification: %s ", err)
-					}
-				}(p)
-			}
+			m.handle(message, time.Now())
+		case now := <-ticker.C:
+			m.flush(now)
+		}
+	}
+}
+
+func (m *ManagerImpl) handle(message Message, now time.Time) {
+	route := m.route(message)
+	if m.duplicate(message, route, now) {
+		return
+	}
+
+	if route == nil {
+		m.send(message)
+		return
+	}
+
+	m.send(&statusOnlyMessage{message})
+
+	key := fmt.Sprintf("%s/%s/%s", route.Env, route.Kind, message.Env())
+	d, ok := m.digests[key]
+	if !ok {
+		d = &digest{
+			route:   route,
+			env:     message.Env(),
+			started: now,
+		}
+		m.digests[key] = d
+	}
+	d.messages = append(d.messages, message)
+}
+
+// flush sends the digests whose window is over
+func (m *ManagerImpl) flush(now time.Time) {
+	for key, d := range m.digests {
+		if now.Sub(d.started) < d.route.Window {
+			continue
 		}
+
+		delete(m.digests, key)
+		m.send(&digestMessage{
+			env:      d.env,
+			window:   d.route.Window,
+			messages: d.messages,
+		})
+	}
+
+	for id, sent := range m.sent {
+		if now.Sub(sent) > m.longestWindow() {
+			delete(m.sent, id)
+		}
+	}
+}
+
+func (m *ManagerImpl) route(message Message) *DigestRoute {
+	for _, r := range m.digestRoutes {
+		if r.matches(message.identity()) {
+			return r
+		}
+	}
+	return nil
+}
+
+func (m *ManagerImpl) duplicate(message Message, route *DigestRoute, now time.Time) bool {
+	window := m.deduplicationWindow
+	if route != nil && route.Window > window {
+		window = route.Window
+	}
+
+	id := message.identity()
+	if sent, ok := m.sent[id]; ok && now.Sub(sent) < window {
+		return true
+	}
+
+	m.sent[id] = now
+	return false
+}
+
+func (m *ManagerImpl) longestWindow() time.Duration {
+	longest := m.deduplicationWindow
+	for _, r := range m.digestRoutes {
+		if r.Window > longest {
+			longest = r.Window
+		}
+	}
+	return longest
+}
+
+func (m *ManagerImpl) send(message Message) {
+	for _, p := range m.provider {
+		go func(p Provider) {
+			err := p.send(message)
+			if err != nil {
+				logrus.Warnf("cannot send notification: %s ", err)
+			}
+		}(p)
 	}
 }
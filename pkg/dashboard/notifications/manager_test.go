@@ -0,0 +1,105 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+)
+
+type recordingProvider struct {
+	messages chan Message
+}
+
+func (p *recordingProvider) send(msg Message) error {
+	p.messages <- msg
+	return nil
+}
+
+func (p *recordingProvider) received(t *testing.T, count int) []Message {
+	messages := []Message{}
+	for i := 0; i < count; i++ {
+		select {
+		case msg := <-p.messages:
+			messages = append(messages, msg)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d messages, got %d", count, len(messages))
+		}
+	}
+
+	select {
+	case msg := <-p.messages:
+		t.Fatalf("unexpected message: %v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return messages
+}
+
+func deployMessage(env string, app string, sha string) Message {
+	return DeployMessageFromGitOpsResult(model.Result{
+		Manifest:    &dx.Manifest{Env: env, App: app},
+		Artifact:    &dx.Artifact{Version: dx.Version{SHA: sha, RepositoryName: "my-app"}},
+		TriggeredBy: "policy",
+	})
+}
+
+func TestDeduplication(t *testing.T) {
+	provider := &recordingProvider{messages: make(chan Message, 10)}
+	m := NewManager()
+	m.AddProvider(provider)
+
+	now := time.Now()
+	m.handle(deployMessage("staging", "app", "sha1"), now)
+	m.handle(deployMessage("staging", "app", "sha1"), now.Add(time.Minute))
+	m.handle(deployMessage("staging", "app", "sha2"), now.Add(time.Minute))
+	provider.received(t, 2)
+
+	m.flush(now.Add(10 * time.Minute))
+	m.handle(deployMessage("staging", "app", "sha1"), now.Add(10*time.Minute))
+	provider.received(t, 1)
+}
+
+func TestDigest(t *testing.T) {
+	provider := &recordingProvider{messages: make(chan Message, 10)}
+	m := NewManager()
+	m.AddProvider(provider)
+	m.AddDigestRoute(DigestRoute{Env: "preview-*", Kind: "deploy", Window: 15 * time.Minute})
+
+	now := time.Now()
+	m.handle(deployMessage("preview-1", "app", "sha1"), now)
+	m.handle(deployMessage("preview-1", "app", "sha2"), now.Add(time.Minute))
+	m.handle(deployMessage("production", "app", "sha1"), now.Add(time.Minute))
+
+	messages := provider.received(t, 3)
+	chatMessages := 0
+	for _, msg := range messages {
+		slackMsg, _ := msg.AsSlackMessage()
+		if slackMsg != nil {
+			chatMessages++
+			if msg.Env() != "production" {
+				t.Errorf("only the production message should be sent right away")
+			}
+		}
+	}
+	if chatMessages != 1 {
+		t.Errorf("digested messages must not reach chat providers, got %d", chatMessages)
+	}
+
+	m.flush(now.Add(5 * time.Minute))
+	provider.received(t, 0)
+
+	m.flush(now.Add(15 * time.Minute))
+	messages = provider.received(t, 1)
+	slackMsg, err := messages[0].AsSlackMessage()
+	if err != nil {
+		t.Fatalf("cannot create slack message: %s", err)
+	}
+	if !strings.Contains(slackMsg.Text, "2 notifications on preview-1") {
+		t.Errorf("unexpected digest: %s", slackMsg.Text)
+	}
+	if len(slackMsg.Blocks) != 3 {
+		t.Errorf("digest should list both messages, got %d blocks", len(slackMsg.Blocks))
+	}
+}
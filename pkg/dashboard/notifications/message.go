@@ -2,17 +2,20 @@ package notifications
 
 type Message interface {
 	AsSlackMessage() (*slackMessage, error)
-	AsStatus() (*status, error)
+	AsDeployment() (*deployment, error)
 	AsDiscordMessage() (*discordMessage, error)
 	Env() string
 	RepositoryName() string
 	SHA() string
+	identity() identity
 }
 
-type status struct {
-	state       string
-	context     string
-	description string
-	repo        string
-	sha         string
+// identity tells which messages are duplicates of each other,
+// and it is what digest routes match on
+type identity struct {
+	kind   string
+	env    string
+	app    string
+	ref    string
+	status string
 }
@@ -12,9 +12,12 @@ import (
 	"github.com/gimlet-io/gimlet-cli/pkg/agent"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/alert"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/gitops"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/notifications"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/gimlet-io/gimlet-cli/pkg/git/nativeGit"
 	"github.com/go-chi/chi"
 	"github.com/sirupsen/logrus"
 )
@@ -124,6 +127,8 @@ func broadcastAgentDisconnectedEvent(clientHub *streaming.ClientHub, a *streamin
 }
 
 func events(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
 	var events []api.Event
 	err := json.NewDecoder(r.Body).Decode(&events)
 	if err != nil {
@@ -134,7 +139,7 @@ func events(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 
 	alertStateManager, _ := r.Context().Value("alertStateManager").(*alert.AlertStateManager)
-	err = alertStateManager.TrackEvents(events)
+	err = alertStateManager.TrackEvents(name, events)
 	if err != nil {
 		logrus.Errorf("cannot track events: %s", err)
 		http.Error(w, http.StatusText(500), 500)
@@ -157,7 +162,7 @@ func state(w http.ResponseWriter, r *http.Request) {
 
 	// alertStateManager, _ := r.Context().Value("alertStateManager").(*alert.AlertStateManager)
 	// for _, stack := range stacks {
-	// 	err := alertStateManager.TrackPods(stack.Deployment.Pods)
+	// 	err := alertStateManager.TrackPods(name, stack.Deployment.Pods)
 	// 	if err != nil {
 	// 		logrus.Errorf("cannot track pods: %s", err)
 	// 		http.Error(w, http.StatusText(500), 500)
@@ -165,33 +170,93 @@ func state(w http.ResponseWriter, r *http.Request) {
 	// 	}
 	// }
 
-	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
-	agent := agentHub.Agents[name]
-	if agent == nil {
-		time.Sleep(1 * time.Second) // Agenthub has a race condition. Registration is not done when the client sends the state
-		agent = agentHub.Agents[name]
-	}
-
 	stackPointers := []*api.Stack{}
 	for _, s := range stacks {
 		copy := s       // needed as the address of s is constant in the for loop
 		copy.Env = name // making the service aware of its env
 		stackPointers = append(stackPointers, &copy)
 	}
-	agent.Stacks = stackPointers
 
-	envs := []*api.ConnectedAgent{{
-		Name:      name,
-		Stacks:    stackPointers,
-		FluxState: agent.FluxState,
-	}}
+	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
+	agent, err := updateRegisteredAgent(agentHub, name, func(agent *streaming.ConnectedAgent) error {
+		agent.Stacks = stackPointers
+		agent.StateSession = "" // agents sending full states don't version them
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("cannot update the state of %s: %s", name, err)
+		return
+	}
 
-	err = decorateDeployments(r.Context(), envs)
+	err = broadcastEnvState(r, agent)
 	if err != nil {
 		logrus.Errorf("cannot decorate deployments: %s", err)
 		http.Error(w, http.StatusText(500), 500)
 		return
 	}
+}
+
+// stateDelta applies the changes of an env's state since the last acknowledged version.
+// Conflict is returned if the delta is based on a version the dashboard doesn't know,
+// in which case the agent sends its state in full
+func stateDelta(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	var delta api.StateDelta
+	err := json.NewDecoder(r.Body).Decode(&delta)
+	if err != nil {
+		logrus.Errorf("cannot decode state delta: %s", err)
+		http.Error(w, http.StatusText(400), 400)
+		return
+	}
+
+	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
+	agent, err := updateRegisteredAgent(agentHub, name, func(agent *streaming.ConnectedAgent) error {
+		return agent.ApplyStateDelta(&delta)
+	})
+	if err == streaming.ErrAgentNotRegistered {
+		http.Error(w, http.StatusText(http.StatusConflict)+" - "+err.Error(), http.StatusConflict)
+		return
+	} else if err == streaming.ErrStateVersionMismatch {
+		logrus.Infof("state delta of %s is based on an unknown version, requesting full state", name)
+		http.Error(w, http.StatusText(http.StatusConflict)+" - "+err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if !delta.Full && len(delta.Upserted) == 0 && len(delta.Deleted) == 0 {
+		return
+	}
+
+	err = broadcastEnvState(r, agent)
+	if err != nil {
+		logrus.Errorf("cannot decorate deployments: %s", err)
+	}
+}
+
+// updateRegisteredAgent updates the state of an agent. Registration may not be done yet when the agent sends its state,
+// so the update is retried once after a second
+func updateRegisteredAgent(agentHub *streaming.AgentHub, name string, fn func(agent *streaming.ConnectedAgent) error) (*streaming.ConnectedAgent, error) {
+	agent, err := agentHub.UpdateAgent(name, fn)
+	if err == streaming.ErrAgentNotRegistered {
+		time.Sleep(1 * time.Second)
+		agent, err = agentHub.UpdateAgent(name, fn)
+	}
+	return agent, err
+}
+
+func broadcastEnvState(r *http.Request, agent *streaming.ConnectedAgent) error {
+	envs := []*api.ConnectedAgent{{
+		Name:      agent.Name,
+		Stacks:    agent.Stacks,
+		FluxState: agent.FluxState,
+		Drifts:    agent.Drifts,
+	}}
+
+	err := decorateDeployments(r.Context(), envs)
+	if err != nil {
+		return err
+	}
 
 	clientHub, _ := r.Context().Value("clientHub").(*streaming.ClientHub)
 	jsonString, _ := json.Marshal(streaming.EnvsUpdatedEvent{
@@ -199,6 +264,7 @@ func state(w http.ResponseWriter, r *http.Request) {
 		Envs:           envs,
 	})
 	clientHub.Broadcast <- jsonString
+	return nil
 }
 
 func imageBuild(w http.ResponseWriter, r *http.Request) {
@@ -232,12 +298,21 @@ func fluxState(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 
 	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
-	agent := agentHub.Agents[name]
-	if agent == nil {
-		time.Sleep(1 * time.Second) // Agenthub has a race condition. Registration is not done when the client sends the state
-		agent = agentHub.Agents[name]
+	var previousFluxState *api.FluxState
+	_, err = updateRegisteredAgent(agentHub, name, func(agent *streaming.ConnectedAgent) error {
+		previousFluxState = agent.FluxState
+		agent.FluxState = &fluxState
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("cannot update the flux state of %s: %s", name, err)
+		return
+	}
+
+	notificationsManager := r.Context().Value("notificationsManager").(notifications.Manager)
+	for _, message := range fluxResourceMessages(name, previousFluxState, &fluxState) {
+		notificationsManager.Broadcast(message)
 	}
-	agent.FluxState = &fluxState
 
 	clientHub, _ := r.Context().Value("clientHub").(*streaming.ClientHub)
 	jsonString, _ := json.Marshal(streaming.FluxStateUpdatedEvent{
@@ -248,6 +323,107 @@ func fluxState(w http.ResponseWriter, r *http.Request) {
 	clientHub.Broadcast <- jsonString
 }
 
+// agentGitopsManifests serves the rendered manifests of the env's apps, as they are in the gitops repo,
+// so the agent can compare them to the live objects
+func agentGitopsManifests(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	ctx := r.Context()
+	db := ctx.Value("store").(*store.Store)
+	env, err := db.GetEnvironment(name)
+	if err != nil {
+		logrus.Errorf("cannot get env: %s", err)
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	gitRepoCache, _ := ctx.Value("gitRepoCache").(*nativeGit.RepoCache)
+	repo, err := gitRepoCache.InstanceForRead(env.AppsRepo)
+	if err != nil {
+		logrus.Errorf("cannot get repo: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	manifests, err := gitops.AppManifests(repo, env.Name, env.RepoPerEnv)
+	if err != nil {
+		logrus.Errorf("cannot read manifests: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	manifestsString, err := json.Marshal(manifests)
+	if err != nil {
+		logrus.Errorf("cannot serialize manifests: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(manifestsString)
+}
+
+func drift(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	var drifts []*api.Drift
+	err := json.NewDecoder(r.Body).Decode(&drifts)
+	if err != nil {
+		logrus.Errorf("cannot decode drifts: %s", err)
+		http.Error(w, http.StatusText(400), 400)
+		return
+	}
+
+	db := r.Context().Value("store").(*store.Store)
+	flagScaleActions(db, name, drifts)
+
+	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
+	_, err = agentHub.UpdateAgent(name, func(agent *streaming.ConnectedAgent) error {
+		agent.Drifts = drifts
+		return nil
+	})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	alertStateManager, _ := r.Context().Value("alertStateManager").(*alert.AlertStateManager)
+	err = alertStateManager.TrackDrift(name, drifts)
+	if err != nil {
+		logrus.Errorf("cannot track drifts: %s", err)
+	}
+
+	clientHub, _ := r.Context().Value("clientHub").(*streaming.ClientHub)
+	jsonString, _ := json.Marshal(streaming.DriftUpdatedEvent{
+		StreamingEvent: streaming.StreamingEvent{Event: streaming.DriftUpdatedEventString},
+		EnvName:        name,
+		Drifts:         drifts,
+	})
+	clientHub.Broadcast <- jsonString
+}
+
+func certificates(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+
+	var certificates []*api.Certificate
+	err := json.NewDecoder(r.Body).Decode(&certificates)
+	if err != nil {
+		logrus.Errorf("cannot decode certificates: %s", err)
+		http.Error(w, http.StatusText(400), 400)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	alertStateManager, _ := r.Context().Value("alertStateManager").(*alert.AlertStateManager)
+	err = alertStateManager.TrackCertificates(name, certificates)
+	if err != nil {
+		logrus.Errorf("cannot track certificates: %s", err)
+	}
+}
+
 func update(w http.ResponseWriter, r *http.Request) {
 	var update api.StackUpdate
 	err := json.NewDecoder(r.Body).Decode(&update)
@@ -267,6 +443,11 @@ func update(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, http.StatusText(500), 500)
 			return
 		}
+
+		err = saveCrashLogs(db, update)
+		if err != nil {
+			logrus.Errorf("cannot save crash logs: %s", err)
+		}
 	}
 
 	update = decorateDeploymentUpdateWithCommitMessage(update, r)
@@ -309,7 +490,7 @@ func decorateDeploymentUpdateWithCommitMessage(update api.StackUpdate, r *http.R
 
 func handlePodUpdate(alertStateManager *alert.AlertStateManager, db *store.Store, update api.StackUpdate) error {
 	if update.Event == agent.EventPodDeleted {
-		return nil
+		return alertStateManager.PodDeleted(update.Subject)
 	}
 
 	deploymentParts := strings.Split(update.Deployment, "/")
@@ -318,13 +499,52 @@ func handlePodUpdate(alertStateManager *alert.AlertStateManager, db *store.Store
 	namespace := parts[0]
 	name := parts[1]
 
-	return alertStateManager.TrackPods([]*api.Pod{
+	return alertStateManager.TrackPods(update.Env, []*api.Pod{
 		{
 			Namespace:         namespace,
 			Name:              name,
 			DeploymentName:    deployment,
 			Status:            update.Status,
 			StatusDescription: update.ErrorCause,
+			RestartCount:      update.RestartCount,
+			LastExitCode:      update.LastExitCode,
 		},
 	})
 }
+
+func saveCrashLogs(db *store.Store, update api.StackUpdate) error {
+	if len(update.CrashLogs) == 0 {
+		return nil
+	}
+
+	namespace, pod := splitKey(update.Subject)
+	_, app := splitKey(update.Deployment)
+	for _, c := range update.CrashLogs {
+		err := db.SaveCrashLog(&model.CrashLog{
+			Env:          update.Env,
+			Namespace:    namespace,
+			Pod:          pod,
+			App:          app,
+			Container:    c.Container,
+			RestartCount: c.RestartCount,
+			ExitCode:     c.ExitCode,
+			Reason:       c.Reason,
+			FinishedAt:   c.FinishedAt,
+			Logs:         c.Logs,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitKey splits a namespace/name key
+func splitKey(key string) (string, string) {
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) != 2 {
+		return "", key
+	}
+	return parts[0], parts[1]
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+)
+
+func getAlerts(w http.ResponseWriter, r *http.Request) {
+	db := r.Context().Value("store").(*store.Store)
+	alerts, err := db.ActiveAlerts()
+	if err != nil {
+		logrus.Errorf("cannot get alerts from database: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	alertsString, err := json.Marshal(alerts)
+	if err != nil {
+		logrus.Errorf("cannot serialize alerts: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+	w.Write(alertsString)
+}
+
+func acknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	alertType := r.URL.Query().Get("type")
+	if name == "" || alertType == "" {
+		http.Error(w, http.StatusText(http.StatusBadRequest)+" - name and type parameters are mandatory", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	user := ctx.Value("user").(*model.User)
+	db := ctx.Value("store").(*store.Store)
+
+	alert, err := db.Alert(name, alertType)
+	if err == sql.ErrNoRows {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.Errorf("cannot get alert: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if alert.Status != model.AlertFiring {
+		http.Error(w, http.StatusText(http.StatusBadRequest)+" - only firing alerts can be acknowledged", http.StatusBadRequest)
+		return
+	}
+
+	alert.Status = model.AlertAcknowledged
+	alert.AcknowledgedBy = user.Login
+	alert.LastStateChange = time.Now().Unix()
+	err = db.SaveOrUpdateAlert(alert)
+	if err != nil {
+		logrus.Errorf("cannot save alert: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	alertString, err := json.Marshal(alert)
+	if err != nil {
+		logrus.Errorf("cannot serialize alert: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(alertString)
+}
+
+func getSilences(w http.ResponseWriter, r *http.Request) {
+	db := r.Context().Value("store").(*store.Store)
+	silences, err := db.ActiveSilences()
+	if err != nil {
+		logrus.Errorf("cannot get silences from database: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	silencesString, err := json.Marshal(silences)
+	if err != nil {
+		logrus.Errorf("cannot serialize silences: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(silencesString)
+}
+
+func saveSilence(w http.ResponseWriter, r *http.Request) {
+	var silence model.Silence
+	err := json.NewDecoder(r.Body).Decode(&silence)
+	if err != nil {
+		logrus.Errorf("cannot decode silence: %s", err)
+		http.Error(w, http.StatusText(400), 400)
+		return
+	}
+
+	if silence.Env == "" && silence.Namespace == "" && silence.Deployment == "" {
+		http.Error(w, http.StatusText(http.StatusBadRequest)+" - env, namespace or deployment is mandatory", http.StatusBadRequest)
+		return
+	}
+	if silence.Until <= time.Now().Unix() {
+		http.Error(w, http.StatusText(http.StatusBadRequest)+" - silence must end in the future", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	user := ctx.Value("user").(*model.User)
+	db := ctx.Value("store").(*store.Store)
+
+	silence.ID = 0
+	silence.CreatedBy = user.Login
+	silence.Created = time.Now().Unix()
+	err = db.CreateSilence(&silence)
+	if err != nil {
+		logrus.Errorf("cannot save silence: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	silenceString, err := json.Marshal(silence)
+	if err != nil {
+		logrus.Errorf("cannot serialize silence: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(silenceString)
+}
+
+func deleteSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest)+" - invalid silence id", http.StatusBadRequest)
+		return
+	}
+
+	db := r.Context().Value("store").(*store.Store)
+	err = db.DeleteSilence(id)
+	if err != nil {
+		logrus.Errorf("cannot delete silence: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
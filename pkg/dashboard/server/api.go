@@ -61,14 +61,12 @@ func envs(w http.ResponseWriter, r *http.Request) {
 	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
 
 	connectedAgents := []*api.ConnectedAgent{}
-	for _, a := range agentHub.Agents {
-		for _, stack := range a.Stacks {
-			stack.Env = a.Name
-		}
+	for _, a := range agentHub.ConnectedAgents() {
 		connectedAgents = append(connectedAgents, &api.ConnectedAgent{
 			Name:      a.Name,
 			Stacks:    a.Stacks,
 			FluxState: a.FluxState,
+			Drifts:    a.Drifts,
 		})
 	}
 
@@ -180,25 +178,6 @@ func stopPodLogs(w http.ResponseWriter, r *http.Request) {
 	agentHub.StopPodLogs(namespace, serviceName)
 }
 
-func getAlerts(w http.ResponseWriter, r *http.Request) {
-	// db := r.Context().Value("store").(*store.Store)
-	// alerts, err := db.FiringAlerts()
-	// if err != nil {
-	// 	logrus.Errorf("cannot get alerts from database: %s", err)
-	// 	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-	// }
-
-	alertsString, err := json.Marshal([]*model.Alert{}) //(alerts)
-	if err != nil {
-		logrus.Errorf("cannot serialize alerts: %s", err)
-		http.Error(w, http.StatusText(500), 500)
-		return
-	}
-
-	w.WriteHeader(200)
-	w.Write(alertsString)
-}
-
 func deploymentAutomationEnabled(envName string, envs []*api.GitopsEnv) bool {
 	for _, env := range envs {
 		if env.StackConfig == nil {
@@ -269,7 +248,7 @@ func agents(w http.ResponseWriter, r *http.Request) {
 	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
 
 	agents := []string{}
-	for _, a := range agentHub.Agents {
+	for _, a := range agentHub.ConnectedAgents() {
 		agents = append(agents, a.Name)
 	}
 
@@ -294,10 +273,14 @@ func decorateDeployments(ctx context.Context, envs []*api.ConnectedAgent) error
 	token, _, _ := tokenManager.Token()
 	for _, env := range envs {
 		for _, stack := range env.Stacks {
-			if stack.Deployment == nil {
-				continue
+			if stack.Deployment != nil {
+				_, err := decorateDeploymentWithSCMData(stack.Repo, stack.Deployment, dao, gitServiceImpl, token)
+				if err != nil {
+					return fmt.Errorf("cannot decorate commits: %s", err)
+				}
 			}
-			_, err := decorateDeploymentWithSCMData(stack.Repo, stack.Deployment, dao, gitServiceImpl, token)
+
+			err := decorateWorkloadsWithSCMData(stack, dao, gitServiceImpl, token)
 			if err != nil {
 				return fmt.Errorf("cannot decorate commits: %s", err)
 			}
@@ -306,6 +289,38 @@ func decorateDeployments(ctx context.Context, envs []*api.ConnectedAgent) error
 	return nil
 }
 
+// decorateWorkloadsWithSCMData sets the commit message of statefulsets, daemonsets and cronjobs
+func decorateWorkloadsWithSCMData(
+	stack *api.Stack,
+	dao *store.Store,
+	gitServiceImpl customScm.CustomGitService,
+	token string,
+) error {
+	var err error
+	if stack.StatefulSet != nil && stack.StatefulSet.SHA != "" {
+		stack.StatefulSet.CommitMessage, err = commitMessage(stack.Repo, stack.StatefulSet.SHA, dao, gitServiceImpl, token, false)
+		if err != nil {
+			return err
+		}
+	}
+	if stack.DaemonSet != nil && stack.DaemonSet.SHA != "" {
+		stack.DaemonSet.CommitMessage, err = commitMessage(stack.Repo, stack.DaemonSet.SHA, dao, gitServiceImpl, token, false)
+		if err != nil {
+			return err
+		}
+	}
+	for _, cronJob := range stack.CronJobs {
+		if cronJob.SHA == "" {
+			continue
+		}
+		cronJob.CommitMessage, err = commitMessage(stack.Repo, cronJob.SHA, dao, gitServiceImpl, token, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func deploymentTemplates(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	config := ctx.Value("config").(*config.Config)
@@ -331,7 +346,7 @@ func deploymentTemplates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var templates []DeploymentTemplate
+	var templates []api.DeploymentTemplate
 	for _, chart := range charts {
 		m := &dx.Manifest{
 			Chart: chart,
@@ -360,7 +375,7 @@ func deploymentTemplates(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		templates = append(templates, DeploymentTemplate{
+		templates = append(templates, api.DeploymentTemplate{
 			Reference: chart,
 			Schema:    schema,
 			UISchema:  schemaUI,
@@ -652,6 +667,11 @@ func deleteEnvFromDB(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
+	if agentHub != nil {
+		agentHub.Forget(envNameToDelete)
+	}
+
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(envNameToDelete))
 }
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+)
+
+func getArtifactSigning(w http.ResponseWriter, r *http.Request) {
+	envName := chi.URLParam(r, "env")
+	db := r.Context().Value("store").(*store.Store)
+
+	env, err := db.GetEnvironment(envName)
+	if err == sql.ErrNoRows {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.Errorf("cannot get environment: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	signing := api.ArtifactSigning{
+		RequireSignedArtifacts: env.RequireSignedArtifacts,
+		TrustedKeys:            env.TrustedKeys,
+	}
+	if signing.TrustedKeys == nil {
+		signing.TrustedKeys = []string{}
+	}
+
+	signingString, err := json.Marshal(signing)
+	if err != nil {
+		logrus.Errorf("cannot serialize artifact signing: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(signingString)
+}
+
+// saveArtifactSigning sets which keys an env trusts, and whether it deploys unsigned artifacts
+func saveArtifactSigning(w http.ResponseWriter, r *http.Request) {
+	var signing api.ArtifactSigning
+	err := json.NewDecoder(r.Body).Decode(&signing)
+	if err != nil {
+		logrus.Errorf("cannot decode artifact signing: %s", err)
+		http.Error(w, http.StatusText(400), 400)
+		return
+	}
+
+	if signing.RequireSignedArtifacts && len(signing.TrustedKeys) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest)+" - requiring signed artifacts needs at least one trusted key", http.StatusBadRequest)
+		return
+	}
+	for _, key := range signing.TrustedKeys {
+		_, err := dx.ParsePublicKey([]byte(key))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%s - %s", http.StatusText(http.StatusBadRequest), err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	envName := chi.URLParam(r, "env")
+	db := r.Context().Value("store").(*store.Store)
+
+	env, err := db.GetEnvironment(envName)
+	if err == sql.ErrNoRows {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.Errorf("cannot get environment: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	env.RequireSignedArtifacts = signing.RequireSignedArtifacts
+	env.TrustedKeys = signing.TrustedKeys
+	err = db.UpdateEnvironment(env)
+	if err != nil {
+		logrus.Errorf("cannot update environment: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("{}"))
+}
@@ -63,13 +63,13 @@ func commits(w http.ResponseWriter, r *http.Request) {
 	}
 
 	limit := 10
-	commits := []*Commit{}
+	commits := []*api.Commit{}
 	err = commitWalker.ForEach(func(c *object.Commit) error {
 		if limit != 0 && len(commits) >= limit {
 			return fmt.Errorf("%s", "LIMIT")
 		}
 
-		commits = append(commits, &Commit{
+		commits = append(commits, &api.Commit{
 			SHA:        c.Hash.String(),
 			AuthorName: c.Author.Name,
 			Message:    c.Message,
@@ -129,34 +129,13 @@ func triggerCommitSync(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("{}"))
 }
 
-type DeployTarget struct {
-	App        string `json:"app"`
-	Env        string `json:"env"`
-	Tenant     string `json:"tenant"`
-	ArtifactId string `json:"artifactId"`
-}
-
-// Commit represents a Github commit
-type Commit struct {
-	SHA           string               `json:"sha"`
-	URL           string               `json:"url"`
-	Author        string               `json:"author"`
-	AuthorName    string               `json:"authorName"`
-	AuthorPic     string               `json:"author_pic"`
-	Message       string               `json:"message"`
-	CreatedAt     int64                `json:"created_at"`
-	Tags          []string             `json:"tags,omitempty"`
-	Status        model.CombinedStatus `json:"status,omitempty"`
-	DeployTargets []*DeployTarget      `json:"deployTargets,omitempty"`
-}
-
 func decorateCommitsWithSCMData(
 	repo string,
-	commits []*Commit,
+	commits []*api.Commit,
 	dao *store.Store,
 	gitServiceImpl customScm.CustomGitService,
 	token string,
-) ([]*Commit, error) {
+) ([]*api.Commit, error) {
 	return decorateCommitsWithSCMDataWithRetry(
 		repo,
 		commits,
@@ -169,12 +148,12 @@ func decorateCommitsWithSCMData(
 
 func decorateCommitsWithSCMDataWithRetry(
 	repo string,
-	commits []*Commit,
+	commits []*api.Commit,
 	dao *store.Store,
 	gitServiceImpl customScm.CustomGitService,
 	token string,
 	isRetry bool,
-) ([]*Commit, error) {
+) ([]*api.Commit, error) {
 	var hashes []string
 	for _, commit := range commits {
 		hashes = append(hashes, commit.SHA)
@@ -190,7 +169,7 @@ func decorateCommitsWithSCMDataWithRetry(
 		dbCommitsByHash[dbCommit.SHA] = dbCommit
 	}
 
-	var decoratedCommits []*Commit
+	var decoratedCommits []*api.Commit
 	var hashesToFetch []string
 	for _, commit := range commits {
 		if dbCommit, ok := dbCommitsByHash[commit.SHA]; ok {
@@ -232,50 +211,48 @@ func decorateDeploymentWithSCMData(
 	gitServiceImpl customScm.CustomGitService,
 	token string,
 ) (*api.Deployment, error) {
-	return decorateDeploymentWithSCMDataWithRetry(
-		repo,
-		deployment,
-		dao,
-		gitServiceImpl,
-		token,
-		false,
-	)
+	message, err := commitMessage(repo, deployment.SHA, dao, gitServiceImpl, token, false)
+	if err != nil {
+		return nil, err
+	}
+	deployment.CommitMessage = message
+
+	return deployment, nil
 }
 
-func decorateDeploymentWithSCMDataWithRetry(
+// commitMessage looks up the message of a commit, fetching it from the SCM if it is not stored yet
+func commitMessage(
 	repo string,
-	deployment *api.Deployment,
+	sha string,
 	dao *store.Store,
 	gitServiceImpl customScm.CustomGitService,
 	token string,
 	isRetry bool,
-) (*api.Deployment, error) {
-	dbCommits, err := dao.CommitsByRepoAndSHA(repo, []string{deployment.SHA})
+) (string, error) {
+	dbCommits, err := dao.CommitsByRepoAndSHA(repo, []string{sha})
 	if err != nil {
-		return nil, fmt.Errorf("cannot get commits from db %s", err)
+		return "", fmt.Errorf("cannot get commits from db %s", err)
 	}
 
 	if len(dbCommits) > 0 {
-		deployment.CommitMessage = dbCommits[0].Message
-	} else {
-		if isRetry { // we only retry once
-			return deployment, nil
-		}
-		owner, name := scm.Split(repo)
+		return dbCommits[0].Message, nil
+	}
 
-		// fetch remote commit info, then try to decorate again
-		fetchCommits(owner, name, gitServiceImpl, token, dao, []string{deployment.SHA})
-		return decorateDeploymentWithSCMDataWithRetry(
-			repo,
-			deployment,
-			dao,
-			gitServiceImpl,
-			token,
-			true,
-		)
+	if isRetry { // we only retry once
+		return "", nil
 	}
+	owner, name := scm.Split(repo)
 
-	return deployment, nil
+	// fetch remote commit info, then try to decorate again
+	fetchCommits(owner, name, gitServiceImpl, token, dao, []string{sha})
+	return commitMessage(
+		repo,
+		sha,
+		dao,
+		gitServiceImpl,
+		token,
+		true,
+	)
 }
 
 // commits come from go-scm based live git traversal
@@ -319,8 +296,8 @@ func fetchCommits(
 	}
 }
 
-func squashCommitStatuses(commits []*Commit) []*Commit {
-	var commitsWithSquashedStatuses []*Commit
+func squashCommitStatuses(commits []*api.Commit) []*api.Commit {
+	var commitsWithSquashedStatuses []*api.Commit
 
 	for _, commit := range commits {
 		statusMap := map[string]model.CommitStatus{}
@@ -34,13 +34,8 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-type saveInfrastructureComponentsReq struct {
-	Env                      string                 `json:"env"`
-	InfrastructureComponents map[string]interface{} `json:"infrastructureComponents"`
-}
-
 func saveInfrastructureComponents(w http.ResponseWriter, r *http.Request) {
-	var req saveInfrastructureComponentsReq
+	var req api.InfrastructureComponents
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		logrus.Errorf("cannot decode req: %s", err)
@@ -279,6 +274,7 @@ func bootstrapGitops(w http.ResponseWriter, r *http.Request) {
 		false,
 		false,
 		scmURL,
+		config.FluxAPIVersion,
 	)
 	if err != nil {
 		logrus.Error(err)
@@ -298,6 +294,7 @@ func bootstrapGitops(w http.ResponseWriter, r *http.Request) {
 		environment.KustomizationPerApp,
 		true,
 		scmURL,
+		config.FluxAPIVersion,
 	)
 	if err != nil {
 		logrus.Error(err)
@@ -350,6 +347,7 @@ func BootstrapEnv(
 	kustomizationPerApp bool,
 	deployKeyCanWrite bool,
 	scmURL string,
+	fluxAPIVersion string,
 ) (string, string, error) {
 	repo, tmpPath, err := gitRepoCache.InstanceForWrite(repoName)
 	defer os.RemoveAll(tmpPath)
@@ -385,6 +383,7 @@ func BootstrapEnv(
 		ShouldGenerateDeployKey:            true,
 		GitopsRepoUrl:                      fmt.Sprintf("git@%s:%s.git", scmHost, repoName),
 		Branch:                             headBranch,
+		FluxAPIVersion:                     fluxAPIVersion,
 	})
 	if err != nil {
 		return "", "", fmt.Errorf("cannot generate manifest: %s", err)
@@ -442,6 +441,9 @@ func MigrateEnv(
 		return "", "", fmt.Errorf("cannot get head branch: %s", err)
 	}
 
+	// the migrated repo keeps the Flux API version it was bootstrapped with
+	fluxAPIVersion := gitops.FluxAPIVersionFromRepo(tmpPath, filepath.Join(envName, "flux"))
+
 	owner, repoName := scm.Split(oldRepoName)
 	deployKeyName := fmt.Sprintf("deploy-key-%s.yaml", gitops.UniqueName(repoPerEnv, owner, repoName, envName))
 	err = os.Remove(tmpPath + "/flux/" + deployKeyName)
@@ -461,6 +463,7 @@ func MigrateEnv(
 		ShouldGenerateDeployKey:            true,
 		GitopsRepoUrl:                      fmt.Sprintf("git@%s:%s.git", scmHost, newRepoName),
 		Branch:                             headBranch,
+		FluxAPIVersion:                     fluxAPIVersion,
 	})
 	if err != nil {
 		return "", "", fmt.Errorf("cannot generate manifest: %s", err)
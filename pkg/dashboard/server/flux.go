@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	fluxEvents "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/notifications"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
@@ -186,3 +187,64 @@ func updateGitopsCommitStatuses(
 
 	return nil
 }
+
+type fluxResourceStatus struct {
+	kind       string
+	namespace  string
+	name       string
+	revision   string
+	status     string
+	statusDesc string
+}
+
+func fluxResourceStatuses(fluxState *api.FluxState) map[string]fluxResourceStatus {
+	statuses := map[string]fluxResourceStatus{}
+	if fluxState == nil {
+		return statuses
+	}
+
+	add := func(s fluxResourceStatus) {
+		statuses[s.kind+"/"+s.namespace+"/"+s.name] = s
+	}
+	for _, h := range fluxState.HelmReleases {
+		add(fluxResourceStatus{"HelmRelease", h.Namespace, h.Name, h.LastAttemptedRevision, h.Status, h.StatusDesc})
+	}
+	for kind, sources := range map[string][]*api.FluxSource{
+		"HelmRepository": fluxState.HelmRepositories,
+		"OCIRepository":  fluxState.OCIRepositories,
+		"Bucket":         fluxState.Buckets,
+	} {
+		for _, s := range sources {
+			add(fluxResourceStatus{kind, s.Namespace, s.Name, s.Revision, s.Status, s.StatusDesc})
+		}
+	}
+
+	return statuses
+}
+
+// fluxResourceMessages notifies about the HelmReleases and Flux sources that turned failed or recovered
+// since the previous flux state. Without a previous state there is nothing to compare to
+func fluxResourceMessages(env string, previous *api.FluxState, current *api.FluxState) []notifications.Message {
+	if previous == nil {
+		return nil
+	}
+
+	previousStatuses := fluxResourceStatuses(previous)
+	messages := []notifications.Message{}
+	for key, s := range fluxResourceStatuses(current) {
+		p, existed := previousStatuses[key]
+		if existed && p.status == s.status {
+			continue
+		}
+
+		failed := strings.HasSuffix(s.status, "Failed")
+		recovered := existed && strings.HasSuffix(p.status, "Failed") && strings.HasSuffix(s.status, "Succeeded")
+		if failed || recovered {
+			messages = append(messages, notifications.NewFluxResourceMessage(
+				env, s.kind, s.namespace, s.name, s.revision, s.status, s.statusDesc,
+			))
+		}
+	}
+
+	return messages
+}
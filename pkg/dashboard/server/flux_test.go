@@ -11,6 +11,7 @@ import (
 
 	fluxEvents "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/config"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/notifications"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
 	"github.com/stretchr/testify/assert"
@@ -77,3 +78,19 @@ func Test_parseRev(t *testing.T) {
 	parsed, _ = parseRev("main@sha1:69b59063470310ebbd88a9156325322a124e55a3")
 	assert.Equal(t, "69b59063470310ebbd88a9156325322a124e55a3", parsed)
 }
+
+func TestFluxResourceMessages(t *testing.T) {
+	healthy := &api.FluxState{
+		HelmReleases:     []*api.HelmRelease{{Name: "redis", Namespace: "infrastructure", Status: "UpgradeSucceeded"}},
+		HelmRepositories: []*api.FluxSource{{Name: "bitnami", Namespace: "flux-system", Status: "Succeeded"}},
+	}
+	failing := &api.FluxState{
+		HelmReleases:     []*api.HelmRelease{{Name: "redis", Namespace: "infrastructure", Status: "UpgradeFailed"}},
+		HelmRepositories: []*api.FluxSource{{Name: "bitnami", Namespace: "flux-system", Status: "Succeeded"}},
+	}
+
+	assert.Equal(t, 0, len(fluxResourceMessages("staging", nil, failing)), "the first state should not notify")
+	assert.Equal(t, 1, len(fluxResourceMessages("staging", healthy, failing)))
+	assert.Equal(t, 0, len(fluxResourceMessages("staging", failing, failing)), "unchanged failures should not notify again")
+	assert.Equal(t, 1, len(fluxResourceMessages("staging", failing, healthy)), "recoveries should notify")
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"sort"
 
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
@@ -127,7 +128,7 @@ func orderRolloutHistoryFromAscending(rolloutHistory []*Env) []*Env {
 	return orderedRolloutHistory
 }
 
-func decorateCommitsWithGimletArtifacts(commits []*Commit, store *store.Store) ([]*Commit, error) {
+func decorateCommitsWithGimletArtifacts(commits []*api.Commit, store *store.Store) ([]*api.Commit, error) {
 	var hashes []string
 	for _, c := range commits {
 		hashes = append(hashes, c.SHA)
@@ -157,15 +158,15 @@ func decorateCommitsWithGimletArtifacts(commits []*Commit, store *store.Store) (
 		artifactsBySha[a.Version.SHA] = a
 	}
 
-	var decoratedCommits []*Commit
+	var decoratedCommits []*api.Commit
 	for _, c := range commits {
 		if artifact, ok := artifactsBySha[c.SHA]; ok && !artifact.Fake {
 			for _, targetEnv := range artifact.Environments {
 				targetEnv.ResolveVars(artifact.CollectVariables())
 				if c.DeployTargets == nil {
-					c.DeployTargets = []*DeployTarget{}
+					c.DeployTargets = []*api.DeployTarget{}
 				}
-				c.DeployTargets = append(c.DeployTargets, &DeployTarget{
+				c.DeployTargets = append(c.DeployTargets, &api.DeployTarget{
 					App:        targetEnv.App,
 					Env:        targetEnv.Env,
 					Tenant:     targetEnv.Tenant.Name,
@@ -113,7 +113,7 @@ func getMetas(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	fileInfos := []fileInfo{}
+	fileInfos := []api.FileInfo{}
 	for fileName, content := range files {
 		var envConfig dx.Manifest
 		err = yaml.Unmarshal([]byte(content), &envConfig)
@@ -121,14 +121,14 @@ func getMetas(w http.ResponseWriter, r *http.Request) {
 			logrus.Warnf("cannot parse env config string: %s", err)
 			continue
 		}
-		fileInfos = append(fileInfos, fileInfo{
+		fileInfos = append(fileInfos, api.FileInfo{
 			AppName:  envConfig.App,
 			EnvName:  envConfig.Env,
 			FileName: fileName,
 		})
 	}
 
-	gitRepoM := gitRepoMetas{
+	gitRepoM := api.GitRepoMetas{
 		GithubActions:        hasGithubActionsConfig,
 		CircleCi:             hasCircleCiConfig,
 		GithubActionsShipper: githubActionsShipper,
@@ -286,20 +286,6 @@ func getPullRequestsFromInfraRepos(w http.ResponseWriter, r *http.Request) {
 	w.Write(infraRepoPullRequestsString)
 }
 
-type fileInfo struct {
-	EnvName  string `json:"envName"`
-	AppName  string `json:"appName"`
-	FileName string `json:"fileName"`
-}
-
-type gitRepoMetas struct {
-	GithubActions        bool       `json:"githubActions"`
-	CircleCi             bool       `json:"circleCi"`
-	GithubActionsShipper *string    `json:"githubActionsShipper"`
-	CircleCiShipper      *string    `json:"circleCiShipper"`
-	FileInfos            []fileInfo `json:"fileInfos"`
-}
-
 // envConfig fetches all environment configs from source control for a repo
 func envConfigs(w http.ResponseWriter, r *http.Request) {
 	owner := chi.URLParam(r, "owner")
@@ -366,31 +352,8 @@ func envConfigs(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(configsPerEnvJson))
 }
 
-type envConfig struct {
-	Values          map[string]interface{}
-	Namespace       string
-	Chart           Chart
-	AppName         string
-	UseDeployPolicy bool
-	DeployBranch    string
-	DeployTag       string
-	DeployEvent     *dx.GitEvent
-}
-
-type DeploymentTemplate struct {
-	Reference dx.Chart    `json:"reference"`
-	Schema    interface{} `json:"schema"`
-	UISchema  interface{} `json:"uiSchema"`
-}
-
-type Chart struct {
-	Repository string
-	Name       string
-	Version    string
-}
-
 func saveEnvConfig(w http.ResponseWriter, r *http.Request) {
-	envConfigData := &envConfig{}
+	envConfigData := &api.EnvConfig{}
 	err := json.NewDecoder(r.Body).Decode(&envConfigData)
 	if err != nil {
 		logrus.Errorf("cannot decode env config data: %s", err)
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/insights"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/sirupsen/logrus"
+)
+
+func getInsights(w http.ResponseWriter, r *http.Request) {
+	until := time.Now()
+	since := until.Add(-30 * 24 * time.Hour)
+	var app, env string
+
+	params := r.URL.Query()
+	if val, ok := params["since"]; ok {
+		t, err := time.Parse(time.RFC3339, val[0])
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest)+" - "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+	if val, ok := params["until"]; ok {
+		t, err := time.Parse(time.RFC3339, val[0])
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest)+" - "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		until = t
+	}
+	if val, ok := params["app"]; ok {
+		app = val[0]
+	}
+	if val, ok := params["env"]; ok {
+		env = val[0]
+	}
+
+	db := r.Context().Value("store").(*store.Store)
+	metrics, err := insights.Load(db, since, until)
+	if err != nil {
+		logrus.Errorf("cannot compute insights: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	filtered := []*model.Insights{}
+	for _, m := range metrics {
+		if env != "" && m.Env != env {
+			continue
+		}
+		if app != "" && m.App != app {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+
+	metricsString, err := json.Marshal(filtered)
+	if err != nil {
+		logrus.Errorf("cannot serialize insights: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(metricsString)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/config"
 	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/dynamicconfig"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/notifications"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
 	"github.com/gimlet-io/gimlet-cli/pkg/git/customScm"
 	"github.com/gimlet-io/gimlet-cli/pkg/git/customScm/customGithub"
@@ -119,8 +120,9 @@ func installed(w http.ResponseWriter, r *http.Request) {
 	tokenManager := ctx.Value("tokenManager").(*customScm.TokenManager)
 	tokenManager.Configure(dynamicConfig)
 
+	agentHub := ctx.Value("agentHub").(*streaming.AgentHub)
 	notificationsManager := ctx.Value("notificationsManager").(notifications.Manager)
-	notificationsManager.AddProvider(notifications.NewGithubProvider(tokenManager))
+	notificationsManager.AddProvider(notifications.NewGithubProvider(tokenManager, notifications.IngressURL(agentHub), dao))
 
 	tokenString, err := tokenManager.AppToken()
 	if err != nil {
@@ -191,8 +193,10 @@ func gitlabInit(w http.ResponseWriter, r *http.Request) {
 	tokenManager := ctx.Value("tokenManager").(*customScm.TokenManager)
 	tokenManager.Configure(dynamicConfig)
 
+	agentHub := ctx.Value("agentHub").(*streaming.AgentHub)
 	notificationsManager := ctx.Value("notificationsManager").(notifications.Manager)
-	notificationsManager.AddProvider(notifications.NewGitlabProvider(tokenManager, gitlabUrl))
+	dao := ctx.Value("store").(*store.Store)
+	notificationsManager.AddProvider(notifications.NewGitlabProvider(tokenManager, gitlabUrl, notifications.IngressURL(agentHub), dao))
 
 	router := ctx.Value("router").(*chi.Mux)
 	config := ctx.Value("config").(*config.Config)
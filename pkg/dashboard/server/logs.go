@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/sirupsen/logrus"
+)
+
+// getCrashLogs returns the stored crash logs of an app, in an optional time range
+func getCrashLogs(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	env := params.Get("env")
+	app := params.Get("app")
+	if env == "" || app == "" {
+		http.Error(w, http.StatusText(http.StatusBadRequest)+" - env and app are mandatory", http.StatusBadRequest)
+		return
+	}
+
+	until := time.Now()
+	since := time.Unix(0, 0)
+	if val := params.Get("since"); val != "" {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest)+" - "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+	if val := params.Get("until"); val != "" {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest)+" - "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		until = t
+	}
+	limit := 20
+	if val := params.Get("limit"); val != "" {
+		l, err := strconv.Atoi(val)
+		if err != nil || l <= 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest)+" - invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	db := r.Context().Value("store").(*store.Store)
+	crashLogs, err := db.CrashLogs(env, app, since.Unix(), until.Unix(), limit)
+	if err != nil {
+		logrus.Errorf("cannot get crash logs from database: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	crashLogsString, err := json.Marshal(crashLogs)
+	if err != nil {
+		logrus.Errorf("cannot serialize crash logs: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(crashLogsString)
+}
+
+const followHeartbeat = 15 * time.Second
+
+// followPodLogs streams the live logs of all pods and containers of an app,
+// one json encoded line at a time, in the order they arrive from the agent
+func followPodLogs(w http.ResponseWriter, r *http.Request) {
+	env := r.URL.Query().Get("env")
+	app := r.URL.Query().Get("app")
+
+	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
+	agent, ok := agentHub.Agent(env)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusNotFound)+" - env is not connected", http.StatusNotFound)
+		return
+	}
+	namespace := ""
+	for _, stack := range agent.Stacks {
+		if stack.Service != nil && stack.Service.Name == app {
+			namespace = stack.Service.Namespace
+			break
+		}
+	}
+	if namespace == "" {
+		http.Error(w, http.StatusText(http.StatusNotFound)+" - app not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	podLogs, _ := r.Context().Value("podLogs").(*streaming.PodLogBroker)
+	svc := namespace + "/" + app
+	lines := podLogs.Follow(svc)
+	defer func() {
+		if podLogs.Unfollow(svc, lines) {
+			agentHub.StopPodLogs(namespace, app)
+		}
+	}()
+	agentHub.StreamPodLogsSend(namespace, app)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// The request context is not watched, as it times out with the rest of the api calls.
+	// A disconnected client is noticed on writing, the heartbeat makes sure there are writes
+	heartbeat := time.NewTicker(followHeartbeat)
+	defer heartbeat.Stop()
+	encoder := json.NewEncoder(w)
+	for {
+		var err error
+		select {
+		case line := <-lines:
+			err = encoder.Encode(line)
+		case <-heartbeat.C:
+			_, err = w.Write([]byte("\n"))
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,313 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/config"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/gitops"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/gimlet-io/gimlet-cli/pkg/git/nativeGit"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// getReleaseDiff returns what changed between two releases of an app: the source commits,
+// the manifest values and the rendered manifests.
+// Without the from and to gitops refs, it compares the latest release with the one before it
+func getReleaseDiff(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	env := params.Get("env")
+	app := params.Get("app")
+	if env == "" || app == "" {
+		http.Error(w, fmt.Sprintf("%s: %s", http.StatusText(http.StatusBadRequest), "env and app parameters are mandatory"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	db := ctx.Value("store").(*store.Store)
+	gitopsRepoCache := ctx.Value("gitRepoCache").(*nativeGit.RepoCache)
+	perf := ctx.Value("perf").(*prometheus.HistogramVec)
+	config := ctx.Value("config").(*config.Config)
+
+	repoName, repoPerEnv, err := gitopsRepoForEnv(db, env)
+	if err != nil {
+		logrus.Error(err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	repo, pathToCleanUp, err := gitopsRepoCache.InstanceForWriteWithHistory(repoName) // using a copy of the repo to avoid concurrent map writes error
+	defer gitopsRepoCache.CleanupWrittenRepo(pathToCleanUp)
+	if err != nil {
+		logrus.Errorf("cannot get gitops repo for write: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	since := time.Now().Add(-1 * time.Hour * 24 * time.Duration(config.ReleaseHistorySinceDays))
+	releases, err := gitops.Releases(repo, app, env, repoPerEnv, &since, nil, -1, "", perf)
+	if err != nil {
+		logrus.Errorf("cannot get releases: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	from, to, err := releasesToDiff(releases, params.Get("from"), params.Get("to"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("%s: %s", http.StatusText(http.StatusNotFound), err), http.StatusNotFound)
+		return
+	}
+	from.GitopsRepo = repoName
+	to.GitopsRepo = repoName
+
+	diff := api.ReleaseDiff{
+		Env:  env,
+		App:  app,
+		From: from,
+		To:   to,
+	}
+
+	diff.Commits, err = releaseCommits(gitopsRepoCache, db, from, to)
+	if err != nil {
+		logrus.Warnf("cannot list source commits: %s", err)
+		diff.Commits = []*model.Commit{}
+		diff.CommitsUnavailable = err.Error()
+	}
+
+	fromValues, fromErr := manifestValues(db, from, env, app)
+	toValues, toErr := manifestValues(db, to, env, app)
+	if fromErr != nil || toErr != nil {
+		for _, err := range []error{fromErr, toErr} {
+			if err != nil {
+				logrus.Warnf("cannot get manifest values: %s", err)
+				diff.ValuesUnavailable = err.Error()
+			}
+		}
+	} else {
+		diff.Values, err = gitops.UnifiedDiff(
+			map[string]string{"values.yaml": fromValues},
+			map[string]string{"values.yaml": toValues},
+		)
+		if err != nil {
+			logrus.Errorf("cannot diff values: %s", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	fromManifests, err := gitops.AppManifestsOnHash(repo, from.GitopsRef, env, app, repoPerEnv)
+	if err != nil {
+		logrus.Errorf("cannot get manifests of %s: %s", from.GitopsRef, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	toManifests, err := gitops.AppManifestsOnHash(repo, to.GitopsRef, env, app, repoPerEnv)
+	if err != nil {
+		logrus.Errorf("cannot get manifests of %s: %s", to.GitopsRef, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	diff.Manifests, err = gitops.UnifiedDiff(fromManifests, toManifests)
+	if err != nil {
+		logrus.Errorf("cannot diff manifests: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	diffString, err := json.Marshal(diff)
+	if err != nil {
+		logrus.Errorf("cannot serialize release diff: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(diffString)
+}
+
+// releasesToDiff picks the two releases to compare from the release history, that is ordered newest first.
+// Refs may be abbreviated. The default of to is the latest release, the default of from is the release before to
+func releasesToDiff(releases []*dx.Release, fromRef, toRef string) (*dx.Release, *dx.Release, error) {
+	find := func(ref string) int {
+		for i, release := range releases {
+			if strings.HasPrefix(release.GitopsRef, ref) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	if len(releases) == 0 {
+		return nil, nil, fmt.Errorf("no releases found")
+	}
+
+	toIdx := 0
+	if toRef != "" {
+		toIdx = find(toRef)
+		if toIdx == -1 {
+			return nil, nil, fmt.Errorf("release %s not found in the release history", toRef)
+		}
+	}
+
+	fromIdx := toIdx + 1
+	if fromRef != "" {
+		fromIdx = find(fromRef)
+		if fromIdx == -1 {
+			return nil, nil, fmt.Errorf("release %s not found in the release history", fromRef)
+		}
+	} else if fromIdx >= len(releases) {
+		return nil, nil, fmt.Errorf("no release before %s", releases[toIdx].GitopsRef)
+	}
+
+	return releases[fromIdx], releases[toIdx], nil
+}
+
+// maxReleaseCommits caps the source commits listed between two releases
+const maxReleaseCommits = 250
+
+// releaseCommits returns the source commits between two releases, if both are built from the same repository.
+// They are the commits that the newer version has on top of the older one, walked in the source repository
+func releaseCommits(gitRepoCache *nativeGit.RepoCache, db *store.Store, from *dx.Release, to *dx.Release) ([]*model.Commit, error) {
+	if from.Version == nil || to.Version == nil ||
+		from.Version.RepositoryName != to.Version.RepositoryName {
+		return []*model.Commit{}, nil
+	}
+	repoName := to.Version.RepositoryName
+
+	repo, err := gitRepoCache.InstanceForReadWithHistory(repoName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get repo %s: %s", repoName, err)
+	}
+
+	commits, err := commitsBetween(repo, from.Version.SHA, to.Version.SHA)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 { // a rollback
+		commits, err = commitsBetween(repo, to.Version.SHA, from.Version.SHA)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return withStoredCommits(db, repoName, commits), nil
+}
+
+// commitsBetween returns the commits reachable from head, but not from base, newest first
+func commitsBetween(repo *git.Repository, base string, head string) ([]*model.Commit, error) {
+	baseCommit, err := repo.CommitObject(plumbing.NewHash(base))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find commit %s: %s", base, err)
+	}
+	headCommit, err := repo.CommitObject(plumbing.NewHash(head))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find commit %s: %s", head, err)
+	}
+
+	reachable := map[plumbing.Hash]bool{}
+	err = object.NewCommitPreorderIter(baseCommit, nil, nil).ForEach(func(c *object.Commit) error {
+		reachable[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot walk commits: %s", err)
+	}
+
+	commits := []*model.Commit{}
+	err = object.NewCommitPreorderIter(headCommit, reachable, nil).ForEach(func(c *object.Commit) error {
+		if len(commits) >= maxReleaseCommits {
+			return storer.ErrStop
+		}
+		commits = append(commits, &model.Commit{
+			SHA:     c.Hash.String(),
+			Author:  c.Author.Name,
+			Message: c.Message,
+			Created: c.Committer.When.Unix(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot walk commits: %s", err)
+	}
+
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Created > commits[j].Created
+	})
+	return commits, nil
+}
+
+// withStoredCommits replaces the walked commits with the ones stored from the SCM, as those have links and statuses
+func withStoredCommits(db *store.Store, repo string, commits []*model.Commit) []*model.Commit {
+	shas := []string{}
+	for _, c := range commits {
+		shas = append(shas, c.SHA)
+	}
+	stored, err := db.CommitsByRepoAndSHA(repo, shas)
+	if err != nil {
+		logrus.Warnf("cannot get commits: %s", err)
+		return commits
+	}
+
+	storedBySHA := map[string]*model.Commit{}
+	for _, c := range stored {
+		storedBySHA[c.SHA] = c
+	}
+	for i, c := range commits {
+		if storedCommit, ok := storedBySHA[c.SHA]; ok {
+			storedCommit.Created = c.Created
+			commits[i] = storedCommit
+		}
+	}
+	return commits
+}
+
+// manifestValues returns the manifest values of a release as yaml, from the released artifact
+func manifestValues(db *store.Store, release *dx.Release, env string, app string) (string, error) {
+	artifactEvent, err := db.Artifact(release.ArtifactID)
+	if err != nil {
+		return "", fmt.Errorf("cannot find artifact %s: %s", release.ArtifactID, err)
+	}
+	artifact, err := model.ToArtifact(artifactEvent)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse artifact %s: %s", release.ArtifactID, err)
+	}
+	manifests, err := artifact.CueEnvironmentsToManifests()
+	if err != nil {
+		return "", fmt.Errorf("cannot parse cue environments of %s: %s", release.ArtifactID, err)
+	}
+	artifact.Environments = append(artifact.Environments, manifests...)
+
+	for _, manifest := range artifact.Environments {
+		if manifest.Env != env {
+			continue
+		}
+		err = manifest.ResolveVars(artifact.CollectVariables())
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve variables of %s: %s", release.ArtifactID, err)
+		}
+		if manifest.App != app {
+			continue
+		}
+
+		values, err := yaml.Marshal(manifest.Values)
+		if err != nil {
+			return "", fmt.Errorf("cannot serialize values of %s: %s", release.ArtifactID, err)
+		}
+		return string(values), nil
+	}
+
+	return "", fmt.Errorf("artifact %s has no manifest of %s in %s", release.ArtifactID, app, env)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleasesToDiff(t *testing.T) {
+	releases := []*dx.Release{
+		{GitopsRef: "ccc333"},
+		{GitopsRef: "bbb222"},
+		{GitopsRef: "aaa111"},
+	}
+
+	from, to, err := releasesToDiff(releases, "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "bbb222", from.GitopsRef, "should compare with the release before")
+	assert.Equal(t, "ccc333", to.GitopsRef, "should default to the latest release")
+
+	from, to, err = releasesToDiff(releases, "", "bbb")
+	assert.Nil(t, err)
+	assert.Equal(t, "aaa111", from.GitopsRef)
+	assert.Equal(t, "bbb222", to.GitopsRef, "should accept abbreviated refs")
+
+	from, to, err = releasesToDiff(releases, "ccc333", "aaa111")
+	assert.Nil(t, err)
+	assert.Equal(t, "ccc333", from.GitopsRef)
+	assert.Equal(t, "aaa111", to.GitopsRef)
+
+	_, _, err = releasesToDiff(releases, "", "aaa111")
+	assert.NotNil(t, err, "the oldest release has nothing to compare with")
+
+	_, _, err = releasesToDiff(releases, "ddd", "")
+	assert.NotNil(t, err)
+
+	_, _, err = releasesToDiff([]*dx.Release{}, "", "")
+	assert.NotNil(t, err)
+}
+
+func TestCommitsBetween(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	assert.Nil(t, err)
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+
+	start := time.Now().Add(-time.Hour)
+	commit := func(message string, authored time.Time, committed time.Time) string {
+		err := util.WriteFile(worktree.Filesystem, "file", []byte(message), 0666)
+		assert.Nil(t, err)
+		_, err = worktree.Add("file")
+		assert.Nil(t, err)
+		sha, err := worktree.Commit(message, &git.CommitOptions{
+			Author:    &object.Signature{Name: "Gimlet", Email: "gimlet@gimlet.io", When: authored},
+			Committer: &object.Signature{Name: "Gimlet", Email: "gimlet@gimlet.io", When: committed},
+		})
+		assert.Nil(t, err)
+		return sha.String()
+	}
+
+	first := commit("first", start, start)
+	skewed := commit("authored long ago", start.Add(-30*24*time.Hour), start.Add(time.Minute))
+
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	assert.Nil(t, err)
+	commit("on another branch", start.Add(2*time.Minute), start.Add(2*time.Minute))
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master")})
+	assert.Nil(t, err)
+	last := commit("last", start.Add(3*time.Minute), start.Add(3*time.Minute))
+
+	commits, err := commitsBetween(repo, first, last)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(commits), "commits of other branches should be left out")
+	assert.Equal(t, last, commits[0].SHA)
+	assert.Equal(t, skewed, commits[1].SHA, "commits should be found regardless of their author date")
+
+	commits, err = commitsBetween(repo, last, first)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(commits))
+
+	_, err = commitsBetween(repo, "0000000000000000000000000000000000000000", last)
+	assert.NotNil(t, err)
+}
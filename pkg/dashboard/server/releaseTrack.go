@@ -0,0 +1,205 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	trackStatusPeriod = 5 * time.Second
+	trackWriteWait    = 10 * time.Second
+)
+
+var trackUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// releaseTrackScope tells which client hub messages are about a release request
+type releaseTrackScope struct {
+	shas map[string]bool
+	envs map[string]bool
+	apps map[string]bool // env/app
+}
+
+func newReleaseTrackScope(status *dx.ReleaseStatus) *releaseTrackScope {
+	scope := &releaseTrackScope{
+		shas: map[string]bool{},
+		envs: map[string]bool{},
+		apps: map[string]bool{},
+	}
+	for _, result := range status.Results {
+		if result.Hash != "" {
+			scope.shas[result.Hash] = true
+		}
+		scope.envs[result.Env] = true
+		scope.apps[result.Env+"/"+result.App] = true
+	}
+	return scope
+}
+
+func (s *releaseTrackScope) relevant(message []byte) bool {
+	var event streaming.StreamingEvent
+	err := json.Unmarshal(message, &event)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case event.Event == streaming.GitopsCommitEventString:
+		var gitopsEvent struct {
+			GitopsCommit model.GitopsCommit `json:"gitopsCommit"`
+		}
+		err = json.Unmarshal(message, &gitopsEvent)
+		return err == nil && s.shas[gitopsEvent.GitopsCommit.Sha]
+	case event.Event == streaming.FluxStateUpdatedEventString:
+		var fluxStateEvent streaming.FluxStateUpdatedEvent
+		err = json.Unmarshal(message, &fluxStateEvent)
+		return err == nil && s.envs[fluxStateEvent.EnvName]
+	case strings.HasPrefix(event.Event, "pod") || strings.HasPrefix(event.Event, "deployment"):
+		var update api.StackUpdate
+		err = json.Unmarshal(message, &update)
+		if err != nil {
+			return false
+		}
+		parts := strings.SplitN(update.Svc, "/", 2)
+		return len(parts) == 2 && s.apps[update.Env+"/"+parts[1]]
+	}
+
+	return false
+}
+
+// finished tells if a release request reached its final state
+func finished(status *dx.ReleaseStatus) bool {
+	switch status.Status {
+	case model.StatusError:
+		return true
+	case model.StatusProcessed:
+		allGitopsCommitsApplied, gitopsCommitsHaveFailed := status.ExtractGitopsEndState()
+		return allGitopsCommitsApplied || gitopsCommitsHaveFailed
+	}
+	return false
+}
+
+// followEventReleaseTrack streams the progress of a release request on a websocket:
+// its status, and the gitops commit, Flux and pod updates of the released apps.
+// The stream ends when the release reaches its final state
+func followEventReleaseTrack(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, fmt.Sprintf("%s: %s", http.StatusText(http.StatusBadRequest), "id parameter is mandatory"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	db := ctx.Value("store").(*store.Store)
+	clientHub, _ := ctx.Value("clientHub").(*streaming.ClientHub)
+	user := ctx.Value("user").(*model.User)
+
+	_, err := db.EventReleaseTrack(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.Errorf("cannot get event: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	conn, err := trackUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logrus.Errorf("cannot upgrade to websocket: %s", err)
+		return
+	}
+	defer conn.Close()
+
+	subscription := clientHub.Subscribe(user.Login)
+	defer func() {
+		clientHub.Unregister <- subscription
+	}()
+
+	// the client only sends a close message, reading is needed to notice it
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(message []byte) error {
+		conn.SetWriteDeadline(time.Now().Add(trackWriteWait))
+		return conn.WriteMessage(websocket.TextMessage, message)
+	}
+
+	var scope *releaseTrackScope
+	var lastStatus []byte
+	// sendStatus sends the release status if it changed, and reports whether the release is finished
+	sendStatus := func() (bool, error) {
+		event, err := db.EventReleaseTrack(id)
+		if err != nil {
+			return false, err
+		}
+		status := releaseStatus(db, event)
+		scope = newReleaseTrackScope(status)
+
+		statusBytes, _ := json.Marshal(streaming.ReleaseStatusEvent{
+			StreamingEvent: streaming.StreamingEvent{Event: streaming.ReleaseStatusEventString},
+			TrackingID:     id,
+			ReleaseStatus:  status,
+		})
+		if string(statusBytes) != string(lastStatus) {
+			lastStatus = statusBytes
+			err = write(statusBytes)
+			if err != nil {
+				return false, err
+			}
+		}
+		return finished(status), nil
+	}
+
+	ticker := time.NewTicker(trackStatusPeriod)
+	defer ticker.Stop()
+	done, err := sendStatus()
+	for !done && err == nil {
+		select {
+		case <-closed:
+			return
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				err = fmt.Errorf("dropped by the client hub")
+				break
+			}
+			if !scope.relevant(message) {
+				continue
+			}
+			err = write(message)
+			if err == nil && strings.Contains(string(message), streaming.GitopsCommitEventString) {
+				done, err = sendStatus()
+			}
+		case <-ticker.C:
+			done, err = sendStatus()
+		}
+	}
+	if err != nil {
+		logrus.Debugf("release track stream of %s ended: %s", id, err)
+		return
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(trackWriteWait))
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "release finished"))
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseTrackScope(t *testing.T) {
+	scope := newReleaseTrackScope(&dx.ReleaseStatus{
+		Results: []dx.Result{
+			{App: "myapp", Env: "staging", Hash: "abc123"},
+		},
+	})
+
+	message := func(v interface{}) []byte {
+		b, _ := json.Marshal(v)
+		return b
+	}
+
+	assert.True(t, scope.relevant(message(map[string]interface{}{
+		"event":        streaming.GitopsCommitEventString,
+		"gitopsCommit": model.GitopsCommit{Sha: "abc123"},
+	})), "gitops commit of the release should be relevant")
+	assert.False(t, scope.relevant(message(map[string]interface{}{
+		"event":        streaming.GitopsCommitEventString,
+		"gitopsCommit": model.GitopsCommit{Sha: "def456"},
+	})), "other gitops commits should not be relevant")
+
+	assert.True(t, scope.relevant(message(streaming.FluxStateUpdatedEvent{
+		StreamingEvent: streaming.StreamingEvent{Event: streaming.FluxStateUpdatedEventString},
+		EnvName:        "staging",
+	})))
+	assert.False(t, scope.relevant(message(streaming.FluxStateUpdatedEvent{
+		StreamingEvent: streaming.StreamingEvent{Event: streaming.FluxStateUpdatedEventString},
+		EnvName:        "production",
+	})))
+
+	assert.True(t, scope.relevant(message(api.StackUpdate{Event: "podUpdated", Env: "staging", Svc: "default/myapp"})))
+	assert.False(t, scope.relevant(message(api.StackUpdate{Event: "podUpdated", Env: "production", Svc: "default/myapp"})))
+	assert.False(t, scope.relevant(message(api.StackUpdate{Event: "deploymentUpdated", Env: "staging", Svc: "default/otherapp"})))
+
+	assert.False(t, scope.relevant([]byte("not json")))
+}
+
+func TestReleaseTrackFinished(t *testing.T) {
+	assert.False(t, finished(&dx.ReleaseStatus{Status: model.StatusNew}))
+	assert.True(t, finished(&dx.ReleaseStatus{Status: model.StatusError}))
+	assert.False(t, finished(&dx.ReleaseStatus{
+		Status:  model.StatusProcessed,
+		Results: []dx.Result{{GitopsCommitStatus: dx.NotReconciled}},
+	}), "should wait for the gitops commits to be applied")
+	assert.True(t, finished(&dx.ReleaseStatus{
+		Status:  model.StatusProcessed,
+		Results: []dx.Result{{GitopsCommitStatus: dx.ReconciliationSucceeded}},
+	}))
+	assert.True(t, finished(&dx.ReleaseStatus{
+		Status:  model.StatusProcessed,
+		Results: []dx.Result{{GitopsCommitStatus: dx.ReconciliationFailed}},
+	}))
+}
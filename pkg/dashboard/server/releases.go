@@ -13,8 +13,10 @@ import (
 	"time"
 
 	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/config"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/gitops"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 	"github.com/gimlet-io/gimlet-cli/pkg/git/customScm"
@@ -273,6 +275,75 @@ func release(w http.ResponseWriter, r *http.Request) {
 	w.Write(eventIDBytes)
 }
 
+// promote releases several apps to an env in a single gitops commit
+func promote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	store := ctx.Value("store").(*store.Store)
+	user := ctx.Value("user").(*model.User)
+
+	var promotionRequest dx.PromotionRequest
+	err := json.NewDecoder(r.Body).Decode(&promotionRequest)
+	if err != nil {
+		logrus.Errorf("cannot decode promotion request: %s", err)
+		http.Error(w, http.StatusText(400), 400)
+		return
+	}
+
+	if promotionRequest.Env == "" {
+		http.Error(w, fmt.Sprintf("%s: %s", http.StatusText(http.StatusBadRequest), "env parameter is mandatory"), http.StatusBadRequest)
+		return
+	}
+	if len(promotionRequest.Releases) == 0 {
+		http.Error(w, fmt.Sprintf("%s: %s", http.StatusText(http.StatusBadRequest), "releases are mandatory"), http.StatusBadRequest)
+		return
+	}
+
+	releases := []dx.ReleaseRequest{}
+	for _, releaseRequest := range promotionRequest.Releases {
+		if releaseRequest.App == "" || releaseRequest.ArtifactID == "" {
+			http.Error(w, fmt.Sprintf("%s: %s", http.StatusText(http.StatusBadRequest), "app and artifact are mandatory for each release"), http.StatusBadRequest)
+			return
+		}
+		_, err := store.Artifact(releaseRequest.ArtifactID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%s - cannot find artifact with id %s", http.StatusText(http.StatusNotFound), releaseRequest.ArtifactID), http.StatusNotFound)
+			return
+		}
+		releases = append(releases, dx.ReleaseRequest{
+			Env:         promotionRequest.Env,
+			App:         releaseRequest.App,
+			ArtifactID:  releaseRequest.ArtifactID,
+			TriggeredBy: user.Login,
+		})
+	}
+
+	promotionRequestStr, err := json.Marshal(dx.PromotionRequest{
+		Env:         promotionRequest.Env,
+		Releases:    releases,
+		TriggeredBy: user.Login,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("%s - cannot serialize promotion request: %s", http.StatusText(http.StatusInternalServerError), err), http.StatusInternalServerError)
+		return
+	}
+
+	event, err := store.CreateEvent(&model.Event{
+		Type: model.PromotionRequestedEvent,
+		Blob: string(promotionRequestStr),
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("%s - cannot save promotion request: %s", http.StatusText(http.StatusInternalServerError), err), http.StatusInternalServerError)
+		return
+	}
+
+	eventIDBytes, _ := json.Marshal(map[string]string{
+		"id": event.ID,
+	})
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(eventIDBytes)
+}
+
 func performRollback(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	store := ctx.Value("store").(*store.Store)
@@ -443,11 +514,21 @@ func getEventReleaseTrack(w http.ResponseWriter, r *http.Request) {
 	event, err := store.EventReleaseTrack(id)
 	if err == sql.ErrNoRows {
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
 	} else if err != nil {
 		logrus.Errorf("cannot get event: %s", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
 	}
 
+	statusBytes, _ := json.Marshal(releaseStatus(store, event))
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(statusBytes)
+}
+
+// releaseStatus assembles the outcome of a release or rollback request with the state of its gitops commits
+func releaseStatus(store *store.Store, event *model.Event) *dx.ReleaseStatus {
 	results := []dx.Result{}
 	for _, result := range event.Results {
 		gitopsCommitStatus, gitopsCommitStatusDesc, _ := gitopsCommitMetasFromHash(store, result.GitopsRef)
@@ -471,17 +552,15 @@ func getEventReleaseTrack(w http.ResponseWriter, r *http.Request) {
 			GitopsCommitStatusDesc: gitopsCommitStatusDesc,
 			Env:                    result.Manifest.Env,
 			StatusDesc:             result.StatusDesc,
+			Signature:              result.Signature,
 		})
 	}
 
-	statusBytes, _ := json.Marshal(dx.ReleaseStatus{
+	return &dx.ReleaseStatus{
 		Status:     event.Status,
 		StatusDesc: event.StatusDesc,
 		Results:    results,
-	})
-
-	w.WriteHeader(http.StatusOK)
-	w.Write(statusBytes)
+	}
 }
 
 func getEventArtifactTrack(w http.ResponseWriter, r *http.Request) {
@@ -517,6 +596,7 @@ func getEventArtifactTrack(w http.ResponseWriter, r *http.Request) {
 			GitopsCommitStatusDesc: gitopsCommitStatusDesc,
 			Env:                    result.Manifest.Env,
 			StatusDesc:             result.StatusDesc,
+			Signature:              result.Signature,
 		})
 	}
 
@@ -545,3 +625,49 @@ func gitopsCommitMetasFromHash(store *store.Store, gitopsRef string) (string, st
 
 	return gitopsCommit.Status, gitopsCommit.StatusDesc, gitopsCommit.Created
 }
+
+// getUsage returns the pods of each app in an env with their resource requests, limits and usage
+func getUsage(w http.ResponseWriter, r *http.Request) {
+	app := r.URL.Query().Get("app")
+	env := r.URL.Query().Get("env")
+	if env == "" {
+		http.Error(w, fmt.Sprintf("%s: %s", http.StatusText(http.StatusBadRequest), "env parameter is mandatory"), http.StatusBadRequest)
+		return
+	}
+
+	agentHub, _ := r.Context().Value("agentHub").(*streaming.AgentHub)
+	agent, ok := agentHub.Agent(env)
+	if !ok {
+		http.Error(w, fmt.Sprintf("%s: %s", http.StatusText(http.StatusNotFound), "agent is not connected"), http.StatusNotFound)
+		return
+	}
+
+	usage := map[string][]*api.Pod{}
+	for _, stack := range agent.Stacks {
+		if stack.Service == nil || (app != "" && stack.Service.Name != app) {
+			continue
+		}
+
+		var pods []*api.Pod
+		if stack.Deployment != nil {
+			pods = append(pods, stack.Deployment.Pods...)
+		}
+		if stack.StatefulSet != nil {
+			pods = append(pods, stack.StatefulSet.Pods...)
+		}
+		if stack.DaemonSet != nil {
+			pods = append(pods, stack.DaemonSet.Pods...)
+		}
+		usage[stack.Service.Name] = pods
+	}
+
+	usageString, err := json.Marshal(usage)
+	if err != nil {
+		logrus.Errorf("cannot serialize usage: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(usageString)
+}
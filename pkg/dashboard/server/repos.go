@@ -7,6 +7,7 @@ import (
 
 	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/config"
 	"github.com/gimlet-io/gimlet-cli/cmd/dashboard/dynamicconfig"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
 	"github.com/gimlet-io/gimlet-cli/pkg/git/customScm"
@@ -179,12 +180,8 @@ func intersection(s1, s2 []string) []string {
 	return inter
 }
 
-type favRepos struct {
-	FavoriteRepos []string `json:"favoriteRepos"`
-}
-
 func saveFavoriteRepos(w http.ResponseWriter, r *http.Request) {
-	var reposPayload favRepos
+	var reposPayload api.FavoriteRepos
 	err := json.NewDecoder(r.Body).Decode(&reposPayload)
 	if err != nil {
 		logrus.Errorf("cannot decode repos payload: %s", err)
@@ -209,7 +206,7 @@ func saveFavoriteRepos(w http.ResponseWriter, r *http.Request) {
 }
 
 func saveFavoriteServices(w http.ResponseWriter, r *http.Request) {
-	var servicesPayload map[string]interface{}
+	var servicesPayload api.FavoriteServices
 	err := json.NewDecoder(r.Body).Decode(&servicesPayload)
 	if err != nil {
 		logrus.Errorf("cannot decode services payload: %s", err)
@@ -217,20 +214,11 @@ func saveFavoriteServices(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var services []string
-	if s, ok := servicesPayload["favoriteServices"]; ok {
-		services = s.([]string)
-	} else {
-		logrus.Errorf("cannot get favorite services: %s", err)
-		http.Error(w, http.StatusText(400), 400)
-		return
-	}
-
 	ctx := r.Context()
 	user := ctx.Value("user").(*model.User)
 	dao := ctx.Value("store").(*store.Store)
 
-	user.FavoriteServices = services
+	user.FavoriteServices = servicesPayload.FavoriteServices
 	err = dao.UpdateUser(user)
 	if err != nil {
 		logrus.Errorf("cannot save favorite services: %s", err)
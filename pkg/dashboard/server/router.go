@@ -68,6 +68,9 @@ func SetupRouter(
 	r.Use(middleware.WithValue("imageBuilds", imageBuilds))
 	r.Use(middleware.WithValue("router", r))
 	r.Use(middleware.WithValue("gitUser", gitUser))
+	if agentWSHub != nil {
+		r.Use(middleware.WithValue("podLogs", agentWSHub.PodLogs))
+	}
 
 	r.Use(middleware.WithValue("notificationsManager", notificationsManager))
 	r.Use(middleware.WithValue("perf", perf))
@@ -120,16 +123,22 @@ func gimletdRoutes(r *chi.Mux) {
 		r.Post("/api/artifact", saveArtifact)
 		r.Get("/api/artifacts", getArtifacts)
 		r.Get("/api/releases", getReleases)
+		r.Get("/api/releases/diff", getReleaseDiff)
 		r.Get("/api/status", getStatus)
+		r.Get("/api/usage", getUsage)
 		r.Post("/api/releases", release)
+		r.Post("/api/promotions", promote)
 		r.Post("/api/deploy", magicDeploy)
 		r.Post("/api/rollback", performRollback)
 		r.Post("/api/delete", delete)
 		r.Get("/api/eventReleaseTrack", getEventReleaseTrack)
+		r.Get("/api/eventReleaseTrack/follow", followEventReleaseTrack)
 		r.Get("/api/eventArtifactTrack", getEventArtifactTrack)
 		r.Post("/api/flux-events", fluxEvent)
 		r.Get("/api/gitopsCommits", getGitopsCommits)
 		r.Get("/api/gitopsManifests/{env}", getGitopsManifests)
+		r.Get("/api/insights", getInsights)
+		r.Get("/api/environments/{env}/artifactSigning", getArtifactSigning)
 	})
 
 	r.Group(func(r chi.Router) {
@@ -139,6 +148,7 @@ func gimletdRoutes(r *chi.Mux) {
 		r.Post("/api/user", saveUserGimletD)
 		r.Post("/api/deleteUser", deleteUser)
 		r.Get("/api/users", getUsers)
+		r.Post("/api/environments/{env}/artifactSigning", saveArtifactSigning)
 	})
 }
 
@@ -154,7 +164,16 @@ func userRoutes(r *chi.Mux, clientHub *streaming.ClientHub) {
 		r.Get("/api/envs", envs)
 		r.Get("/api/podLogs", getPodLogs)
 		r.Get("/api/stopPodLogs", stopPodLogs)
+		r.Get("/api/podLogs/follow", followPodLogs)
+		r.Get("/api/crashLogs", getCrashLogs)
 		r.Get("/api/alerts", getAlerts)
+		r.Post("/api/alerts/acknowledge", acknowledgeAlert)
+		r.Get("/api/silences", getSilences)
+		r.Post("/api/silences", saveSilence)
+		r.Post("/api/silences/{id}/delete", deleteSilence)
+		r.Get("/api/actions", getWorkloadActions)
+		r.Post("/api/actions", saveWorkloadAction)
+		r.Get("/api/actions/{id}", getWorkloadAction)
 		r.Get("/api/gitRepos", gitRepos)
 		r.Get("/api/refreshRepos", refreshRepos)
 		r.Get("/api/settings", settings)
@@ -192,9 +211,13 @@ func agentRoutes(r *chi.Mux, agentWSHub *streaming.AgentWSHub, jwtSecret string)
 
 		r.Get("/agent/register", register)
 		r.Post("/agent/state", state)
+		r.Post("/agent/state/delta", stateDelta)
 		r.Post("/agent/state/{name}/update", update)
 		r.Post("/agent/events", events)
 		r.Post("/agent/fluxState", fluxState)
+		r.Get("/agent/gitopsManifests", agentGitopsManifests)
+		r.Post("/agent/drift", drift)
+		r.Post("/agent/certificates", certificates)
 		r.Get("/agent/imagebuild/{imageBuildId}", imageBuild)
 
 		r.Get("/agent/ws/", func(w http.ResponseWriter, r *http.Request) {
@@ -23,7 +23,8 @@ type PodLogWSMessage struct {
 	Timestamp string `json:"timestamp"`
 	Container string `json:"container"`
 	Message   string `json:"message"`
-	Pod       string `json:"pod"`
+	Pod       string `json:"pod"` // namespace/service
+	PodName   string `json:"podName"`
 }
 
 type ImageBuildStatusWSMessage struct {
@@ -33,6 +34,12 @@ type ImageBuildStatusWSMessage struct {
 	ClientId string `json:"clientId"`
 }
 
+type WorkloadActionResultWSMessage struct {
+	ID         int64  `json:"id"`
+	Status     string `json:"status"`
+	StatusDesc string `json:"statusDesc"`
+}
+
 // Client is a middleman between the websocket connection and the hub.
 type AgentWSClient struct {
 	hub *AgentWSHub
@@ -85,13 +92,16 @@ func (c *AgentWSClient) readPump() {
 				log.Errorf("could not decode podlog ws message from agent")
 			}
 
-			jsonString, _ := json.Marshal(PodLogsEvent{
+			podLogsEvent := PodLogsEvent{
 				StreamingEvent: StreamingEvent{Event: PodLogsEventString},
 				Timestamp:      podLogWSMessage.Timestamp,
 				Container:      podLogWSMessage.Container,
 				Pod:            podLogWSMessage.Pod,
+				PodName:        podLogWSMessage.PodName,
 				Message:        podLogWSMessage.Message,
-			})
+			}
+			c.hub.PodLogs.Publish(&podLogsEvent)
+			jsonString, _ := json.Marshal(podLogsEvent)
 			c.hub.ClientHub.Broadcast <- jsonString
 		}
 
@@ -117,6 +127,17 @@ func (c *AgentWSClient) readPump() {
 				c.hub.successfullImageBuilds <- imageBuildStatus
 			}
 		}
+
+		if wsMessage.Type == "workloadActionResult" {
+			var result WorkloadActionResultWSMessage
+			err = json.Unmarshal([]byte(wsMessage.Payload), &result)
+			if err != nil {
+				log.Errorf("could not decode workload action result ws message from agent")
+				continue
+			}
+
+			c.hub.workloadActionResults <- result
+		}
 	}
 }
 
@@ -195,16 +216,26 @@ type AgentWSHub struct {
 
 	ClientHub *ClientHub
 
+	// PodLogs fans out the streamed log lines to the API clients that follow them
+	PodLogs *PodLogBroker
+
 	successfullImageBuilds chan ImageBuildStatusWSMessage
+	workloadActionResults  chan WorkloadActionResultWSMessage
 }
 
-func NewAgentWSHub(clientHub ClientHub, successfullImageBuilds chan ImageBuildStatusWSMessage) *AgentWSHub {
+func NewAgentWSHub(
+	clientHub ClientHub,
+	successfullImageBuilds chan ImageBuildStatusWSMessage,
+	workloadActionResults chan WorkloadActionResultWSMessage,
+) *AgentWSHub {
 	return &AgentWSHub{
 		Register:               make(chan *AgentWSClient),
 		Unregister:             make(chan *AgentWSClient),
 		AgentWSClients:         make(map[*AgentWSClient]bool),
 		ClientHub:              &clientHub,
+		PodLogs:                NewPodLogBroker(),
 		successfullImageBuilds: successfullImageBuilds,
+		workloadActionResults:  workloadActionResults,
 	}
 }
 
@@ -2,8 +2,12 @@ package streaming
 
 import (
 	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 	"github.com/sirupsen/logrus"
 )
@@ -15,6 +19,59 @@ type ConnectedAgent struct {
 	EventChannel chan []byte    `json:"-"`
 	Stacks       []*api.Stack   `json:"-"`
 	FluxState    *api.FluxState `json:"-"`
+	Drifts       []*api.Drift   `json:"-"`
+
+	// StateSession and StateVersion identify the state version the stacks are at
+	StateSession string `json:"-"`
+	StateVersion int64  `json:"-"`
+}
+
+// ErrStateVersionMismatch is returned when a state delta is not based on the known state version
+var ErrStateVersionMismatch = fmt.Errorf("state version mismatch")
+
+// ErrAgentNotRegistered is returned when updating the state of an agent that is not connected
+var ErrAgentNotRegistered = fmt.Errorf("agent is not registered")
+
+// ApplyStateDelta brings the stacks to the version of the delta
+func (a *ConnectedAgent) ApplyStateDelta(delta *api.StateDelta) error {
+	if delta.Full {
+		a.Stacks = []*api.Stack{}
+	} else if delta.Session != a.StateSession || delta.BaseVersion != a.StateVersion {
+		return ErrStateVersionMismatch
+	}
+
+	deleted := map[string]bool{}
+	for _, key := range delta.Deleted {
+		deleted[key] = true
+	}
+	upserted := map[string]*api.Stack{}
+	for _, stack := range delta.Upserted {
+		stack.Env = a.Name
+		upserted[stack.Key()] = stack
+	}
+
+	stacks := []*api.Stack{}
+	for _, stack := range a.Stacks {
+		if deleted[stack.Key()] {
+			continue
+		}
+		if updated, ok := upserted[stack.Key()]; ok {
+			stacks = append(stacks, updated)
+			delete(upserted, stack.Key())
+			continue
+		}
+		stacks = append(stacks, stack)
+	}
+	for _, stack := range delta.Upserted {
+		if _, ok := upserted[stack.Key()]; ok {
+			stacks = append(stacks, stack)
+		}
+	}
+
+	a.Stacks = stacks
+	a.StateSession = delta.Session
+	a.StateVersion = delta.Version
+	return nil
 }
 
 type ImageBuildTrigger struct {
@@ -26,9 +83,26 @@ type ImageBuildTrigger struct {
 	SourcePath    string                `json:"sourcePath"`
 }
 
+type WorkloadActionTrigger struct {
+	Action         string               `json:"action"`
+	WorkloadAction model.WorkloadAction `json:"workloadAction"`
+}
+
+// disconnectedExpiry is how long the state of a disconnected agent is kept for it to resume
+const disconnectedExpiry = 24 * time.Hour
+
 // AgentHub is the central registry of all connected agents
 type AgentHub struct {
-	Agents map[string]*ConnectedAgent
+	// agents are the connected agents, read and updated with Agent, ConnectedAgents and UpdateAgent
+	agents map[string]*ConnectedAgent
+
+	// disconnected keeps the state of agents that went away,
+	// so they can resume sending deltas when they reconnect
+	disconnected map[string]*disconnectedAgent
+
+	// lock guards the agent maps and the state of the agents in them.
+	// Agents send their state on HTTP requests, concurrently to the registrations in Run
+	lock sync.RWMutex
 
 	// Register requests from the agents.
 	Register chan *ConnectedAgent
@@ -37,11 +111,17 @@ type AgentHub struct {
 	Unregister chan *ConnectedAgent
 }
 
+type disconnectedAgent struct {
+	agent          *ConnectedAgent
+	disconnectedAt time.Time
+}
+
 func NewAgentHub() *AgentHub {
 	return &AgentHub{
-		Register:   make(chan *ConnectedAgent),
-		Unregister: make(chan *ConnectedAgent),
-		Agents:     make(map[string]*ConnectedAgent),
+		Register:     make(chan *ConnectedAgent),
+		Unregister:   make(chan *ConnectedAgent),
+		agents:       make(map[string]*ConnectedAgent),
+		disconnected: make(map[string]*disconnectedAgent),
 	}
 }
 
@@ -49,23 +129,107 @@ func (h *AgentHub) Run() {
 	for {
 		select {
 		case agent := <-h.Register:
-			h.Agents[agent.Name] = agent
+			h.register(agent)
 		case agent := <-h.Unregister:
-			if _, ok := h.Agents[agent.Name]; ok {
-				delete(h.Agents, agent.Name)
-			}
+			h.unregister(agent)
+		}
+	}
+}
+
+func (h *AgentHub) register(agent *ConnectedAgent) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.expireDisconnected()
+	if previous, ok := h.disconnected[agent.Name]; ok {
+		agent.Stacks = previous.agent.Stacks
+		agent.FluxState = previous.agent.FluxState
+		agent.Drifts = previous.agent.Drifts
+		agent.StateSession = previous.agent.StateSession
+		agent.StateVersion = previous.agent.StateVersion
+		delete(h.disconnected, agent.Name)
+	}
+	h.agents[agent.Name] = agent
+}
+
+func (h *AgentHub) unregister(agent *ConnectedAgent) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.expireDisconnected()
+	if registered, ok := h.agents[agent.Name]; ok && registered == agent {
+		delete(h.agents, agent.Name)
+		h.disconnected[agent.Name] = &disconnectedAgent{
+			agent:          agent,
+			disconnectedAt: time.Now(),
+		}
+	}
+}
+
+func (h *AgentHub) expireDisconnected() {
+	for name, d := range h.disconnected {
+		if time.Since(d.disconnectedAt) > disconnectedExpiry {
+			delete(h.disconnected, name)
 		}
 	}
 }
 
+// Forget drops the kept state of a disconnected agent, used when its env is deleted
+func (h *AgentHub) Forget(name string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.disconnected, name)
+}
+
+// Agent returns a snapshot of a connected agent's state
+func (h *AgentHub) Agent(name string) (*ConnectedAgent, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	agent, ok := h.agents[name]
+	if !ok {
+		return nil, false
+	}
+	return agent.snapshot(), true
+}
+
+// ConnectedAgents returns a snapshot of the state of all connected agents
+func (h *AgentHub) ConnectedAgents() []*ConnectedAgent {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	agents := []*ConnectedAgent{}
+	for _, agent := range h.agents {
+		agents = append(agents, agent.snapshot())
+	}
+	return agents
+}
+
+// UpdateAgent changes the state of a connected agent with fn, and returns a snapshot of the updated state.
+// Updates of an agent are applied one at a time
+func (h *AgentHub) UpdateAgent(name string, fn func(agent *ConnectedAgent) error) (*ConnectedAgent, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	agent, ok := h.agents[name]
+	if !ok {
+		return nil, ErrAgentNotRegistered
+	}
+	err := fn(agent)
+	if err != nil {
+		return nil, err
+	}
+	return agent.snapshot(), nil
+}
+
 func (h *AgentHub) ForceStateSend() {
-	for _, a := range h.Agents {
+	for _, a := range h.ConnectedAgents() {
 		a.EventChannel <- []byte("{\"action\": \"refetch\"}")
 	}
 }
 
 func (h *AgentHub) TriggerImageBuild(trigger ImageBuildTrigger) {
-	for _, a := range h.Agents {
+	for _, a := range h.ConnectedAgents() {
 		if a.Name != trigger.DeployRequest.Env {
 			continue
 		}
@@ -81,6 +245,25 @@ func (h *AgentHub) TriggerImageBuild(trigger ImageBuildTrigger) {
 	}
 }
 
+// TriggerWorkloadAction sends an action to the agent of the action's env
+func (h *AgentHub) TriggerWorkloadAction(action model.WorkloadAction) error {
+	a, ok := h.Agent(action.Env)
+	if !ok {
+		return fmt.Errorf("agent of %s is not connected", action.Env)
+	}
+
+	triggerString, err := json.Marshal(WorkloadActionTrigger{
+		Action:         "workloadAction",
+		WorkloadAction: action,
+	})
+	if err != nil {
+		return fmt.Errorf("could not serialize request: %s", err)
+	}
+
+	a.EventChannel <- triggerString
+	return nil
+}
+
 func (h *AgentHub) StreamPodLogsSend(namespace string, serviceName string) {
 	podlogsRequest := map[string]interface{}{
 		"action":      "podLogs",
@@ -94,7 +277,7 @@ func (h *AgentHub) StreamPodLogsSend(namespace string, serviceName string) {
 		return
 	}
 
-	for _, a := range h.Agents {
+	for _, a := range h.ConnectedAgents() {
 		a.EventChannel <- []byte(podlogsRequestString)
 	}
 }
@@ -112,11 +295,37 @@ func (h *AgentHub) StopPodLogs(namespace string, serviceName string) {
 		return
 	}
 
-	for _, a := range h.Agents {
+	for _, a := range h.ConnectedAgents() {
 		a.EventChannel <- []byte(podlogsRequestString)
 	}
 }
 
+// snapshot copies the state of the agent. Stacks are copied deep, as the readers decorate them
+// with commit data after the hub's lock is released
+func (a *ConnectedAgent) snapshot() *ConnectedAgent {
+	snapshot := *a
+	snapshot.Stacks = copyStacks(a.Stacks)
+	return &snapshot
+}
+
+func copyStacks(stacks []*api.Stack) []*api.Stack {
+	if stacks == nil {
+		return nil
+	}
+
+	copied := []*api.Stack{}
+	stacksString, err := json.Marshal(stacks)
+	if err != nil {
+		logrus.Errorf("cannot copy stacks: %s", err)
+		return copied
+	}
+	err = json.Unmarshal(stacksString, &copied)
+	if err != nil {
+		logrus.Errorf("cannot copy stacks: %s", err)
+	}
+	return copied
+}
+
 func (a *ConnectedAgent) RepoStacks(repo string) []*api.Stack {
 	stacks := []*api.Stack{}
 
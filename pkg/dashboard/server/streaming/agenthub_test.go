@@ -0,0 +1,103 @@
+package streaming
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_concurrentStateDeltas(t *testing.T) {
+	hub := NewAgentHub()
+	hub.register(&ConnectedAgent{Name: "staging"})
+
+	_, err := hub.UpdateAgent("staging", func(agent *ConnectedAgent) error {
+		return agent.ApplyStateDelta(&api.StateDelta{Full: true, Session: "s", Version: 0})
+	})
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// every delta is retried until it is based on the current version, as agents do
+			for {
+				_, err := hub.UpdateAgent("staging", func(agent *ConnectedAgent) error {
+					return agent.ApplyStateDelta(&api.StateDelta{
+						Session:     "s",
+						BaseVersion: agent.StateVersion,
+						Version:     agent.StateVersion + 1,
+						Upserted:    []*api.Stack{{Service: &api.Service{Namespace: "default", Name: fmt.Sprintf("app%d", i)}}},
+					})
+				})
+				if err != ErrStateVersionMismatch {
+					return
+				}
+			}
+		}(i)
+		go hub.ConnectedAgents()
+	}
+	wg.Wait()
+
+	agent, ok := hub.Agent("staging")
+	assert.True(t, ok)
+	assert.Equal(t, int64(20), agent.StateVersion)
+	assert.Equal(t, 20, len(agent.Stacks))
+
+	_, err = hub.UpdateAgent("production", func(agent *ConnectedAgent) error { return nil })
+	assert.Equal(t, ErrAgentNotRegistered, err)
+}
+
+func Test_disconnectedAgents(t *testing.T) {
+	hub := NewAgentHub()
+	staging := &ConnectedAgent{Name: "staging", StateSession: "s", StateVersion: 3}
+	hub.register(staging)
+	hub.unregister(staging)
+
+	resumed := &ConnectedAgent{Name: "staging"}
+	hub.register(resumed)
+	assert.Equal(t, int64(3), resumed.StateVersion, "a reconnecting agent should resume its state")
+
+	hub.unregister(resumed)
+	hub.Forget("staging")
+	assert.Equal(t, 0, len(hub.disconnected), "deleted envs should not be kept")
+
+	production := &ConnectedAgent{Name: "production"}
+	hub.register(production)
+	hub.unregister(production)
+	hub.disconnected["production"].disconnectedAt = time.Now().Add(-disconnectedExpiry - time.Minute)
+	hub.register(&ConnectedAgent{Name: "staging"})
+	assert.Equal(t, 0, len(hub.disconnected), "the state of long gone agents should expire")
+}
+
+func Test_snapshotStacks(t *testing.T) {
+	hub := NewAgentHub()
+	hub.register(&ConnectedAgent{Name: "staging"})
+	_, err := hub.UpdateAgent("staging", func(agent *ConnectedAgent) error {
+		return agent.ApplyStateDelta(&api.StateDelta{
+			Full:     true,
+			Session:  "s",
+			Upserted: []*api.Stack{{Service: &api.Service{Namespace: "default", Name: "app"}, Deployment: &api.Deployment{SHA: "abc"}}},
+		})
+	})
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			agent, _ := hub.Agent("staging")
+			agent.Stacks[0].Deployment.CommitMessage = fmt.Sprintf("message %d", i)
+		}(i)
+	}
+	wg.Wait()
+
+	agent, _ := hub.Agent("staging")
+	assert.Equal(t, "abc", agent.Stacks[0].Deployment.SHA)
+	assert.Equal(t, "", agent.Stacks[0].Deployment.CommitMessage, "decorating a snapshot should not change the hub's state")
+}
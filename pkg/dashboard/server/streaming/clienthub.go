@@ -149,6 +149,23 @@ func ServeWs(hub *ClientHub, w http.ResponseWriter, r *http.Request) {
 	go client.readPump()
 }
 
+// Subscribe registers a client without a websocket connection, so server side code can
+// listen to the broadcasted messages. The messages channel is closed when the hub drops the client
+func (h *ClientHub) Subscribe(clientId string) *Client {
+	client := &Client{
+		hub:      h,
+		send:     make(chan []byte, 256),
+		clientId: clientId,
+	}
+	h.Register <- client
+	return client
+}
+
+// Messages returns the messages the hub sent to the client
+func (c *Client) Messages() <-chan []byte {
+	return c.send
+}
+
 // ClientHub maintains the set of active clients and broadcasts messages to the
 // clients.
 type ClientHub struct {
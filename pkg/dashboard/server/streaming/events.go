@@ -3,6 +3,7 @@ package streaming
 import (
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 )
 
 const AgentConnectedEventString = "agentConnected"
@@ -15,6 +16,9 @@ const PodLogsEventString = "podLogs"
 const ImageBuildLogEventString = "imageBuildLogEvent"
 const ArtifactCreatedEventString = "artifactCreatedEvent"
 const FluxStateUpdatedEventString = "fluxStateUpdatedEvent"
+const WorkloadActionEventString = "workloadActionEvent"
+const DriftUpdatedEventString = "driftUpdatedEvent"
+const ReleaseStatusEventString = "releaseStatus"
 
 type StreamingEvent struct {
 	Event string `json:"event"`
@@ -41,6 +45,18 @@ type FluxStateUpdatedEvent struct {
 	StreamingEvent
 }
 
+type DriftUpdatedEvent struct {
+	EnvName string       `json:"envName"`
+	Drifts  []*api.Drift `json:"drifts"`
+	StreamingEvent
+}
+
+type ReleaseStatusEvent struct {
+	TrackingID    string            `json:"trackingId"`
+	ReleaseStatus *dx.ReleaseStatus `json:"releaseStatus"`
+	StreamingEvent
+}
+
 type StaleRepoDataEvent struct {
 	Repo string `json:"repo"`
 	StreamingEvent
@@ -78,6 +94,12 @@ type PodLogsEvent struct {
 	Timestamp string `json:"timestamp"`
 	Container string `json:"container"`
 	Message   string `json:"message"`
-	Pod       string `json:"pod"`
+	Pod       string `json:"pod"` // namespace/service
+	PodName   string `json:"podName"`
+	StreamingEvent
+}
+
+type WorkloadActionEvent struct {
 	StreamingEvent
+	WorkloadAction *model.WorkloadAction `json:"workloadAction"`
 }
@@ -0,0 +1,55 @@
+package streaming
+
+import "sync"
+
+// PodLogBroker passes the log lines of a service to its followers.
+// Slow followers miss lines rather than holding up the agent connection
+type PodLogBroker struct {
+	lock      sync.Mutex
+	followers map[string]map[chan *PodLogsEvent]bool
+}
+
+func NewPodLogBroker() *PodLogBroker {
+	return &PodLogBroker{
+		followers: map[string]map[chan *PodLogsEvent]bool{},
+	}
+}
+
+// Follow subscribes to the log lines of a namespace/service
+func (b *PodLogBroker) Follow(svc string) chan *PodLogsEvent {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	lines := make(chan *PodLogsEvent, 100)
+	if b.followers[svc] == nil {
+		b.followers[svc] = map[chan *PodLogsEvent]bool{}
+	}
+	b.followers[svc][lines] = true
+	return lines
+}
+
+// Unfollow unsubscribes, and tells if the service has no followers left
+func (b *PodLogBroker) Unfollow(svc string, lines chan *PodLogsEvent) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.followers[svc], lines)
+	close(lines)
+	if len(b.followers[svc]) == 0 {
+		delete(b.followers, svc)
+		return true
+	}
+	return false
+}
+
+func (b *PodLogBroker) Publish(line *PodLogsEvent) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for lines := range b.followers[line.Pod] {
+		select {
+		case lines <- line:
+		default:
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+)
+
+func getWorkloadActions(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if val := r.URL.Query().Get("limit"); val != "" {
+		l, err := strconv.Atoi(val)
+		if err != nil || l <= 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest)+" - invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+
+	db := r.Context().Value("store").(*store.Store)
+	actions, err := db.WorkloadActions(limit)
+	if err != nil {
+		logrus.Errorf("cannot get workload actions from database: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	actionsString, err := json.Marshal(actions)
+	if err != nil {
+		logrus.Errorf("cannot serialize workload actions: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(actionsString)
+}
+
+func getWorkloadAction(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest)+" - invalid action id", http.StatusBadRequest)
+		return
+	}
+
+	db := r.Context().Value("store").(*store.Store)
+	action, err := db.WorkloadAction(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.Errorf("cannot get workload action: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	actionString, err := json.Marshal(action)
+	if err != nil {
+		logrus.Errorf("cannot serialize workload action: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(actionString)
+}
+
+// saveWorkloadAction records the action for auditing, then hands it to the agent of the env.
+// The agent reports the outcome over its websocket connection
+func saveWorkloadAction(w http.ResponseWriter, r *http.Request) {
+	var action model.WorkloadAction
+	err := json.NewDecoder(r.Body).Decode(&action)
+	if err != nil {
+		logrus.Errorf("cannot decode workload action: %s", err)
+		http.Error(w, http.StatusText(400), 400)
+		return
+	}
+
+	err = action.Validate()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest)+" - "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	user := ctx.Value("user").(*model.User)
+	db := ctx.Value("store").(*store.Store)
+	agentHub, _ := ctx.Value("agentHub").(*streaming.AgentHub)
+
+	action.ID = 0
+	action.TriggeredBy = user.Login
+	action.Created = time.Now().Unix()
+	action.Status = model.WorkloadActionPending
+	action.StatusDesc = ""
+	err = db.CreateWorkloadAction(&action)
+	if err != nil {
+		logrus.Errorf("cannot save workload action: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	err = agentHub.TriggerWorkloadAction(action)
+	if err != nil {
+		logrus.Warnf("cannot trigger workload action: %s", err)
+		updateErr := db.UpdateWorkloadActionStatus(action.ID, model.WorkloadActionFailed, err.Error())
+		if updateErr != nil {
+			logrus.Errorf("cannot update workload action: %s", updateErr)
+		}
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable)+" - "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	actionString, err := json.Marshal(action)
+	if err != nil {
+		logrus.Errorf("cannot serialize workload action: %s", err)
+		http.Error(w, http.StatusText(500), 500)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(actionString)
+}
+
+// flagScaleActions marks the replica drifts that match the last scale action of the workload.
+// Those are intended changes made from the dashboard, not drifts to alert on
+func flagScaleActions(db *store.Store, env string, drifts []*api.Drift) {
+	for _, drift := range drifts {
+		if drift.Type != api.DriftModified || drift.Field != "spec.replicas" {
+			continue
+		}
+
+		action, err := db.LastSucceededWorkloadAction(env, model.WorkloadActionScale, strings.ToLower(drift.Kind), drift.Namespace, drift.Name)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			logrus.Warnf("cannot get scale actions of %s: %s", drift.FQN(), err)
+			continue
+		}
+
+		if fmt.Sprint(action.Replicas) == drift.Live {
+			drift.ScaledBy = action.TriggeredBy
+		}
+	}
+}
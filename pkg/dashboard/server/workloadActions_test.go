@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/api"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_flagScaleActions(t *testing.T) {
+	store := store.NewTest(encryptionKey, encryptionKeyNew)
+	defer store.Close()
+
+	scale := &model.WorkloadAction{Env: "staging", Action: model.WorkloadActionScale, Kind: "deployment", Namespace: "default", Name: "app", Replicas: 5, TriggeredBy: "laszlo", Created: time.Now().Unix()}
+	err := store.CreateWorkloadAction(scale)
+	assert.Nil(t, err)
+	err = store.UpdateWorkloadActionStatus(scale.ID, model.WorkloadActionSucceeded, "")
+	assert.Nil(t, err)
+
+	scaled := &api.Drift{Type: api.DriftModified, Kind: "Deployment", Namespace: "default", Name: "app", Field: "spec.replicas", Desired: "1", Live: "5"}
+	rescaled := &api.Drift{Type: api.DriftModified, Kind: "Deployment", Namespace: "default", Name: "app", Field: "spec.replicas", Desired: "1", Live: "3"}
+	otherEnv := &api.Drift{Type: api.DriftModified, Kind: "Deployment", Namespace: "default", Name: "app", Field: "spec.replicas", Desired: "1", Live: "5"}
+	flagScaleActions(store, "staging", []*api.Drift{scaled, rescaled})
+	flagScaleActions(store, "production", []*api.Drift{otherEnv})
+
+	assert.Equal(t, "laszlo", scaled.ScaledBy)
+	assert.Equal(t, "", rescaled.ScaledBy, "replicas changed since the scale action are a drift")
+	assert.Equal(t, "", otherEnv.ScaledBy)
+}
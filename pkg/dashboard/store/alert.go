@@ -43,10 +43,13 @@ func (db *Store) SaveOrUpdateAlert(alert *model.Alert) error {
 	}
 
 	storedAlert.DeploymentName = alert.DeploymentName
+	storedAlert.Env = alert.Env
 	storedAlert.Status = alert.Status
 	storedAlert.StatusDesc = alert.StatusDesc
 	storedAlert.LastStateChange = alert.LastStateChange
 	storedAlert.Count = alert.Count
+	storedAlert.AcknowledgedBy = alert.AcknowledgedBy
+	storedAlert.ResolvedAt = alert.ResolvedAt
 	return meddler.Update(db, "alerts", storedAlert)
 }
 
@@ -63,3 +66,18 @@ func (db *Store) PendingAlerts() ([]*model.Alert, error) {
 
 	return data, err
 }
+
+// ActiveAlerts returns the firing and the acknowledged alerts
+func (db *Store) ActiveAlerts() ([]*model.Alert, error) {
+	stmt := queries.Stmt(db.driver, queries.SelectActiveAlerts)
+	data := []*model.Alert{}
+	err := meddler.QueryAll(db, &data, stmt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return data, err
+}
@@ -55,3 +55,19 @@ func TestGetPendingAlerts(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(pendingAlerts))
 }
+
+func TestGetActiveAlerts(t *testing.T) {
+	s := NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		s.Close()
+	}()
+
+	s.SaveOrUpdateAlert(&model.Alert{Type: "pod", Name: "default/pod1", Status: model.AlertFiring})
+	s.SaveOrUpdateAlert(&model.Alert{Type: "pod", Name: "default/pod2", Status: model.AlertAcknowledged, AcknowledgedBy: "laszlo"})
+	s.SaveOrUpdateAlert(&model.Alert{Type: "pod", Name: "default/pod3", Status: model.AlertResolved})
+	s.SaveOrUpdateAlert(&model.Alert{Type: "pod", Name: "default/pod4", Status: model.AlertPending})
+
+	activeAlerts, err := s.ActiveAlerts()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(activeAlerts))
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"database/sql"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	queries "github.com/gimlet-io/gimlet-cli/pkg/dashboard/store/sql"
+	"github.com/russross/meddler"
+)
+
+// SaveCrashLog stores the crash log, unless the same restart was already stored
+func (db *Store) SaveCrashLog(crashLog *model.CrashLog) error {
+	stmt := queries.Stmt(db.driver, queries.InsertCrashLog)
+	_, err := db.Exec(stmt,
+		crashLog.Env,
+		crashLog.Namespace,
+		crashLog.Pod,
+		crashLog.App,
+		crashLog.Container,
+		crashLog.RestartCount,
+		crashLog.ExitCode,
+		crashLog.Reason,
+		crashLog.FinishedAt,
+		crashLog.Logs,
+	)
+
+	return err
+}
+
+// CrashLogs returns the crash logs of an app that finished in the given time range, newest first
+func (db *Store) CrashLogs(env string, app string, since int64, until int64, limit int) ([]*model.CrashLog, error) {
+	stmt := queries.Stmt(db.driver, queries.SelectCrashLogs)
+	data := []*model.CrashLog{}
+	err := meddler.QueryAll(db, &data, stmt, env, app, since, until, limit)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return data, err
+}
+
+// DeleteCrashLogsBefore enforces the retention of crash logs
+func (db *Store) DeleteCrashLogsBefore(before int64) error {
+	stmt := queries.Stmt(db.driver, queries.DeleteCrashLogsBefore)
+	_, err := db.Exec(stmt, before)
+
+	return err
+}
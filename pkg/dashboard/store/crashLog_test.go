@@ -0,0 +1,44 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashLogCRUD(t *testing.T) {
+	s := NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		s.Close()
+	}()
+
+	now := time.Now()
+	old := model.CrashLog{Env: "staging", Namespace: "default", Pod: "my-app-1", App: "my-app", Container: "my-app", RestartCount: 1, FinishedAt: now.Add(-10 * 24 * time.Hour).Unix(), Logs: "panic: old"}
+	recent := model.CrashLog{Env: "staging", Namespace: "default", Pod: "my-app-1", App: "my-app", Container: "my-app", RestartCount: 2, FinishedAt: now.Add(-time.Hour).Unix(), Logs: "panic: recent"}
+
+	err := s.SaveCrashLog(&old)
+	assert.Nil(t, err)
+	err = s.SaveCrashLog(&recent)
+	assert.Nil(t, err)
+	duplicate := recent
+	duplicate.ID = 0
+	err = s.SaveCrashLog(&duplicate)
+	assert.Nil(t, err, "the same restart reported again should be skipped")
+
+	crashLogs, err := s.CrashLogs("staging", "my-app", 0, now.Unix(), 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(crashLogs))
+	assert.Equal(t, "panic: recent", crashLogs[0].Logs)
+
+	crashLogs, err = s.CrashLogs("staging", "my-app", now.Add(-24*time.Hour).Unix(), now.Unix(), 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(crashLogs))
+
+	err = s.DeleteCrashLogsBefore(now.Add(-7 * 24 * time.Hour).Unix())
+	assert.Nil(t, err)
+	crashLogs, err = s.CrashLogs("staging", "my-app", 0, now.Unix(), 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(crashLogs))
+}
@@ -44,6 +44,18 @@ const createTableEvents = "create-table-events"
 const createTableGitopsCommits = "create-table-gitopsCommits"
 const createTableKubeEvents = "create-table-kube-events"
 const createTableAlerts = "create-table-alerts"
+const addEnvColumnToAlertsTable = "add-env-column-to-alerts-table"
+const addAcknowledgedByColumnToAlertsTable = "add-acknowledged-by-column-to-alerts-table"
+const addResolvedAtColumnToAlertsTable = "add-resolved-at-column-to-alerts-table"
+const createTableSilences = "create-table-silences"
+const createTableWorkloadActions = "create-table-workload-actions"
+const createTableCrashLogs = "create-table-crash-logs"
+const addRequireSignedArtifactsToEnvironmentsTable = "add-require-signed-artifacts-to-environments-table"
+const defaultValueForRequireSignedArtifacts = "default-value-for-require-signed-artifacts"
+const addTrustedKeysToEnvironmentsTable = "add-trusted-keys-to-environments-table"
+const defaultValueForTrustedKeys = "default-value-for-trusted-keys"
+const addLastStateChangeColumnToPodsTable = "add-last-state-change-column-to-pods-table"
+const createTableScmDeployments = "create-table-scm-deployments"
 
 type migration struct {
 	name string
@@ -264,6 +276,111 @@ last_state_change INTEGER,
 count			  INTEGER,
 UNIQUE(id)
 );
+`,
+		},
+		{
+			name: addEnvColumnToAlertsTable,
+			stmt: `ALTER TABLE alerts ADD COLUMN env TEXT DEFAULT '';`,
+		},
+		{
+			name: addAcknowledgedByColumnToAlertsTable,
+			stmt: `ALTER TABLE alerts ADD COLUMN acknowledged_by TEXT DEFAULT '';`,
+		},
+		{
+			name: addResolvedAtColumnToAlertsTable,
+			stmt: `ALTER TABLE alerts ADD COLUMN resolved_at INTEGER DEFAULT 0;`,
+		},
+		{
+			name: createTableSilences,
+			stmt: `
+CREATE TABLE IF NOT EXISTS silences (
+id				  INTEGER PRIMARY KEY AUTOINCREMENT,
+env				  TEXT DEFAULT '',
+namespace		  TEXT DEFAULT '',
+deployment		  TEXT DEFAULT '',
+until			  INTEGER,
+reason			  TEXT DEFAULT '',
+created_by		  TEXT DEFAULT '',
+created			  INTEGER DEFAULT 0,
+UNIQUE(id)
+);
+`,
+		},
+		{
+			name: createTableWorkloadActions,
+			stmt: `
+CREATE TABLE IF NOT EXISTS workload_actions (
+id				  INTEGER PRIMARY KEY AUTOINCREMENT,
+env				  TEXT DEFAULT '',
+action			  TEXT DEFAULT '',
+kind			  TEXT DEFAULT '',
+namespace		  TEXT DEFAULT '',
+name			  TEXT DEFAULT '',
+replicas		  INTEGER DEFAULT 0,
+triggered_by	  TEXT DEFAULT '',
+created			  INTEGER DEFAULT 0,
+status			  TEXT DEFAULT '',
+status_desc		  TEXT DEFAULT '',
+UNIQUE(id)
+);
+`,
+		},
+		{
+			name: createTableCrashLogs,
+			stmt: `
+CREATE TABLE IF NOT EXISTS crash_logs (
+id				  INTEGER PRIMARY KEY AUTOINCREMENT,
+env				  TEXT DEFAULT '',
+namespace		  TEXT DEFAULT '',
+pod				  TEXT DEFAULT '',
+app				  TEXT DEFAULT '',
+container		  TEXT DEFAULT '',
+restart_count	  INTEGER DEFAULT 0,
+exit_code		  INTEGER DEFAULT 0,
+reason			  TEXT DEFAULT '',
+finished_at		  INTEGER DEFAULT 0,
+logs			  TEXT DEFAULT '',
+UNIQUE(id),
+UNIQUE(namespace, pod, container, restart_count)
+);
+`,
+		},
+		{
+			name: addRequireSignedArtifactsToEnvironmentsTable,
+			stmt: `ALTER TABLE environments ADD COLUMN require_signed_artifacts BOOLEAN;`,
+		},
+		{
+			name: defaultValueForRequireSignedArtifacts,
+			stmt: `update environments set require_signed_artifacts=false where require_signed_artifacts is null;`,
+		},
+		{
+			name: addTrustedKeysToEnvironmentsTable,
+			stmt: `ALTER TABLE environments ADD COLUMN trusted_keys TEXT;`,
+		},
+		{
+			name: defaultValueForTrustedKeys,
+			stmt: `update environments set trusted_keys='[]' where trusted_keys is null;`,
+		},
+		{
+			name: addLastStateChangeColumnToPodsTable,
+			stmt: `ALTER TABLE pods ADD COLUMN last_state_change INTEGER DEFAULT 0;`,
+		},
+		{
+			name: createTableScmDeployments,
+			stmt: `
+CREATE TABLE IF NOT EXISTS scm_deployments (
+id				  INTEGER PRIMARY KEY AUTOINCREMENT,
+scm				  TEXT DEFAULT '',
+deployment_id	  INTEGER DEFAULT 0,
+repo			  TEXT DEFAULT '',
+ref				  TEXT DEFAULT '',
+env				  TEXT DEFAULT '',
+app				  TEXT DEFAULT '',
+gitops_ref		  TEXT DEFAULT '',
+pending			  BOOLEAN DEFAULT false,
+created			  INTEGER DEFAULT 0,
+UNIQUE(id)
+);
 `,
 		},
 	},
@@ -479,6 +596,111 @@ last_state_change INTEGER,
 count			  INTEGER,
 UNIQUE(id)
 );
+`,
+		},
+		{
+			name: addEnvColumnToAlertsTable,
+			stmt: `ALTER TABLE alerts ADD COLUMN env TEXT DEFAULT '';`,
+		},
+		{
+			name: addAcknowledgedByColumnToAlertsTable,
+			stmt: `ALTER TABLE alerts ADD COLUMN acknowledged_by TEXT DEFAULT '';`,
+		},
+		{
+			name: addResolvedAtColumnToAlertsTable,
+			stmt: `ALTER TABLE alerts ADD COLUMN resolved_at INTEGER DEFAULT 0;`,
+		},
+		{
+			name: createTableSilences,
+			stmt: `
+CREATE TABLE IF NOT EXISTS silences (
+id				  SERIAL,
+env				  TEXT DEFAULT '',
+namespace		  TEXT DEFAULT '',
+deployment		  TEXT DEFAULT '',
+until			  INTEGER,
+reason			  TEXT DEFAULT '',
+created_by		  TEXT DEFAULT '',
+created			  INTEGER DEFAULT 0,
+UNIQUE(id)
+);
+`,
+		},
+		{
+			name: createTableWorkloadActions,
+			stmt: `
+CREATE TABLE IF NOT EXISTS workload_actions (
+id				  SERIAL,
+env				  TEXT DEFAULT '',
+action			  TEXT DEFAULT '',
+kind			  TEXT DEFAULT '',
+namespace		  TEXT DEFAULT '',
+name			  TEXT DEFAULT '',
+replicas		  INTEGER DEFAULT 0,
+triggered_by	  TEXT DEFAULT '',
+created			  INTEGER DEFAULT 0,
+status			  TEXT DEFAULT '',
+status_desc		  TEXT DEFAULT '',
+UNIQUE(id)
+);
+`,
+		},
+		{
+			name: createTableCrashLogs,
+			stmt: `
+CREATE TABLE IF NOT EXISTS crash_logs (
+id				  SERIAL,
+env				  TEXT DEFAULT '',
+namespace		  TEXT DEFAULT '',
+pod				  TEXT DEFAULT '',
+app				  TEXT DEFAULT '',
+container		  TEXT DEFAULT '',
+restart_count	  INTEGER DEFAULT 0,
+exit_code		  INTEGER DEFAULT 0,
+reason			  TEXT DEFAULT '',
+finished_at		  INTEGER DEFAULT 0,
+logs			  TEXT DEFAULT '',
+UNIQUE(id),
+UNIQUE(namespace, pod, container, restart_count)
+);
+`,
+		},
+		{
+			name: addRequireSignedArtifactsToEnvironmentsTable,
+			stmt: `ALTER TABLE environments ADD COLUMN require_signed_artifacts BOOLEAN;`,
+		},
+		{
+			name: defaultValueForRequireSignedArtifacts,
+			stmt: `update environments set require_signed_artifacts=false where require_signed_artifacts is null;`,
+		},
+		{
+			name: addTrustedKeysToEnvironmentsTable,
+			stmt: `ALTER TABLE environments ADD COLUMN trusted_keys TEXT;`,
+		},
+		{
+			name: defaultValueForTrustedKeys,
+			stmt: `update environments set trusted_keys='[]' where trusted_keys is null;`,
+		},
+		{
+			name: addLastStateChangeColumnToPodsTable,
+			stmt: `ALTER TABLE pods ADD COLUMN last_state_change INTEGER DEFAULT 0;`,
+		},
+		{
+			name: createTableScmDeployments,
+			stmt: `
+CREATE TABLE IF NOT EXISTS scm_deployments (
+id				  SERIAL,
+scm				  TEXT DEFAULT '',
+deployment_id	  BIGINT DEFAULT 0,
+repo			  TEXT DEFAULT '',
+ref				  TEXT DEFAULT '',
+env				  TEXT DEFAULT '',
+app				  TEXT DEFAULT '',
+gitops_ref		  TEXT DEFAULT '',
+pending			  BOOLEAN DEFAULT false,
+created			  INTEGER DEFAULT 0,
+UNIQUE(id)
+);
 `,
 		},
 	},
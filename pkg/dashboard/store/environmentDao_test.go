@@ -70,3 +70,28 @@ func TestEnvironmentDelete(t *testing.T) {
 
 	assert.Equal(t, 0, len(data))
 }
+
+func TestEnvironmentArtifactSigning(t *testing.T) {
+	s := NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		s.Close()
+	}()
+
+	err := s.CreateEnvironment(&model.Environment{Name: "production"})
+	assert.Nil(t, err)
+
+	production, err := s.GetEnvironment("production")
+	assert.Nil(t, err)
+	assert.False(t, production.RequireSignedArtifacts)
+	assert.Equal(t, 0, len(production.TrustedKeys))
+
+	production.RequireSignedArtifacts = true
+	production.TrustedKeys = []string{"-----BEGIN PUBLIC KEY-----"}
+	err = s.UpdateEnvironment(production)
+	assert.Nil(t, err)
+
+	production, err = s.GetEnvironment("production")
+	assert.Nil(t, err)
+	assert.True(t, production.RequireSignedArtifacts)
+	assert.Equal(t, []string{"-----BEGIN PUBLIC KEY-----"}, production.TrustedKeys)
+}
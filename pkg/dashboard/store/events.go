@@ -165,3 +165,10 @@ func addFilter(filters []string, filter string) []string {
 
 	return append(filters, "AND "+filter)
 }
+
+// DeploymentEvents returns the processed artifact, release and rollback events since the given time
+func (db *Store) DeploymentEvents(since int64) (events []*model.Event, err error) {
+	stmt := sql.Stmt(db.driver, sql.SelectDeploymentEvents)
+	err = meddler.QueryAll(db, &events, stmt, since)
+	return events, err
+}
@@ -108,3 +108,28 @@ func setupData(s *Store) error {
 	_, err = s.createEvent(aModel, tenHoursAgo.Unix())
 	return err
 }
+
+func TestDeploymentEvents(t *testing.T) {
+	s := NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		s.Close()
+	}()
+
+	release, err := s.CreateEvent(&model.Event{Type: model.ReleaseRequestedEvent, Blob: "{}"})
+	assert.Nil(t, err)
+	_, err = s.CreateEvent(&model.Event{Type: model.ReleaseRequestedEvent, Blob: "{}"})
+	assert.Nil(t, err)
+
+	results, _ := json.Marshal([]model.Result{{GitopsRef: "abc123"}})
+	err = s.UpdateEventStatus(release.ID, model.StatusProcessed, "", string(results))
+	assert.Nil(t, err)
+
+	events, err := s.DeploymentEvents(time.Now().Add(-1 * time.Hour).Unix())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, "abc123", events[0].Results[0].GitopsRef)
+
+	events, err = s.DeploymentEvents(time.Now().Add(1 * time.Hour).Unix())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(events))
+}
@@ -36,6 +36,14 @@ func (db *Store) GitopsCommits() ([]*model.GitopsCommit, error) {
 	return data, err
 }
 
+// GitopsCommitsSince returns the gitops commits created or reconciled since the given time
+func (db *Store) GitopsCommitsSince(since int64) ([]*model.GitopsCommit, error) {
+	stmt := queries.Stmt(db.driver, queries.SelectGitopsCommitsSince)
+	data := []*model.GitopsCommit{}
+	err := meddler.QueryAll(db, &data, stmt, since)
+	return data, err
+}
+
 func (db *Store) SaveOrUpdateGitopsCommit(gitopsCommit *model.GitopsCommit) (bool, error) {
 	if db.driver != "sqlite" {
 		return db.saveOrUpdateGitopsCommitWithTx(gitopsCommit)
@@ -37,6 +37,7 @@ func (db *Store) SaveOrUpdatePod(pod *model.Pod) error {
 
 	storedPod.Status = pod.Status
 	storedPod.StatusDesc = pod.StatusDesc
+	storedPod.LastStateChange = pod.LastStateChange
 
 	return meddler.Update(db, "pods", storedPod)
 }
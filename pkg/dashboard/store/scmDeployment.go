@@ -0,0 +1,61 @@
+package store
+
+import (
+	"database/sql"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	queries "github.com/gimlet-io/gimlet-cli/pkg/dashboard/store/sql"
+	"github.com/russross/meddler"
+)
+
+func (db *Store) CreateScmDeployment(deployment *model.ScmDeployment) error {
+	return meddler.Insert(db, "scm_deployments", deployment)
+}
+
+// PendingScmDeployments returns the deployments of an env whose gitops commit is not applied yet, oldest first
+func (db *Store) PendingScmDeployments(scm string, env string) ([]*model.ScmDeployment, error) {
+	stmt := queries.Stmt(db.driver, queries.SelectPendingScmDeployments)
+	data := []*model.ScmDeployment{}
+	err := meddler.QueryAll(db, &data, stmt, scm, env)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return data, err
+}
+
+// LatestScmDeployment returns the latest deployment of an app in an env
+func (db *Store) LatestScmDeployment(scm string, env string, app string) (*model.ScmDeployment, error) {
+	stmt := queries.Stmt(db.driver, queries.SelectLatestScmDeployment)
+	deployment := new(model.ScmDeployment)
+	err := meddler.QueryRow(db, deployment, stmt, scm, env, app)
+
+	return deployment, err
+}
+
+// ScmDeploymentApplied marks a deployment as applied, so the gitops commit lifecycle does not update it anymore
+func (db *Store) ScmDeploymentApplied(id int64) error {
+	stmt := queries.Stmt(db.driver, queries.UpdateScmDeploymentApplied)
+	_, err := db.Exec(stmt, id)
+
+	return err
+}
+
+// DeleteAppliedScmDeployments drops the applied deployments of an app, they are not needed once a newer one is tracked
+func (db *Store) DeleteAppliedScmDeployments(scm string, env string, app string) error {
+	stmt := queries.Stmt(db.driver, queries.DeleteAppliedScmDeployments)
+	_, err := db.Exec(stmt, scm, env, app)
+
+	return err
+}
+
+// DeleteScmDeployments drops every deployment of an app in an env
+func (db *Store) DeleteScmDeployments(scm string, env string, app string) error {
+	stmt := queries.Stmt(db.driver, queries.DeleteScmDeployments)
+	_, err := db.Exec(stmt, scm, env, app)
+
+	return err
+}
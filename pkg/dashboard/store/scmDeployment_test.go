@@ -0,0 +1,50 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScmDeploymentCRUD(t *testing.T) {
+	s := NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		s.Close()
+	}()
+
+	first := model.ScmDeployment{Scm: "github", DeploymentID: 1, Env: "staging", App: "app1", Pending: true}
+	second := model.ScmDeployment{Scm: "github", DeploymentID: 2, Env: "staging", App: "app1", Pending: true}
+	otherScm := model.ScmDeployment{Scm: "gitlab", DeploymentID: 3, Env: "staging", App: "app1", Pending: true}
+	assert.Nil(t, s.CreateScmDeployment(&first))
+	assert.Nil(t, s.CreateScmDeployment(&second))
+	assert.Nil(t, s.CreateScmDeployment(&otherScm))
+
+	pending, err := s.PendingScmDeployments("github", "staging")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pending))
+	assert.Equal(t, int64(1), pending[0].DeploymentID)
+
+	assert.Nil(t, s.ScmDeploymentApplied(first.ID))
+	pending, err = s.PendingScmDeployments("github", "staging")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pending))
+
+	latest, err := s.LatestScmDeployment("github", "staging", "app1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), latest.DeploymentID)
+
+	assert.Nil(t, s.DeleteAppliedScmDeployments("github", "staging", "app1"))
+	assert.Nil(t, s.ScmDeploymentApplied(second.ID))
+	latest, err = s.LatestScmDeployment("github", "staging", "app1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), latest.DeploymentID, "only the deployments applied before are deleted")
+
+	assert.Nil(t, s.DeleteScmDeployments("github", "staging", "app1"))
+	_, err = s.LatestScmDeployment("github", "staging", "app1")
+	assert.Equal(t, sql.ErrNoRows, err)
+	latest, err = s.LatestScmDeployment("gitlab", "staging", "app1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), latest.DeploymentID)
+}
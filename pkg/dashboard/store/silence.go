@@ -0,0 +1,36 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	queries "github.com/gimlet-io/gimlet-cli/pkg/dashboard/store/sql"
+	"github.com/russross/meddler"
+)
+
+func (db *Store) CreateSilence(silence *model.Silence) error {
+	return meddler.Insert(db, "silences", silence)
+}
+
+// ActiveSilences returns the silences that did not expire yet
+func (db *Store) ActiveSilences() ([]*model.Silence, error) {
+	stmt := queries.Stmt(db.driver, queries.SelectActiveSilences)
+	data := []*model.Silence{}
+	err := meddler.QueryAll(db, &data, stmt, time.Now().Unix())
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return data, err
+}
+
+func (db *Store) DeleteSilence(id int64) error {
+	stmt := queries.Stmt(db.driver, queries.DeleteSilence)
+	_, err := db.Exec(stmt, id)
+
+	return err
+}
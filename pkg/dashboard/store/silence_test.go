@@ -0,0 +1,42 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSilenceCRUD(t *testing.T) {
+	s := NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		s.Close()
+	}()
+
+	active := model.Silence{
+		Env:   "staging",
+		Until: time.Now().Add(time.Hour).Unix(),
+	}
+	expired := model.Silence{
+		Env:   "production",
+		Until: time.Now().Add(-time.Hour).Unix(),
+	}
+
+	err := s.CreateSilence(&active)
+	assert.Nil(t, err)
+	err = s.CreateSilence(&expired)
+	assert.Nil(t, err)
+
+	silences, err := s.ActiveSilences()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(silences))
+	assert.Equal(t, "staging", silences[0].Env)
+
+	err = s.DeleteSilence(silences[0].ID)
+	assert.Nil(t, err)
+
+	silences, err = s.ActiveSilences()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(silences))
+}
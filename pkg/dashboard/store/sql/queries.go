@@ -35,6 +35,21 @@ const DeleteKubeEventByName = "delete-kube-event-by-name"
 const SelectFiringAlerts = "select-firing-alerts"
 const SelectAlertByNameAndType = "select-alert-by-name-and-type"
 const SelectPendingAlerts = "select-pending-alerts"
+const SelectActiveAlerts = "select-active-alerts"
+const SelectActiveSilences = "select-active-silences"
+const DeleteSilence = "delete-silence"
+const SelectDeploymentEvents = "select-deployment-events"
+const SelectGitopsCommitsSince = "select-gitops-commits-since"
+const SelectWorkloadActions = "select-workload-actions"
+const SelectLastSucceededWorkloadAction = "select-last-succeeded-workload-action"
+const InsertCrashLog = "insert-crash-log"
+const SelectCrashLogs = "select-crash-logs"
+const DeleteCrashLogsBefore = "delete-crash-logs-before"
+const SelectPendingScmDeployments = "select-pending-scm-deployments"
+const SelectLatestScmDeployment = "select-latest-scm-deployment"
+const UpdateScmDeploymentApplied = "update-scm-deployment-applied"
+const DeleteAppliedScmDeployments = "delete-applied-scm-deployments"
+const DeleteScmDeployments = "delete-scm-deployments"
 
 var queries = map[string]map[string]string{
 	"sqlite": {
@@ -69,12 +84,12 @@ FROM key_values
 WHERE key = $1;
 `,
 		SelectEnvironments: `
-SELECT id, name, infra_repo, apps_repo, repo_per_env, kustomization_per_app, built_in
+SELECT id, name, infra_repo, apps_repo, repo_per_env, kustomization_per_app, built_in, require_signed_artifacts, trusted_keys
 FROM environments
 ORDER BY name asc;
 `,
 		SelectEnvironment: `
-SELECT id, name, infra_repo, apps_repo, repo_per_env, kustomization_per_app, built_in
+SELECT id, name, infra_repo, apps_repo, repo_per_env, kustomization_per_app, built_in, require_signed_artifacts, trusted_keys
 FROM environments
 WHERE name = $1;
 `,
@@ -83,7 +98,7 @@ DELETE FROM environments
 WHERE name = ?;
 `,
 		SelectPodByName: `
-SELECT id, name, status, status_desc
+SELECT id, name, status, status_desc, last_state_change
 FROM pods
 WHERE name = $1;
 `,
@@ -102,6 +117,19 @@ UPDATE events SET status = $1, status_desc = $2, results = $3 WHERE id = $4;
 SELECT id, sha, status, status_desc, created
 FROM gitops_commits
 WHERE sha = $1;
+`,
+		SelectDeploymentEvents: `
+SELECT id, created, type, status, status_desc, results
+FROM events
+WHERE status = 'processed'
+AND type IN ('artifact', 'release', 'rollback', 'promotion')
+AND created >= $1
+ORDER BY created ASC;
+`,
+		SelectGitopsCommitsSince: `
+SELECT id, sha, status, status_desc, created, env
+FROM gitops_commits
+WHERE created >= $1;
 `,
 		SelectGitopsCommits: `
 SELECT id, sha, status, status_desc, created, env
@@ -118,21 +146,90 @@ WHERE name = $1;
 DELETE FROM kube_events where name = $1;
 `,
 		SelectFiringAlerts: `
-SELECT id, type, name, deployment_name, status, status_desc, last_state_change, count
+SELECT id, type, name, deployment_name, env, status, status_desc, last_state_change, count, acknowledged_by, resolved_at
 FROM alerts
 WHERE status LIKE 'Firing'
 ORDER BY last_state_change desc;
 `,
 		SelectAlertByNameAndType: `
-SELECT id, type, name, deployment_name, status, status_desc, last_state_change, count
+SELECT id, type, name, deployment_name, env, status, status_desc, last_state_change, count, acknowledged_by, resolved_at
 FROM alerts
 WHERE name = $1
 AND type = $2;
 `,
 		SelectPendingAlerts: `
-SELECT id, type, name, deployment_name, status, status_desc, last_state_change, count
+SELECT id, type, name, deployment_name, env, status, status_desc, last_state_change, count, acknowledged_by, resolved_at
 FROM alerts
 WHERE status LIKE 'Pending';
+`,
+		SelectActiveAlerts: `
+SELECT id, type, name, deployment_name, env, status, status_desc, last_state_change, count, acknowledged_by, resolved_at
+FROM alerts
+WHERE status = 'Firing'
+OR status = 'Acknowledged'
+ORDER BY last_state_change desc;
+`,
+		SelectActiveSilences: `
+SELECT id, env, namespace, deployment, until, reason, created_by, created
+FROM silences
+WHERE until > $1
+ORDER BY until asc;
+`,
+		DeleteSilence: `
+DELETE FROM silences where id = $1;
+`,
+		SelectWorkloadActions: `
+SELECT id, env, action, kind, namespace, name, replicas, triggered_by, created, status, status_desc
+FROM workload_actions
+ORDER BY created desc
+LIMIT $1;
+`,
+		SelectLastSucceededWorkloadAction: `
+SELECT id, env, action, kind, namespace, name, replicas, triggered_by, created, status, status_desc
+FROM workload_actions
+WHERE env = $1 AND action = $2 AND kind = $3 AND namespace = $4 AND name = $5 AND status = 'success'
+ORDER BY created desc
+LIMIT 1;
+`,
+		InsertCrashLog: `
+INSERT INTO crash_logs (env, namespace, pod, app, container, restart_count, exit_code, reason, finished_at, logs)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (namespace, pod, container, restart_count) DO NOTHING;
+`,
+		SelectCrashLogs: `
+SELECT id, env, namespace, pod, app, container, restart_count, exit_code, reason, finished_at, logs
+FROM crash_logs
+WHERE env = $1
+AND app = $2
+AND finished_at >= $3
+AND finished_at <= $4
+ORDER BY finished_at desc
+LIMIT $5;
+`,
+		DeleteCrashLogsBefore: `
+DELETE FROM crash_logs WHERE finished_at < $1;
+`,
+		SelectPendingScmDeployments: `
+SELECT id, scm, deployment_id, repo, ref, env, app, gitops_ref, pending, created
+FROM scm_deployments
+WHERE scm = $1 AND env = $2 AND pending = true
+ORDER BY id asc;
+`,
+		SelectLatestScmDeployment: `
+SELECT id, scm, deployment_id, repo, ref, env, app, gitops_ref, pending, created
+FROM scm_deployments
+WHERE scm = $1 AND env = $2 AND app = $3
+ORDER BY id desc
+LIMIT 1;
+`,
+		UpdateScmDeploymentApplied: `
+UPDATE scm_deployments SET pending = false WHERE id = $1;
+`,
+		DeleteAppliedScmDeployments: `
+DELETE FROM scm_deployments WHERE scm = $1 AND env = $2 AND app = $3 AND pending = false;
+`,
+		DeleteScmDeployments: `
+DELETE FROM scm_deployments WHERE scm = $1 AND env = $2 AND app = $3;
 `,
 	},
 	"postgres": {
@@ -167,12 +264,12 @@ FROM key_values
 WHERE key = $1;
 `,
 		SelectEnvironments: `
-SELECT id, name, infra_repo, apps_repo, repo_per_env, kustomization_per_app, built_in
+SELECT id, name, infra_repo, apps_repo, repo_per_env, kustomization_per_app, built_in, require_signed_artifacts, trusted_keys
 FROM environments
 ORDER BY name asc;
 `,
 		SelectEnvironment: `
-SELECT id, name, infra_repo, apps_repo, repo_per_env, kustomization_per_app, built_in
+SELECT id, name, infra_repo, apps_repo, repo_per_env, kustomization_per_app, built_in, require_signed_artifacts, trusted_keys
 FROM environments
 WHERE name = $1;
 `,
@@ -181,7 +278,7 @@ DELETE FROM environments
 WHERE name = $1;
 `,
 		SelectPodByName: `
-SELECT id, name, status, status_desc
+SELECT id, name, status, status_desc, last_state_change
 FROM pods
 WHERE name = $1;
 `,
@@ -200,6 +297,19 @@ UPDATE events SET status = $1, status_desc = $2, results = $3 WHERE id = $4;
 SELECT id, sha, status, status_desc, created
 FROM gitops_commits
 WHERE sha = $1;
+`,
+		SelectDeploymentEvents: `
+SELECT id, created, type, status, status_desc, results
+FROM events
+WHERE status = 'processed'
+AND type IN ('artifact', 'release', 'rollback', 'promotion')
+AND created >= $1
+ORDER BY created ASC;
+`,
+		SelectGitopsCommitsSince: `
+SELECT id, sha, status, status_desc, created, env
+FROM gitops_commits
+WHERE created >= $1;
 `,
 		SelectGitopsCommits: `
 SELECT id, sha, status, status_desc, created, env
@@ -216,21 +326,90 @@ WHERE name = $1;
 DELETE FROM kube_events where name = $1;
 `,
 		SelectFiringAlerts: `
-SELECT id, type, name, deployment_name, status, status_desc, last_state_change, count
+SELECT id, type, name, deployment_name, env, status, status_desc, last_state_change, count, acknowledged_by, resolved_at
 FROM alerts
 WHERE status LIKE 'Firing'
 ORDER BY last_state_change desc;
 `,
 		SelectAlertByNameAndType: `
-SELECT id, type, name, deployment_name, status, status_desc, last_state_change, count
+SELECT id, type, name, deployment_name, env, status, status_desc, last_state_change, count, acknowledged_by, resolved_at
 FROM alerts
 WHERE name = $1
 AND type = $2;
 `,
 		SelectPendingAlerts: `
-SELECT id, type, name, deployment_name, status, status_desc, last_state_change, count
+SELECT id, type, name, deployment_name, env, status, status_desc, last_state_change, count, acknowledged_by, resolved_at
 FROM alerts
 WHERE status LIKE 'Pending';
+`,
+		SelectActiveAlerts: `
+SELECT id, type, name, deployment_name, env, status, status_desc, last_state_change, count, acknowledged_by, resolved_at
+FROM alerts
+WHERE status = 'Firing'
+OR status = 'Acknowledged'
+ORDER BY last_state_change desc;
+`,
+		SelectActiveSilences: `
+SELECT id, env, namespace, deployment, until, reason, created_by, created
+FROM silences
+WHERE until > $1
+ORDER BY until asc;
+`,
+		DeleteSilence: `
+DELETE FROM silences where id = $1;
+`,
+		SelectWorkloadActions: `
+SELECT id, env, action, kind, namespace, name, replicas, triggered_by, created, status, status_desc
+FROM workload_actions
+ORDER BY created desc
+LIMIT $1;
+`,
+		SelectLastSucceededWorkloadAction: `
+SELECT id, env, action, kind, namespace, name, replicas, triggered_by, created, status, status_desc
+FROM workload_actions
+WHERE env = $1 AND action = $2 AND kind = $3 AND namespace = $4 AND name = $5 AND status = 'success'
+ORDER BY created desc
+LIMIT 1;
+`,
+		InsertCrashLog: `
+INSERT INTO crash_logs (env, namespace, pod, app, container, restart_count, exit_code, reason, finished_at, logs)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (namespace, pod, container, restart_count) DO NOTHING;
+`,
+		SelectCrashLogs: `
+SELECT id, env, namespace, pod, app, container, restart_count, exit_code, reason, finished_at, logs
+FROM crash_logs
+WHERE env = $1
+AND app = $2
+AND finished_at >= $3
+AND finished_at <= $4
+ORDER BY finished_at desc
+LIMIT $5;
+`,
+		DeleteCrashLogsBefore: `
+DELETE FROM crash_logs WHERE finished_at < $1;
+`,
+		SelectPendingScmDeployments: `
+SELECT id, scm, deployment_id, repo, ref, env, app, gitops_ref, pending, created
+FROM scm_deployments
+WHERE scm = $1 AND env = $2 AND pending = true
+ORDER BY id asc;
+`,
+		SelectLatestScmDeployment: `
+SELECT id, scm, deployment_id, repo, ref, env, app, gitops_ref, pending, created
+FROM scm_deployments
+WHERE scm = $1 AND env = $2 AND app = $3
+ORDER BY id desc
+LIMIT 1;
+`,
+		UpdateScmDeploymentApplied: `
+UPDATE scm_deployments SET pending = false WHERE id = $1;
+`,
+		DeleteAppliedScmDeployments: `
+DELETE FROM scm_deployments WHERE scm = $1 AND env = $2 AND app = $3 AND pending = false;
+`,
+		DeleteScmDeployments: `
+DELETE FROM scm_deployments WHERE scm = $1 AND env = $2 AND app = $3;
 `,
 	},
 }
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"database/sql"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	queries "github.com/gimlet-io/gimlet-cli/pkg/dashboard/store/sql"
+	"github.com/russross/meddler"
+)
+
+func (db *Store) CreateWorkloadAction(action *model.WorkloadAction) error {
+	return meddler.Insert(db, "workload_actions", action)
+}
+
+func (db *Store) WorkloadAction(id int64) (*model.WorkloadAction, error) {
+	action := new(model.WorkloadAction)
+	err := meddler.Load(db, "workload_actions", action, id)
+
+	return action, err
+}
+
+// UpdateWorkloadActionStatus records the outcome that the agent reported
+func (db *Store) UpdateWorkloadActionStatus(id int64, status string, statusDesc string) error {
+	action, err := db.WorkloadAction(id)
+	if err != nil {
+		return err
+	}
+
+	action.Status = status
+	action.StatusDesc = statusDesc
+	return meddler.Update(db, "workload_actions", action)
+}
+
+// LastSucceededWorkloadAction returns the latest successful action of a kind on a workload
+func (db *Store) LastSucceededWorkloadAction(env string, action string, kind string, namespace string, name string) (*model.WorkloadAction, error) {
+	stmt := queries.Stmt(db.driver, queries.SelectLastSucceededWorkloadAction)
+	workloadAction := new(model.WorkloadAction)
+	err := meddler.QueryRow(db, workloadAction, stmt, env, action, kind, namespace, name)
+
+	return workloadAction, err
+}
+
+// WorkloadActions returns the latest actions, newest first
+func (db *Store) WorkloadActions(limit int) ([]*model.WorkloadAction, error) {
+	stmt := queries.Stmt(db.driver, queries.SelectWorkloadActions)
+	data := []*model.WorkloadAction{}
+	err := meddler.QueryAll(db, &data, stmt, limit)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return data, err
+}
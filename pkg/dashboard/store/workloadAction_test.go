@@ -0,0 +1,56 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkloadActionCRUD(t *testing.T) {
+	s := NewTest(encryptionKey, encryptionKeyNew)
+	defer func() {
+		s.Close()
+	}()
+
+	restart := model.WorkloadAction{
+		Env:         "staging",
+		Action:      model.WorkloadActionRestart,
+		Kind:        "deployment",
+		Namespace:   "default",
+		Name:        "my-app",
+		TriggeredBy: "laszlo",
+		Created:     time.Now().Add(-time.Minute).Unix(),
+		Status:      model.WorkloadActionPending,
+	}
+	scale := model.WorkloadAction{
+		Env:       "staging",
+		Action:    model.WorkloadActionScale,
+		Kind:      "deployment",
+		Namespace: "default",
+		Name:      "my-app",
+		Replicas:  3,
+		Created:   time.Now().Unix(),
+		Status:    model.WorkloadActionPending,
+	}
+
+	err := s.CreateWorkloadAction(&restart)
+	assert.Nil(t, err)
+	err = s.CreateWorkloadAction(&scale)
+	assert.Nil(t, err)
+
+	err = s.UpdateWorkloadActionStatus(restart.ID, model.WorkloadActionFailed, "deployment not found")
+	assert.Nil(t, err)
+
+	action, err := s.WorkloadAction(restart.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, model.WorkloadActionFailed, action.Status)
+	assert.Equal(t, "deployment not found", action.StatusDesc)
+	assert.Equal(t, "laszlo", action.TriggeredBy)
+
+	actions, err := s.WorkloadActions(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(actions))
+	assert.Equal(t, int32(3), actions[0].Replicas, "newest first")
+}
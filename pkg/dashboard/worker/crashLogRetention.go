@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/sirupsen/logrus"
+)
+
+// CrashLogRetentionWorker deletes the crash logs that are older than the retention period
+type CrashLogRetentionWorker struct {
+	Store     *store.Store
+	Retention time.Duration
+}
+
+func (w *CrashLogRetentionWorker) Run() {
+	for {
+		err := w.Store.DeleteCrashLogsBefore(time.Now().Add(-w.Retention).Unix())
+		if err != nil {
+			logrus.Errorf("could not delete old crash logs: %s", err)
+		}
+
+		time.Sleep(1 * time.Hour)
+	}
+}
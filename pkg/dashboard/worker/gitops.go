@@ -148,6 +148,16 @@ func processEvent(
 			gitUser,
 			gitHost,
 		)
+	case model.PromotionRequestedEvent:
+		results, err = processPromotionEvent(
+			store,
+			repoCache,
+			token,
+			event,
+			perf,
+			gitUser,
+			gitHost,
+		)
 	case model.RollbackRequestedEvent:
 		results, err = processRollbackEvent(
 			gitopsRepo,
@@ -216,6 +226,8 @@ func processEvent(
 			case model.ArtifactCreatedEvent:
 				fallthrough
 			case model.ReleaseRequestedEvent:
+				fallthrough
+			case model.PromotionRequestedEvent:
 				notificationsManager.Broadcast(notifications.DeployMessageFromGitOpsResult(result))
 			case model.BranchDeletedEvent:
 				notificationsManager.Broadcast(notifications.MessageFromDeleteEvent(result))
@@ -364,6 +376,14 @@ func processReleaseEvent(
 			continue
 		}
 
+		deployResult.Signature, err = verifyArtifactSignature(artifactEvent, envFromStore)
+		if err != nil {
+			deployResult.Status = model.Failure
+			deployResult.StatusDesc = err.Error()
+			deployResults = append(deployResults, deployResult)
+			continue
+		}
+
 		releaseMeta := &dx.Release{
 			App:         manifest.App,
 			Env:         manifest.Env,
@@ -398,6 +418,183 @@ func processReleaseEvent(
 	return deployResults, nil
 }
 
+// processPromotionEvent templates the manifests of all promoted apps and writes them in a single gitops commit.
+// If any of the apps fail, none of them are released
+func processPromotionEvent(
+	store *store.Store,
+	gitopsRepoCache *nativeGit.RepoCache,
+	nonImpersonatedToken string,
+	event *model.Event,
+	perf *prometheus.HistogramVec,
+	gitUser *model.User,
+	gitHost string,
+) ([]model.Result, error) {
+	t0 := time.Now()
+
+	var promotionRequest dx.PromotionRequest
+	err := json.Unmarshal([]byte(event.Blob), &promotionRequest)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse promotion request with id: %s", event.ID)
+	}
+
+	envFromStore, err := store.GetEnvironment(promotionRequest.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, repoTmpPath, err := gitopsRepoCache.InstanceForWrite(envFromStore.AppsRepo)
+	defer nativeGit.TmpFsCleanup(repoTmpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fluxPath := filepath.Join(promotionRequest.Env, "flux")
+	if envFromStore.RepoPerEnv {
+		fluxPath = "flux"
+	}
+	fluxAPIVersion := bootstrap.FluxAPIVersionFromRepo(repoTmpPath, fluxPath)
+
+	var results []model.Result
+	var apps []string
+	for _, releaseRequest := range promotionRequest.Releases {
+		artifactEvent, err := store.Artifact(releaseRequest.ArtifactID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot find artifact with id: %s", releaseRequest.ArtifactID)
+		}
+		signature, err := verifyArtifactSignature(artifactEvent, envFromStore)
+		if err != nil {
+			return nil, fmt.Errorf("cannot promote %s: %s", releaseRequest.App, err)
+		}
+		artifact, manifest, err := artifactManifest(artifactEvent, promotionRequest.Env, releaseRequest.App)
+		if err != nil {
+			return nil, err
+		}
+
+		var kustomizationManifest *manifestgen.Manifest
+		if envFromStore.KustomizationPerApp {
+			kustomizationManifest, err = kustomizationTemplate(
+				manifest,
+				envFromStore.AppsRepo,
+				repoTmpPath,
+				envFromStore.RepoPerEnv,
+				fluxAPIVersion,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		releaseMeta := &dx.Release{
+			App:         manifest.App,
+			Env:         manifest.Env,
+			ArtifactID:  artifact.ID,
+			Version:     &artifact.Version,
+			TriggeredBy: promotionRequest.TriggeredBy,
+		}
+		files, releaseString, err := gitopsTemplate(manifest, releaseMeta, nonImpersonatedToken, kustomizationManifest, fluxAPIVersion)
+		if err != nil {
+			return nil, fmt.Errorf("cannot template %s: %s", manifest.App, err)
+		}
+		err = nativeGit.StageFilesToGit(repo, files, manifest.Env, manifest.App, envFromStore.RepoPerEnv, releaseString)
+		if err != nil {
+			return nil, fmt.Errorf("cannot write to git: %s", err)
+		}
+
+		apps = append(apps, manifest.App)
+		results = append(results, model.Result{
+			Manifest:    manifest,
+			Artifact:    artifact,
+			TriggeredBy: promotionRequest.TriggeredBy,
+			Status:      model.Success,
+			GitopsRepo:  envFromStore.AppsRepo,
+			Signature:   signature,
+		})
+	}
+
+	empty, err := nativeGit.NothingToCommit(repo)
+	if err != nil {
+		return nil, err
+	}
+	if empty {
+		return nil, fmt.Errorf("no changes made to the gitops state. Maybe these are the current versions already?")
+	}
+
+	sha, err := nativeGit.Commit(repo, fmt.Sprintf("[Gimlet] %s promotion of %s", promotionRequest.Env, strings.Join(apps, ", ")))
+	if err != nil {
+		return nil, fmt.Errorf("cannot write to git: %s", err)
+	}
+
+	err = pushWithRetry(repo, repoTmpPath, envFromStore, nonImpersonatedToken, gitUser, gitHost)
+	if err != nil {
+		return nil, err
+	}
+	gitopsRepoCache.InvalidateNow(envFromStore.AppsRepo)
+
+	for i := range results {
+		results[i].GitopsRef = sha
+	}
+
+	perf.WithLabelValues("gitops_processPromotionEvent").Observe(float64(time.Since(t0).Seconds()))
+	return results, nil
+}
+
+// artifactManifest returns the manifest of an app in an artifact for an env, with its variables resolved
+func artifactManifest(artifactEvent *model.Event, env string, app string) (*dx.Artifact, *dx.Manifest, error) {
+	artifact, err := model.ToArtifact(artifactEvent)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse artifact %s", err.Error())
+	}
+
+	manifests, err := artifact.CueEnvironmentsToManifests()
+	if err != nil {
+		return nil, nil, err
+	}
+	artifact.Environments = append(artifact.Environments, manifests...)
+
+	for _, manifest := range artifact.Environments {
+		if manifest.Env != env || manifest.App != app {
+			continue
+		}
+
+		err = manifest.ResolveVars(artifact.CollectVariables())
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot resolve variables of %s: %s", app, err)
+		}
+		return artifact, manifest, nil
+	}
+
+	return nil, nil, fmt.Errorf("artifact %s has no manifest for %s in %s", artifact.ID, app, env)
+}
+
+// verifyArtifactSignature checks the signatures of an artifact against the trusted keys of an env.
+// It returns the outcome to record in the results, and an error if the env requires signed artifacts
+// and the artifact is not signed by a trusted key.
+// Signatures cover the artifact as it was pushed, so it is read from the stored event, not the resolved artifact
+func verifyArtifactSignature(artifactEvent *model.Event, env *model.Environment) (string, error) {
+	if !env.RequireSignedArtifacts && len(env.TrustedKeys) == 0 {
+		return "", nil
+	}
+
+	artifact, err := model.ToArtifact(artifactEvent)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse artifact %s", err.Error())
+	}
+	payload, err := artifact.CanonicalJSON()
+	if err == nil {
+		var keyID string
+		keyID, err = dx.VerifySignatures(payload, artifact.Signatures, env.TrustedKeys)
+		if err == nil {
+			return fmt.Sprintf("verified with key %s", keyID), nil
+		}
+	}
+
+	outcome := fmt.Sprintf("not verified: %s", err)
+	if env.RequireSignedArtifacts {
+		return outcome, fmt.Errorf("%s requires signed artifacts: %s", env.Name, err)
+	}
+	return outcome, nil
+}
+
 func processRollbackEvent(
 	gitopsRepo string,
 	gitopsRepoDeployKeyPath string,
@@ -528,7 +725,7 @@ func processArtifactEvent(
 	artifact.Environments = append(artifact.Environments, manifests...)
 
 	for _, manifest := range artifact.Environments {
-		if !deployTrigger(artifact, manifest.Deploy) {
+		if !dx.DeployTrigger(artifact, manifest.Deploy) {
 			continue
 		}
 
@@ -545,6 +742,14 @@ func processArtifactEvent(
 			GitopsRepo:  envFromStore.AppsRepo,
 		}
 
+		deployResult.Signature, err = verifyArtifactSignature(event, envFromStore)
+		if err != nil {
+			deployResult.Status = model.Failure
+			deployResult.StatusDesc = err.Error()
+			deployResults = append(deployResults, deployResult)
+			continue
+		}
+
 		err = manifest.ResolveVars(artifact.CollectVariables())
 		if err != nil {
 			deployResult.Status = model.Failure
@@ -631,6 +836,12 @@ func cloneTemplateWriteAndPush(
 		return "", err
 	}
 
+	fluxPath := filepath.Join(manifest.Env, "flux")
+	if envFromStore.RepoPerEnv {
+		fluxPath = "flux"
+	}
+	fluxAPIVersion := bootstrap.FluxAPIVersionFromRepo(repoTmpPath, fluxPath)
+
 	var kustomizationManifest *manifestgen.Manifest
 	if envFromStore.KustomizationPerApp {
 		kustomizationManifest, err = kustomizationTemplate(
@@ -638,6 +849,7 @@ func cloneTemplateWriteAndPush(
 			envFromStore.AppsRepo,
 			repoTmpPath,
 			envFromStore.RepoPerEnv,
+			fluxAPIVersion,
 		)
 		if err != nil {
 			return "", err
@@ -651,28 +863,14 @@ func cloneTemplateWriteAndPush(
 		nonImpersonatedToken,
 		envFromStore.RepoPerEnv,
 		kustomizationManifest,
+		fluxAPIVersion,
 	)
 	if err != nil {
 		return "", err
 	}
 
 	if sha != "" { // if there is a change to push
-		operation := func() error {
-			head, _ := repo.Head()
-			url := fmt.Sprintf("https://abc123:%s@github.com/%s.git", nonImpersonatedToken, envFromStore.AppsRepo)
-			if envFromStore.BuiltIn {
-				url = fmt.Sprintf("http://%s:%s@%s/%s", gitUser.Login, gitUser.Secret, gitHost, envFromStore.AppsRepo)
-			}
-
-			return nativeGit.NativePushWithToken(
-				url,
-				repoTmpPath,
-				head.Name().Short(),
-			)
-
-		}
-		backoffStrategy := backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 5)
-		err := backoff.Retry(operation, backoffStrategy)
+		err := pushWithRetry(repo, repoTmpPath, envFromStore, nonImpersonatedToken, gitUser, gitHost)
 		if err != nil {
 			return "", err
 		}
@@ -683,6 +881,31 @@ func cloneTemplateWriteAndPush(
 	return sha, nil
 }
 
+func pushWithRetry(
+	repo *git.Repository,
+	repoTmpPath string,
+	envFromStore *model.Environment,
+	nonImpersonatedToken string,
+	gitUser *model.User,
+	gitHost string,
+) error {
+	operation := func() error {
+		head, _ := repo.Head()
+		url := fmt.Sprintf("https://abc123:%s@github.com/%s.git", nonImpersonatedToken, envFromStore.AppsRepo)
+		if envFromStore.BuiltIn {
+			url = fmt.Sprintf("http://%s:%s@%s/%s", gitUser.Login, gitUser.Secret, gitHost, envFromStore.AppsRepo)
+		}
+
+		return nativeGit.NativePushWithToken(
+			url,
+			repoTmpPath,
+			head.Name().Short(),
+		)
+	}
+	backoffStrategy := backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 5)
+	return backoff.Retry(operation, backoffStrategy)
+}
+
 func cloneTemplateDeleteAndPush(
 	gitopsRepo string,
 	gitopsRepoCache *nativeGit.RepoCache,
@@ -818,37 +1041,11 @@ func gitopsTemplateAndWrite(
 	tokenForChartClone string,
 	repoPerEnv bool,
 	kustomizationManifest *manifestgen.Manifest,
+	fluxAPIVersion string,
 ) (string, error) {
-	if strings.HasPrefix(manifest.Chart.Name, "git@") {
-		return "", fmt.Errorf("only HTTPS git repo urls supported in GimletD for git based charts")
-	}
-	if strings.Contains(manifest.Chart.Name, ".git") {
-		t0 := time.Now().UnixNano()
-		tmpChartDir, err := dx.CloneChartFromRepo(manifest, tokenForChartClone)
-		if err != nil {
-			return "", fmt.Errorf("cannot fetch chart from git %s", err.Error())
-		}
-		logrus.Infof("Cloning chart took %d", (time.Now().UnixNano()-t0)/1000/1000)
-		manifest.Chart.Name = tmpChartDir
-		defer os.RemoveAll(tmpChartDir)
-	}
-
-	t0 := time.Now().UnixNano()
-	templatedManifests, err := manifest.Render()
-	if err != nil {
-		return "", fmt.Errorf("cannot run render template %s", err.Error())
-	}
-	logrus.Infof("Helm template took %d", (time.Now().UnixNano()-t0)/1000/1000)
-
-	files := dx.SplitHelmOutput(map[string]string{"manifest.yaml": templatedManifests})
-
-	if kustomizationManifest != nil {
-		files[kustomizationManifest.Path] = kustomizationManifest.Content
-	}
-
-	releaseString, err := json.Marshal(release)
+	files, releaseString, err := gitopsTemplate(manifest, release, tokenForChartClone, kustomizationManifest, fluxAPIVersion)
 	if err != nil {
-		return "", fmt.Errorf("cannot marshal release meta data %s", err.Error())
+		return "", err
 	}
 
 	sha, err := nativeGit.CommitFilesToGit(
@@ -858,7 +1055,7 @@ func gitopsTemplateAndWrite(
 		manifest.App,
 		repoPerEnv,
 		"automated deploy",
-		string(releaseString))
+		releaseString)
 	if err != nil {
 		return "", fmt.Errorf("cannot write to git: %s", err.Error())
 	}
@@ -866,78 +1063,47 @@ func gitopsTemplateAndWrite(
 	return sha, nil
 }
 
-func deployTrigger(artifactToCheck *dx.Artifact, deployPolicy *dx.Deploy) bool {
-	if deployPolicy == nil {
-		return false
-	}
-
-	if deployPolicy.Branch == "" &&
-		deployPolicy.Event == nil &&
-		deployPolicy.Tag == "" {
-		return false
-	}
-
-	if deployPolicy.Branch != "" &&
-		(deployPolicy.Event == nil || *deployPolicy.Event != *dx.PushPtr() && *deployPolicy.Event != *dx.PRPtr()) {
-		return false
-	}
-
-	if deployPolicy.Tag != "" &&
-		(deployPolicy.Event == nil || *deployPolicy.Event != *dx.TagPtr()) {
-		return false
+// gitopsTemplate renders the files of a manifest and its release meta data
+func gitopsTemplate(
+	manifest *dx.Manifest,
+	release *dx.Release,
+	tokenForChartClone string,
+	kustomizationManifest *manifestgen.Manifest,
+	fluxAPIVersion string,
+) (map[string]string, string, error) {
+	if strings.HasPrefix(manifest.Chart.Name, "git@") {
+		return nil, "", fmt.Errorf("only HTTPS git repo urls supported in GimletD for git based charts")
 	}
-
-	if deployPolicy.Tag != "" {
-		negate := false
-		tag := deployPolicy.Branch
-		if strings.HasPrefix(deployPolicy.Tag, "!") {
-			negate = true
-			tag = deployPolicy.Tag[1:]
-		}
-		g := glob.MustCompile(deployPolicy.Tag)
-
-		exactMatch := tag == artifactToCheck.Version.Tag
-		patternMatch := g.Match(artifactToCheck.Version.Tag)
-
-		match := exactMatch || patternMatch
-
-		if negate && match {
-			return false
-		}
-		if !negate && !match {
-			return false
+	if strings.Contains(manifest.Chart.Name, ".git") {
+		t0 := time.Now().UnixNano()
+		tmpChartDir, err := dx.CloneChartFromRepo(manifest, tokenForChartClone)
+		if err != nil {
+			return nil, "", fmt.Errorf("cannot fetch chart from git %s", err.Error())
 		}
+		logrus.Infof("Cloning chart took %d", (time.Now().UnixNano()-t0)/1000/1000)
+		manifest.Chart.Name = tmpChartDir
+		defer os.RemoveAll(tmpChartDir)
 	}
 
-	if deployPolicy.Branch != "" {
-		negate := false
-		branch := deployPolicy.Branch
-		if strings.HasPrefix(deployPolicy.Branch, "!") {
-			negate = true
-			branch = deployPolicy.Branch[1:]
-		}
-		g := glob.MustCompile(branch)
-
-		exactMatch := branch == artifactToCheck.Version.Branch
-		patternMatch := g.Match(artifactToCheck.Version.Branch)
+	t0 := time.Now().UnixNano()
+	templatedManifests, err := manifest.RenderWithFluxAPIVersion(fluxAPIVersion)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot run render template %s", err.Error())
+	}
+	logrus.Infof("Helm template took %d", (time.Now().UnixNano()-t0)/1000/1000)
 
-		match := exactMatch || patternMatch
+	files := dx.SplitHelmOutput(map[string]string{"manifest.yaml": templatedManifests})
 
-		if negate && match {
-			return false
-		}
-		if !negate && !match {
-			return false
-		}
+	if kustomizationManifest != nil {
+		files[kustomizationManifest.Path] = kustomizationManifest.Content
 	}
 
-	if deployPolicy.Event != nil {
-		if *deployPolicy.Event != artifactToCheck.Version.Event {
-			return false
-		}
+	releaseString, err := json.Marshal(release)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot marshal release meta data %s", err.Error())
 	}
 
-	return true
+	return files, string(releaseString), nil
 }
 
 func cleanupTrigger(branch string, cleanupPolicy *dx.Cleanup) bool {
@@ -1009,6 +1175,7 @@ func kustomizationTemplate(
 	repoName string,
 	repoPath string,
 	repoPerEnv bool,
+	fluxAPIVersion string,
 ) (*manifestgen.Manifest, error) {
 	owner, repository := server.ParseRepo(repoName)
 	kustomizationName := uniqueKustomizationName(repoPerEnv, owner, repository, manifest.Env, manifest.Namespace, manifest.App)
@@ -1028,7 +1195,8 @@ func kustomizationTemplate(
 		manifest.Env,
 		kustomizationName,
 		sourceName,
-		repoPerEnv)
+		repoPerEnv,
+		fluxAPIVersion)
 }
 
 func uniqueKustomizationName(singleEnv bool, owner string, repoName string, env string, namespace string, appName string) string {
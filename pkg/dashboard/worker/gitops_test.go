@@ -15,12 +15,18 @@
 package worker
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
 	"github.com/gimlet-io/gimlet-cli/pkg/dx"
 	"github.com/gimlet-io/gimlet-cli/pkg/git/nativeGit"
 	"github.com/go-git/go-billy/v5/memfs"
@@ -97,7 +103,7 @@ func Test_gitopsTemplateAndWrite(t *testing.T) {
 	repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{""}})
 
 	repoPerEnv := false
-	_, err := gitopsTemplateAndWrite(repo, a.Environments[0], &dx.Release{}, "", repoPerEnv, nil)
+	_, err := gitopsTemplateAndWrite(repo, a.Environments[0], &dx.Release{}, "", repoPerEnv, nil, "")
 	assert.Nil(t, err)
 	content, _ := nativeGit.Content(repo, "staging/my-app/deployment.yaml")
 	assert.True(t, len(content) > 100)
@@ -107,7 +113,7 @@ func Test_gitopsTemplateAndWrite(t *testing.T) {
 	assert.True(t, len(content) > 1)
 
 	repoPerEnv = true
-	_, err = gitopsTemplateAndWrite(repo, a.Environments[0], &dx.Release{}, "", repoPerEnv, nil)
+	_, err = gitopsTemplateAndWrite(repo, a.Environments[0], &dx.Release{}, "", repoPerEnv, nil, "")
 	assert.Nil(t, err)
 	content, _ = nativeGit.Content(repo, "my-app/deployment.yaml")
 	assert.True(t, len(content) > 100)
@@ -163,10 +169,10 @@ func Test_gitopsTemplateAndWrite_deleteStaleFiles(t *testing.T) {
 	json.Unmarshal([]byte(withVolume), &a)
 
 	repoPerEnv := true
-	_, err := gitopsTemplateAndWrite(repo, a.Environments[0], &dx.Release{}, "", repoPerEnv, nil)
+	_, err := gitopsTemplateAndWrite(repo, a.Environments[0], &dx.Release{}, "", repoPerEnv, nil, "")
 	assert.Nil(t, err)
 
-	_, err = gitopsTemplateAndWrite(repo, a.Environments[0], &dx.Release{}, "", repoPerEnv, nil)
+	_, err = gitopsTemplateAndWrite(repo, a.Environments[0], &dx.Release{}, "", repoPerEnv, nil, "")
 	assert.Nil(t, err)
 
 	content, _ := nativeGit.Content(repo, "my-app/deployment.yaml")
@@ -204,193 +210,13 @@ func Test_gitopsTemplateAndWrite_deleteStaleFiles(t *testing.T) {
 
 	var b dx.Artifact
 	json.Unmarshal([]byte(withoutVolume), &b)
-	_, err = gitopsTemplateAndWrite(repo, b.Environments[0], &dx.Release{}, "", false, nil)
+	_, err = gitopsTemplateAndWrite(repo, b.Environments[0], &dx.Release{}, "", false, nil, "")
 	assert.Nil(t, err)
 
 	content, _ = nativeGit.Content(repo, "staging/my-app/pvc.yaml")
 	assert.Equal(t, content, "")
 }
 
-func Test_emptyTrigger(t *testing.T) {
-	triggered := deployTrigger(
-		&dx.Artifact{}, nil)
-	assert.False(t, triggered, "Empty deploy policy should not trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{}, &dx.Deploy{})
-	assert.False(t, triggered, "Empty deploy policy should not trigger a deploy")
-}
-
-func Test_branchTrigger(t *testing.T) {
-	triggered := deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Branch: "master",
-			},
-		},
-		&dx.Deploy{
-			Branch: "notMaster",
-			Event:  dx.PushPtr(),
-		})
-	assert.False(t, triggered, "Branch mismatch should not trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Branch: "master",
-			},
-		},
-		&dx.Deploy{
-			Branch: "master",
-			Event:  dx.PushPtr(),
-		})
-	assert.True(t, triggered, "Matching branch should trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Branch: "master",
-				Event:  *dx.PRPtr(),
-			},
-		},
-		&dx.Deploy{
-			Branch: "master",
-			Event:  dx.PRPtr(),
-		})
-	assert.True(t, triggered, "Matching branch should trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Branch: "master",
-			},
-		},
-		&dx.Deploy{
-			Branch: "master",
-		})
-	assert.False(t, triggered, "Branch triggers need an event always to trigger a deploy")
-}
-
-func Test_eventTrigger(t *testing.T) {
-	triggered := deployTrigger(
-		&dx.Artifact{},
-		&dx.Deploy{
-			Event: dx.PushPtr(),
-		})
-	assert.True(t, triggered, "Default Push event should trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{},
-		&dx.Deploy{},
-	)
-	assert.False(t, triggered, "Non matching event should not trigger a deploy, default is Push in the Artifact")
-
-	triggered = deployTrigger(
-		&dx.Artifact{},
-		&dx.Deploy{
-			Event: dx.PRPtr(),
-		})
-	assert.False(t, triggered, "Non matching event should not trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{Version: dx.Version{
-			Event: dx.PR,
-		}},
-		&dx.Deploy{
-			Event: dx.PRPtr(),
-		})
-	assert.True(t, triggered, "Should trigger a PR deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{Version: dx.Version{
-			Event: dx.Tag,
-		}},
-		&dx.Deploy{
-			Event: dx.TagPtr(),
-		})
-	assert.True(t, triggered, "Should trigger a tag deploy")
-}
-
-func Test_tag_and_branch_pattern_triggers(t *testing.T) {
-	triggered := deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Branch: "feature/coolness",
-				Event:  *dx.PRPtr(),
-			},
-		},
-		&dx.Deploy{
-			Branch: "feature/*",
-			Event:  dx.PRPtr(),
-		})
-	assert.True(t, triggered, "Matching branch pattern should trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Tag:   "v3.0.1",
-				Event: *dx.TagPtr(),
-			},
-		},
-		&dx.Deploy{
-			Tag:   "v*",
-			Event: dx.TagPtr(),
-		})
-	assert.True(t, triggered, "Matching tag pattern should trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Tag: "xxx",
-			},
-		},
-		&dx.Deploy{
-			Tag:   "v*",
-			Event: dx.TagPtr(),
-		})
-	assert.False(t, triggered, "Non matching tag pattern should not trigger a deploy")
-}
-
-func Test_negative_tag_and_branch_triggers(t *testing.T) {
-	triggered := deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Branch: "a-bugfix",
-				Event:  *dx.PushPtr(),
-			},
-		},
-		&dx.Deploy{
-			Branch: "!main",
-			Event:  dx.PushPtr(),
-		})
-	assert.True(t, triggered, "Matching branch pattern should trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Tag:   "v2",
-				Event: *dx.TagPtr(),
-			},
-		},
-		&dx.Deploy{
-			Tag:   "!v1",
-			Event: dx.TagPtr(),
-		})
-	assert.True(t, triggered, "Matching tag pattern should trigger a deploy")
-
-	triggered = deployTrigger(
-		&dx.Artifact{
-			Version: dx.Version{
-				Branch: "main",
-			},
-		},
-		&dx.Deploy{
-			Branch: "!main",
-			Event:  dx.TagPtr(),
-		})
-	assert.False(t, triggered, "Non matching branch pattern should not trigger a deploy")
-}
-
 func Test_unmarshal(t *testing.T) {
 	var many dx.Manifest
 	err := yaml.Unmarshal([]byte(`
@@ -584,13 +410,13 @@ func Test_kustomizationTemplateAndWrite(t *testing.T) {
 	repoName := "test/test-app"
 	repoPerEnv := false
 
-	kustomization, err := kustomizationTemplate(m, repoName, dirToWrite, repoPerEnv)
+	kustomization, err := kustomizationTemplate(m, repoName, dirToWrite, repoPerEnv, "")
 	assert.Nil(t, err)
 	assert.True(t, kustomization != nil)
 	assert.Equal(t, "staging/flux/kustomization-myapp.yaml", kustomization.Path)
 
 	repoPerEnv = true
-	kustomization, err = kustomizationTemplate(m, repoName, dirToWrite, repoPerEnv)
+	kustomization, err = kustomizationTemplate(m, repoName, dirToWrite, repoPerEnv, "")
 	assert.Nil(t, err)
 	assert.True(t, kustomization != nil)
 	assert.Equal(t, "flux/kustomization-myapp.yaml", kustomization.Path)
@@ -610,3 +436,36 @@ func Test_uniqueKustomizationName(t *testing.T) {
 	uniqueName = uniqueKustomizationName(singleEnv, owner, repoName, env, namespace, appName)
 	assert.Equal(t, "gimlet-io-staging-infra-my-team-myapp", uniqueName)
 }
+
+func Test_verifyArtifactSignature(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(key)
+	publicDER, _ := x509.MarshalPKIXPublicKey(key.Public())
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+
+	artifact := dx.Artifact{
+		Version:      dx.Version{RepositoryName: "gimlet-io/myapp"},
+		Environments: []*dx.Manifest{{App: "myapp", Env: "production"}},
+	}
+	unsignedEvent, _ := model.ToEvent(artifact)
+	artifact.Sign(privatePEM)
+	artifact.ID = "gimlet-io/myapp-1234"
+	signedEvent, _ := model.ToEvent(artifact)
+
+	outcome, err := verifyArtifactSignature(unsignedEvent, &model.Environment{Name: "staging"})
+	assert.Nil(t, err)
+	assert.Equal(t, "", outcome, "envs without trusted keys should not verify")
+
+	outcome, err = verifyArtifactSignature(unsignedEvent, &model.Environment{Name: "staging", TrustedKeys: []string{publicPEM}})
+	assert.Nil(t, err, "unsigned artifacts are allowed if signatures are not required")
+	assert.Equal(t, "not verified: the artifact is not signed", outcome)
+
+	production := &model.Environment{Name: "production", RequireSignedArtifacts: true, TrustedKeys: []string{publicPEM}}
+	_, err = verifyArtifactSignature(unsignedEvent, production)
+	assert.EqualError(t, err, "production requires signed artifacts: the artifact is not signed")
+
+	outcome, err = verifyArtifactSignature(signedEvent, production)
+	assert.Nil(t, err)
+	assert.Contains(t, outcome, "verified with key")
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/insights"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// insightsWindow is the time window the DORA metrics are exported for
+const insightsWindow = 30 * 24 * time.Hour
+
+// InsightsWorker periodically exports the DORA metrics as Prometheus gauges
+type InsightsWorker struct {
+	Store               *store.Store
+	DeploymentFrequency *prometheus.GaugeVec
+	LeadTime            *prometheus.GaugeVec
+	ChangeFailureRate   *prometheus.GaugeVec
+	TimeToRestore       *prometheus.GaugeVec
+}
+
+func (w *InsightsWorker) Run() {
+	for {
+		until := time.Now()
+		metrics, err := insights.Load(w.Store, until.Add(-insightsWindow), until)
+		if err != nil {
+			logrus.Warnf("could not compute insights: %s", err)
+			time.Sleep(5 * time.Minute)
+			continue
+		}
+
+		w.DeploymentFrequency.Reset()
+		w.LeadTime.Reset()
+		w.ChangeFailureRate.Reset()
+		w.TimeToRestore.Reset()
+		for _, m := range metrics {
+			w.DeploymentFrequency.WithLabelValues(m.Env, m.App).Set(m.DeploymentFrequency)
+			w.LeadTime.WithLabelValues(m.Env, m.App).Set(float64(m.LeadTime))
+			w.ChangeFailureRate.WithLabelValues(m.Env, m.App).Set(m.ChangeFailureRate)
+			w.TimeToRestore.WithLabelValues(m.Env, m.App).Set(float64(m.TimeToRestore))
+		}
+
+		time.Sleep(5 * time.Minute)
+	}
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"encoding/json"
+
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/server/streaming"
+	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/store"
+	"github.com/sirupsen/logrus"
+)
+
+// WorkloadActionWorker records the outcome of workload actions that the agents report,
+// and streams them to the dashboard
+type WorkloadActionWorker struct {
+	Store     *store.Store
+	ClientHub *streaming.ClientHub
+	Results   chan streaming.WorkloadActionResultWSMessage
+}
+
+func (w *WorkloadActionWorker) Run() {
+	for result := range w.Results {
+		err := w.Store.UpdateWorkloadActionStatus(result.ID, result.Status, result.StatusDesc)
+		if err != nil {
+			logrus.Errorf("could not update workload action: %s", err)
+			continue
+		}
+
+		action, err := w.Store.WorkloadAction(result.ID)
+		if err != nil {
+			logrus.Errorf("could not get workload action: %s", err)
+			continue
+		}
+
+		jsonString, _ := json.Marshal(streaming.WorkloadActionEvent{
+			StreamingEvent: streaming.StreamingEvent{Event: streaming.WorkloadActionEventString},
+			WorkloadAction: action,
+		})
+		w.ClientHub.Broadcast <- jsonString
+	}
+}
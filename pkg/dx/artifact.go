@@ -2,7 +2,9 @@ package dx
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/gobwas/glob"
 	"gopkg.in/yaml.v3"
 )
 
@@ -53,6 +55,9 @@ type Artifact struct {
 
 	// Fake is true if the artifact was generated by the magic deploy link
 	Fake bool `json:"fake,omitempty"`
+
+	// Signatures over the canonical JSON of the artifact, see gimlet artifact sign
+	Signatures []Signature `json:"signatures,omitempty"`
 }
 
 func (a *Artifact) HasCleanupPolicy() bool {
@@ -103,3 +108,78 @@ func (a *Artifact) CueEnvironmentsToManifests() ([]*Manifest, error) {
 	}
 	return manifests, nil
 }
+
+// DeployTrigger tells if the deploy policy of a manifest triggers a deploy of the artifact
+func DeployTrigger(artifactToCheck *Artifact, deployPolicy *Deploy) bool {
+	if deployPolicy == nil {
+		return false
+	}
+
+	if deployPolicy.Branch == "" &&
+		deployPolicy.Event == nil &&
+		deployPolicy.Tag == "" {
+		return false
+	}
+
+	if deployPolicy.Branch != "" &&
+		(deployPolicy.Event == nil || *deployPolicy.Event != *PushPtr() && *deployPolicy.Event != *PRPtr()) {
+		return false
+	}
+
+	if deployPolicy.Tag != "" &&
+		(deployPolicy.Event == nil || *deployPolicy.Event != *TagPtr()) {
+		return false
+	}
+
+	if deployPolicy.Tag != "" {
+		negate := false
+		tag := deployPolicy.Branch
+		if strings.HasPrefix(deployPolicy.Tag, "!") {
+			negate = true
+			tag = deployPolicy.Tag[1:]
+		}
+		g := glob.MustCompile(deployPolicy.Tag)
+
+		exactMatch := tag == artifactToCheck.Version.Tag
+		patternMatch := g.Match(artifactToCheck.Version.Tag)
+
+		match := exactMatch || patternMatch
+
+		if negate && match {
+			return false
+		}
+		if !negate && !match {
+			return false
+		}
+	}
+
+	if deployPolicy.Branch != "" {
+		negate := false
+		branch := deployPolicy.Branch
+		if strings.HasPrefix(deployPolicy.Branch, "!") {
+			negate = true
+			branch = deployPolicy.Branch[1:]
+		}
+		g := glob.MustCompile(branch)
+
+		exactMatch := branch == artifactToCheck.Version.Branch
+		patternMatch := g.Match(artifactToCheck.Version.Branch)
+
+		match := exactMatch || patternMatch
+
+		if negate && match {
+			return false
+		}
+		if !negate && !match {
+			return false
+		}
+	}
+
+	if deployPolicy.Event != nil {
+		if *deployPolicy.Event != artifactToCheck.Version.Event {
+			return false
+		}
+	}
+
+	return true
+}
@@ -66,3 +66,183 @@ configs: [ for instance in _instances {
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(manifests))
 }
+
+func Test_emptyTrigger(t *testing.T) {
+	triggered := DeployTrigger(
+		&Artifact{}, nil)
+	assert.False(t, triggered, "Empty deploy policy should not trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{}, &Deploy{})
+	assert.False(t, triggered, "Empty deploy policy should not trigger a deploy")
+}
+
+func Test_branchTrigger(t *testing.T) {
+	triggered := DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Branch: "master",
+			},
+		},
+		&Deploy{
+			Branch: "notMaster",
+			Event:  PushPtr(),
+		})
+	assert.False(t, triggered, "Branch mismatch should not trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Branch: "master",
+			},
+		},
+		&Deploy{
+			Branch: "master",
+			Event:  PushPtr(),
+		})
+	assert.True(t, triggered, "Matching branch should trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Branch: "master",
+				Event:  *PRPtr(),
+			},
+		},
+		&Deploy{
+			Branch: "master",
+			Event:  PRPtr(),
+		})
+	assert.True(t, triggered, "Matching branch should trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Branch: "master",
+			},
+		},
+		&Deploy{
+			Branch: "master",
+		})
+	assert.False(t, triggered, "Branch triggers need an event always to trigger a deploy")
+}
+
+func Test_eventTrigger(t *testing.T) {
+	triggered := DeployTrigger(
+		&Artifact{},
+		&Deploy{
+			Event: PushPtr(),
+		})
+	assert.True(t, triggered, "Default Push event should trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{},
+		&Deploy{},
+	)
+	assert.False(t, triggered, "Non matching event should not trigger a deploy, default is Push in the Artifact")
+
+	triggered = DeployTrigger(
+		&Artifact{},
+		&Deploy{
+			Event: PRPtr(),
+		})
+	assert.False(t, triggered, "Non matching event should not trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{Version: Version{
+			Event: PR,
+		}},
+		&Deploy{
+			Event: PRPtr(),
+		})
+	assert.True(t, triggered, "Should trigger a PR deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{Version: Version{
+			Event: Tag,
+		}},
+		&Deploy{
+			Event: TagPtr(),
+		})
+	assert.True(t, triggered, "Should trigger a tag deploy")
+}
+
+func Test_tag_and_branch_pattern_triggers(t *testing.T) {
+	triggered := DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Branch: "feature/coolness",
+				Event:  *PRPtr(),
+			},
+		},
+		&Deploy{
+			Branch: "feature/*",
+			Event:  PRPtr(),
+		})
+	assert.True(t, triggered, "Matching branch pattern should trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Tag:   "v3.0.1",
+				Event: *TagPtr(),
+			},
+		},
+		&Deploy{
+			Tag:   "v*",
+			Event: TagPtr(),
+		})
+	assert.True(t, triggered, "Matching tag pattern should trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Tag: "xxx",
+			},
+		},
+		&Deploy{
+			Tag:   "v*",
+			Event: TagPtr(),
+		})
+	assert.False(t, triggered, "Non matching tag pattern should not trigger a deploy")
+}
+
+func Test_negative_tag_and_branch_triggers(t *testing.T) {
+	triggered := DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Branch: "a-bugfix",
+				Event:  *PushPtr(),
+			},
+		},
+		&Deploy{
+			Branch: "!main",
+			Event:  PushPtr(),
+		})
+	assert.True(t, triggered, "Matching branch pattern should trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Tag:   "v2",
+				Event: *TagPtr(),
+			},
+		},
+		&Deploy{
+			Tag:   "!v1",
+			Event: TagPtr(),
+		})
+	assert.True(t, triggered, "Matching tag pattern should trigger a deploy")
+
+	triggered = DeployTrigger(
+		&Artifact{
+			Version: Version{
+				Branch: "main",
+			},
+		},
+		&Deploy{
+			Branch: "!main",
+			Event:  TagPtr(),
+		})
+	assert.False(t, triggered, "Non matching branch pattern should not trigger a deploy")
+}
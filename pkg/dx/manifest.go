@@ -150,6 +150,12 @@ func (m *Manifest) ResolveVars(vars map[string]string) error {
 }
 
 func (m *Manifest) Render() (string, error) {
+	return m.RenderWithFluxAPIVersion("")
+}
+
+// RenderWithFluxAPIVersion renders the manifest with its Flux resources in the given API version.
+// Empty version renders them in the default version
+func (m *Manifest) RenderWithFluxAPIVersion(fluxAPIVersion string) (string, error) {
 	var templatedManifests string
 	var err error
 	if m.Chart.Name != "" {
@@ -178,7 +184,7 @@ func (m *Manifest) Render() (string, error) {
 	}
 
 	for _, dependency := range m.Dependencies {
-		renderredDep, err := renderDependency(dependency, m)
+		renderredDep, err := renderDependency(dependency, m, fluxAPIVersion)
 		if err != nil {
 			return templatedManifests, fmt.Errorf("cannot render dependency %s", err)
 		}
@@ -188,7 +194,7 @@ func (m *Manifest) Render() (string, error) {
 	return templatedManifests, nil
 }
 
-func renderDependency(dependency Dependency, manifest *Manifest) (string, error) {
+func renderDependency(dependency Dependency, manifest *Manifest, fluxAPIVersion string) (string, error) {
 	depString := ""
 	switch dependency.Kind {
 	case "terraform":
@@ -227,6 +233,7 @@ func renderDependency(dependency Dependency, manifest *Manifest) (string, error)
 			tag,
 			sha,
 			tfSpec.Module.Secret,
+			fluxAPIVersion,
 		)
 		if err != nil {
 			return "", err
@@ -263,8 +270,12 @@ func renderTFGitRepo(
 	tag string,
 	sha string,
 	secretName string,
+	fluxAPIVersion string,
 ) ([]byte, error) {
 	gvk := sourcev1.GroupVersion.WithKind(sourcev1.GitRepositoryKind)
+	if fluxAPIVersion != "" {
+		gvk.Version = fluxAPIVersion
+	}
 	gitRepository := sourcev1.GitRepository{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       gvk.Kind,
@@ -195,7 +195,7 @@ dependencies:
 	var m Manifest
 	err := yaml.Unmarshal([]byte(manifestString), &m)
 	if assert.NoError(t, err) {
-		renderredDep, err := renderDependency(m.Dependencies[0], &m)
+		renderredDep, err := renderDependency(m.Dependencies[0], &m, "")
 		if assert.NoError(t, err) {
 			assert.True(t, strings.Contains(string(renderredDep), "url: https://github.com/gimlet-io/tfmodule"), "git repo url must be set")
 			assert.True(t, strings.Contains(string(renderredDep), "commit: xyz"), "git tag must be set")
@@ -204,5 +204,10 @@ dependencies:
 			assert.True(t, strings.Contains(string(renderredDep), "value: my-app"), "values must be set")
 			// fmt.Println(string(renderredDep))
 		}
+
+		renderredDep, err = renderDependency(m.Dependencies[0], &m, "v1")
+		if assert.NoError(t, err) {
+			assert.True(t, strings.Contains(string(renderredDep), "apiVersion: source.toolkit.fluxcd.io/v1\n"), "git repo must be in the requested flux api version")
+		}
 	}
 }
@@ -40,6 +40,13 @@ type ReleaseRequest struct {
 	TriggeredBy string `json:"triggeredBy"`
 }
 
+// PromotionRequest contains releases to an environment that are written in a single gitops commit
+type PromotionRequest struct {
+	Env         string           `json:"env"`
+	Releases    []ReleaseRequest `json:"releases"`
+	TriggeredBy string           `json:"triggeredBy"`
+}
+
 // MagicDeployRequest contains all metadata about a simplified release intent
 type MagicDeployRequest struct {
 	Owner       string `json:"owner"`
@@ -71,6 +78,8 @@ type Result struct {
 	GitopsCommitStatusDesc string `json:"gitopsCommitStatusDesc,omitempty"`
 	Env                    string `json:"env,omitempty"`
 	StatusDesc             string `json:"statusDesc,omitempty"`
+	// Signature is the outcome of the artifact signature verification
+	Signature string `json:"signature,omitempty"`
 }
 
 // ReleaseStatus is the result of an artifact shipping or an on-demand deploy
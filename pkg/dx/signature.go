@@ -0,0 +1,190 @@
+package dx
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+)
+
+// Signature is a signature over the canonical JSON of an artifact
+type Signature struct {
+	// KeyID is the fingerprint of the public key that verifies the signature
+	KeyID string `json:"keyId"`
+	// Signature is the base64 encoded signature
+	Signature string `json:"signature"`
+}
+
+// CanonicalJSON is the signed form of the artifact.
+// It leaves out the fields set by the server on save, and the signatures themselves
+func (a *Artifact) CanonicalJSON() ([]byte, error) {
+	unsigned := *a
+	unsigned.ID = ""
+	unsigned.Created = 0
+	unsigned.Signatures = nil
+
+	artifactBytes, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	// a round trip through a generic structure, so numbers and keys are serialized the same way
+	// no matter how the artifact was constructed
+	var generic interface{}
+	err = json.Unmarshal(artifactBytes, &generic)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// Sign adds a signature to the artifact with a PEM encoded ECDSA or Ed25519 private key.
+// An earlier signature with the same key is replaced
+func (a *Artifact) Sign(privateKeyPEM []byte) error {
+	privateKey, err := ParsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return err
+	}
+	keyID, err := KeyID(privateKey.Public())
+	if err != nil {
+		return err
+	}
+
+	payload, err := a.CanonicalJSON()
+	if err != nil {
+		return fmt.Errorf("cannot serialize artifact: %s", err)
+	}
+
+	var signature []byte
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(payload)
+		signature, err = ecdsa.SignASN1(rand.Reader, key, digest[:])
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, payload)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot sign artifact: %s", err)
+	}
+
+	signatures := []Signature{}
+	for _, s := range a.Signatures {
+		if s.KeyID != keyID {
+			signatures = append(signatures, s)
+		}
+	}
+	a.Signatures = append(signatures, Signature{
+		KeyID:     keyID,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+	})
+
+	return nil
+}
+
+// VerifySignatures checks if any of the signatures is made by one of the PEM encoded trusted public keys.
+// It returns the key ID of the trusted key that verified the payload
+func VerifySignatures(payload []byte, signatures []Signature, trustedKeysPEM []string) (string, error) {
+	if len(signatures) == 0 {
+		return "", fmt.Errorf("the artifact is not signed")
+	}
+
+	for _, keyPEM := range trustedKeysPEM {
+		publicKey, err := ParsePublicKey([]byte(keyPEM))
+		if err != nil {
+			return "", err
+		}
+		keyID, err := KeyID(publicKey)
+		if err != nil {
+			return "", err
+		}
+
+		for _, s := range signatures {
+			if s.KeyID != keyID {
+				continue
+			}
+
+			signature, err := base64.StdEncoding.DecodeString(s.Signature)
+			if err != nil {
+				return "", fmt.Errorf("cannot decode signature of key %s: %s", keyID, err)
+			}
+			if verify(publicKey, payload, signature) {
+				return keyID, nil
+			}
+			return "", fmt.Errorf("invalid signature of key %s, the artifact was modified after signing", keyID)
+		}
+	}
+
+	return "", fmt.Errorf("the artifact is not signed by a trusted key")
+}
+
+func verify(publicKey crypto.PublicKey, payload []byte, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, payload, signature)
+	}
+	return false
+}
+
+// KeyID is the first 16 hex characters of the SHA256 fingerprint of a public key
+func KeyID(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("cannot serialize public key: %s", err)
+	}
+	fingerprint := sha256.Sum256(der)
+	return hex.EncodeToString(fingerprint[:])[:16], nil
+}
+
+// ParsePrivateKey parses a PEM encoded ECDSA or Ed25519 private key, in PKCS8 or SEC1 format
+func ParsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("cannot decode private key: not PEM encoded")
+	}
+
+	if block.Type == "EC PRIVATE KEY" {
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse private key: %s", err)
+	}
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported private key type %T, use an ECDSA or Ed25519 key", key)
+}
+
+// ParsePublicKey parses a PEM encoded ECDSA or Ed25519 public key
+func ParsePublicKey(keyPEM []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("cannot decode public key: not PEM encoded")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse public key: %s", err)
+	}
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		return key, nil
+	case ed25519.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T, use an ECDSA or Ed25519 key", key)
+}
@@ -0,0 +1,75 @@
+package dx
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_signAndVerify(t *testing.T) {
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecdsaPrivate, ecdsaPublic := pemKeys(t, ecdsaKey, ecdsaKey.Public())
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+	ed25519Private, ed25519Public := pemKeys(t, ed25519Key, ed25519Key.Public())
+
+	artifact := &Artifact{
+		Version: Version{RepositoryName: "gimlet-io/myapp", SHA: "abc123"},
+		Environments: []*Manifest{
+			{App: "myapp", Env: "production", Values: map[string]interface{}{"replicas": 2}},
+		},
+		Vars: map[string]string{"IMAGE": "myapp:abc123"},
+	}
+	err := artifact.Sign(ecdsaPrivate)
+	assert.Nil(t, err)
+	err = artifact.Sign(ed25519Private)
+	assert.Nil(t, err)
+	err = artifact.Sign(ecdsaPrivate)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(artifact.Signatures), "signing again with a key should replace its signature")
+
+	// the server sets the id and creation time, and stores the artifact as json
+	artifact.ID = "gimlet-io/myapp-1234"
+	artifact.Created = 1622792757
+	artifactBytes, _ := json.Marshal(artifact)
+	var saved Artifact
+	json.Unmarshal(artifactBytes, &saved)
+
+	payload, err := saved.CanonicalJSON()
+	assert.Nil(t, err)
+
+	keyID, err := VerifySignatures(payload, saved.Signatures, []string{string(ed25519Public)})
+	assert.Nil(t, err)
+	assert.Equal(t, saved.Signatures[0].KeyID, keyID)
+	_, err = VerifySignatures(payload, saved.Signatures, []string{string(ecdsaPublic)})
+	assert.Nil(t, err)
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, otherPublic := pemKeys(t, otherKey, otherKey.Public())
+	_, err = VerifySignatures(payload, saved.Signatures, []string{string(otherPublic)})
+	assert.EqualError(t, err, "the artifact is not signed by a trusted key")
+
+	saved.Vars["IMAGE"] = "evil:latest"
+	tampered, _ := saved.CanonicalJSON()
+	_, err = VerifySignatures(tampered, saved.Signatures, []string{string(ecdsaPublic)})
+	assert.Contains(t, err.Error(), "modified after signing")
+
+	_, err = VerifySignatures(payload, nil, []string{string(ecdsaPublic)})
+	assert.EqualError(t, err, "the artifact is not signed")
+}
+
+func pemKeys(t *testing.T, private interface{}, public interface{}) ([]byte, []byte) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.Nil(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	assert.Nil(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+}
@@ -487,9 +487,35 @@ func CommitFilesToGit(
 		return "", fmt.Errorf("there are staged changes in the gitops repo. Commit them first then try again")
 	}
 
+	err = StageFilesToGit(repo, files, env, app, repoPerEnv, releaseString)
+	if err != nil {
+		return "", err
+	}
+
+	empty, err = NothingToCommit(repo)
+	if err != nil {
+		return "", err
+	}
+	if empty {
+		return "", nil
+	}
+
+	gitMessage := fmt.Sprintf("[Gimlet] %s/%s %s", env, app, message)
+	return Commit(repo, gitMessage)
+}
+
+// StageFilesToGit replaces the files of an app in the gitops repo and stages them, without committing
+func StageFilesToGit(
+	repo *git.Repository,
+	files map[string]string,
+	env string,
+	app string,
+	repoPerEnv bool,
+	releaseString string,
+) error {
 	w, err := repo.Worktree()
 	if err != nil {
-		return "", fmt.Errorf("cannot get worktree %s", err)
+		return fmt.Errorf("cannot get worktree %s", err)
 	}
 
 	rootPath := filepath.Join(env, app)
@@ -501,11 +527,11 @@ func CommitFilesToGit(
 	// to remove stale template files
 	err = DelDir(repo, rootPath)
 	if err != nil {
-		return "", fmt.Errorf("cannot del dir: %s", err)
+		return fmt.Errorf("cannot del dir: %s", err)
 	}
 	err = w.Filesystem.MkdirAll(rootPath, Dir_RWX_RX_R)
 	if err != nil {
-		return "", fmt.Errorf("cannot create dir %s", err)
+		return fmt.Errorf("cannot create dir %s", err)
 	}
 
 	for path, content := range files {
@@ -515,14 +541,14 @@ func CommitFilesToGit(
 		if strings.Contains(path, fmt.Sprintf("kustomization-%s.yaml", app)) {
 			err = StageFile(w, content, path)
 			if err != nil {
-				return "", fmt.Errorf("cannot stage file %s", err)
+				return fmt.Errorf("cannot stage file %s", err)
 			}
 			continue
 		}
 
 		err = StageFile(w, content, filepath.Join(rootPath, filepath.Base(path)))
 		if err != nil {
-			return "", fmt.Errorf("cannot stage file %s", err)
+			return fmt.Errorf("cannot stage file %s", err)
 		}
 	}
 
@@ -538,24 +564,15 @@ func CommitFilesToGit(
 
 		err = StageFile(w, releaseString, filepath.Join(envReleaseJsonPath, "release.json"))
 		if err != nil {
-			return "", fmt.Errorf("cannot stage file %s", err)
+			return fmt.Errorf("cannot stage file %s", err)
 		}
 		err = StageFile(w, releaseString, filepath.Join(rootPath, "release.json"))
 		if err != nil {
-			return "", fmt.Errorf("cannot stage file %s", err)
+			return fmt.Errorf("cannot stage file %s", err)
 		}
 	}
 
-	empty, err = NothingToCommit(repo)
-	if err != nil {
-		return "", err
-	}
-	if empty {
-		return "", nil
-	}
-
-	gitMessage := fmt.Sprintf("[Gimlet] %s/%s %s", env, app, message)
-	return Commit(repo, gitMessage)
+	return nil
 }
 
 func StageFile(worktree *git.Worktree, content string, path string) error {
@@ -37,6 +37,7 @@ type ManifestOpts struct {
 	ShouldGenerateBasicAuthSecret      bool
 	BasicAuthUser                      string
 	BasicAuthPassword                  string
+	FluxAPIVersion                     string
 }
 
 func DefaultManifestOpts() ManifestOpts {
@@ -126,6 +127,7 @@ func GenerateManifests(opts ManifestOpts) (string, string, string, error) {
 			Branch:               opts.Branch,
 			ManifestFile:         gitopsRepoFileName,
 			GenerateDependencies: opts.ShouldGenerateDependencies,
+			FluxAPIVersion:       opts.FluxAPIVersion,
 		}
 
 		syncOpts.DependenciesPath = opts.Env
@@ -348,3 +350,32 @@ func GitopsRepoFileAndMetaNameFromRepo(repoPath string, contentPath string) (str
 	}
 	return gitopsRepoFileName, gitRepo.ObjectMeta.Name
 }
+
+// FluxAPIVersionFromRepo returns the Flux API version the gitops repo was bootstrapped with,
+// so manifests added later can be generated in the same version
+func FluxAPIVersionFromRepo(repoPath string, contentPath string) string {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return ""
+	}
+	branch, _ := helper.HeadBranch(repo)
+
+	files, _ := helper.RemoteFolderOnBranchWithoutCheckout(repo, branch, contentPath)
+	for fileName, fileContent := range files {
+		if !strings.Contains(fileName, "gitops-repo") {
+			continue
+		}
+
+		var typeMeta metav1.TypeMeta
+		err := yaml.Unmarshal([]byte(fileContent), &typeMeta)
+		if err != nil {
+			logrus.Warnf("couldn't unmarshal %s: %s", fileName, err)
+			continue
+		}
+		parts := strings.SplitN(typeMeta.APIVersion, "/", 2)
+		if len(parts) == 2 {
+			return parts[1]
+		}
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gimlet-io/gimlet-cli/pkg/dashboard/model"
@@ -222,3 +223,34 @@ func Test_uniqueGitopsRepoName(t *testing.T) {
 	uniqueName = UniqueGitopsRepoName(singleEnv, owner, repoName, env)
 	assert.Equal(t, "gimlet-io-staging-infra", uniqueName)
 }
+
+func Test_generateManifestWithFluxAPIVersion(t *testing.T) {
+	dirToWrite, err := ioutil.TempDir("/tmp", "gimlet")
+	defer os.RemoveAll(dirToWrite)
+	if err != nil {
+		t.Errorf("Cannot create directory")
+		return
+	}
+
+	opts := DefaultManifestOpts()
+	opts.ShouldGenerateController = false
+	opts.GitopsRepoUrl = "git@github.com:gimlet-io/gitops-staging-infra.git"
+	opts.GitopsRepoPath = dirToWrite
+	opts.ShouldGenerateDeployKey = false
+	opts.FluxAPIVersion = "v1"
+
+	_, _, _, err = GenerateManifests(opts)
+	if err != nil {
+		t.Errorf("Cannot generate the manifest files, %s", err)
+		return
+	}
+
+	gitopsRepo, err := ioutil.ReadFile(filepath.Join(dirToWrite, "flux", "gitops-repo-gimlet-io-gitops-staging-infra.yaml"))
+	if err != nil {
+		t.Errorf("Should generate gitops repo: %s", err)
+		return
+	}
+	if !strings.Contains(string(gitopsRepo), "apiVersion: source.toolkit.fluxcd.io/v1\n") {
+		t.Errorf("Should generate the gitops repo in the requested api version")
+	}
+}
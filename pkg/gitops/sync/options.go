@@ -33,6 +33,9 @@ type Options struct {
 	GitImplementation    string
 	RecurseSubmodules    bool
 	GenerateDependencies bool
+	// FluxAPIVersion is the version of the generated Flux resources, eg.: v1.
+	// Empty keeps the versions that the sync manifests were always generated in
+	FluxAPIVersion string
 }
 
 func MakeDefaultOptions() Options {
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
 
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta2"
@@ -35,12 +36,49 @@ import (
 	"github.com/fluxcd/flux2/v2/pkg/manifestgen"
 )
 
+// FluxAPIVersions are the Flux API versions the manifests can be generated in
+var FluxAPIVersions = []string{"v1", "v1beta2", "v1beta1"}
+
+// ValidateFluxAPIVersion checks if manifests can be generated in the given Flux API version
+func ValidateFluxAPIVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	for _, v := range FluxAPIVersions {
+		if v == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported flux api version %s, use one of %s", version, strings.Join(FluxAPIVersions, ", "))
+}
+
+// apiVersion returns the group in the requested version, or in its default version if none requested
+func apiVersion(groupVersion schema.GroupVersion, version string) string {
+	if version == "" {
+		return groupVersion.String()
+	}
+	return schema.GroupVersion{Group: groupVersion.Group, Version: version}.String()
+}
+
+// kustomizationValidation is dropped from v1 Kustomizations
+func kustomizationValidation(version string) string {
+	if version == "v1" {
+		return ""
+	}
+	return "client"
+}
+
 func Generate(options Options) (*manifestgen.Manifest, error) {
+	err := ValidateFluxAPIVersion(options.FluxAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	gvk := sourcev1.GroupVersion.WithKind(sourcev1.GitRepositoryKind)
 	gitRepository := sourcev1.GitRepository{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       gvk.Kind,
-			APIVersion: gvk.GroupVersion().String(),
+			APIVersion: apiVersion(gvk.GroupVersion(), options.FluxAPIVersion),
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      options.Name,
@@ -62,6 +100,10 @@ func Generate(options Options) (*manifestgen.Manifest, error) {
 		},
 	}
 
+	if options.FluxAPIVersion == "v1" {
+		gitRepository.Spec.GitImplementation = ""
+	}
+
 	gitData, err := yaml.Marshal(gitRepository)
 	if err != nil {
 		return nil, err
@@ -73,7 +115,7 @@ func Generate(options Options) (*manifestgen.Manifest, error) {
 		kustomizationDependencies = kustomizev1.Kustomization{
 			TypeMeta: metav1.TypeMeta{
 				Kind:       gvk.Kind,
-				APIVersion: gvk.GroupVersion().String(),
+				APIVersion: apiVersion(gvk.GroupVersion(), options.FluxAPIVersion),
 			},
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      fmt.Sprintf("%s-%s", options.Name, "dependencies"),
@@ -89,7 +131,7 @@ func Generate(options Options) (*manifestgen.Manifest, error) {
 					Kind: sourcev1.GitRepositoryKind,
 					Name: options.Name,
 				},
-				Validation: "client",
+				Validation: kustomizationValidation(options.FluxAPIVersion),
 			},
 		}
 	}
@@ -102,7 +144,7 @@ func Generate(options Options) (*manifestgen.Manifest, error) {
 	kustomization := kustomizev1.Kustomization{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       gvk.Kind,
-			APIVersion: gvk.GroupVersion().String(),
+			APIVersion: apiVersion(gvk.GroupVersion(), options.FluxAPIVersion),
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      options.Name,
@@ -118,7 +160,7 @@ func Generate(options Options) (*manifestgen.Manifest, error) {
 				Kind: sourcev1.GitRepositoryKind,
 				Name: options.Name,
 			},
-			Validation: "client",
+			Validation: kustomizationValidation(options.FluxAPIVersion),
 		},
 	}
 
@@ -220,7 +262,13 @@ func GenerateKustomizationForApp(
 	kustomizationName string,
 	sourceName string,
 	singleEnv bool,
+	fluxAPIVersion string,
 ) (*manifestgen.Manifest, error) {
+	err := ValidateFluxAPIVersion(fluxAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	filePath := filepath.Join(env, "flux")
 	kustomizationPath := filepath.Join(env, app)
 	if singleEnv {
@@ -231,7 +279,7 @@ func GenerateKustomizationForApp(
 	kustomization := kustomizev1.Kustomization{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       gvk.Kind,
-			APIVersion: gvk.GroupVersion().String(),
+			APIVersion: apiVersion(gvk.GroupVersion(), fluxAPIVersion),
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      kustomizationName,
@@ -247,7 +295,7 @@ func GenerateKustomizationForApp(
 				Kind: sourcev1.GitRepositoryKind,
 				Name: sourceName,
 			},
-			Validation: "client",
+			Validation: kustomizationValidation(fluxAPIVersion),
 		},
 	}
 
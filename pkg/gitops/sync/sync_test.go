@@ -42,6 +42,30 @@ func TestGenerate(t *testing.T) {
 	fmt.Println(output.Content)
 }
 
+func TestGenerateFluxAPIVersion(t *testing.T) {
+	opts := MakeDefaultOptions()
+	opts.FluxAPIVersion = "v1"
+	output, err := Generate(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, apiVersion := range []string{"source.toolkit.fluxcd.io/v1\n", "kustomize.toolkit.fluxcd.io/v1\n"} {
+		if !strings.Contains(output.Content, apiVersion) {
+			t.Errorf("apiVersion '%s' not found", apiVersion)
+		}
+	}
+	if strings.Contains(output.Content, "validation:") {
+		t.Errorf("validation is not part of the v1 kustomization spec")
+	}
+
+	opts.FluxAPIVersion = "v2"
+	_, err = Generate(opts)
+	if err == nil {
+		t.Errorf("unsupported api version should not be generated")
+	}
+}
+
 func TestGenerateNotificationProvider(t *testing.T) {
 	envName := "staging"
 	gimletdUrl := "https://test.gimlet.io"
@@ -84,6 +108,7 @@ func TestGenerateKustomizationForApp(t *testing.T) {
 		kustomizationName,
 		sourceName,
 		singleEnv,
+		"",
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -60,6 +60,10 @@ export default class GimletClient {
   stopPodlogsRequest = (namespace, serviceName) => this.getWithAxios(`/api/stopPodLogs?namespace=${namespace}&serviceName=${serviceName}`);
 
   getAlerts = () => this.getWithAxios("/api/alerts");
+
+  postWorkloadAction = (env, action, kind, namespace, name, replicas) => this.postWithAxios("/api/actions", JSON.stringify({ env, action, kind, namespace, name, replicas }));
+
+  getWorkloadActions = () => this.getWithAxios("/api/actions");
   
   bootstrapGitops = (envName, repoPerEnv, kustomizationPerApp, infraRepo, appsRepo) => this.postWithAxios('/api/bootstrapGitops', JSON.stringify({ envName, repoPerEnv, kustomizationPerApp, infraRepo, appsRepo }));
 
@@ -21,7 +21,7 @@ export class Env extends Component {
   render() {
     const { searchFilter, env, repoRolloutHistory, envConfigs, navigateToConfigEdit, linkToDeployment, newConfig, rollback, owner, repoName, fileInfos, pullRequests, releaseHistorySinceDays, gimletClient, store, kubernetesAlerts, deploymentFromParams, scmUrl, history } = this.props;
 
-    const renderedServices = renderServices(env.stacks, envConfigs, env.name, repoRolloutHistory, navigateToConfigEdit, linkToDeployment, rollback, owner, repoName, fileInfos, releaseHistorySinceDays, gimletClient, store, kubernetesAlerts, deploymentFromParams, scmUrl, env.builtIn);
+    const renderedServices = renderServices(env.stacks, envConfigs, env.name, repoRolloutHistory, navigateToConfigEdit, linkToDeployment, rollback, owner, repoName, fileInfos, releaseHistorySinceDays, gimletClient, store, kubernetesAlerts, deploymentFromParams, scmUrl, env.builtIn, env.drifts);
 
     return (
       <div>
@@ -110,7 +110,8 @@ function renderServices(
   kubernetesAlerts,
   deploymentFromParams,
   scmUrl,
-  builtInEnv) {
+  builtInEnv,
+  drifts) {
   let services = [];
 
   let configsWeHave = [];
@@ -151,6 +152,7 @@ function renderServices(
         deploymentFromParams={deploymentFromParams}
         scmUrl={scmUrl}
         builtInEnv={builtInEnv}
+        drifts={drifts?.filter(drift => drift.app === stack.service.name)}
       />
     )
   })
@@ -16,7 +16,7 @@ import { Menu } from '@headlessui/react'
 import { usePostHog } from 'posthog-js/react'
 
 function ServiceDetail(props) {
-  const { stack, rolloutHistory, rollback, envName, owner, repoName, navigateToConfigEdit, linkToDeployment, configExists, config, fileName, releaseHistorySinceDays, gimletClient, store, kubernetesAlerts, deploymentFromParams, scmUrl, builtInEnv } = props;
+  const { stack, rolloutHistory, rollback, envName, owner, repoName, navigateToConfigEdit, linkToDeployment, configExists, config, fileName, releaseHistorySinceDays, gimletClient, store, kubernetesAlerts, deploymentFromParams, scmUrl, builtInEnv, drifts } = props;
   const ref = useRef(null);
   const posthog = usePostHog()
 
@@ -168,6 +168,11 @@ function ServiceDetail(props) {
               alerts={kubernetesAlerts}
               hideButton
             />
+            <Drifts
+              drifts={drifts}
+              envName={envName}
+              gimletClient={gimletClient}
+            />
           </div>}
           <div className="my-2 mb-4 sm:my-4 sm:mb-6">
             <RolloutHistory
@@ -197,6 +202,22 @@ function ServiceDetail(props) {
                 setLogsOverlayService={setLogsOverlayService}
                 scmUrl={scmUrl}
               />
+              <Workload
+                kind="statefulset"
+                workload={stack.statefulSet}
+                replicas={stack.statefulSet && `${stack.statefulSet.readyReplicas}/${stack.statefulSet.replicas} ready`}
+                repo={stack.repo}
+                scmUrl={scmUrl}
+              />
+              <Workload
+                kind="daemonset"
+                workload={stack.daemonSet}
+                replicas={stack.daemonSet && `${stack.daemonSet.ready}/${stack.daemonSet.desired} ready`}
+                repo={stack.repo}
+                scmUrl={scmUrl}
+              />
+              {stack.cronJobs ? stack.cronJobs.map((cronJob) => <CronJob cronJob={cronJob} repo={stack.repo} scmUrl={scmUrl} key={`${cronJob.namespace}/${cronJob.name}`} />) : null}
+              <HPA hpa={stack.hpa} />
             </div>
             <div className="flex-1 min-w-full md:min-w-0" />
           </div>
@@ -220,11 +241,180 @@ class Ingress extends Component {
         <div className="mb-1 truncate "><a href={'https://' + ingress.url} target="_blank" rel="noopener noreferrer">{ingress.url}</a>
         </div>
         <p className="text-xs truncate mb-6">{ingress.namespace}/{ingress.name}</p>
+        {ingress.certificates && ingress.certificates.map(certificate =>
+          <CertificateBadge certificate={certificate} key={certificate.secretName} />
+        )}
       </div>
     );
   }
 }
 
+const certificateExpiryWarningDays = 14;
+
+function CertificateBadge(props) {
+  const { certificate } = props;
+
+  const daysLeft = certificate.notAfter ? Math.floor((certificate.notAfter * 1000 - Date.now()) / (24 * 60 * 60 * 1000)) : undefined;
+  const expiry = certificate.notAfter ? new Date(certificate.notAfter * 1000).toLocaleDateString() : "unknown";
+
+  let color = "bg-green-100 text-green-800";
+  let label = `TLS expires in ${daysLeft} days`;
+  if (!certificate.ready) {
+    color = "bg-red-100 text-red-800";
+    label = "TLS not ready";
+  } else if (daysLeft !== undefined && daysLeft < certificateExpiryWarningDays) {
+    color = "bg-yellow-100 text-yellow-800";
+  } else if (daysLeft === undefined) {
+    label = "TLS";
+  }
+
+  const title = `${certificate.secretName} issued by ${certificate.issuer || "unknown"}, expires on ${expiry}` +
+    (certificate.statusDesc ? `\n${certificate.statusDesc}` : "");
+
+  return (
+    <span
+      title={title}
+      className={`${color} inline-block px-2 py-0.5 mr-1 text-xs font-medium rounded-full`}
+    >
+      {label}
+    </span>
+  );
+}
+
+function Commit(props) {
+  const { workload, repo, scmUrl } = props;
+
+  return (
+    <div className="mb-1">
+      <p className="truncate">{workload.commitMessage && <Emoji text={workload.commitMessage} />}</p>
+      {workload.sha &&
+        <p className="text-xs italic"><a href={`${scmUrl}/${repo}/commit/${workload.sha}`} target="_blank"
+          rel="noopener noreferrer">{workload.sha.slice(0, 6)}</a></p>
+      }
+    </div>
+  );
+}
+
+function Workload(props) {
+  const { kind, workload, replicas, repo, scmUrl } = props;
+
+  if (!workload) {
+    return null;
+  }
+
+  return (
+    <div className="bg-gray-100 p-2 mb-1 border rounded-sm border-blue-200 text-gray-500 relative">
+      <span className="text-xs text-gray-400 absolute bottom-0 right-0 p-2">{kind}</span>
+      <Commit workload={workload} repo={repo} scmUrl={scmUrl} />
+      <p className="text-xs truncate w-9/12">{workload.namespace}/{workload.name} <span className="ml-1">{replicas}</span></p>
+      {
+        workload.pods && workload.pods.map((pod) => (
+          <Pod key={pod.name} pod={pod} />
+        ))
+      }
+    </div>
+  );
+}
+
+function CronJob(props) {
+  const { cronJob, repo, scmUrl } = props;
+
+  let lastJobColor = 'text-blue-400';
+  if (cronJob.lastJob?.status === 'Succeeded') {
+    lastJobColor = 'text-green-600';
+  } else if (cronJob.lastJob?.status === 'Failed') {
+    lastJobColor = 'text-red-600';
+  }
+
+  return (
+    <div className="bg-gray-100 p-2 mb-1 border rounded-sm border-blue-200 text-gray-500 relative">
+      <span className="text-xs text-gray-400 absolute bottom-0 right-0 p-2">cronjob</span>
+      <Commit workload={cronJob} repo={repo} scmUrl={scmUrl} />
+      <p className="text-xs truncate w-9/12">{cronJob.namespace}/{cronJob.name}</p>
+      <p className="text-xs">
+        <span className="font-mono">{cronJob.schedule}</span>
+        {cronJob.suspended && <span className="ml-1 italic">suspended</span>}
+      </p>
+      {cronJob.lastJob &&
+        <p className="text-xs">last job: <span className={lastJobColor}>{cronJob.lastJob.status}</span></p>
+      }
+    </div>
+  );
+}
+
+function Drifts(props) {
+  const { drifts, envName, gimletClient } = props;
+
+  if (!drifts || drifts.length === 0) {
+    return null;
+  }
+
+  const kustomizations = [...new Set(drifts.map(drift => drift.kustomization).filter(k => k))];
+
+  return (
+    <div className="rounded-md bg-yellow-50 p-4 mt-2 text-sm text-yellow-800">
+      <div className="flex justify-between">
+        <h3 className="font-medium">Live objects drifted from the gitops repo</h3>
+        {kustomizations.map(kustomization => {
+          const [namespace, name] = kustomization.split("/");
+          return (
+            <button
+              key={kustomization}
+              onClick={() => {
+                // eslint-disable-next-line no-restricted-globals
+                if (confirm(`Are you sure you want to reconcile ${kustomization}? Manual changes will be reverted.`)) {
+                  gimletClient.postWorkloadAction(envName, "reconcile", "kustomization", namespace, name)
+                }
+              }}
+              className="bg-yellow-100 hover:bg-yellow-200 rounded px-2 py-1 font-medium"
+            >
+              Reconcile
+            </button>
+          )
+        })}
+      </div>
+      <ul className="mt-2 list-disc pl-5 space-y-1">
+        {drifts.map(drift => (
+          <li key={`${drift.kind}/${drift.namespace}/${drift.name}/${drift.field}`}>
+            {driftDescription(drift)}
+          </li>
+        ))}
+      </ul>
+    </div>
+  );
+}
+
+function driftDescription(drift) {
+  const object = `${drift.kind} ${drift.namespace}/${drift.name}`;
+  switch (drift.type) {
+    case "missing":
+      return `${object} is missing from the cluster`;
+    case "unmanaged":
+      return `${object} is not in the gitops repo`;
+    default:
+      if (drift.scaledBy) {
+        return `${object} ${drift.field}: ${drift.live} (gitops repo: ${drift.desired ?? "<none>"}), scaled by ${drift.scaledBy}`;
+      }
+      return `${object} ${drift.field}: ${drift.live ?? "<none>"} (gitops repo: ${drift.desired ?? "<none>"})`;
+  }
+}
+
+function HPA(props) {
+  const { hpa } = props;
+
+  if (!hpa) {
+    return null;
+  }
+
+  return (
+    <div className="bg-gray-100 p-2 mb-1 border rounded-sm border-gray-200 text-gray-500 relative">
+      <span className="text-xs text-gray-400 absolute bottom-0 right-0 p-2">hpa</span>
+      <p className="text-xs truncate w-9/12">{hpa.namespace}/{hpa.name}</p>
+      <p className="text-xs">{hpa.currentReplicas} replicas, desired {hpa.desiredReplicas} ({hpa.minReplicas}-{hpa.maxReplicas})</p>
+    </div>
+  );
+}
+
 class Deployment extends Component {
   constructor(props) {
     super(props);
@@ -242,7 +432,7 @@ class Deployment extends Component {
   };
 
   render() {
-    const { deployment, service, repo, gimletClient, config, setLogsOverlayVisible, setLogsOverlayNamespace, setLogsOverlayService, scmUrl } = this.props;
+    const { envName, deployment, service, repo, gimletClient, config, setLogsOverlayVisible, setLogsOverlayNamespace, setLogsOverlayService, scmUrl } = this.props;
 
     if (deployment === undefined) {
       return null;
@@ -326,6 +516,24 @@ class Deployment extends Component {
                     </button>
                   )}
                 </Menu.Item>
+                <Menu.Item key="restart">
+                  {({ active }) => (
+                    <button
+                      onClick={() => {
+                        // eslint-disable-next-line no-restricted-globals
+                        if (confirm(`Are you sure you want to restart ${deployment.namespace}/${deployment.name}?`)) {
+                          gimletClient.postWorkloadAction(envName, "restart", "deployment", deployment.namespace, deployment.name)
+                        }
+                      }}
+                      className={(
+                        active ? 'bg-gray-100 text-gray-900' : 'text-gray-700') +
+                        ' block px-4 py-2 text-sm w-full text-left'
+                      }
+                    >
+                      Restart deployment
+                    </button>
+                  )}
+                </Menu.Item>
               </div>
             </Menu.Items>
           </Menu>
@@ -421,10 +629,27 @@ function Pod(props) {
       <svg viewBox="0 0 1 1"
            className={`fill-current ${color} ${pulsar}`}>
         <g>
-          <title>{pod.name} - {pod.status}</title>
+          <title>{pod.name} - {pod.status}{usage(pod)}</title>
           <rect width="1" height="1"/>
         </g>
       </svg>
     </span>
   );
 }
+
+function usage(pod) {
+  if (!pod.containers) {
+    return '';
+  }
+
+  return pod.containers
+    .filter(c => c.cpuUsage || c.memoryUsage)
+    .map(c => {
+      const cpu = c.cpuLimit ? `${c.cpuUsage ?? 0}m/${c.cpuLimit}m` : `${c.cpuUsage ?? 0}m`;
+      const memory = c.memoryLimit
+        ? `${Math.round((c.memoryUsage ?? 0) / 1048576)}Mi/${Math.round(c.memoryLimit / 1048576)}Mi`
+        : `${Math.round((c.memoryUsage ?? 0) / 1048576)}Mi`;
+      return `\n${c.name}: cpu ${cpu}, memory ${memory}`;
+    })
+    .join('');
+}
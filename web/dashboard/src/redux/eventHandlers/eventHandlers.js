@@ -314,6 +314,16 @@ export function fluxStateUpdated(state, event) {
   return state
 }
 
+export function driftUpdated(state, event) {
+  if (state.connectedAgents[event.envName] === undefined) {
+    return state;
+  }
+
+  state.connectedAgents[event.envName].drifts = event.drifts;
+
+  return state
+}
+
 export function updateCommitStatus(state, event) {
   const repo = `${event.owner}/${event.repo}`;
 
@@ -71,6 +71,7 @@ export const EVENT_IMAGE_BUILD_LOG_EVENT = 'imageBuildLogEvent';
 export const EVENT_ARTIFACT_CREATED_EVENT = 'artifactCreatedEvent';
 
 export const EVENT_FLUX_STATE_UPDATED_EVENT = 'fluxStateUpdatedEvent';
+export const EVENT_DRIFT_UPDATED_EVENT = 'driftUpdatedEvent';
 
 export const initialState = {
   settings: {
@@ -242,6 +243,8 @@ function processStreamingEvent(state, event) {
       return eventHandlers.updateCommitStatus(state, event);
     case EVENT_FLUX_STATE_UPDATED_EVENT:
       return eventHandlers.fluxStateUpdated(state, event);
+    case EVENT_DRIFT_UPDATED_EVENT:
+      return eventHandlers.driftUpdated(state, event);
     default:
       console.log('Could not process streaming event: ' + JSON.stringify(event));
       return state;
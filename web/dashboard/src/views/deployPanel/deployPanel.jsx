@@ -237,6 +237,60 @@ export default class DeployPanel extends Component {
       )
     });
 
+    const helmReleaseWidgets = (state.fluxState.helmReleases || []).map(helmRelease => {
+      let color = "bg-yellow-400";
+
+      if (helmRelease.status.includes("Succeeded")) {
+          color = "bg-green-400";
+      } else if (helmRelease.status.includes("Failed")) {
+          color = "bg-red-400";
+      }
+
+      const title = helmRelease.status + " at " + new Date(helmRelease.lastTransitionTime*1000) + "\n" + helmRelease.statusDesc
+      const dateLabel = formatDistance(helmRelease.lastTransitionTime * 1000, new Date());
+      const nameAndNamespace = helmRelease.namespace + "/" + helmRelease.name;
+      const revision = helmRelease.lastAppliedRevision !== helmRelease.lastAttemptedRevision
+        ? `${helmRelease.lastAppliedRevision} (attempted ${helmRelease.lastAttemptedRevision})`
+        : helmRelease.lastAppliedRevision;
+
+      return (
+        <div key={nameAndNamespace} title={title}>
+          <p>
+            <span className={(color === "bg-yellow-400" && "animate-pulse") + ` h-4 w-4 rounded-full mr-1 relative top-1 inline-block ${color}`} />
+            <span className="font-bold">{nameAndNamespace}</span>: {helmRelease.chart}@{revision} "{helmRelease.statusDesc}" {dateLabel} ago
+          </p>
+        </div>
+      )
+    });
+
+    const sources = [
+      ...(state.fluxState.helmRepositories || []),
+      ...(state.fluxState.ociRepositories || []),
+      ...(state.fluxState.buckets || []),
+    ];
+    const sourceWidgets = sources.map(source => {
+      let color = "bg-yellow-400";
+
+      if (source.status.includes("Succeeded")) {
+          color = "bg-green-400";
+      } else if (source.status.includes("Failed")) {
+          color = "bg-red-400";
+      }
+
+      const title = source.status + " at " + new Date(source.lastTransitionTime*1000) + "\n" + source.url
+      const dateLabel = formatDistance(source.lastTransitionTime * 1000, new Date());
+      const nameAndNamespace = source.namespace + "/" + source.name;
+
+      return (
+        <div key={source.url + nameAndNamespace} title={title}>
+          <p>
+            <span className={(color === "bg-yellow-400" && "animate-pulse") + ` h-4 w-4 rounded-full mr-1 relative top-1 inline-block ${color}`} />
+            <span className="font-bold">{nameAndNamespace}</span>: "{source.statusDesc}" {dateLabel} ago
+          </p>
+        </div>
+      )
+    });
+
     return (
         <div className="w-full truncate text-lg" key={env.name}>
             <p className="font-semibold">{`${env.name.toUpperCase()}`}</p>
@@ -245,6 +299,18 @@ export default class DeployPanel extends Component {
               <div className="ml-2">{gitrepositoryWidgets}</div>
               <h3 className="mt-4 text-lg">Kustomizations:</h3>
               <div className="ml-2">{kustomizationWidgets}</div>
+              {helmReleaseWidgets.length > 0 &&
+              <>
+                <h3 className="mt-4 text-lg">Helm Releases:</h3>
+                <div className="ml-2">{helmReleaseWidgets}</div>
+              </>
+              }
+              {sourceWidgets.length > 0 &&
+              <>
+                <h3 className="mt-4 text-lg">Other sources:</h3>
+                <div className="ml-2">{sourceWidgets}</div>
+              </>
+              }
             </div>
         </div>
     );
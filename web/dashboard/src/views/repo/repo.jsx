@@ -625,7 +625,8 @@ function envsForRepoFilteredBySearchFilter(envs, connectedAgents, repoName, sear
     filteredEnvs[env.name] = {
       name: env.name,
       builtIn: env.builtIn,
-      isOnline: isOnline(connectedAgents, env)
+      isOnline: isOnline(connectedAgents, env),
+      drifts: connectedAgents[env.name]?.drifts ?? []
     };
 
     // find all stacks that belong to this repo